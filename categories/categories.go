@@ -28,6 +28,9 @@ const (
 	// Generators is for modules, which generate something (random values, etc..)
 	Generators = "Generators"
 
+	// Network is for modules, which diagnose networks and hosts
+	Network = "Network"
+
 	// Execute is for modules, which main purpose is to execute another program
 	Execute = "Execute"
 )
@@ -358,7 +358,15 @@ func (a *App) RunAsSubcommand(ctx *Context, parentCommand *Command) (err error)
 		return err
 	}
 
-	err = parseIter(set, a, ctx.Args().Tail(), ctx.shellComplete)
+	tail := ctx.Args().Tail()
+	err = parseIter(set, a, append([]string(nil), tail...), ctx.shellComplete)
+	// Flags after positional arguments belong to the parent command, unless the first argument is a subcommand
+	if err == nil && parentCommand.InterspersedFlags && set.NArg() > 0 && a.Command(set.Arg(0)) == nil {
+		if set, err = a.newFlagSet(); err != nil {
+			return err
+		}
+		err = parseInterspersed(set, a, tail, ctx.shellComplete)
+	}
 	nerr := normalizeFlags(a.Flags, set)
 	newContext := NewContext(a, set, ctx)
 	a.category = parentCommand.Category
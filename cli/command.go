@@ -85,6 +85,9 @@ type Command struct {
 	// single-character bool arguments into one
 	// i.e. foobar -o -v -> foobar -ov
 	UseShortOptionHandling bool
+	// Boolean to parse flags, which are placed after positional arguments
+	// i.e. foobar arg -o -> foobar -o arg
+	InterspersedFlags bool
 
 	// Full name of command for help, defaults to full command name, including parent commands.
 	HelpName        string
@@ -331,7 +334,11 @@ func (c *Command) parseFlags(args Args, shellComplete bool) (*flag.FlagSet, erro
 		return set, set.Parse(append([]string{"--"}, args.Tail()...))
 	}
 
-	err = parseIter(set, c, args.Tail(), shellComplete)
+	if c.InterspersedFlags {
+		err = parseInterspersed(set, c, args.Tail(), shellComplete)
+	} else {
+		err = parseIter(set, c, args.Tail(), shellComplete)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -67,6 +67,44 @@ func parseIter(set *flag.FlagSet, ip iterativeParser, args []string, shellComple
 	}
 }
 
+// parseInterspersed parses flags, which are placed after positional arguments (e.g. "dops query '.a' -i file.json").
+// All arguments after "--" are treated as positional arguments.
+// The flags of all rounds are parsed again at once, because parseIter replaces the flag set when it splits short options.
+func parseInterspersed(set *flag.FlagSet, ip iterativeParser, args []string, shellComplete bool) error {
+	var flags, positional []string
+
+	for len(args) > 0 {
+		round, err := ip.newFlagSet()
+		if err != nil {
+			return err
+		}
+		// parseIter changes the arguments in place, when it splits short options
+		if err := parseIter(round, ip, append([]string(nil), args...), shellComplete); err != nil {
+			return err
+		}
+
+		// The remaining arguments are the same, no matter if short options were split before them
+		rest := round.Args()
+		consumed := len(args) - len(rest)
+
+		// The flag package consumes the terminator, so check if it was right before the remaining arguments
+		if consumed > 0 && args[consumed-1] == "--" {
+			flags = append(flags, args[:consumed-1]...)
+			positional = append(positional, rest...)
+			break
+		}
+
+		flags = append(flags, args[:consumed]...)
+		if len(rest) == 0 {
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+
+	return parseIter(set, ip, append(append(flags, "--"), positional...), shellComplete)
+}
+
 func splitShortOptions(set *flag.FlagSet, arg string) []string {
 	shortFlagsExist := func(s string) bool {
 		for _, c := range s[1:] {
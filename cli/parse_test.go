@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseCommand(t *testing.T, interspersed bool, arguments ...string) (map[string]bool, []string, error) {
+	t.Helper()
+	c := &Command{
+		Name:                   "test",
+		UseShortOptionHandling: true,
+		InterspersedFlags:      interspersed,
+		Flags: []Flag{
+			&BoolFlag{Name: "raw", Aliases: []string{"r"}},
+			&BoolFlag{Name: "compact", Aliases: []string{"c"}},
+			&BoolFlag{Name: "sort", Aliases: []string{"S"}},
+			&StringFlag{Name: "input", Aliases: []string{"i"}},
+		},
+	}
+	a := args(append([]string{"test"}, arguments...))
+	set, err := c.parseFlags(&a, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	bools := map[string]bool{}
+	for _, name := range []string{"raw", "compact", "sort"} {
+		bools[name] = set.Lookup(name).Value.String() == "true"
+	}
+	return bools, set.Args(), nil
+}
+
+func TestParseInterspersed(t *testing.T) {
+	tests := []struct {
+		args       []string
+		bools      map[string]bool
+		positional []string
+	}{
+		{[]string{"-r", ".a", "-cS"}, map[string]bool{"raw": true, "compact": true, "sort": true}, []string{".a"}},
+		{[]string{"-cS", ".a", "-r"}, map[string]bool{"raw": true, "compact": true, "sort": true}, []string{".a"}},
+		{[]string{".a", "-i", "file", "b", "-c"}, map[string]bool{"raw": false, "compact": true, "sort": false}, []string{".a", "b"}},
+		{[]string{".a", "--", "-r", "-c"}, map[string]bool{"raw": false, "compact": false, "sort": false}, []string{".a", "-r", "-c"}},
+	}
+	for _, tt := range tests {
+		bools, positional, err := parseCommand(t, true, tt.args...)
+		if err != nil {
+			t.Errorf("%v: %v", tt.args, err)
+			continue
+		}
+		if !reflect.DeepEqual(bools, tt.bools) || !reflect.DeepEqual(positional, tt.positional) {
+			t.Errorf("%v: got %v %v, want %v %v", tt.args, bools, positional, tt.bools, tt.positional)
+		}
+	}
+}
+
+func TestParseFlagsBeforeArguments(t *testing.T) {
+	bools, positional, err := parseCommand(t, false, "-rc", "foo", "-bar", "-S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bools["raw"] || !bools["compact"] || bools["sort"] || !reflect.DeepEqual(positional, []string{"foo", "-bar", "-S"}) {
+		t.Errorf("got %v %v", bools, positional)
+	}
+}
+
+func TestParseInterspersedWithSubcommands(t *testing.T) {
+	var got []string
+	record := func(c *Context) error {
+		got = append(got, c.Command.Name, c.String("input"), c.Args().First())
+		return nil
+	}
+	app := &App{Commands: []*Command{{
+		Name:              "test",
+		InterspersedFlags: true,
+		Flags:             []Flag{&StringFlag{Name: "input", Aliases: []string{"i"}}},
+		Action:            record,
+		Subcommands: []*Command{{
+			Name:              "sub",
+			InterspersedFlags: true,
+			Flags:             []Flag{&StringFlag{Name: "input", Aliases: []string{"i"}}},
+			Action:            record,
+		}},
+	}}}
+
+	tests := map[string][]string{
+		"test a -i file":     {"", "file", "a"},
+		"test sub b -i file": {"sub", "file", "b"},
+	}
+	for args, want := range tests {
+		got = nil
+		if err := app.Run(append([]string{"dops"}, strings.Fields(args)...)); err != nil {
+			t.Errorf("%s: %v", args, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %q, want %q", args, got, want)
+		}
+	}
+}
@@ -15,7 +15,6 @@ import (
 
 	"github.com/dops-cli/dops/global/options"
 	"github.com/dops-cli/dops/theme"
-	"github.com/dops-cli/dops/utils"
 )
 
 // AppHelpTemplate is the text template for the Default help topic.
@@ -334,7 +333,16 @@ func generateCastFile(command string) string {
 		fmt.Printf("\n\nOutput:\n%v\n\nErrors:\n%v\n", out.String(), stderr.String())
 	}
 
-	utils.WriteFile("./example_casts/"+filename+".json", []byte("[5, \"o\", \"\\r\\nrestarting...\\r\\n\"]"), true)
+	f, err := os.OpenFile("./example_casts/"+filename+".json", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err == nil {
+		_, err = f.WriteString("[5, \"o\", \"\\r\\nrestarting...\\r\\n\"]")
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fmt.Println(err)
+	}
 
 	return filename
 }
@@ -6,6 +6,8 @@ require (
 	github.com/BurntSushi/toml v0.3.1
 	github.com/VividCortex/ewma v1.1.1
 	github.com/acarl005/stripansi v0.0.0-20180116102854-5a71ef0e047d
+	github.com/andybalholm/cascadia v1.1.0
+	github.com/antchfx/htmlquery v1.2.3
 	github.com/c-bata/go-prompt v0.2.5
 	github.com/cpuguy83/go-md2man/v2 v2.0.0
 	github.com/flopp/go-findfont v0.0.0-20200805110358-089b91d05de8
@@ -13,14 +15,17 @@ require (
 	github.com/gdamore/tcell v1.4.0
 	github.com/go-ping/ping v0.0.0-20200914062013-800dd84e47f2
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
+	github.com/klauspost/compress v1.11.1
 	github.com/mattn/go-colorable v0.1.8
 	github.com/mattn/go-isatty v0.0.12
 	github.com/mattn/go-runewidth v0.0.9
 	github.com/pkg/browser v0.0.0-20180916011732-0a3d74bf9ce4
 	github.com/pterm/pterm v0.5.1
+	github.com/ulikunitz/xz v0.5.8
+	golang.org/x/crypto v0.0.0-20201002170205-7f63de1d35b0
 	golang.org/x/image v0.0.0-20200927104501-e162460cd6b5 // indirect
-	golang.org/x/net v0.0.0-20201002202402-0a1ea396d57c // indirect
-	golang.org/x/sys v0.0.0-20201009025420-dfb3f7c4e634
-	golang.org/x/text v0.3.3 // indirect
+	golang.org/x/net v0.0.0-20210330230544-e57232859fb2
+	golang.org/x/sys v0.0.0-20210330210617-4fbd30eecc44
 	gopkg.in/yaml.v2 v2.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,296 @@
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/global/options"
+	"github.com/dops-cli/dops/progressbar"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "archive",
+			Usage: "Creates, lists and extracts zip, tar, tar.gz, tar.zst and tar.xz archives",
+			Description: `Archive creates, lists and extracts archives without tar, unzip or other tools installed.
+The format of existing archives is detected by their magic bytes, so the file extension doesn't matter.
+Permissions, modification times and symbolic links are kept.`,
+			Category: categories.IO,
+			Subcommands: []*cli.Command{
+				createCommand(),
+				listCommand(),
+				extractCommand(),
+			},
+		},
+	}
+}
+
+func quietFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:    "quiet",
+		Aliases: []string{"q"},
+		Usage:   "Does not show a progress bar",
+	}
+}
+
+// progress shows a progress bar, unless --quiet is set. The returned function finishes the bar.
+// With --raw or --ci the bar isn't rendered and would never finish, so none is created.
+func progress(c *cli.Context, total int64) (func(n int64), func()) {
+	if c.Bool("quiet") || options.Raw || options.CI || total <= 0 {
+		return nil, func() {}
+	}
+	bar := say.ProgressBar(total)
+	return bar.IncrInt64, func() { finish(bar) }
+}
+
+func finish(bar *progressbar.Bar) {
+	bar.SetTotal(0, true)
+	bar.GetContainer().Wait()
+}
+
+func createCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "create",
+		Usage:     "Creates an archive of files and directories",
+		ArgsUsage: "ARCHIVE PATH...",
+		// Flags are also accepted after the paths
+		InterspersedFlags: true,
+		Description: `Create writes the files and directories into the archive. The format is detected by the extension of the archive, unless --format is set.
+Directories are added with their name, unless the path ends with a slash or is '.', then only their contents are added.
+Excluded patterns are matched against the names in the archive and their base names, so '*.log' excludes log files in all directories.
+The archive itself is never added.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Create a tar.gz archive of a directory without log files",
+				Usage:            "dops archive create out.tar.gz ./dir --exclude '*.log'",
+			},
+			{
+				ShortDescription: "Create a zip archive of the contents of the current directory",
+				Usage:            "dops archive create --exclude .git --exclude node_modules ../release.zip .",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() < 2 {
+				return errors.New("an archive and at least one path are needed")
+			}
+			name := c.Args().First()
+			paths := c.Args().Tail()
+
+			format := c.Option("format")
+			if format == "" {
+				var err error
+				if format, err = FormatFromName(name); err != nil {
+					return fmt.Errorf("%w, please set --format", err)
+				}
+			}
+
+			sources, size, err := collect(paths, CreateOptions{Exclude: c.StringSlice("exclude"), Skip: name})
+			if err != nil {
+				return err
+			}
+
+			flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+			if c.Bool("force") {
+				flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+			}
+			f, err := os.OpenFile(name, flags, 0644)
+			if os.IsExist(err) {
+				return fmt.Errorf("%s already exists, use --force to replace it", name)
+			}
+			if err != nil {
+				return err
+			}
+
+			increment, done := progress(c, size)
+			count, err := write(f, format, sources, increment)
+			done()
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(name)
+				return err
+			}
+			say.Success(fmt.Sprintf("Created %s with %d entries (%s)", name, count, formatSize(size)))
+			return nil
+		},
+		Flags: []cli.Flag{
+			&cli.OptionFlag{
+				Name:        "format",
+				Aliases:     []string{"f"},
+				Usage:       "Creates the archive as `FORMAT`",
+				Options:     Formats,
+				DefaultText: "detected by extension",
+			},
+			&cli.StringSliceFlag{
+				Name:    "exclude",
+				Aliases: []string{"e"},
+				Usage:   "Skips files and directories matching the `PATTERN`. Can be used multiple times.",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Replaces an existing archive",
+			},
+			quietFlag(),
+		},
+	}
+}
+
+func listCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "list",
+		Aliases:   []string{"ls"},
+		Usage:     "Lists the contents of an archive",
+		ArgsUsage: "ARCHIVE",
+		// Flags are also accepted after the archive
+		InterspersedFlags: true,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "List the contents of an archive",
+				Usage:            "dops archive list release.tar.zst",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			name := c.Args().First()
+			if name == "" {
+				return errors.New("no archive given")
+			}
+			entries, err := List(name)
+			if err != nil {
+				return err
+			}
+
+			if c.Bool("json") {
+				type entryJSON struct {
+					Entry
+					Mode string `json:"mode"`
+				}
+				out := make([]entryJSON, len(entries))
+				for i, e := range entries {
+					out[i] = entryJSON{e, e.Mode.String()}
+				}
+				data, err := json.MarshalIndent(out, "", "  ")
+				if err != nil {
+					return err
+				}
+				return utils.WriteOutput(c, []string{string(data)})
+			}
+
+			var total int64
+			data := [][]string{{"Mode", "Size", "Modified", "Name"}}
+			for _, e := range entries {
+				name := e.Name
+				switch {
+				case e.Hardlink:
+					name += " => " + e.Link
+				case e.Link != "":
+					name += " -> " + e.Link
+				}
+				size := ""
+				if e.Mode.IsRegular() && !e.Hardlink {
+					size = formatSize(e.Size)
+					total += e.Size
+				}
+				data = append(data, []string{e.Mode.String(), size, e.ModTime.Local().Format("2006-01-02 15:04"), name})
+			}
+			table := say.Table(data, true, color.NewColorizer(c.String("output") == ""))
+			return utils.WriteOutput(c, []string{table, "", strconv.Itoa(len(entries)) + " entries, " + formatSize(total)})
+		},
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:    "json",
+				Aliases: []string{"j"},
+				Usage:   "Outputs the entries as JSON",
+			},
+		}, utils.OutputFlags()...),
+	}
+}
+
+func extractCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "extract",
+		Aliases:   []string{"x"},
+		Usage:     "Extracts an archive",
+		ArgsUsage: "ARCHIVE [DIRECTORY]",
+		// Flags are also accepted after the archive and the directory
+		InterspersedFlags: true,
+		Description: `Extract extracts the archive into the directory, which is the current directory by default.
+Entries with absolute paths or '..', which would be written outside of the directory, are rejected, as well as links pointing outside of it.
+Extracted symbolic links are never followed. Existing files are only replaced with --force.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Extract an archive into the current directory",
+				Usage:            "dops archive extract release.zip",
+			},
+			{
+				ShortDescription: "Extract an archive without its top-level directory",
+				Usage:            "dops archive extract --strip 1 node-v14.13.1-linux-x64.tar.xz /opt/node",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			name := c.Args().First()
+			if name == "" {
+				return errors.New("no archive given")
+			}
+			dest := c.Args().Get(1)
+			if dest == "" {
+				dest = "."
+			}
+			info, err := os.Stat(name)
+			if err != nil {
+				return err
+			}
+
+			start := time.Now()
+			increment, done := progress(c, info.Size())
+			count, err := Extract(name, dest, ExtractOptions{Strip: c.Int("strip"), Force: c.Bool("force"), Progress: increment})
+			done()
+			if err != nil {
+				return err
+			}
+			say.Success(fmt.Sprintf("Extracted %d entries into %s in %s", count, dest, time.Since(start).Round(time.Millisecond)))
+			return nil
+		},
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:    "strip",
+				Aliases: []string{"s"},
+				Usage:   "Removes `N` leading directories from the names",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Replaces existing files",
+			},
+			quietFlag(),
+		},
+	}
+}
+
+// formatSize formats a number of bytes like 1.2MB
+func formatSize(n int64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	size := float64(n)
+	i := 0
+	for size >= 1024 && i < len(units)-1 {
+		size /= 1024
+		i++
+	}
+	if i == 0 {
+		return strconv.FormatInt(n, 10) + units[i]
+	}
+	return strconv.FormatFloat(size, 'f', 1, 64) + units[i]
+}
@@ -0,0 +1,179 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/global/options"
+)
+
+func TestCreateAndExtract(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := os.MkdirAll(filepath.Join(src, "sub"), 0750); err != nil {
+		t.Fatal(err)
+	}
+	_ = ioutil.WriteFile(filepath.Join(src, "run.sh"), []byte("#!/bin/sh"), 0755)
+	_ = ioutil.WriteFile(filepath.Join(src, "sub", "data.txt"), []byte("data"), 0600)
+	_ = ioutil.WriteFile(filepath.Join(src, "sub", "debug.log"), []byte("log"), 0644)
+	_ = os.Symlink("sub/data.txt", filepath.Join(src, "link"))
+
+	for _, format := range Formats {
+		name := filepath.Join(dir, "out."+format)
+		var buf bytes.Buffer
+		count, err := Create(&buf, format, []string{src}, CreateOptions{Exclude: []string{"*.log"}})
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if count != 5 {
+			t.Errorf("%s: got %d entries, want 5", format, count)
+		}
+		if err := ioutil.WriteFile(name, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if detected, err := DetectFormatOfFile(name); err != nil || detected != format {
+			t.Errorf("%s: detected %s, %v", format, detected, err)
+		}
+
+		entries, err := List(name)
+		if err != nil || len(entries) != 5 || entries[0].Name != "src/" {
+			t.Errorf("%s: got %+v, %v", format, entries, err)
+		}
+
+		dest := filepath.Join(dir, format)
+		if _, err := Extract(name, dest, ExtractOptions{Strip: 1}); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if data, err := ioutil.ReadFile(filepath.Join(dest, "link")); err != nil || string(data) != "data" {
+			t.Errorf("%s: got %q, %v through the link", format, data, err)
+		}
+		for file, mode := range map[string]os.FileMode{"run.sh": 0755, "sub": 0750 | os.ModeDir, "sub/data.txt": 0600} {
+			if info, err := os.Stat(filepath.Join(dest, file)); err != nil || info.Mode() != mode {
+				t.Errorf("%s: %s has mode %v, want %v (%v)", format, file, info.Mode(), mode, err)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dest, "sub", "debug.log")); !os.IsNotExist(err) {
+			t.Errorf("%s: excluded file was added", format)
+		}
+
+		if _, err := Extract(name, dest, ExtractOptions{Strip: 1}); err == nil {
+			t.Errorf("%s: existing files were replaced without force", format)
+		}
+		if _, err := Extract(name, dest, ExtractOptions{Strip: 1, Force: true}); err != nil {
+			t.Errorf("%s: %v", format, err)
+		}
+	}
+}
+
+func TestExtractRejectsTraversal(t *testing.T) {
+	tests := map[string][]tar.Header{
+		"parent directory": {{Name: "../escape", Typeflag: tar.TypeReg, Mode: 0644}},
+		"absolute path":    {{Name: "/tmp/escape", Typeflag: tar.TypeReg, Mode: 0644}},
+		"absolute link":    {{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc"}},
+		"relative link":    {{Name: "dir/link", Typeflag: tar.TypeSymlink, Linkname: "../../etc"}},
+		"through a link": {
+			{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "a/a/escape", Typeflag: tar.TypeReg, Mode: 0644},
+		},
+		"hard link": {{Name: "passwd", Typeflag: tar.TypeLink, Linkname: "../../etc/passwd"}},
+	}
+	for name, headers := range tests {
+		dir := t.TempDir()
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		for _, h := range headers {
+			h := h
+			if err := tw.WriteHeader(&h); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tw.Close()
+		archive := filepath.Join(dir, "evil.tar")
+		_ = ioutil.WriteFile(archive, buf.Bytes(), 0644)
+
+		if _, err := Extract(archive, filepath.Join(dir, "out"), ExtractOptions{}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if _, err := os.Lstat(filepath.Join(dir, "escape")); err == nil {
+			t.Errorf("%s: a file was written outside", name)
+		}
+	}
+}
+
+func TestFormatFromName(t *testing.T) {
+	for name, want := range map[string]string{"a.zip": Zip, "a.TGZ": TarGz, "a.tar.zst": TarZst, "a.tar": Tar, "a.tar.bz2": TarBz2} {
+		if got, err := FormatFromName(name); err != nil || got != want {
+			t.Errorf("%s: got %s, %v", name, got, err)
+		}
+	}
+	if _, err := FormatFromName("a.rar"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestCreateInRawMode(t *testing.T) {
+	// The progress bar isn't rendered in raw mode, waiting for it would never return
+	options.Raw = true
+	defer func() { options.Raw = false }()
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "data.txt")
+	if err := ioutil.WriteFile(src, []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, "out.tar.gz")
+
+	app := &cli.App{Commands: Module{}.GetModuleCommands()}
+	done := make(chan error, 1)
+	go func() { done <- app.Run([]string{"dops", "archive", "create", name, src}) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("archive create did not return in raw mode")
+	}
+	if entries, err := List(name); err != nil || len(entries) != 1 {
+		t.Errorf("got %+v, %v", entries, err)
+	}
+}
+
+func TestCommandsWithFlagsAfterArguments(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "dir")
+	if err := os.Mkdir(src, 0750); err != nil {
+		t.Fatal(err)
+	}
+	_ = ioutil.WriteFile(filepath.Join(src, "data.txt"), []byte("data"), 0600)
+	_ = ioutil.WriteFile(filepath.Join(src, "debug.log"), []byte("log"), 0600)
+	name, listing, dest := filepath.Join(dir, "out.tar.gz"), filepath.Join(dir, "listing.json"), filepath.Join(dir, "dest")
+
+	// The documented argument order, with the flags after the arguments
+	for _, args := range [][]string{
+		{"create", name, src, "--exclude", "*.log", "-q"},
+		{"list", name, "--json", "-o", listing},
+		{"extract", name, dest, "--strip", "1", "-q"},
+	} {
+		app := &cli.App{Commands: Module{}.GetModuleCommands()}
+		if err := app.Run(append([]string{"dops", "archive"}, args...)); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+
+	if entries, err := List(name); err != nil || len(entries) != 2 {
+		t.Errorf("got %+v, %v, want the directory and data.txt", entries, err)
+	}
+	if _, err := os.Stat(listing); err != nil {
+		t.Error(err)
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(dest, "data.txt")); err != nil || string(data) != "data" {
+		t.Errorf("got %q, %v", data, err)
+	}
+}
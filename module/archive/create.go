@@ -0,0 +1,237 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// source is a file or directory, which is added to an archive
+type source struct {
+	path string
+	// name is the slash separated path in the archive
+	name string
+	info os.FileInfo
+}
+
+// CreateOptions change how archives are created
+type CreateOptions struct {
+	// Exclude contains patterns like *.log or build/*, which are matched against the names and the paths in the archive
+	Exclude []string
+	// Skip is a file, which is never added, like the archive itself
+	Skip string
+	// Progress is called with the number of bytes, which were added
+	Progress func(n int64)
+}
+
+// excluded checks, if the name in the archive or its base name matches a pattern
+func excluded(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(filepath.ToSlash(pattern), "/")
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(name)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// collect walks the sources. The contents of directories ending with a slash, and of '.', are added without the directory name, like in rsync.
+func collect(paths []string, options CreateOptions) ([]source, int64, error) {
+	skip := ""
+	if options.Skip != "" {
+		skip, _ = filepath.Abs(options.Skip)
+	}
+
+	var sources []source
+	var size int64
+	for _, root := range paths {
+		info, err := os.Lstat(root)
+		if err != nil {
+			return nil, 0, err
+		}
+		prefix := filepath.Base(filepath.Clean(root))
+		if info.IsDir() && (strings.HasSuffix(root, "/") || strings.HasSuffix(root, string(filepath.Separator)) || filepath.Clean(root) == ".") {
+			prefix = ""
+		}
+		if prefix == ".." || filepath.IsAbs(prefix) || prefix == string(filepath.Separator) {
+			return nil, 0, fmt.Errorf("%s has no name, add a slash to add its contents", root)
+		}
+
+		err = filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			name := path.Join(prefix, filepath.ToSlash(rel))
+			if name == "." {
+				return nil
+			}
+			abs, _ := filepath.Abs(p)
+			if excluded(name, options.Exclude) || abs == skip {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			// Devices, sockets and named pipes are skipped
+			if !info.Mode().IsRegular() && !info.IsDir() && info.Mode()&os.ModeSymlink == 0 {
+				return nil
+			}
+			sources = append(sources, source{path: p, name: name, info: info})
+			if info.Mode().IsRegular() {
+				size += info.Size()
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return sources, size, nil
+}
+
+// progressWriter reports the number of written bytes
+type progressWriter struct {
+	io.Writer
+	progress func(n int64)
+}
+
+func (w progressWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	if w.progress != nil {
+		w.progress(int64(n))
+	}
+	return n, err
+}
+
+func copyFile(w io.Writer, s source, progress func(n int64)) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(progressWriter{w, progress}, f)
+	return err
+}
+
+// nopCloser turns a writer into a WriteCloser, which doesn't close it
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func compress(w io.Writer, format string) (io.WriteCloser, error) {
+	switch format {
+	case Tar:
+		return nopCloser{w}, nil
+	case TarGz:
+		return gzip.NewWriter(w), nil
+	case TarZst:
+		return zstd.NewWriter(w)
+	case TarXz:
+		return xz.NewWriter(w)
+	}
+	return nil, fmt.Errorf("archives can't be created as %s", format)
+}
+
+// Create writes the files and directories of paths into an archive. It returns the number of added entries.
+func Create(w io.Writer, format string, paths []string, options CreateOptions) (int, error) {
+	sources, _, err := collect(paths, options)
+	if err != nil {
+		return 0, err
+	}
+	return write(w, format, sources, options.Progress)
+}
+
+func write(w io.Writer, format string, sources []source, progress func(n int64)) (int, error) {
+	if format == Zip {
+		return len(sources), writeZip(w, sources, progress)
+	}
+
+	c, err := compress(w, format)
+	if err != nil {
+		return 0, err
+	}
+	tw := tar.NewWriter(c)
+	for _, s := range sources {
+		link := ""
+		if s.info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(s.path); err != nil {
+				return 0, err
+			}
+		}
+		header, err := tar.FileInfoHeader(s.info, link)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", s.path, err)
+		}
+		header.Name = s.name
+		if s.info.IsDir() {
+			header.Name += "/"
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return 0, err
+		}
+		if s.info.Mode().IsRegular() {
+			if err := copyFile(tw, s, progress); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return 0, err
+	}
+	return len(sources), c.Close()
+}
+
+func writeZip(w io.Writer, sources []source, progress func(n int64)) error {
+	zw := zip.NewWriter(w)
+	for _, s := range sources {
+		header, err := zip.FileInfoHeader(s.info)
+		if err != nil {
+			return err
+		}
+		header.Name = s.name
+		switch {
+		case s.info.IsDir():
+			header.Name += "/"
+			header.Method = zip.Store
+		case s.info.Mode().IsRegular():
+			header.Method = zip.Deflate
+		}
+		entry, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case s.info.Mode()&os.ModeSymlink != 0:
+			// Symbolic links are stored with their target as content, like Info-ZIP does
+			link, err := os.Readlink(s.path)
+			if err != nil {
+				return err
+			}
+			if _, err := io.WriteString(entry, filepath.ToSlash(link)); err != nil {
+				return err
+			}
+		case s.info.Mode().IsRegular():
+			if err := copyFile(entry, s, progress); err != nil {
+				return err
+			}
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,99 @@
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dops-cli/dops/utils"
+)
+
+// Archive formats
+const (
+	Zip    = "zip"
+	Tar    = "tar"
+	TarGz  = "tar.gz"
+	TarZst = "tar.zst"
+	TarXz  = "tar.xz"
+	// TarBz2 can only be read, because there is no bzip2 writer in the standard library
+	TarBz2 = "tar.bz2"
+)
+
+// Formats contains the formats, which can be created
+var Formats = []string{Zip, Tar, TarGz, TarZst, TarXz}
+
+var extensions = []struct {
+	extension, format string
+}{
+	{".zip", Zip},
+	{".tar.gz", TarGz},
+	{".tgz", TarGz},
+	{".tar.zst", TarZst},
+	{".tzst", TarZst},
+	{".tar.xz", TarXz},
+	{".txz", TarXz},
+	{".tar.bz2", TarBz2},
+	{".tbz2", TarBz2},
+	{".tar", Tar},
+}
+
+// FormatFromName returns the format of an archive by its file extension
+func FormatFromName(name string) (string, error) {
+	lower := strings.ToLower(name)
+	for _, e := range extensions {
+		if strings.HasSuffix(lower, e.extension) {
+			return e.format, nil
+		}
+	}
+	return "", fmt.Errorf("the format of %s could not be detected by its extension", name)
+}
+
+var (
+	zipMagic      = []byte("PK\x03\x04")
+	emptyZipMagic = []byte("PK\x05\x06")
+)
+
+// DetectFormat returns the format of an archive by its magic bytes
+func DetectFormat(r io.Reader) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	head = head[:n]
+
+	if bytes.HasPrefix(head, zipMagic) || bytes.HasPrefix(head, emptyZipMagic) {
+		return Zip, nil
+	}
+	switch utils.DetectCompression(head) {
+	case utils.CompressionGzip:
+		return TarGz, nil
+	case utils.CompressionZstd:
+		return TarZst, nil
+	case utils.CompressionXz:
+		return TarXz, nil
+	case utils.CompressionBzip2:
+		return TarBz2, nil
+	}
+	// The magic of POSIX tar archives is at offset 257, old V7 archives have none
+	if len(head) >= 262 && string(head[257:262]) == "ustar" {
+		return Tar, nil
+	}
+	return "", fmt.Errorf("unknown archive format, only %s and tar.bz2 are supported", strings.Join(Formats, ", "))
+}
+
+// DetectFormatOfFile returns the format of an archive file by its magic bytes
+func DetectFormatOfFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	format, err := DetectFormat(f)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return format, nil
+}
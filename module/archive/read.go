@@ -0,0 +1,319 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dops-cli/dops/utils"
+)
+
+// Entry is a file, directory or link in an archive
+type Entry struct {
+	Name    string      `json:"name"`
+	Size    int64       `json:"size"`
+	Mode    os.FileMode `json:"-"`
+	ModTime time.Time   `json:"modified"`
+	// Link is the target of symbolic and hard links
+	Link string `json:"link,omitempty"`
+	// Hardlink is set, if Link is the name of another entry
+	Hardlink bool `json:"hardlink,omitempty"`
+}
+
+// countingReader reports the number of read bytes
+type countingReader struct {
+	io.Reader
+	progress func(n int64)
+}
+
+func (r countingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if r.progress != nil {
+		r.progress(int64(n))
+	}
+	return n, err
+}
+
+// walk calls fn for every entry of the archive. The content of an entry can only be read in fn.
+// Progress is called with the number of bytes, which were read from the archive file.
+func walk(archive string, progress func(n int64), fn func(Entry, io.Reader) error) error {
+	format, err := DetectFormatOfFile(archive)
+	if err != nil {
+		return err
+	}
+	if format == Zip {
+		return walkZip(archive, progress, fn)
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	r, err := utils.Decompress(ioutil.NopCloser(countingReader{f, progress}))
+	if err != nil {
+		f.Close()
+		return err
+	}
+	defer f.Close()
+	defer r.Close()
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s is no valid %s archive: %w", archive, format, err)
+		}
+		e := Entry{
+			Name:     header.Name,
+			Size:     header.Size,
+			Mode:     header.FileInfo().Mode(),
+			ModTime:  header.ModTime,
+			Link:     header.Linkname,
+			Hardlink: header.Typeflag == tar.TypeLink,
+		}
+		if err := fn(e, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func walkZip(archive string, progress func(n int64), fn func(Entry, io.Reader) error) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("%s is no valid zip archive: %w", archive, err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		e := Entry{Name: f.Name, Size: int64(f.UncompressedSize64), Mode: f.Mode(), ModTime: f.Modified}
+		if e.ModTime.IsZero() {
+			e.ModTime = f.ModTime() //nolint:staticcheck // archives without extended timestamps only have the MS-DOS time
+		}
+		err := func() error {
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			defer rc.Close()
+			if e.Mode&os.ModeSymlink != 0 {
+				link, err := ioutil.ReadAll(io.LimitReader(rc, 4096))
+				if err != nil {
+					return err
+				}
+				e.Link = string(link)
+			}
+			return fn(e, rc)
+		}()
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+		if progress != nil {
+			progress(int64(f.CompressedSize64))
+		}
+	}
+	return nil
+}
+
+// List returns the entries of an archive
+func List(archive string) ([]Entry, error) {
+	var entries []Entry
+	err := walk(archive, nil, func(e Entry, _ io.Reader) error {
+		entries = append(entries, e)
+		return nil
+	})
+	return entries, err
+}
+
+// ExtractOptions change how archives are extracted
+type ExtractOptions struct {
+	// Strip removes the number of leading directories from the names
+	Strip int
+	// Force replaces existing files
+	Force bool
+	// Progress is called with the number of bytes, which were read from the archive file
+	Progress func(n int64)
+}
+
+// target returns the path of an entry in dest. Entries, which would be written outside of dest, are rejected.
+func target(dest, name string, strip int) (string, error) {
+	name = strings.ReplaceAll(name, `\`, "/")
+	clean := path.Clean(name)
+	if path.IsAbs(clean) || filepath.VolumeName(clean) != "" || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("%q would be extracted outside of %s", name, dest)
+	}
+	parts := strings.Split(clean, "/")
+	if clean == "." || len(parts) <= strip {
+		return "", nil
+	}
+	return filepath.Join(dest, filepath.FromSlash(strings.Join(parts[strip:], "/"))), nil
+}
+
+// checkParents rejects paths, which would be written through a symbolic link in dest.
+// Extracted links are never followed, so that an archive can't create a link to a directory outside of dest and write into it.
+func checkParents(dest, p string) error {
+	rel, err := filepath.Rel(dest, p)
+	if err != nil {
+		return err
+	}
+	current := dest
+	parts := strings.Split(rel, string(filepath.Separator))
+	for _, part := range parts[:len(parts)-1] {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%s would be written through the symbolic link %s", p, current)
+		}
+	}
+	return nil
+}
+
+// Extract extracts an archive into dest. It returns the number of extracted entries.
+// Permissions and modification times are kept. Devices and named pipes are skipped.
+func Extract(archive, dest string, options ExtractOptions) (int, error) {
+	dest, err := filepath.Abs(dest)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return 0, err
+	}
+	type dir struct {
+		path    string
+		mode    os.FileMode
+		modTime time.Time
+	}
+	var dirs []dir
+	count := 0
+
+	err = walk(archive, options.Progress, func(e Entry, r io.Reader) error {
+		p, err := target(dest, e.Name, options.Strip)
+		if err != nil || p == "" {
+			return err
+		}
+		if err := checkParents(dest, p); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return err
+		}
+
+		switch {
+		case e.Mode.IsDir():
+			if info, err := os.Lstat(p); err == nil && !info.IsDir() {
+				return fmt.Errorf("%s already exists and is no directory", p)
+			}
+			if err := os.Mkdir(p, 0755); err != nil && !os.IsExist(err) {
+				return err
+			}
+			// The permissions of directories are set at the end, so that read-only directories can be filled first
+			dirs = append(dirs, dir{p, e.Mode.Perm(), e.ModTime})
+		case e.Hardlink:
+			linked, err := target(dest, e.Link, options.Strip)
+			if err != nil || linked == "" {
+				return fmt.Errorf("the hard link %s points to %q outside of %s", e.Name, e.Link, dest)
+			}
+			if err := checkParents(dest, linked); err != nil {
+				return err
+			}
+			if err := replace(p, options.Force); err != nil {
+				return err
+			}
+			if err := os.Link(linked, p); err != nil {
+				return err
+			}
+		case e.Mode&os.ModeSymlink != 0:
+			// Targets are checked, so that extracted links don't point outside of dest
+			resolved := e.Link
+			if !filepath.IsAbs(resolved) {
+				resolved = filepath.Join(filepath.Dir(p), filepath.FromSlash(e.Link))
+			}
+			if rel, err := filepath.Rel(dest, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return fmt.Errorf("the symbolic link %s points to %q outside of %s", e.Name, e.Link, dest)
+			}
+			if err := replace(p, options.Force); err != nil {
+				return err
+			}
+			if err := os.Symlink(filepath.FromSlash(e.Link), p); err != nil {
+				return err
+			}
+		case e.Mode.IsRegular():
+			if err := replace(p, options.Force); err != nil {
+				return err
+			}
+			if err := writeFile(p, r, e.Mode.Perm()); err != nil {
+				return err
+			}
+			if err := os.Chtimes(p, e.ModTime, e.ModTime); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	// Nested directories are changed first, so that their parents can still be written
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i].path) > len(dirs[j].path) })
+	for _, d := range dirs {
+		if err := os.Chmod(d.path, d.mode); err != nil {
+			return count, err
+		}
+		if err := os.Chtimes(d.path, d.modTime, d.modTime); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// replace removes an existing file, if force is set. Directories are never removed.
+func replace(p string, force bool) error {
+	info, err := os.Lstat(p)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !force || info.IsDir() {
+		return fmt.Errorf("%s already exists, use --force to replace it", p)
+	}
+	return os.Remove(p)
+}
+
+func writeFile(p string, r io.Reader, mode os.FileMode) error {
+	// O_EXCL makes sure, that no symbolic link, which was created in the meantime, is followed
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// The mode is set explicitly, because the umask would change it otherwise
+	return os.Chmod(p, mode)
+}
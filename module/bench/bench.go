@@ -0,0 +1,399 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/global/options"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:      "bench",
+			Aliases:   []string{"loadtest"},
+			Usage:     "Load tests HTTP endpoints",
+			ArgsUsage: "URL",
+			// Flags are also accepted after the URL
+			InterspersedFlags: true,
+			Description: `Bench sends requests with concurrent workers and reports the throughput, latency percentiles, status codes and errors.
+It sends --requests requests, or as many requests as possible for --duration. Ctrl+C stops the benchmark and reports the requests sent so far.
+Redirects are not followed, so that the latency of the endpoint itself is measured.
+
+The latencies can be exported with --hdr as JSON histogram, which contains the percentiles and all recorded buckets with 3 significant digits.
+Exported runs can be compared with 'dops bench compare'.`,
+			Category: categories.Web,
+			Examples: []cli.Example{
+				{
+					ShortDescription: "Send 10000 requests with 50 concurrent workers",
+					Usage:            "dops bench -c 50 -n 10000 https://example.com/path",
+				},
+				{
+					ShortDescription: "Send requests for 30 seconds and export the latencies",
+					Usage:            "dops bench -c 50 -d 30s --hdr before.json https://example.com/path",
+				},
+				{
+					ShortDescription: "POST a JSON body with at most 100 requests per second",
+					Usage:            "dops bench -r 100 -H 'Content-Type: application/json' -b @body.json https://example.com/api",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return errors.New("expected exactly one URL")
+				}
+				o, err := optionsFromFlags(c)
+				if err != nil {
+					return err
+				}
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				interrupt := make(chan os.Signal, 1)
+				signal.Notify(interrupt, os.Interrupt)
+				defer signal.Stop(interrupt)
+				go func() {
+					select {
+					case <-interrupt:
+						cancel()
+					case <-ctx.Done():
+					}
+				}()
+
+				// The bar is not rendered with --raw or --ci, so it would never finish
+				stopProgress := func() {}
+				if !c.Bool("quiet") && !options.Raw && !options.CI {
+					stopProgress = progress(ctx, &o)
+				}
+				result, err := Run(ctx, o)
+				stopProgress()
+				if err != nil {
+					return err
+				}
+
+				if path := c.Path("hdr"); path != "" {
+					data, err := json.Marshal(result.Latency)
+					if err != nil {
+						return err
+					}
+					if err := utils.WriteFile(path, append(data, '\n'), false); err != nil {
+						return err
+					}
+				}
+
+				if c.Bool("json") {
+					data, err := json.MarshalIndent(toJSON(o, result), "", "  ")
+					if err != nil {
+						return err
+					}
+					return utils.WriteOutput(c, []string{string(data)})
+				}
+				return utils.WriteOutput(c, []string{report(o, result, color.NewColorizer(c.String("output") == ""))})
+			},
+			Subcommands: []*cli.Command{
+				Compare(),
+			},
+			Flags: append([]cli.Flag{
+				&cli.IntFlag{
+					Name:    "concurrency",
+					Aliases: []string{"c"},
+					Usage:   "Sends requests with `N` concurrent workers",
+					Value:   10,
+				},
+				&cli.IntFlag{
+					Name:    "requests",
+					Aliases: []string{"n"},
+					Usage:   "Sends `N` requests, defaults to 200 if no --duration is set",
+				},
+				&cli.DurationFlag{
+					Name:    "duration",
+					Aliases: []string{"d"},
+					Usage:   "Sends requests for `DURATION`",
+				},
+				&cli.Float64Flag{
+					Name:    "rate",
+					Aliases: []string{"r"},
+					Usage:   "Sends at most `N` requests per second, 0 is unlimited",
+				},
+				&cli.StringFlag{
+					Name:    "method",
+					Aliases: []string{"m"},
+					Usage:   "Uses the HTTP `METHOD`, defaults to GET, or POST if a body is set",
+				},
+				&cli.StringSliceFlag{
+					Name:    "header",
+					Aliases: []string{"H"},
+					Usage:   "Adds a `HEADER` like 'Name: value', can be used multiple times",
+				},
+				&cli.StringFlag{
+					Name:    "body",
+					Aliases: []string{"b"},
+					Usage:   "Sends `DATA` as body, '@FILE' reads the body from a file",
+				},
+				&cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "Cancels requests after `DURATION`",
+					Value: 30 * time.Second,
+				},
+				&cli.BoolFlag{
+					Name:    "insecure",
+					Aliases: []string{"k"},
+					Usage:   "Does not verify TLS certificates",
+				},
+				&cli.PathFlag{
+					Name:      "hdr",
+					Usage:     "Exports the latency histogram as JSON to `FILE`",
+					TakesFile: true,
+				},
+				&cli.BoolFlag{
+					Name:    "json",
+					Aliases: []string{"j"},
+					Usage:   "Outputs the report as JSON",
+				},
+				&cli.BoolFlag{
+					Name:    "quiet",
+					Aliases: []string{"q"},
+					Usage:   "Does not show a progress bar",
+				},
+			}, utils.OutputFlags()...),
+		},
+	}
+}
+
+// optionsFromFlags returns the benchmark options, which are set by the flags of the bench command
+func optionsFromFlags(c *cli.Context) (Options, error) {
+	o := Options{
+		Method:      strings.ToUpper(c.String("method")),
+		URL:         c.Args().First(),
+		Header:      http.Header{},
+		Concurrency: c.Int("concurrency"),
+		Requests:    int64(c.Int("requests")),
+		Duration:    c.Duration("duration"),
+		Rate:        c.Float64("rate"),
+		Timeout:     c.Duration("timeout"),
+		Insecure:    c.Bool("insecure"),
+	}
+	if o.Requests == 0 && o.Duration == 0 {
+		o.Requests = 200
+	}
+	if o.Requests < 0 || o.Duration < 0 || o.Rate < 0 {
+		return o, errors.New("--requests, --duration and --rate must not be negative")
+	}
+
+	for _, header := range c.StringSlice("header") {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) != 2 {
+			return o, errors.New("header must be 'Name: value': " + header)
+		}
+		o.Header.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+
+	if c.IsSet("body") {
+		o.Body = []byte(c.String("body"))
+		if strings.HasPrefix(c.String("body"), "@") {
+			// The file is sent as it is, so that compressed files are not decompressed like other inputs
+			var err error
+			if o.Body, err = ioutil.ReadFile(strings.TrimPrefix(c.String("body"), "@")); err != nil {
+				return o, err
+			}
+		}
+	}
+	if o.Method == "" {
+		o.Method = "GET"
+		if o.Body != nil {
+			o.Method = "POST"
+		}
+	}
+	return o, nil
+}
+
+// progress shows a progress bar for the requests or the duration of a benchmark. The returned function removes it.
+func progress(ctx context.Context, o *Options) func() {
+	if o.Requests > 0 && o.Duration == 0 {
+		bar := say.ProgressBar(o.Requests)
+		o.OnResponse = bar.Increment
+		return func() {
+			bar.SetTotal(0, true)
+			bar.GetContainer().Wait()
+		}
+	}
+
+	// Benchmarks with a duration show the elapsed time
+	bar := say.ProgressBar(o.Duration.Milliseconds())
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		start := time.Now()
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				bar.SetCurrent(time.Since(start).Milliseconds())
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		bar.SetTotal(0, true)
+		bar.GetContainer().Wait()
+	}
+}
+
+// formatDuration rounds a latency to a readable precision
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	}
+	return d.Round(time.Microsecond).String()
+}
+
+// formatBytes formats a number of bytes like 1.2MB
+func formatBytes(n float64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	i := 0
+	for n >= 1024 && i < len(units)-1 {
+		n /= 1024
+		i++
+	}
+	if i == 0 {
+		return strconv.FormatFloat(n, 'f', 0, 64) + units[i]
+	}
+	return strconv.FormatFloat(n, 'f', 1, 64) + units[i]
+}
+
+// report renders the result of a benchmark as tables
+func report(o Options, r *Result, colored color.Colorizer) string {
+	sections := []string{say.Section(o.Method+" "+o.URL, colored)}
+
+	sections = append(sections, say.Table([][]string{
+		{"Requests", strconv.FormatInt(r.Requests, 10)},
+		{"Errors", strconv.FormatInt(r.ErrorCount(), 10)},
+		{"Concurrency", strconv.Itoa(o.Concurrency)},
+		{"Duration", formatDuration(r.Duration)},
+		{"Requests/s", strconv.FormatFloat(r.RequestsPerSecond(), 'f', 1, 64)},
+		{"Transfer/s", formatBytes(r.BytesPerSecond())},
+	}, false, colored))
+
+	if r.Latency.Count() > 0 {
+		latency := [][]string{
+			{"Latency", "Value"},
+			{"min", formatDuration(r.Latency.Min())},
+			{"mean", formatDuration(r.Latency.Mean())},
+			{"stddev", formatDuration(r.Latency.StdDev())},
+		}
+		for _, p := range percentiles {
+			latency = append(latency, []string{formatPercentile(p), formatDuration(r.Latency.Percentile(p))})
+		}
+		latency = append(latency, []string{"max", formatDuration(r.Latency.Max())})
+		sections = append(sections, say.Table(latency, true, colored))
+	}
+
+	if len(r.Status) > 0 {
+		codes := make([]int, 0, len(r.Status))
+		for code := range r.Status {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		data := [][]string{{"Status", "Count", "Share"}}
+		for _, code := range codes {
+			data = append(data, []string{strconv.Itoa(code), strconv.FormatInt(r.Status[code], 10), share(r.Status[code], r.Requests)})
+		}
+		sections = append(sections, say.Table(data, true, colored))
+	}
+
+	if len(r.Errors) > 0 {
+		messages := make([]string, 0, len(r.Errors))
+		for message := range r.Errors {
+			messages = append(messages, message)
+		}
+		sort.Slice(messages, func(i, j int) bool { return r.Errors[messages[i]] > r.Errors[messages[j]] })
+		data := [][]string{{"Error", "Count", "Share"}}
+		for _, message := range messages {
+			data = append(data, []string{message, strconv.FormatInt(r.Errors[message], 10), share(r.Errors[message], r.Requests)})
+		}
+		sections = append(sections, say.Table(data, true, colored))
+	}
+
+	return strings.Join(sections, "\n\n")
+}
+
+func share(count, total int64) string {
+	if total == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(count)*100/float64(total))
+}
+
+// resultJSON is the JSON form of a report, latencies are in milliseconds
+type resultJSON struct {
+	Method            string             `json:"method"`
+	URL               string             `json:"url"`
+	Concurrency       int                `json:"concurrency"`
+	Requests          int64              `json:"requests"`
+	Errors            int64              `json:"errors"`
+	Duration          float64            `json:"duration_seconds"`
+	RequestsPerSecond float64            `json:"requests_per_second"`
+	BytesPerSecond    float64            `json:"bytes_per_second"`
+	Latency           map[string]float64 `json:"latency_ms"`
+	Status            map[string]int64   `json:"status"`
+	ErrorMessages     map[string]int64   `json:"error_messages"`
+}
+
+func toJSON(o Options, r *Result) resultJSON {
+	ms := func(d time.Duration) float64 {
+		return float64(d) / float64(time.Millisecond)
+	}
+	out := resultJSON{
+		Method:            o.Method,
+		URL:               o.URL,
+		Concurrency:       o.Concurrency,
+		Requests:          r.Requests,
+		Errors:            r.ErrorCount(),
+		Duration:          r.Duration.Seconds(),
+		RequestsPerSecond: r.RequestsPerSecond(),
+		BytesPerSecond:    r.BytesPerSecond(),
+		Latency: map[string]float64{
+			"min":    ms(r.Latency.Min()),
+			"mean":   ms(r.Latency.Mean()),
+			"stddev": ms(r.Latency.StdDev()),
+			"max":    ms(r.Latency.Max()),
+		},
+		Status:        map[string]int64{},
+		ErrorMessages: r.Errors,
+	}
+	for _, p := range percentiles {
+		out.Latency[formatPercentile(p)] = ms(r.Latency.Percentile(p))
+	}
+	for code, count := range r.Status {
+		out.Status[strconv.Itoa(code)] = count
+	}
+	return out
+}
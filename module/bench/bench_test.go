@@ -0,0 +1,157 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/global/options"
+)
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram()
+	for v := int64(1); v <= 100000; v++ {
+		h.RecordValues(v, 1)
+	}
+
+	for _, tt := range []struct {
+		percentile float64
+		want       int64
+	}{
+		{50, 50000},
+		{90, 90000},
+		{99, 99000},
+		{100, 100000},
+	} {
+		got := h.Percentile(tt.percentile).Microseconds()
+		// Values are recorded with 3 significant digits
+		if diff := got - tt.want; diff < 0 || diff > tt.want/1000 {
+			t.Errorf("p%g: got %d, want %d", tt.percentile, got, tt.want)
+		}
+	}
+	if h.Min() != time.Microsecond || h.Max() != 100*time.Millisecond || h.Count() != 100000 {
+		t.Errorf("got min %s, max %s and count %d", h.Min(), h.Max(), h.Count())
+	}
+
+	// Exported histograms can be loaded to compare runs
+	data, err := json.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "hdr.json")
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadHistogram(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range percentiles {
+		if loaded.Percentile(p) != h.Percentile(p) {
+			t.Errorf("%s of the loaded histogram is %s, want %s", formatPercentile(p), loaded.Percentile(p), h.Percentile(p))
+		}
+	}
+	if loaded.Mean() != h.Mean() || loaded.Count() != h.Count() {
+		t.Errorf("loaded histogram has mean %s and count %d", loaded.Mean(), loaded.Count())
+	}
+}
+
+func TestRun(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&calls, 1)%4 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var responses int64
+	result, err := Run(context.Background(), Options{
+		Method:      "GET",
+		URL:         server.URL,
+		Concurrency: 8,
+		Requests:    100,
+		Timeout:     5 * time.Second,
+		OnResponse:  func() { atomic.AddInt64(&responses, 1) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Requests != 100 || responses != 100 || result.Latency.Count() != 100 {
+		t.Errorf("got %d requests, %d responses and %d latencies", result.Requests, responses, result.Latency.Count())
+	}
+	if result.Status[http.StatusOK] != 75 || result.Status[http.StatusServiceUnavailable] != 25 {
+		t.Errorf("unexpected status codes: %v", result.Status)
+	}
+	if result.Bytes != 150 || result.ErrorCount() != 0 {
+		t.Errorf("got %d bytes and errors %v", result.Bytes, result.Errors)
+	}
+
+	// Requests, which can't be sent, are counted as errors
+	server.Close()
+	result, err = Run(context.Background(), Options{Method: "GET", URL: server.URL, Concurrency: 2, Requests: 4, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ErrorCount() != 4 || result.Latency.Count() != 0 {
+		t.Errorf("got errors %v", result.Errors)
+	}
+}
+
+func TestBodyFromFile(t *testing.T) {
+	// Compressed bodies are sent as they are
+	compressed := []byte{0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff}
+	path := filepath.Join(t.TempDir(), "body.json.gz")
+	if err := ioutil.WriteFile(path, compressed, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var o Options
+	command := Module{}.GetModuleCommands()[0]
+	command.Action = func(c *cli.Context) (err error) {
+		o, err = optionsFromFlags(c)
+		return err
+	}
+	app := &cli.App{Commands: []*cli.Command{command}}
+	if err := app.Run([]string{"dops", command.Name, "-b", "@" + path, "http://localhost"}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(o.Body, compressed) || o.Method != "POST" {
+		t.Errorf("got %s %v", o.Method, o.Body)
+	}
+}
+
+func TestActionInRawMode(t *testing.T) {
+	// The progress bar isn't rendered in raw mode, waiting for it would never return
+	options.Raw = true
+	defer func() { options.Raw = false }()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	output := filepath.Join(t.TempDir(), "bench.json")
+
+	app := &cli.App{Commands: Module{}.GetModuleCommands()}
+	done := make(chan error, 1)
+	go func() { done <- app.Run([]string{"dops", "bench", "-n", "10", "--json", "-o", output, server.URL}) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("bench did not return in raw mode")
+	}
+	if _, err := ioutil.ReadFile(output); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,93 @@
+package bench
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Compare returns the compare subcommand
+func Compare() *cli.Command {
+	return &cli.Command{
+		Name:      "compare",
+		Usage:     "Compares the latencies of two benchmarks",
+		ArgsUsage: "BASELINE CANDIDATE",
+		// Flags are also accepted after the files
+		InterspersedFlags: true,
+		Description: `Compare shows the latency percentiles of two histograms, which were exported with 'dops bench --hdr', and the change from the baseline to the candidate.
+With --max-regression it exits with status 1, if any percentile got slower by more than the given percentage, so that it can be used in CI.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Compare a benchmark before and after a change",
+				Usage:            "dops bench compare before.json after.json",
+			},
+			{
+				ShortDescription: "Fail if the latency got more than 10% worse",
+				Usage:            "dops bench compare --max-regression 10 before.json after.json",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 2 {
+				return errors.New("expected BASELINE and CANDIDATE")
+			}
+			baseline, err := LoadHistogram(c.Args().Get(0))
+			if err != nil {
+				return err
+			}
+			candidate, err := LoadHistogram(c.Args().Get(1))
+			if err != nil {
+				return err
+			}
+
+			type row struct {
+				name      string
+				base, new time.Duration
+			}
+			rows := []row{
+				{"min", baseline.Min(), candidate.Min()},
+				{"mean", baseline.Mean(), candidate.Mean()},
+			}
+			for _, p := range percentiles {
+				rows = append(rows, row{formatPercentile(p), baseline.Percentile(p), candidate.Percentile(p)})
+			}
+			rows = append(rows, row{"max", baseline.Max(), candidate.Max()})
+
+			maxRegression := c.Float64("max-regression")
+			regressed := false
+			data := [][]string{{"Latency", "Baseline", "Candidate", "Change"}}
+			for _, r := range rows {
+				change := "-"
+				if r.base > 0 {
+					percent := (float64(r.new) - float64(r.base)) * 100 / float64(r.base)
+					change = fmt.Sprintf("%+.1f%%", percent)
+					if c.IsSet("max-regression") && r.name != "min" && r.name != "max" && percent > maxRegression {
+						regressed = true
+						change += " !"
+					}
+				}
+				data = append(data, []string{r.name, formatDuration(r.base), formatDuration(r.new), change})
+			}
+			data = append(data, []string{"count", strconv.FormatInt(baseline.Count(), 10), strconv.FormatInt(candidate.Count(), 10), ""})
+
+			if err := utils.WriteOutput(c, []string{say.Table(data, true, color.NewColorizer(c.String("output") == ""))}); err != nil {
+				return err
+			}
+			if regressed {
+				return cli.Exit(fmt.Sprintf("latency regressed by more than %g%%", maxRegression), 1)
+			}
+			return nil
+		},
+		Flags: append([]cli.Flag{
+			&cli.Float64Flag{
+				Name:  "max-regression",
+				Usage: "Exits with status 1, if the mean or a percentile is more than `PERCENT` slower",
+			},
+		}, utils.OutputFlags()...),
+	}
+}
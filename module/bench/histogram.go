@@ -0,0 +1,246 @@
+package bench
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math"
+	"math/bits"
+	"strconv"
+	"time"
+)
+
+// subBucketBits sets the precision of the histogram, values are recorded with 3 significant digits
+const subBucketBits = 11
+
+const (
+	subBucketCount = 1 << subBucketBits
+	subBucketHalf  = subBucketCount / 2
+)
+
+// Histogram records latencies in microseconds with a fixed relative precision, like a HDR histogram.
+// Values below 2048µs are recorded exactly, larger values in buckets with a width of less than 0.1% of the value.
+type Histogram struct {
+	counts []int64
+	total  int64
+	min    int64
+	max    int64
+	sum    float64
+	sumSq  float64
+}
+
+// NewHistogram returns an empty histogram
+func NewHistogram() *Histogram {
+	return &Histogram{min: math.MaxInt64}
+}
+
+func bucketIndex(v int64) int {
+	shift := bits.Len64(uint64(v)) - subBucketBits
+	if shift <= 0 {
+		return int(v)
+	}
+	return shift*subBucketHalf + int(v>>uint(shift))
+}
+
+// bucketRange returns the lowest and highest value of a bucket
+func bucketRange(index int) (int64, int64) {
+	if index < subBucketCount {
+		return int64(index), int64(index)
+	}
+	shift := (index - subBucketHalf) / subBucketHalf
+	lowest := int64(index-shift*subBucketHalf) << uint(shift)
+	return lowest, lowest + 1<<uint(shift) - 1
+}
+
+// Record adds a latency to the histogram
+func (h *Histogram) Record(d time.Duration) {
+	h.RecordValues(d.Microseconds(), 1)
+}
+
+// RecordValues adds count values of v microseconds to the histogram
+func (h *Histogram) RecordValues(v, count int64) {
+	if v < 0 {
+		v = 0
+	}
+	index := bucketIndex(v)
+	if index >= len(h.counts) {
+		counts := make([]int64, index+1, (index+1)*5/4)
+		copy(counts, h.counts)
+		h.counts = counts
+	}
+	h.counts[index] += count
+	h.total += count
+	if v < h.min {
+		h.min = v
+	}
+	if v > h.max {
+		h.max = v
+	}
+	h.sum += float64(v) * float64(count)
+	h.sumSq += float64(v) * float64(v) * float64(count)
+}
+
+// Merge adds all values of other to the histogram
+func (h *Histogram) Merge(other *Histogram) {
+	for index, count := range other.counts {
+		if count == 0 {
+			continue
+		}
+		if index >= len(h.counts) {
+			counts := make([]int64, index+1)
+			copy(counts, h.counts)
+			h.counts = counts
+		}
+		h.counts[index] += count
+	}
+	h.total += other.total
+	if other.total > 0 {
+		if other.min < h.min {
+			h.min = other.min
+		}
+		if other.max > h.max {
+			h.max = other.max
+		}
+	}
+	h.sum += other.sum
+	h.sumSq += other.sumSq
+}
+
+// Count returns the number of recorded values
+func (h *Histogram) Count() int64 {
+	return h.total
+}
+
+// Min returns the lowest recorded value
+func (h *Histogram) Min() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return time.Duration(h.min) * time.Microsecond
+}
+
+// Max returns the highest recorded value
+func (h *Histogram) Max() time.Duration {
+	return time.Duration(h.max) * time.Microsecond
+}
+
+// Mean returns the average of the recorded values
+func (h *Histogram) Mean() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return time.Duration(h.sum / float64(h.total) * float64(time.Microsecond))
+}
+
+// StdDev returns the standard deviation of the recorded values
+func (h *Histogram) StdDev() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	mean := h.sum / float64(h.total)
+	variance := h.sumSq/float64(h.total) - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+	return time.Duration(math.Sqrt(variance) * float64(time.Microsecond))
+}
+
+// Percentile returns the value, which is higher than or equal to p percent of the recorded values
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	target := int64(math.Ceil(p / 100 * float64(h.total)))
+	if target < 1 {
+		target = 1
+	}
+	var seen int64
+	for index, count := range h.counts {
+		seen += count
+		if seen >= target {
+			_, highest := bucketRange(index)
+			if highest > h.max {
+				highest = h.max
+			}
+			return time.Duration(highest) * time.Microsecond
+		}
+	}
+	return h.Max()
+}
+
+// percentiles are exported and shown in reports
+var percentiles = []float64{50, 75, 90, 95, 99, 99.9}
+
+// formatPercentile formats a percentile like p99.9
+func formatPercentile(p float64) string {
+	return "p" + strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// histogramJSON is the exported form of a histogram, which can be loaded again to compare runs
+type histogramJSON struct {
+	Unit              string           `json:"unit"`
+	SignificantDigits int              `json:"significantDigits"`
+	Count             int64            `json:"count"`
+	Min               int64            `json:"min"`
+	Max               int64            `json:"max"`
+	Mean              float64          `json:"mean"`
+	StdDev            float64          `json:"stddev"`
+	Percentiles       map[string]int64 `json:"percentiles"`
+	Buckets           [][2]int64       `json:"buckets"`
+}
+
+// MarshalJSON exports the percentiles and the non-empty buckets of the histogram in microseconds
+func (h *Histogram) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.export())
+}
+
+func (h *Histogram) export() histogramJSON {
+	out := histogramJSON{
+		Unit:              "microseconds",
+		SignificantDigits: 3,
+		Count:             h.total,
+		Min:               h.Min().Microseconds(),
+		Max:               h.max,
+		Mean:              float64(h.Mean()) / float64(time.Microsecond),
+		StdDev:            float64(h.StdDev()) / float64(time.Microsecond),
+		Percentiles:       map[string]int64{},
+		Buckets:           [][2]int64{},
+	}
+	for _, p := range percentiles {
+		out.Percentiles[formatPercentile(p)] = h.Percentile(p).Microseconds()
+	}
+	for index, count := range h.counts {
+		if count > 0 {
+			_, highest := bucketRange(index)
+			out.Buckets = append(out.Buckets, [2]int64{highest, count})
+		}
+	}
+	return out
+}
+
+// LoadHistogram reads a histogram, which was exported with --hdr
+func LoadHistogram(path string) (*Histogram, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var in histogramJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return nil, err
+	}
+	if in.Unit != "microseconds" {
+		return nil, errors.New(path + " is not a histogram exported by dops bench")
+	}
+
+	h := NewHistogram()
+	for _, bucket := range in.Buckets {
+		h.RecordValues(bucket[0], bucket[1])
+	}
+	// The exact values are restored, which are lost in the buckets
+	if h.total > 0 {
+		h.min, h.max = in.Min, in.Max
+		h.sum = in.Mean * float64(h.total)
+		h.sumSq = (in.StdDev*in.StdDev + in.Mean*in.Mean) * float64(h.total)
+	}
+	return h, nil
+}
@@ -0,0 +1,208 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Options configure a benchmark. It sends Requests requests, or as many requests as possible during Duration.
+type Options struct {
+	Method      string
+	URL         string
+	Header      http.Header
+	Body        []byte
+	Concurrency int
+	Requests    int64
+	Duration    time.Duration
+	// Rate limits the requests per second of all workers, 0 means unlimited
+	Rate     float64
+	Timeout  time.Duration
+	Insecure bool
+	// OnResponse is called after every request, it must be safe for concurrent use
+	OnResponse func()
+}
+
+// Result is the outcome of a benchmark
+type Result struct {
+	Latency  *Histogram
+	Duration time.Duration
+	Requests int64
+	Bytes    int64
+	Status   map[int]int64
+	Errors   map[string]int64
+}
+
+// ErrorCount returns the number of requests, which could not be sent
+func (r *Result) ErrorCount() int64 {
+	var n int64
+	for _, count := range r.Errors {
+		n += count
+	}
+	return n
+}
+
+// RequestsPerSecond returns the throughput of the benchmark
+func (r *Result) RequestsPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Duration.Seconds()
+}
+
+// BytesPerSecond returns the received bytes per second
+func (r *Result) BytesPerSecond() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Bytes) / r.Duration.Seconds()
+}
+
+// worker collects the results of one goroutine, so that no locking is needed while sending requests
+type worker struct {
+	latency *Histogram
+	bytes   int64
+	status  map[int]int64
+	errors  map[string]int64
+}
+
+// Run sends the requests of a benchmark until all requests are sent, the duration is over or ctx is done
+func Run(ctx context.Context, o Options) (*Result, error) {
+	if o.Concurrency < 1 {
+		return nil, errors.New("concurrency must be at least 1")
+	}
+	if o.Requests <= 0 && o.Duration <= 0 {
+		return nil, errors.New("a number of requests or a duration is needed")
+	}
+	if _, err := http.NewRequest(o.Method, o.URL, nil); err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		Timeout: o.Timeout,
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			MaxIdleConns:        o.Concurrency,
+			MaxIdleConnsPerHost: o.Concurrency,
+			TLSClientConfig:     &tls.Config{InsecureSkipVerify: o.Insecure}, //nolint:gosec
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	if o.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.Duration)
+		defer cancel()
+	}
+
+	var tokens <-chan time.Time
+	if o.Rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / o.Rate))
+		defer ticker.Stop()
+		tokens = ticker.C
+	}
+
+	var sent int64
+	// next reserves the next request, it returns false if the benchmark is over
+	next := func() bool {
+		if o.Requests > 0 && atomic.AddInt64(&sent, 1) > o.Requests {
+			return false
+		}
+		if tokens != nil {
+			select {
+			case <-tokens:
+			case <-ctx.Done():
+				return false
+			}
+		}
+		return ctx.Err() == nil
+	}
+
+	workers := make([]*worker, o.Concurrency)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := range workers {
+		w := &worker{latency: NewHistogram(), status: map[int]int64{}, errors: map[string]int64{}}
+		workers[i] = w
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for next() {
+				w.send(client, o)
+				if o.OnResponse != nil {
+					o.OnResponse()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	result := &Result{
+		Latency:  NewHistogram(),
+		Duration: time.Since(start),
+		Status:   map[int]int64{},
+		Errors:   map[string]int64{},
+	}
+	for _, w := range workers {
+		result.Latency.Merge(w.latency)
+		result.Bytes += w.bytes
+		for status, count := range w.status {
+			result.Status[status] += count
+			result.Requests += count
+		}
+		for err, count := range w.errors {
+			result.Errors[err] += count
+			result.Requests += count
+		}
+	}
+	return result, nil
+}
+
+// send sends one request. Requests are not canceled at the end of the duration, so that they are not counted as errors.
+func (w *worker) send(client *http.Client, o Options) {
+	var body io.Reader
+	if o.Body != nil {
+		body = bytes.NewReader(o.Body)
+	}
+	req, err := http.NewRequest(o.Method, o.URL, body)
+	if err != nil {
+		w.errors[err.Error()]++
+		return
+	}
+	for name, values := range o.Header {
+		req.Header[name] = values
+	}
+	if host := o.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err == nil {
+		var n int64
+		n, err = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		w.bytes += n
+	}
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		w.errors[err.Error()]++
+		return
+	}
+	w.latency.Record(time.Since(start))
+	w.status[resp.StatusCode]++
+}
@@ -0,0 +1,742 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/module/tlstool"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "cert",
+			Usage: "Creates certificate authorities, certificates and keys for development",
+			Description: `Cert is a small certificate authority for development. It creates a local certificate authority,
+issues server and client certificates, creates keys and certificate signing requests,
+converts between PEM, DER and PKCS #12 and shows fingerprints.
+Keys are written with permissions, which only allow the owner to read them, and existing files are only replaced with --force.`,
+			Category: categories.Generators,
+			Subcommands: []*cli.Command{
+				caCommand(),
+				issueCommand(),
+				csrCommand(),
+				keyCommand(),
+				convertCommand(),
+				fingerprintCommand(),
+			},
+		},
+	}
+}
+
+func keyFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "key-type",
+			Aliases: []string{"t"},
+			Usage:   "Generates a key of `TYPE`, which is ecdsa, rsa or ed25519",
+			Value:   ECDSA,
+		},
+		&cli.IntFlag{
+			Name:    "bits",
+			Aliases: []string{"b"},
+			Usage:   "Generates a key with `BITS`, 2048 or more for RSA and 256, 384 or 521 for ECDSA",
+		},
+	}
+}
+
+var forceFlag = &cli.BoolFlag{
+	Name:    "force",
+	Aliases: []string{"f"},
+	Usage:   "Replaces existing files",
+}
+
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
+
+func encodeCertificate(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: TypeCertificate, Bytes: cert.Raw})
+}
+
+// checkWritable fails, if one of the files exists and force is false, so that nothing is written, if one of them exists
+func checkWritable(force bool, paths ...string) error {
+	if force {
+		return nil
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists, use --force to replace it", path)
+		}
+	}
+	return nil
+}
+
+// loadCA reads the certificate and key of a certificate authority
+func loadCA(certPath, keyPath string) (*x509.Certificate, crypto.Signer, error) {
+	if _, err := os.Stat(certPath); os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("%s does not exist, create a certificate authority with 'dops cert ca'", certPath)
+	}
+	objects, err := LoadObjects(certPath, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	var ca *x509.Certificate
+	for _, o := range objects {
+		if o.Certificate != nil {
+			ca = o.Certificate
+			break
+		}
+	}
+	if ca == nil {
+		return nil, nil, fmt.Errorf("%s contains no certificate", certPath)
+	}
+	key, err := LoadKey(keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool }); !ok || !pub.Equal(ca.PublicKey) {
+		return nil, nil, fmt.Errorf("the key of %s does not belong to %s", keyPath, certPath)
+	}
+	return ca, key, nil
+}
+
+func caCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "ca",
+		Usage: "Creates a certificate authority",
+		Description: `CA creates a self-signed certificate authority, which issues certificates with 'dops cert issue'.
+Add the certificate to the trust store of your system or browser, so that the issued certificates are trusted.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Create a certificate authority in ca.pem and ca-key.pem",
+				Usage:            "dops cert ca",
+			},
+			{
+				ShortDescription: "Create a certificate authority with an RSA key, which is valid for a year",
+				Usage:            "dops cert ca --name \"Team CA\" --key-type rsa --bits 4096 --days 365",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			certPath, keyPath := c.String("cert"), c.String("key")
+			if err := checkWritable(c.Bool("force"), certPath, keyPath); err != nil {
+				return err
+			}
+			key, err := GenerateKey(c.String("key-type"), c.Int("bits"))
+			if err != nil {
+				return err
+			}
+			ca, err := CreateCA(c.String("name"), key, days(c.Int("days")))
+			if err != nil {
+				return err
+			}
+			keyPEM, err := EncodeKey(key)
+			if err != nil {
+				return err
+			}
+			if err := writeFile(keyPath, keyPEM, true, c.Bool("force")); err != nil {
+				return err
+			}
+			if err := writeFile(certPath, encodeCertificate(ca), false, c.Bool("force")); err != nil {
+				return err
+			}
+			say.Success(fmt.Sprintf("Created the certificate authority %q in %s and its key in %s", ca.Subject.CommonName, certPath, keyPath))
+			say.Info("Issued certificates are trusted, once " + certPath + " is added to the trust store of your system or browser")
+			return nil
+		},
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:    "name",
+				Aliases: []string{"n"},
+				Usage:   "Names the certificate authority `NAME`",
+				Value:   "dops development CA",
+			},
+			&cli.IntFlag{
+				Name:    "days",
+				Aliases: []string{"d"},
+				Usage:   "Keeps the certificate authority valid for `DAYS`",
+				Value:   3650,
+			},
+			&cli.PathFlag{
+				Name:      "cert",
+				Usage:     "Writes the certificate to `FILE`",
+				Value:     "ca.pem",
+				TakesFile: true,
+			},
+			&cli.PathFlag{
+				Name:      "key",
+				Usage:     "Writes the key to `FILE`",
+				Value:     "ca-key.pem",
+				TakesFile: true,
+			},
+			forceFlag,
+		}, keyFlags()...),
+	}
+}
+
+func issueCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "issue",
+		Usage:     "Issues a certificate with the certificate authority",
+		ArgsUsage: "[NAMES...]",
+		Description: `Issue creates a key and a certificate for the names, which is signed by the certificate authority of --ca and --ca-key.
+Names can be hostnames, wildcards like *.example.com, IP addresses, email addresses and URIs.
+The first name is the common name and is used for the file names, like localhost.pem and localhost-key.pem.
+
+With --csr a certificate signing request is signed instead of creating a key. The names of the request are used,
+names given as arguments are added.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Issue a server certificate for localhost",
+				Usage:            "dops cert issue localhost 127.0.0.1 ::1",
+			},
+			{
+				ShortDescription: "Issue a wildcard certificate with an RSA key",
+				Usage:            "dops cert issue --key-type rsa \"*.dev.example.com\" dev.example.com",
+			},
+			{
+				ShortDescription: "Issue a client certificate",
+				Usage:            "dops cert issue --client alice@example.com",
+			},
+			{
+				ShortDescription: "Sign a certificate signing request",
+				Usage:            "dops cert issue --csr server.csr --cert server.pem",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ca, caKey, err := loadCA(c.String("ca"), c.String("ca-key"))
+			if err != nil {
+				return err
+			}
+			args := c.Args().Slice()
+			names, err := ParseNames(args)
+			if err != nil {
+				return err
+			}
+			commonName := ""
+			if len(args) > 0 {
+				commonName = args[0]
+			}
+
+			var key crypto.Signer
+			var pub crypto.PublicKey
+			if csr := c.String("csr"); csr != "" {
+				req, err := loadRequest(csr)
+				if err != nil {
+					return err
+				}
+				requested := requestNames(req)
+				requested.add(names)
+				names = requested
+				if req.Subject.CommonName != "" {
+					commonName = req.Subject.CommonName
+				}
+				pub = req.PublicKey
+			} else {
+				if key, err = GenerateKey(c.String("key-type"), c.Int("bits")); err != nil {
+					return err
+				}
+				pub = key.Public()
+			}
+			if commonName == "" {
+				return errors.New("no names to issue a certificate for")
+			}
+
+			certPath, keyPath := c.String("cert"), c.String("key")
+			if certPath == "" {
+				certPath = fileName(commonName) + ".pem"
+			}
+			if keyPath == "" {
+				keyPath = fileName(commonName) + "-key.pem"
+			}
+			paths := []string{certPath}
+			if key != nil {
+				paths = append(paths, keyPath)
+			}
+			if err := checkWritable(c.Bool("force"), paths...); err != nil {
+				return err
+			}
+
+			cert, err := Issue(ca, caKey, pub, IssueOptions{
+				CommonName: commonName,
+				Names:      names,
+				Client:     c.Bool("client"),
+				Validity:   days(c.Int("days")),
+			})
+			if err != nil {
+				return err
+			}
+			if key != nil {
+				keyPEM, err := EncodeKey(key)
+				if err != nil {
+					return err
+				}
+				if err := writeFile(keyPath, keyPEM, true, c.Bool("force")); err != nil {
+					return err
+				}
+			}
+			if err := writeFile(certPath, encodeCertificate(cert), false, c.Bool("force")); err != nil {
+				return err
+			}
+
+			kind := "server"
+			if c.Bool("client") {
+				kind = "client"
+			}
+			message := fmt.Sprintf("Issued a %s certificate for %s in %s", kind, strings.Join(tlstool.SANs(cert), ", "), certPath)
+			if key != nil {
+				message += " with its key in " + keyPath
+			}
+			say.Success(message)
+			say.Info("It expires on " + cert.NotAfter.Format("2006-01-02"))
+			return nil
+		},
+		Flags: append([]cli.Flag{
+			&cli.PathFlag{
+				Name:      "ca",
+				Usage:     "Signs with the certificate authority of `FILE`",
+				Value:     "ca.pem",
+				TakesFile: true,
+			},
+			&cli.PathFlag{
+				Name:      "ca-key",
+				Usage:     "Signs with the key of `FILE`",
+				Value:     "ca-key.pem",
+				TakesFile: true,
+			},
+			&cli.PathFlag{
+				Name:      "csr",
+				Usage:     "Signs the certificate signing request of `FILE` instead of creating a key",
+				TakesFile: true,
+			},
+			&cli.BoolFlag{
+				Name:    "client",
+				Aliases: []string{"c"},
+				Usage:   "Issues a certificate for client authentication",
+			},
+			&cli.IntFlag{
+				Name:    "days",
+				Aliases: []string{"d"},
+				Usage:   "Keeps the certificate valid for `DAYS`",
+				Value:   825,
+			},
+			&cli.PathFlag{
+				Name:      "cert",
+				Usage:     "Writes the certificate to `FILE`, defaults to the first name with .pem",
+				TakesFile: true,
+			},
+			&cli.PathFlag{
+				Name:      "key",
+				Usage:     "Writes the key to `FILE`, defaults to the first name with -key.pem",
+				TakesFile: true,
+			},
+			forceFlag,
+		}, keyFlags()...),
+	}
+}
+
+func loadRequest(path string) (*x509.CertificateRequest, error) {
+	objects, err := LoadObjects(path, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range objects {
+		if o.Request != nil {
+			if err := o.Request.CheckSignature(); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			return o.Request, nil
+		}
+	}
+	return nil, fmt.Errorf("%s contains no certificate signing request", path)
+}
+
+func csrCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "csr",
+		Usage:     "Creates a certificate signing request",
+		ArgsUsage: "NAMES...",
+		Description: `CSR creates a certificate signing request for the names, which can be signed by 'dops cert issue --csr' or another certificate authority.
+A new key is created, unless an existing key is given with --use-key.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Create example.com.csr and example.com-key.pem",
+				Usage:            "dops cert csr example.com www.example.com",
+			},
+			{
+				ShortDescription: "Create a request for an existing key",
+				Usage:            "dops cert csr --use-key server-key.pem --csr server.csr example.com",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			args := c.Args().Slice()
+			if len(args) == 0 {
+				return errors.New("no names to request a certificate for")
+			}
+			names, err := ParseNames(args)
+			if err != nil {
+				return err
+			}
+			csrPath, keyPath := c.String("csr"), c.String("key")
+			if csrPath == "" {
+				csrPath = fileName(args[0]) + ".csr"
+			}
+			if keyPath == "" {
+				keyPath = fileName(args[0]) + "-key.pem"
+			}
+
+			var key crypto.Signer
+			paths := []string{csrPath}
+			if existing := c.String("use-key"); existing != "" {
+				if key, err = LoadKey(existing); err != nil {
+					return err
+				}
+				keyPath = ""
+			} else {
+				paths = append(paths, keyPath)
+			}
+			if err := checkWritable(c.Bool("force"), paths...); err != nil {
+				return err
+			}
+			if key == nil {
+				if key, err = GenerateKey(c.String("key-type"), c.Int("bits")); err != nil {
+					return err
+				}
+				keyPEM, err := EncodeKey(key)
+				if err != nil {
+					return err
+				}
+				if err := writeFile(keyPath, keyPEM, true, c.Bool("force")); err != nil {
+					return err
+				}
+			}
+
+			der, err := CreateRequest(key, args[0], names)
+			if err != nil {
+				return err
+			}
+			if err := writeFile(csrPath, pem.EncodeToMemory(&pem.Block{Type: TypeRequest, Bytes: der}), false, c.Bool("force")); err != nil {
+				return err
+			}
+			message := "Created the certificate signing request " + csrPath
+			if keyPath != "" {
+				message += " and its key in " + keyPath
+			}
+			say.Success(message)
+			return nil
+		},
+		Flags: append([]cli.Flag{
+			&cli.PathFlag{
+				Name:      "use-key",
+				Usage:     "Uses the existing key of `FILE`",
+				TakesFile: true,
+			},
+			&cli.PathFlag{
+				Name:      "csr",
+				Usage:     "Writes the request to `FILE`, defaults to the first name with .csr",
+				TakesFile: true,
+			},
+			&cli.PathFlag{
+				Name:      "key",
+				Usage:     "Writes the key to `FILE`, defaults to the first name with -key.pem",
+				TakesFile: true,
+			},
+			forceFlag,
+		}, keyFlags()...),
+	}
+}
+
+func keyCommand() *cli.Command {
+	return &cli.Command{
+		Name:        "key",
+		Usage:       "Creates a private key",
+		Description: `Key creates a private key and writes it as PKCS #8 PEM to stdout or a file.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Create an Ed25519 key",
+				Usage:            "dops cert key --key-type ed25519 --output key.pem",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			key, err := GenerateKey(c.String("key-type"), c.Int("bits"))
+			if err != nil {
+				return err
+			}
+			keyPEM, err := EncodeKey(key)
+			if err != nil {
+				return err
+			}
+			if path := c.String("output"); path != "" {
+				return writeFile(path, keyPEM, true, c.Bool("force"))
+			}
+			return utils.WriteOutput(c, []string{strings.TrimSuffix(string(keyPEM), "\n")})
+		},
+		Flags: append([]cli.Flag{
+			&cli.PathFlag{
+				Name:      "output",
+				Aliases:   []string{"o"},
+				Usage:     "Writes the key to `FILE`, if not set it writes to stdout",
+				TakesFile: true,
+			},
+			forceFlag,
+		}, keyFlags()...),
+	}
+}
+
+// Formats, which objects can be converted to
+const (
+	PEM    = "pem"
+	DER    = "der"
+	PKCS12 = "p12"
+)
+
+func convertCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "convert",
+		Usage:     "Converts certificates and keys between PEM, DER and PKCS #12",
+		ArgsUsage: "FILE",
+		Description: `Convert reads certificates, keys and certificate signing requests from PEM, DER or PKCS #12 files
+and writes them as PEM, DER or PKCS #12. Keys are written as PKCS #8.
+
+DER holds a single object, so only the first one is written. Select which with --only.
+PKCS #12 files hold a key and its certificate chain, the first certificate belongs to the key.
+The key can be read from another file with --key. --password is used to read and to write PKCS #12 files.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Bundle a certificate, the CA and the key into a PKCS #12 file",
+				Usage:            "dops cert convert --to p12 --key localhost-key.pem --password secret --output localhost.p12 localhost.pem",
+			},
+			{
+				ShortDescription: "Extract the certificates and key of a PKCS #12 file as PEM",
+				Usage:            "dops cert convert --to pem --password secret localhost.p12",
+			},
+			{
+				ShortDescription: "Convert a DER certificate to PEM",
+				Usage:            "dops cert convert --to pem --output cert.pem cert.der",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() != 1 {
+				return errors.New("convert needs exactly one input file")
+			}
+			password := c.String("password")
+			objects, err := LoadObjects(c.Args().First(), password)
+			if err != nil {
+				return err
+			}
+			if keyPath := c.String("key"); keyPath != "" {
+				key, err := LoadObjects(keyPath, password)
+				if err != nil {
+					return err
+				}
+				objects = append(objects, key...)
+			}
+			if only := c.String("only"); only != "" {
+				if objects, err = filterObjects(objects, only); err != nil {
+					return err
+				}
+			}
+			if len(objects) == 0 {
+				return errors.New("no objects to convert")
+			}
+
+			var data []byte
+			to := strings.ToLower(c.String("to"))
+			switch to {
+			case PEM:
+				for _, o := range objects {
+					data = append(data, pem.EncodeToMemory(&pem.Block{Type: o.Type, Bytes: o.DER})...)
+				}
+			case DER:
+				if len(objects) > 1 {
+					say.Warning(fmt.Sprintf("Only the first of %d objects is written, DER holds a single object", len(objects)))
+				}
+				objects = objects[:1]
+				data = objects[0].DER
+			case PKCS12, "pkcs12", "pfx":
+				var key crypto.Signer
+				var certs []*x509.Certificate
+				for _, o := range objects {
+					if o.Key != nil && key == nil {
+						key = o.Key
+					}
+					if o.Certificate != nil {
+						certs = append(certs, o.Certificate)
+					}
+				}
+				if data, err = EncodePKCS12(key, certs, password); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("unknown format %q, use %s, %s or %s", to, PEM, DER, PKCS12)
+			}
+
+			private := false
+			for _, o := range objects {
+				private = private || o.Key != nil
+			}
+			path := c.String("output")
+			if path == "" {
+				if to != PEM {
+					return errors.New("binary formats need --output")
+				}
+				return utils.WriteOutput(c, []string{strings.TrimSuffix(string(data), "\n")})
+			}
+			return writeFile(path, data, private, c.Bool("force"))
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "to",
+				Aliases: []string{"t"},
+				Usage:   "Converts to `FORMAT`, which is pem, der or p12",
+				Value:   PEM,
+			},
+			&cli.PathFlag{
+				Name:      "key",
+				Aliases:   []string{"k"},
+				Usage:     "Adds the key of `FILE`",
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:    "password",
+				Aliases: []string{"p"},
+				Usage:   "Reads and writes PKCS #12 files with `PASSWORD`",
+			},
+			&cli.StringFlag{
+				Name:  "only",
+				Usage: "Converts only objects of `TYPE`, which is certificate, key or request",
+			},
+			&cli.PathFlag{
+				Name:      "output",
+				Aliases:   []string{"o"},
+				Usage:     "Writes to `FILE`, if not set PEM is written to stdout",
+				TakesFile: true,
+			},
+			forceFlag,
+		},
+	}
+}
+
+func filterObjects(objects []Object, only string) ([]Object, error) {
+	types := map[string]string{"certificate": TypeCertificate, "key": TypePrivateKey, "request": TypeRequest}
+	t, ok := types[strings.ToLower(only)]
+	if !ok {
+		return nil, fmt.Errorf("unknown type %q, use certificate, key or request", only)
+	}
+	var filtered []Object
+	for _, o := range objects {
+		if o.Type == t {
+			filtered = append(filtered, o)
+		}
+	}
+	return filtered, nil
+}
+
+func fingerprintCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "fingerprint",
+		Usage:     "Shows the fingerprints of certificates and keys",
+		ArgsUsage: "FILES...",
+		Description: `Fingerprint shows the SHA-256 fingerprints of the certificates, certificate signing requests and keys of PEM, DER and PKCS #12 files.
+The public key fingerprint is the hash of the subject public key info, so a key, its certificate signing request
+and its certificates have the same public key fingerprint.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Check, that a key belongs to a certificate",
+				Usage:            "dops cert fingerprint localhost.pem localhost-key.pem",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() == 0 {
+				return errors.New("no files to fingerprint")
+			}
+			var fingerprints []fingerprint
+			for _, path := range c.Args().Slice() {
+				objects, err := LoadObjects(path, c.String("password"))
+				if err != nil {
+					return err
+				}
+				for _, o := range objects {
+					f, err := newFingerprint(path, o)
+					if err != nil {
+						return err
+					}
+					fingerprints = append(fingerprints, f)
+				}
+			}
+
+			if c.Bool("json") {
+				data, err := json.MarshalIndent(fingerprints, "", "  ")
+				if err != nil {
+					return err
+				}
+				return utils.WriteOutput(c, []string{string(data)})
+			}
+			data := [][]string{{"File", "Type", "Name", "Key", "SHA-256", "Public key SHA-256"}}
+			for _, f := range fingerprints {
+				data = append(data, []string{f.File, f.Type, f.Name, f.Key, f.SHA256, f.PublicKeySHA256})
+			}
+			return utils.WriteOutput(c, []string{say.Table(data, true, color.NewColorizer(c.String("output") == ""))})
+		},
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:    "password",
+				Aliases: []string{"p"},
+				Usage:   "Reads PKCS #12 files with `PASSWORD`",
+			},
+			&cli.BoolFlag{
+				Name:    "json",
+				Aliases: []string{"j"},
+				Usage:   "Outputs the fingerprints as JSON",
+			},
+		}, utils.OutputFlags()...),
+	}
+}
+
+type fingerprint struct {
+	File string `json:"file"`
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	Key  string `json:"key"`
+	// SHA256 is the fingerprint of certificates and requests, keys have none, as it would reveal a hash of the private key
+	SHA256          string `json:"sha256,omitempty"`
+	PublicKeySHA256 string `json:"public_key_sha256"`
+}
+
+func newFingerprint(path string, o Object) (fingerprint, error) {
+	spki, err := x509.MarshalPKIXPublicKey(o.PublicKey())
+	if err != nil {
+		return fingerprint{}, err
+	}
+	f := fingerprint{
+		File:            path,
+		Type:            strings.ToLower(o.Type),
+		Key:             tlstool.KeyDescription(o.PublicKey()),
+		PublicKeySHA256: tlstool.Fingerprint(spki),
+	}
+	switch {
+	case o.Certificate != nil:
+		f.Name = o.Certificate.Subject.CommonName
+		f.SHA256 = tlstool.Fingerprint(o.DER)
+	case o.Request != nil:
+		f.Name = o.Request.Subject.CommonName
+		f.SHA256 = tlstool.Fingerprint(o.DER)
+	}
+	return f, nil
+}
@@ -0,0 +1,143 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIssue(t *testing.T) {
+	for _, keyType := range []string{ECDSA, RSA, Ed25519} {
+		caKey, err := GenerateKey(keyType, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ca, err := CreateCA("test CA", caKey, 48*time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := GenerateKey(keyType, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names, err := ParseNames([]string{"localhost", "127.0.0.1", "admin@example.com", "spiffe://example.com/app"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, err := Issue(ca, caKey, key.Public(), IssueOptions{CommonName: "localhost", Names: names, Validity: 24 * time.Hour})
+		if err != nil {
+			t.Fatalf("%s: %v", keyType, err)
+		}
+
+		roots := x509.NewCertPool()
+		roots.AddCert(ca)
+		if _, err := cert.Verify(x509.VerifyOptions{DNSName: "localhost", Roots: roots}); err != nil {
+			t.Errorf("%s: %v", keyType, err)
+		}
+		if len(cert.IPAddresses) != 1 || len(cert.EmailAddresses) != 1 || len(cert.URIs) != 1 {
+			t.Errorf("%s: names are missing: %v %v %v", keyType, cert.IPAddresses, cert.EmailAddresses, cert.URIs)
+		}
+		if _, err := Issue(ca, caKey, key.Public(), IssueOptions{CommonName: "localhost", Names: names, Validity: 72 * time.Hour}); err == nil {
+			t.Errorf("%s: a certificate must not outlive its certificate authority", keyType)
+		}
+	}
+}
+
+func TestRequest(t *testing.T) {
+	key, err := GenerateKey(ECDSA, 384)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names, _ := ParseNames([]string{"example.com", "www.example.com"})
+	der, err := CreateRequest(key, "example.com", names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects, err := ParseObjects(der, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 || objects[0].Request == nil {
+		t.Fatalf("got %+v, want a request", objects)
+	}
+	if got := requestNames(objects[0].Request).DNS; len(got) != 2 {
+		t.Errorf("got names %v", got)
+	}
+}
+
+func TestPKCS12(t *testing.T) {
+	caKey, _ := GenerateKey(ECDSA, 0)
+	ca, err := CreateCA("test CA", caKey, 48*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, keyType := range []string{ECDSA, RSA} {
+		key, _ := GenerateKey(keyType, 0)
+		names, _ := ParseNames([]string{"localhost"})
+		cert, err := Issue(ca, caKey, key.Public(), IssueOptions{CommonName: "localhost", Names: names, Validity: time.Hour})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := EncodePKCS12(key, []*x509.Certificate{cert, ca}, "secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseObjects(data, "wrong"); err == nil {
+			t.Errorf("%s: decoded with a wrong password", keyType)
+		}
+		objects, err := ParseObjects(data, "secret")
+		if err != nil {
+			t.Fatalf("%s: %v", keyType, err)
+		}
+
+		var certs, keys int
+		for _, o := range objects {
+			switch {
+			case o.Certificate != nil:
+				certs++
+			case o.Key != nil:
+				keys++
+				if pub, ok := o.Key.Public().(interface{ Equal(crypto.PublicKey) bool }); !ok || !pub.Equal(key.Public()) {
+					t.Errorf("%s: got a different key", keyType)
+				}
+			}
+		}
+		if certs != 2 || keys != 1 {
+			t.Errorf("%s: got %d certificates and %d keys", keyType, certs, keys)
+		}
+	}
+
+	if _, err := EncodePKCS12(nil, []*x509.Certificate{ca}, ""); err == nil {
+		t.Error("expected an error for a missing key")
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "key.pem")
+	if err := writeFile(path, []byte("key"), true, false); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("got mode %v, %v", info.Mode(), err)
+	}
+	if err := writeFile(path, []byte("other"), true, false); err == nil {
+		t.Error("an existing key was replaced without force")
+	}
+	if err := writeFile(path, []byte("other"), true, true); err != nil {
+		t.Error(err)
+	}
+	if data, _ := ioutil.ReadFile(path); string(data) != "other" {
+		t.Errorf("got %q", data)
+	}
+}
@@ -0,0 +1,222 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Names are the subject alternative names of a certificate
+type Names struct {
+	DNS    []string
+	IPs    []net.IP
+	Emails []string
+	URIs   []*url.URL
+}
+
+// ParseNames sorts names into DNS names, IP addresses, email addresses and URIs
+func ParseNames(names []string) (Names, error) {
+	var n Names
+	for _, name := range names {
+		switch {
+		case net.ParseIP(name) != nil:
+			n.IPs = append(n.IPs, net.ParseIP(name))
+		case strings.Contains(name, "://"):
+			u, err := url.Parse(name)
+			if err != nil {
+				return n, err
+			}
+			n.URIs = append(n.URIs, u)
+		case strings.Contains(name, "@"):
+			n.Emails = append(n.Emails, name)
+		default:
+			n.DNS = append(n.DNS, name)
+		}
+	}
+	return n, nil
+}
+
+// Empty returns true, if there are no names
+func (n Names) Empty() bool {
+	return len(n.DNS)+len(n.IPs)+len(n.Emails)+len(n.URIs) == 0
+}
+
+// add adds the names of o, which are not contained yet
+func (n *Names) add(o Names) {
+	for _, name := range o.DNS {
+		if !contains(n.DNS, name) {
+			n.DNS = append(n.DNS, name)
+		}
+	}
+	for _, ip := range o.IPs {
+		found := false
+		for _, existing := range n.IPs {
+			found = found || existing.Equal(ip)
+		}
+		if !found {
+			n.IPs = append(n.IPs, ip)
+		}
+	}
+	for _, email := range o.Emails {
+		if !contains(n.Emails, email) {
+			n.Emails = append(n.Emails, email)
+		}
+	}
+	for _, u := range o.URIs {
+		found := false
+		for _, existing := range n.URIs {
+			found = found || existing.String() == u.String()
+		}
+		if !found {
+			n.URIs = append(n.URIs, u)
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func serialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// subjectKeyID returns the SHA-1 hash of the public key, like RFC 5280 section 4.2.1.2 suggests
+func subjectKeyID(pub crypto.PublicKey) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+	var info struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, err
+	}
+	id := sha1.Sum(info.PublicKey.Bytes) //nolint:gosec
+	return id[:], nil
+}
+
+// CreateCA creates a self-signed certificate authority, which can issue certificates, but no intermediate authorities
+func CreateCA(name string, key crypto.Signer, validity time.Duration) (*x509.Certificate, error) {
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := subjectKeyID(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: name, Organization: []string{"dops"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(validity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+		SubjectKeyId:          keyID,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// IssueOptions configure an issued certificate
+type IssueOptions struct {
+	CommonName string
+	Names      Names
+	// Client issues a certificate for client authentication instead of a server certificate
+	Client   bool
+	Validity time.Duration
+}
+
+// Issue creates a certificate for the public key, which is signed by the certificate authority
+func Issue(ca *x509.Certificate, caKey crypto.Signer, pub crypto.PublicKey, o IssueOptions) (*x509.Certificate, error) {
+	if !ca.IsCA {
+		return nil, errors.New(ca.Subject.CommonName + " is no certificate authority")
+	}
+	if o.Names.Empty() {
+		return nil, errors.New("certificates need at least one name")
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := subjectKeyID(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	notAfter := time.Now().Add(o.Validity)
+	if notAfter.After(ca.NotAfter) {
+		return nil, fmt.Errorf("the certificate would expire after the certificate authority on %s", ca.NotAfter.Format("2006-01-02"))
+	}
+	template := &x509.Certificate{
+		SerialNumber:   serial,
+		Subject:        pkix.Name{CommonName: o.CommonName, Organization: []string{"dops"}},
+		NotBefore:      time.Now().Add(-time.Hour),
+		NotAfter:       notAfter,
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		SubjectKeyId:   keyID,
+		DNSNames:       o.Names.DNS,
+		IPAddresses:    o.Names.IPs,
+		EmailAddresses: o.Names.Emails,
+		URIs:           o.Names.URIs,
+	}
+	if o.Client {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+	// RSA keys are used to encrypt the key exchange of old TLS versions
+	if _, ok := pub.(*rsa.PublicKey); ok {
+		template.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, pub, caKey)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// CreateRequest creates a certificate signing request for the names
+func CreateRequest(key crypto.Signer, commonName string, names Names) ([]byte, error) {
+	return x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:        pkix.Name{CommonName: commonName},
+		DNSNames:       names.DNS,
+		IPAddresses:    names.IPs,
+		EmailAddresses: names.Emails,
+		URIs:           names.URIs,
+	}, key)
+}
+
+// requestNames returns the names of a certificate signing request
+func requestNames(req *x509.CertificateRequest) Names {
+	return Names{DNS: req.DNSNames, IPs: req.IPAddresses, Emails: req.EmailAddresses, URIs: req.URIs}
+}
+
+// fileName returns a file name for a certificate of name, like _wildcard.example.com for *.example.com
+func fileName(name string) string {
+	return strings.NewReplacer("*", "_wildcard", ":", "_", "/", "_", "\\", "_").Replace(name)
+}
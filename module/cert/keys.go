@@ -0,0 +1,121 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Key types, which can be generated
+const (
+	ECDSA   = "ecdsa"
+	RSA     = "rsa"
+	Ed25519 = "ed25519"
+)
+
+// GenerateKey generates a private key. Bits is the size of RSA keys and the curve size of ECDSA keys (256, 384 or 521).
+// It's ignored for Ed25519 keys. If bits is 0, RSA keys have 2048 bits and ECDSA keys use P-256.
+func GenerateKey(keyType string, bits int) (crypto.Signer, error) {
+	switch strings.ToLower(keyType) {
+	case ECDSA, "ec":
+		curves := map[int]elliptic.Curve{0: elliptic.P256(), 256: elliptic.P256(), 384: elliptic.P384(), 521: elliptic.P521()}
+		curve, ok := curves[bits]
+		if !ok {
+			return nil, fmt.Errorf("ECDSA keys have 256, 384 or 521 bits, not %d", bits)
+		}
+		return ecdsa.GenerateKey(curve, rand.Reader)
+	case RSA:
+		if bits == 0 {
+			bits = 2048
+		}
+		if bits < 2048 {
+			return nil, fmt.Errorf("RSA keys need at least 2048 bits, not %d", bits)
+		}
+		return rsa.GenerateKey(rand.Reader, bits)
+	case Ed25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	}
+	return nil, fmt.Errorf("unknown key type %q, use %s, %s or %s", keyType, ECDSA, RSA, Ed25519)
+}
+
+// EncodeKey encodes a private key as PKCS #8 PEM block
+func EncodeKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// parsePrivateKey parses a PKCS #8, PKCS #1 or SEC 1 encoded private key
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+		return nil, fmt.Errorf("unsupported private key %T", key)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("unsupported private key")
+}
+
+// LoadKey reads the first private key of a PEM or DER file
+func LoadKey(path string) (crypto.Signer, error) {
+	objects, err := LoadObjects(path, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range objects {
+		if o.Key != nil {
+			return o.Key, nil
+		}
+	}
+	return nil, fmt.Errorf("%s contains no private key", path)
+}
+
+// writeFile writes data to path. Private files are only readable by the owner.
+// Existing files are only replaced, if force is true, so that keys are not lost by accident.
+func writeFile(path string, data []byte, private, force bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !force {
+		flags |= os.O_EXCL
+	}
+	mode := os.FileMode(0644)
+	if private {
+		mode = 0600
+	}
+	f, err := os.OpenFile(path, flags, mode)
+	if os.IsExist(err) {
+		return fmt.Errorf("%s already exists, use --force to replace it", path)
+	}
+	if err != nil {
+		return err
+	}
+	if private {
+		// The mode of replaced files is not changed by OpenFile
+		if err := f.Chmod(mode); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,154 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+// Object types
+const (
+	TypeCertificate = "CERTIFICATE"
+	TypeRequest     = "CERTIFICATE REQUEST"
+	TypePrivateKey  = "PRIVATE KEY"
+	TypePublicKey   = "PUBLIC KEY"
+)
+
+// Object is a certificate, certificate request or key of a file
+type Object struct {
+	Type string
+	// DER is the encoded object, keys are encoded with PKCS #8 and PKIX
+	DER         []byte
+	Certificate *x509.Certificate
+	Request     *x509.CertificateRequest
+	Key         crypto.Signer
+	Public      crypto.PublicKey
+}
+
+// PublicKey returns the public key of the object
+func (o Object) PublicKey() crypto.PublicKey {
+	switch {
+	case o.Certificate != nil:
+		return o.Certificate.PublicKey
+	case o.Request != nil:
+		return o.Request.PublicKey
+	case o.Key != nil:
+		return o.Key.Public()
+	}
+	return o.Public
+}
+
+// LoadObjects reads the objects of a PEM, DER or PKCS #12 file. The password is only used for PKCS #12 files.
+func LoadObjects(path, password string) ([]Object, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := ParseObjects(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return objects, nil
+}
+
+// ParseObjects parses all objects of PEM data, or a single DER encoded object, or the certificates and key of a PKCS #12 file
+func ParseObjects(data []byte, password string) ([]Object, error) {
+	if strings.Contains(string(data), "-----BEGIN") {
+		return parsePEM(data)
+	}
+	if o, err := parseDER(data); err == nil {
+		return []Object{o}, nil
+	}
+
+	blocks, err := pkcs12.ToPEM(data, password)
+	if err == pkcs12.ErrIncorrectPassword {
+		return nil, errors.New("incorrect password for PKCS #12 data, set it with --password")
+	}
+	if err != nil {
+		return nil, errors.New("data is neither PEM, DER nor PKCS #12")
+	}
+	var objects []Object
+	for _, block := range blocks {
+		o, err := parseBlock(block)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, o)
+	}
+	return objects, nil
+}
+
+func parsePEM(data []byte) ([]Object, error) {
+	var objects []Object
+	rest := data
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		o, err := parseBlock(block)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, o)
+	}
+	if len(objects) == 0 {
+		return nil, errors.New("no PEM blocks found")
+	}
+	return objects, nil
+}
+
+func parseBlock(block *pem.Block) (Object, error) {
+	switch block.Type {
+	case TypeCertificate:
+		cert, err := x509.ParseCertificate(block.Bytes)
+		return Object{Type: TypeCertificate, DER: block.Bytes, Certificate: cert}, err
+	case TypeRequest, "NEW CERTIFICATE REQUEST":
+		req, err := x509.ParseCertificateRequest(block.Bytes)
+		return Object{Type: TypeRequest, DER: block.Bytes, Request: req}, err
+	case TypePrivateKey, "RSA PRIVATE KEY", "EC PRIVATE KEY":
+		return keyObject(block.Bytes)
+	case TypePublicKey:
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		return Object{Type: TypePublicKey, DER: block.Bytes, Public: key}, err
+	case "ENCRYPTED PRIVATE KEY":
+		return Object{}, errors.New("encrypted private keys are not supported")
+	}
+	return Object{}, fmt.Errorf("unsupported PEM block %q", block.Type)
+}
+
+func parseDER(der []byte) (Object, error) {
+	if cert, err := x509.ParseCertificate(der); err == nil {
+		return Object{Type: TypeCertificate, DER: der, Certificate: cert}, nil
+	}
+	if req, err := x509.ParseCertificateRequest(der); err == nil {
+		return Object{Type: TypeRequest, DER: der, Request: req}, nil
+	}
+	if o, err := keyObject(der); err == nil {
+		return o, nil
+	}
+	if key, err := x509.ParsePKIXPublicKey(der); err == nil {
+		return Object{Type: TypePublicKey, DER: der, Public: key}, nil
+	}
+	return Object{}, errors.New("unknown DER data")
+}
+
+// keyObject parses a private key and encodes it with PKCS #8
+func keyObject(der []byte) (Object, error) {
+	key, err := parsePrivateKey(der)
+	if err != nil {
+		return Object{}, err
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return Object{}, err
+	}
+	return Object{Type: TypePrivateKey, DER: pkcs8, Key: key}, nil
+}
@@ -0,0 +1,283 @@
+package cert
+
+import (
+	"crypto"
+	"crypto/cipher"
+	"crypto/des" //nolint:gosec
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"unicode/utf16"
+)
+
+// pkcs12Iterations is the iteration count of the key derivation, it's the default of OpenSSL
+const pkcs12Iterations = 2048
+
+var (
+	oidData                   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEncryptedData          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+	oidShroudedKeyBag         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidCertBag                = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidX509Certificate        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+	oidLocalKeyID             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 21}
+	oidPBEWithSHA3KeyTDES     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+	oidSHA1                   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	errPKCS12NeedsKey         = errors.New("PKCS #12 files need a private key, add it with --key")
+	errPKCS12NeedsCertificate = errors.New("PKCS #12 files need a certificate")
+)
+
+type pfx struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  macData
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type encryptedData struct {
+	Version              int
+	EncryptedContentInfo encryptedContentInfo
+}
+
+type encryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           []byte `asn1:"tag:0,optional"`
+}
+
+type safeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue     `asn1:"tag:0,explicit"`
+	Attributes []pkcs12Attribute `asn1:"set,optional"`
+}
+
+type pkcs12Attribute struct {
+	ID    asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+type certBag struct {
+	ID   asn1.ObjectIdentifier
+	Data asn1.RawValue `asn1:"tag:0,explicit"`
+}
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbeParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+type macData struct {
+	Mac        digestInfo
+	MacSalt    []byte
+	Iterations int
+}
+
+type digestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+// explicit wraps DER in an explicit [0] tag. encoding/asn1 ignores the tags of RawValue fields, so they are set here.
+func explicit(der []byte) asn1.RawValue {
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: der}
+}
+
+// EncodePKCS12 encodes a key and its certificate chain as PKCS #12 file, like 'openssl pkcs12 -export' does.
+// The key and certificates are encrypted with 3DES, which can be imported by browsers, Java and Windows.
+func EncodePKCS12(key crypto.Signer, certs []*x509.Certificate, password string) ([]byte, error) {
+	if key == nil {
+		return nil, errPKCS12NeedsKey
+	}
+	if len(certs) == 0 {
+		return nil, errPKCS12NeedsCertificate
+	}
+	secret := bmpString(password)
+
+	// The key and the certificate are connected by the hash of the certificate
+	keyID := sha1.Sum(certs[0].Raw) //nolint:gosec
+	keyIDValue, err := asn1.Marshal(keyID[:])
+	if err != nil {
+		return nil, err
+	}
+	localKeyID := []pkcs12Attribute{{
+		ID:    oidLocalKeyID,
+		Value: asn1.RawValue{Tag: asn1.TagSet, IsCompound: true, Bytes: keyIDValue},
+	}}
+
+	var certBags []safeBag
+	for i, cert := range certs {
+		data, err := asn1.Marshal(cert.Raw)
+		if err != nil {
+			return nil, err
+		}
+		value, err := asn1.Marshal(certBag{ID: oidX509Certificate, Data: explicit(data)})
+		if err != nil {
+			return nil, err
+		}
+		bag := safeBag{ID: oidCertBag, Value: explicit(value)}
+		if i == 0 {
+			bag.Attributes = localKeyID
+		}
+		certBags = append(certBags, bag)
+	}
+	certContents, err := asn1.Marshal(certBags)
+	if err != nil {
+		return nil, err
+	}
+	algorithm, encrypted, err := pbEncrypt(certContents, secret)
+	if err != nil {
+		return nil, err
+	}
+	certsInfo, err := asn1.Marshal(encryptedData{
+		EncryptedContentInfo: encryptedContentInfo{
+			ContentType:                oidData,
+			ContentEncryptionAlgorithm: algorithm,
+			EncryptedContent:           encrypted,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	algorithm, encrypted, err = pbEncrypt(pkcs8, secret)
+	if err != nil {
+		return nil, err
+	}
+	shroudedKey, err := asn1.Marshal(encryptedPrivateKeyInfo{Algorithm: algorithm, EncryptedData: encrypted})
+	if err != nil {
+		return nil, err
+	}
+	keyContents, err := asn1.Marshal([]safeBag{{ID: oidShroudedKeyBag, Value: explicit(shroudedKey), Attributes: localKeyID}})
+	if err != nil {
+		return nil, err
+	}
+	keyData, err := asn1.Marshal(keyContents)
+	if err != nil {
+		return nil, err
+	}
+
+	authSafe, err := asn1.Marshal([]contentInfo{
+		{ContentType: oidEncryptedData, Content: explicit(certsInfo)},
+		{ContentType: oidData, Content: explicit(keyData)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	authSafeData, err := asn1.Marshal(authSafe)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha1.New, pkcs12KDF(secret, salt, pkcs12Iterations, 3, 20))
+	mac.Write(authSafe)
+	return asn1.Marshal(pfx{
+		Version:  3,
+		AuthSafe: contentInfo{ContentType: oidData, Content: explicit(authSafeData)},
+		MacData: macData{
+			Mac: digestInfo{
+				Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA1, Parameters: asn1.NullRawValue},
+				Digest:    mac.Sum(nil),
+			},
+			MacSalt:    salt,
+			Iterations: pkcs12Iterations,
+		},
+	})
+}
+
+// pbEncrypt encrypts data with pbeWithSHAAnd3-KeyTripleDES-CBC
+func pbEncrypt(data, secret []byte) (pkix.AlgorithmIdentifier, []byte, error) {
+	salt := make([]byte, 8)
+	if _, err := rand.Read(salt); err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+	params, err := asn1.Marshal(pbeParams{Salt: salt, Iterations: pkcs12Iterations})
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+	block, err := des.NewTripleDESCipher(pkcs12KDF(secret, salt, pkcs12Iterations, 1, 24))
+	if err != nil {
+		return pkix.AlgorithmIdentifier{}, nil, err
+	}
+
+	padding := block.BlockSize() - len(data)%block.BlockSize()
+	encrypted := make([]byte, len(data)+padding)
+	copy(encrypted, data)
+	for i := len(data); i < len(encrypted); i++ {
+		encrypted[i] = byte(padding)
+	}
+	cipher.NewCBCEncrypter(block, pkcs12KDF(secret, salt, pkcs12Iterations, 2, 8)).CryptBlocks(encrypted, encrypted)
+
+	algorithm := pkix.AlgorithmIdentifier{Algorithm: oidPBEWithSHA3KeyTDES, Parameters: asn1.RawValue{FullBytes: params}}
+	return algorithm, encrypted, nil
+}
+
+// pkcs12KDF derives encryption keys (id 1), IVs (id 2) and MAC keys (id 3) from passwords with SHA-1, see RFC 7292 appendix B.2
+func pkcs12KDF(password, salt []byte, iterations int, id byte, size int) []byte {
+	const u, v = sha1.Size, 64
+	repeat := func(b []byte) []byte {
+		out := make([]byte, v*((len(b)+v-1)/v))
+		for i := range out {
+			out[i] = b[i%len(b)]
+		}
+		return out
+	}
+
+	diversifier := make([]byte, v)
+	for i := range diversifier {
+		diversifier[i] = id
+	}
+	input := append(repeat(salt), repeat(password)...)
+
+	var out []byte
+	for {
+		sum := sha1.Sum(append(append([]byte{}, diversifier...), input...)) //nolint:gosec
+		for i := 1; i < iterations; i++ {
+			sum = sha1.Sum(sum[:]) //nolint:gosec
+		}
+		out = append(out, sum[:]...)
+		if len(out) >= size {
+			return out[:size]
+		}
+
+		// Every block of the input is incremented by the hash and 1
+		b := repeat(sum[:u])
+		for j := 0; j < len(input); j += v {
+			carry := 1
+			for k := v - 1; k >= 0; k-- {
+				n := int(input[j+k]) + int(b[k]) + carry
+				input[j+k] = byte(n)
+				carry = n >> 8
+			}
+		}
+	}
+}
+
+// bmpString encodes a password as big endian UTF-16 with a terminating zero
+func bmpString(s string) []byte {
+	encoded := utf16.Encode([]rune(s + "\x00"))
+	out := make([]byte, 0, 2*len(encoded))
+	for _, r := range encoded {
+		out = append(out, byte(r>>8), byte(r))
+	}
+	return out
+}
@@ -74,7 +74,9 @@ func (Module) GetModuleCommands() []*cli.Command {
 
 				newSidebarContent += after
 
-				utils.WriteFile(sidebarPath, []byte(newSidebarContent), false)
+				if err := utils.WriteFile(sidebarPath, []byte(newSidebarContent), false); err != nil {
+					return err
+				}
 
 				say.Success("Documentation successfully generated!")
 
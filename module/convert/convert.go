@@ -0,0 +1,117 @@
+package convert
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:    "convert",
+			Aliases: []string{"conv"},
+			Usage:   "Converts structured data between JSON, YAML, TOML, CSV, XML, .env and HCL",
+			Description: `Convert reads structured data from a file, URL or stdin and writes it in another format.
+The input and output formats are detected by the file extension, if they are not set.
+The order of keys is kept as it appears in the input, unless --sort-keys is used.
+YAML streams with multiple documents, like Kubernetes manifests, are converted to a list of the documents.
+CSV files are converted to a list of objects, using the first row as header. XML attributes are prefixed with '-'.
+HCL support is limited to attributes, blocks, lists and objects.`,
+			Category: categories.DataAnalysis,
+			Examples: []cli.Example{
+				{
+					ShortDescription: "Convert a YAML file to JSON",
+					Usage:            "dops convert --from yaml --to json -i deployment.yaml",
+				},
+				{
+					ShortDescription: "Convert a JSON file to TOML, detecting both formats by extension",
+					Usage:            "dops convert -i config.json -o config.toml",
+				},
+				{
+					ShortDescription: "Print compact JSON with sorted keys from stdin",
+					Usage:            "cat data.yml | dops convert --from yaml --to json --compact --sort-keys",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				input := c.Path("input")
+				output := c.String("output")
+				from := c.Option("from")
+				to := c.Option("to")
+
+				content := utils.Input(input)
+
+				var err error
+				if from == "" {
+					from, err = DetectFormat(input)
+					if err != nil {
+						from = DetectFormatFromContent([]byte(content))
+					}
+				}
+				if to == "" {
+					if output == "" {
+						return errors.New("the output format could not be detected, please set --to")
+					}
+					to, err = DetectFormat(output)
+					if err != nil {
+						return err
+					}
+				}
+
+				value, err := Decode(from, []byte(content))
+				if err != nil {
+					return err
+				}
+
+				data, err := Encode(to, value, EncodeOptions{
+					Compact:  c.Bool("compact"),
+					SortKeys: c.Bool("sort-keys"),
+				})
+				if err != nil {
+					return err
+				}
+
+				return utils.WriteOutput(c, []string{strings.TrimSuffix(string(data), "\n")})
+			},
+			Flags: append([]cli.Flag{
+				&cli.OptionFlag{
+					Name:        "from",
+					Aliases:     []string{"f"},
+					Usage:       "Reads the input as `FORMAT`",
+					Options:     Formats,
+					DefaultText: "detected by extension",
+				},
+				&cli.OptionFlag{
+					Name:        "to",
+					Aliases:     []string{"t"},
+					Usage:       "Writes the output as `FORMAT`",
+					Options:     Formats,
+					DefaultText: "detected by extension",
+				},
+				&cli.PathFlag{
+					Name:      "input",
+					Aliases:   []string{"i"},
+					Usage:     "use `FILE` as input, accepts a file, URL or stdin if not set",
+					TakesFile: true,
+				},
+				&cli.BoolFlag{
+					Name:    "compact",
+					Aliases: []string{"c"},
+					Usage:   "Disables indentation for JSON and XML",
+				},
+				&cli.BoolFlag{
+					Name:    "sort-keys",
+					Aliases: []string{"s"},
+					Usage:   "Sorts the keys of all objects alphabetically",
+				},
+			}, utils.OutputFlags()...),
+		},
+	}
+}
@@ -0,0 +1,280 @@
+package convert
+
+import (
+	"strings"
+	"testing"
+)
+
+// compact encodes a value as compact JSON with sorted keys, so that values can be compared as strings
+func compact(t *testing.T, value interface{}) string {
+	t.Helper()
+	data, err := Encode(JSON, value, EncodeOptions{Compact: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSuffix(string(data), "\n")
+}
+
+func TestDecodeYAML(t *testing.T) {
+	tests := map[string]string{
+		"on: push\n":                                      `{"on":"push"}`,
+		"d: y\nn: no\nt: true\nf: False\n":                `{"d":"y","n":"no","t":true,"f":false}`,
+		"id: 0123\ni: 123\no: 0o17\nh: 0x1F\n":            `{"id":"0123","i":123,"o":15,"h":31}`,
+		"f: 1.5\ne: 1e3\ninf: .inf\n":                     `{"f":1.5,"e":1000,"inf":".inf"}`,
+		"a: ~\nb: null\nc:\n":                             `{"a":null,"b":null,"c":null}`,
+		"q: \"true\"\ns: '12'\nt: !!str 12\n":             `{"q":"true","s":"12","t":"12"}`,
+		"date: 2020-01-01\n":                              `{"date":"2020-01-01"}`,
+		"1: one\ntrue: yes\n":                             `{"1":"one","true":"yes"}`,
+		"- a\n- [1, {b: c}]\n":                            `["a",[1,{"b":"c"}]]`,
+		"base: &b {x: 1, y: 2}\nder:\n  <<: *b\n  y: 3\n": `{"base":{"x":1,"y":2},"der":{"x":1,"y":3}}`,
+		"---\na: 1\n---\n- b\n---\nc\n":                   `[{"a":1},["b"],"c"]`,
+		"---\na: 1\n---\n":                                `{"a":1}`,
+	}
+	for input, want := range tests {
+		value, err := Decode(YAML, []byte(input))
+		if err != nil {
+			t.Errorf("%q: %v", input, err)
+			continue
+		}
+		if got := compact(t, value); got != want {
+			t.Errorf("%q: got %s, want %s", input, got, want)
+		}
+	}
+}
+
+func TestDecodeDocuments(t *testing.T) {
+	documents, err := DecodeDocuments(YAML, []byte("kind: Service\n---\nkind: Deployment\n"))
+	if err != nil || len(documents) != 2 || compact(t, documents[1]) != `{"kind":"Deployment"}` {
+		t.Errorf("got %v, %v", documents, err)
+	}
+	documents, err = DecodeDocuments(JSON, []byte(`[1, 2]`))
+	if err != nil || len(documents) != 1 {
+		t.Errorf("got %v, %v", documents, err)
+	}
+}
+
+func TestDecodeYAMLRejectsRecursiveAliases(t *testing.T) {
+	if _, err := Decode(YAML, []byte("a: &a\n  b: *a\n")); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	workflow := `name: CI
+on:
+  push:
+    branches:
+    - main
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    env:
+      ID: "0123"
+      DEBUG: "y"
+      "n": "off"
+    steps:
+    - run: go test ./...
+`
+	value, err := Decode(YAML, []byte(workflow))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Encode(YAML, value, EncodeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := Decode(YAML, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if compact(t, value) != compact(t, again) {
+		t.Errorf("the values changed:\n%s\n%s", compact(t, value), compact(t, again))
+	}
+	if on, ok := value.(*Object).Get("on"); !ok || on == nil {
+		t.Errorf("the key 'on' was not kept, got %s", data)
+	}
+}
+
+const document = `{"name":"dops","version":2,"ratio":1.5,"enabled":true,"tags":["a","b"],"server":{"host":"localhost","port":8080},"users":[{"name":"x","age":3},{"name":"y","age":4}]}`
+
+// encoded contains the document in all formats, which keep types and structure
+var encoded = map[string]string{
+	JSON: document,
+	YAML: `name: dops
+version: 2
+ratio: 1.5
+enabled: true
+tags:
+- a
+- b
+server:
+  host: localhost
+  port: 8080
+users:
+- name: x
+  age: 3
+- name: "y"
+  age: 4
+`,
+	TOML: `name = "dops"
+version = 2
+ratio = 1.5
+enabled = true
+tags = ["a", "b"]
+
+[server]
+host = "localhost"
+port = 8080
+
+[[users]]
+name = "x"
+age = 3
+
+[[users]]
+name = "y"
+age = 4
+`,
+	HCL: `name = "dops"
+version = 2
+ratio = 1.5
+enabled = true
+tags = ["a", "b"]
+
+server {
+  host = "localhost"
+  port = 8080
+}
+
+users {
+  name = "x"
+  age = 3
+}
+
+users {
+  name = "y"
+  age = 4
+}
+`,
+}
+
+func TestConvertBetweenFormats(t *testing.T) {
+	for from, input := range encoded {
+		value, err := Decode(from, []byte(input))
+		if err != nil {
+			t.Errorf("%s: %v", from, err)
+			continue
+		}
+		if got := compact(t, value); got != document {
+			t.Errorf("%s: decoded %s", from, got)
+		}
+		for to, want := range encoded {
+			if to == JSON {
+				continue
+			}
+			data, err := Encode(to, value, EncodeOptions{})
+			if err != nil {
+				t.Errorf("%s to %s: %v", from, to, err)
+				continue
+			}
+			if string(data) != want {
+				t.Errorf("%s to %s: got\n%s\nwant\n%s", from, to, data, want)
+			}
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	value, err := Decode(JSON, []byte(document))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		Env: "NAME=dops\nVERSION=2\nRATIO=1.5\nENABLED=true\nTAGS_0=a\nTAGS_1=b\nSERVER_HOST=localhost\nSERVER_PORT=8080\n" +
+			"USERS_0_NAME=x\nUSERS_0_AGE=3\nUSERS_1_NAME=y\nUSERS_1_AGE=4\n",
+		XML: `<?xml version="1.0" encoding="UTF-8"?>
+<root>
+  <name>dops</name>
+  <version>2</version>
+  <ratio>1.5</ratio>
+  <enabled>true</enabled>
+  <tags>a</tags>
+  <tags>b</tags>
+  <server>
+    <host>localhost</host>
+    <port>8080</port>
+  </server>
+  <users>
+    <name>x</name>
+    <age>3</age>
+  </users>
+  <users>
+    <name>y</name>
+    <age>4</age>
+  </users>
+</root>
+`,
+	}
+	for format, want := range tests {
+		data, err := Encode(format, value, EncodeOptions{})
+		if err != nil || string(data) != want {
+			t.Errorf("%s: got\n%s\nwant\n%s\n%v", format, data, want, err)
+		}
+	}
+
+	data, err := Encode(JSON, value, EncodeOptions{Compact: true, SortKeys: true})
+	want := `{"enabled":true,"name":"dops","ratio":1.5,"server":{"host":"localhost","port":8080},"tags":["a","b"],"users":[{"age":3,"name":"x"},{"age":4,"name":"y"}],"version":2}`
+	if err != nil || strings.TrimSpace(string(data)) != want {
+		t.Errorf("sorted: got %s, %v", data, err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		format, input, want string
+	}{
+		{XML, `<a id="1"><b>x</b><c/><!-- c --><d k="v">t</d><e>1</e><e>2</e></a>`, `{"a":{"-id":"1","b":"x","c":"","d":{"-k":"v","#text":"t"},"e":["1","2"]}}`},
+		{Env, "# comment\nexport A=1\nB=\"x y\" # c\nC='$HOME'\nD=\n", `{"A":"1","B":"x y","C":"$HOME","D":""}`},
+		{HCL, "a = 1\nb \"x\" \"y\" {\n  c = [1, 2]\n}\n# comment\nd = true\n", `{"a":1,"b":{"x":{"y":{"c":[1,2]}}},"d":true}`},
+		{CSV, "name,age,admin\nx,3,true\ny,,false\n", `[{"name":"x","age":3,"admin":true},{"name":"y","age":"","admin":false}]`},
+		{TOML, "a = 1\n[b]\nc = \"x\"\n[[d]]\ne = 1.5\n", `{"a":1,"b":{"c":"x"},"d":[{"e":1.5}]}`},
+		{JSON, `{"b":1,"a":[true,null,"x",1e2]}`, `{"b":1,"a":[true,null,"x",100]}`},
+	}
+	for _, tt := range tests {
+		value, err := Decode(tt.format, []byte(tt.input))
+		if err != nil {
+			t.Errorf("%s: %v", tt.format, err)
+			continue
+		}
+		if got := compact(t, value); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	input := "name,age\nx,3\n\"y, z\",4\n"
+	value, err := Decode(CSV, []byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := Encode(CSV, value, EncodeOptions{})
+	if err != nil || string(data) != input {
+		t.Errorf("got %q, %v", data, err)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	for path, want := range map[string]string{"a.json": JSON, "b.YML": YAML, "c.tf": HCL, ".env.local": Env, "d.toml.gz": TOML, "x.csv": CSV} {
+		if got, err := DetectFormat(path); err != nil || got != want {
+			t.Errorf("%s: got %s, %v", path, got, err)
+		}
+	}
+	if _, err := DetectFormat("README"); err == nil {
+		t.Error("expected an error")
+	}
+	for content, want := range map[string]string{` {"a":1}`: JSON, "[1]": JSON, "<a/>": XML, "a: 1": YAML} {
+		if got := DetectFormatFromContent([]byte(content)); got != want {
+			t.Errorf("%q: got %s", content, got)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+)
+
+// decodeCSV returns one object per row, using the first row as header.
+// Numbers and booleans are detected automatically.
+func decodeCSV(data []byte) (interface{}, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []interface{}{}
+	if len(records) == 0 {
+		return rows, nil
+	}
+
+	header := records[0]
+	for _, record := range records[1:] {
+		row := NewObject()
+		for i, column := range header {
+			if i < len(record) {
+				row.Set(column, ParseScalar(record[i]))
+			} else {
+				row.Set(column, nil)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// encodeCSV writes a list of objects as CSV. The header contains all keys in the order they first appear.
+// A list of lists is written as plain rows without a header.
+func encodeCSV(value interface{}) ([]byte, error) {
+	var rows []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		rows = v
+	case *Object:
+		rows = []interface{}{v}
+	default:
+		return nil, errors.New("CSV requires a list of objects or a list of lists")
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	var header []string
+	seen := map[string]bool{}
+	for _, row := range rows {
+		if o, ok := row.(*Object); ok {
+			for _, key := range o.keys {
+				if !seen[key] {
+					seen[key] = true
+					header = append(header, key)
+				}
+			}
+		}
+	}
+
+	if len(header) > 0 {
+		if err := writer.Write(header); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, row := range rows {
+		var record []string
+		switch r := row.(type) {
+		case *Object:
+			for _, key := range header {
+				value, _ := r.Get(key)
+				record = append(record, ToString(value))
+			}
+		case []interface{}:
+			for _, value := range r {
+				record = append(record, ToString(value))
+			}
+		default:
+			record = []string{ToString(r)}
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
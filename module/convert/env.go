@@ -0,0 +1,103 @@
+package convert
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	envInvalidKeyChars = regexp.MustCompile(`[^A-Z0-9_]`)
+	envPlainValue      = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,-]*$`)
+)
+
+// decodeEnv parses a .env file. Lines can start with 'export' and values can be single or double quoted.
+func decodeEnv(data []byte) (interface{}, error) {
+	o := NewObject()
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid env file: missing '=' in line %d", lineNumber)
+		}
+
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+
+		switch {
+		case strings.HasPrefix(value, `"`):
+			end := strings.LastIndex(value, `"`)
+			if end == 0 {
+				return nil, fmt.Errorf("invalid env file: unterminated quote in line %d", lineNumber)
+			}
+			unquoted, err := strconv.Unquote(value[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid env file: %v in line %d", err, lineNumber)
+			}
+			value = unquoted
+		case strings.HasPrefix(value, "'"):
+			end := strings.LastIndex(value, "'")
+			if end == 0 {
+				return nil, fmt.Errorf("invalid env file: unterminated quote in line %d", lineNumber)
+			}
+			value = value[1:end]
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+
+		o.Set(key, value)
+	}
+
+	return o, scanner.Err()
+}
+
+// encodeEnv writes a flat list of variables. Nested keys are joined with '_' and converted to upper case.
+func encodeEnv(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	writeEnv(&buf, "", value)
+	return buf.Bytes(), nil
+}
+
+func writeEnv(buf *bytes.Buffer, prefix string, value interface{}) {
+	join := func(key string) string {
+		key = envInvalidKeyChars.ReplaceAllString(strings.ToUpper(key), "_")
+		if prefix == "" {
+			return key
+		}
+		return prefix + "_" + key
+	}
+
+	switch v := value.(type) {
+	case *Object:
+		for _, key := range v.keys {
+			writeEnv(buf, join(key), v.values[key])
+		}
+	case []interface{}:
+		for i, item := range v {
+			writeEnv(buf, join(strconv.Itoa(i)), item)
+		}
+	default:
+		if prefix == "" {
+			prefix = "VALUE"
+		}
+		s := ToString(v)
+		if !envPlainValue.MatchString(s) {
+			s = strconv.Quote(s)
+		}
+		buf.WriteString(prefix + "=" + s + "\n")
+	}
+}
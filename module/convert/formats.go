@@ -0,0 +1,240 @@
+package convert
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Supported formats
+const (
+	JSON = "json"
+	YAML = "yaml"
+	TOML = "toml"
+	CSV  = "csv"
+	XML  = "xml"
+	Env  = "env"
+	HCL  = "hcl"
+)
+
+// Formats contains all formats, which can be decoded and encoded
+var Formats = []string{JSON, YAML, TOML, CSV, XML, Env, HCL}
+
+var extensions = map[string]string{
+	".json": JSON,
+	".yaml": YAML,
+	".yml":  YAML,
+	".toml": TOML,
+	".csv":  CSV,
+	".xml":  XML,
+	".env":  Env,
+	".hcl":  HCL,
+	".tf":   HCL,
+}
+
+// EncodeOptions configure how a value is encoded
+type EncodeOptions struct {
+	// Compact disables indentation for formats which support it (JSON and XML)
+	Compact bool
+	// SortKeys sorts the keys of all objects alphabetically
+	SortKeys bool
+}
+
+// DetectFormat returns the format of a file by its extension.
+// Files named like '.env' or '.env.local' are detected as env files.
+func DetectFormat(path string) (string, error) {
+	base := strings.ToLower(filepath.Base(path))
+	// Compressed files are decompressed and compressed transparently, like config.json.gz
+	for _, ext := range []string{".gz", ".bz2", ".xz", ".zst"} {
+		base = strings.TrimSuffix(base, ext)
+	}
+	if base == ".env" || strings.HasPrefix(base, ".env.") {
+		return Env, nil
+	}
+	if format, ok := extensions[filepath.Ext(base)]; ok {
+		return format, nil
+	}
+	return "", errors.New("could not detect format of " + path)
+}
+
+// DetectFormatFromContent guesses the format of data, if it can't be detected by a file extension.
+// Only JSON and XML can be detected reliably, everything else is treated as YAML.
+func DetectFormatFromContent(data []byte) string {
+	trimmed := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(trimmed, "{"), strings.HasPrefix(trimmed, "["):
+		return JSON
+	case strings.HasPrefix(trimmed, "<"):
+		return XML
+	}
+	return YAML
+}
+
+// Decode parses data of a specific format into a generic value.
+// Objects are returned as *Object, arrays as []interface{} and numbers as int64 or float64.
+func Decode(format string, data []byte) (interface{}, error) {
+	switch format {
+	case JSON:
+		return decodeJSON(data)
+	case YAML:
+		return decodeYAML(data)
+	case TOML:
+		return decodeTOML(data)
+	case CSV:
+		return decodeCSV(data)
+	case XML:
+		return decodeXML(data)
+	case Env:
+		return decodeEnv(data)
+	case HCL:
+		return decodeHCL(data)
+	}
+	return nil, errors.New("unknown format: " + format)
+}
+
+// DecodeDocuments parses data like Decode, but returns every document of a YAML stream on its own.
+// Other formats always contain a single document.
+func DecodeDocuments(format string, data []byte) ([]interface{}, error) {
+	if format == YAML {
+		return decodeYAMLDocuments(data)
+	}
+	value, err := Decode(format, data)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{value}, nil
+}
+
+// Encode converts a generic value, as returned by Decode, into a specific format.
+func Encode(format string, value interface{}, options EncodeOptions) ([]byte, error) {
+	value = Normalize(value)
+	if options.SortKeys {
+		SortKeys(value)
+	}
+
+	switch format {
+	case JSON:
+		return encodeJSON(value, options)
+	case YAML:
+		return yaml.Marshal(toYAML(value))
+	case TOML:
+		return encodeTOML(value)
+	case CSV:
+		return encodeCSV(value)
+	case XML:
+		return encodeXML(value, options)
+	case Env:
+		return encodeEnv(value)
+	case HCL:
+		return encodeHCL(value)
+	}
+	return nil, errors.New("unknown format: " + format)
+}
+
+// Normalize converts the values of third party decoders into the types used by this package.
+func Normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, string, bool, int64, float64:
+		return v
+	case *Object:
+		for _, key := range v.keys {
+			v.values[key] = Normalize(v.values[key])
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = Normalize(v[i])
+		}
+		return v
+	case yaml.MapSlice:
+		o := NewObject()
+		for _, item := range v {
+			o.Set(fmt.Sprint(item.Key), Normalize(item.Value))
+		}
+		return o
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []byte:
+		return string(v)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return float64(rv.Uint())
+		}
+		return int64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.Slice, reflect.Array:
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			list[i] = Normalize(rv.Index(i).Interface())
+		}
+		return list
+	case reflect.Map:
+		o := NewObject()
+		keys := rv.MapKeys()
+		names := make([]string, len(keys))
+		for i, k := range keys {
+			names[i] = fmt.Sprint(k.Interface())
+		}
+		for i, k := range keys {
+			o.Set(names[i], Normalize(rv.MapIndex(k).Interface()))
+		}
+		SortKeys(o)
+		return o
+	}
+
+	return fmt.Sprint(value)
+}
+
+// ToString returns the string representation of a scalar value.
+// Objects and arrays are returned as compact JSON.
+func ToString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	data, err := encodeJSON(value, EncodeOptions{Compact: true})
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSuffix(string(data), "\n")
+}
+
+// ParseScalar converts a string into a number or boolean, if possible.
+// Strings, which are neither, are returned unchanged.
+func ParseScalar(s string) interface{} {
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return f
+	}
+	return s
+}
@@ -0,0 +1,331 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// HCL-lite supports attributes, blocks with labels, lists, objects and comments.
+// Expressions, functions and heredocs of the full HCL syntax are not supported.
+
+var hclIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+type hclParser struct {
+	src  []rune
+	pos  int
+	line int
+}
+
+func decodeHCL(data []byte) (interface{}, error) {
+	p := &hclParser{src: []rune(string(data)), line: 1}
+	body, err := p.parseBody(false)
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func (p *hclParser) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("invalid HCL in line %d: %s", p.line, fmt.Sprintf(format, a...))
+}
+
+func (p *hclParser) peek() rune {
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *hclParser) next() rune {
+	r := p.peek()
+	if r == '\n' {
+		p.line++
+	}
+	p.pos++
+	return r
+}
+
+// skip skips whitespace and comments. Newlines are only skipped if newlines is true.
+func (p *hclParser) skip(newlines bool) {
+	for p.pos < len(p.src) {
+		r := p.peek()
+		switch {
+		case r == '\n' && !newlines:
+			return
+		case unicode.IsSpace(r), r == ',' && newlines:
+			p.next()
+		case r == '#', r == '/' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '/':
+			for p.pos < len(p.src) && p.peek() != '\n' {
+				p.next()
+			}
+		case r == '/' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '*':
+			p.pos += 2
+			for p.pos < len(p.src) && !(p.peek() == '*' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '/') {
+				p.next()
+			}
+			p.pos += 2
+		default:
+			return
+		}
+	}
+}
+
+func (p *hclParser) parseBody(nested bool) (*Object, error) {
+	body := NewObject()
+
+	for {
+		p.skip(true)
+		if p.pos >= len(p.src) {
+			if nested {
+				return nil, p.errorf("missing '}'")
+			}
+			return body, nil
+		}
+		if p.peek() == '}' {
+			if !nested {
+				return nil, p.errorf("unexpected '}'")
+			}
+			p.next()
+			return body, nil
+		}
+
+		name, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skip(false)
+		if p.peek() == '=' || p.peek() == ':' {
+			p.next()
+			p.skip(false)
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			body.Set(name, value)
+			continue
+		}
+
+		var labels []string
+		for p.peek() == '"' {
+			label, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			labels = append(labels, label)
+			p.skip(false)
+		}
+		if p.peek() != '{' {
+			return nil, p.errorf("expected '=' or '{' after %q", name)
+		}
+		p.next()
+		block, err := p.parseBody(true)
+		if err != nil {
+			return nil, err
+		}
+		setHCLBlock(body, append([]string{name}, labels...), block)
+	}
+}
+
+// setHCLBlock nests a block by its type and labels. Repeated blocks with the same path become a list.
+func setHCLBlock(body *Object, path []string, block *Object) {
+	for _, key := range path[:len(path)-1] {
+		child, ok := body.Get(key)
+		childObject, isObject := child.(*Object)
+		if !ok || !isObject {
+			childObject = NewObject()
+			body.Set(key, childObject)
+		}
+		body = childObject
+	}
+
+	key := path[len(path)-1]
+	existing, ok := body.Get(key)
+	switch e := existing.(type) {
+	case []interface{}:
+		body.Set(key, append(e, block))
+	default:
+		if ok {
+			body.Set(key, []interface{}{existing, block})
+		} else {
+			body.Set(key, block)
+		}
+	}
+}
+
+func (p *hclParser) parseKey() (string, error) {
+	if p.peek() == '"' {
+		return p.parseString()
+	}
+	start := p.pos
+	for p.pos < len(p.src) {
+		r := p.peek()
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.') {
+			break
+		}
+		p.next()
+	}
+	if start == p.pos {
+		return "", p.errorf("unexpected character %q", p.peek())
+	}
+	return string(p.src[start:p.pos]), nil
+}
+
+func (p *hclParser) parseValue() (interface{}, error) {
+	switch r := p.peek(); {
+	case r == '"':
+		return p.parseString()
+	case r == '[':
+		p.next()
+		list := []interface{}{}
+		for {
+			p.skip(true)
+			if p.peek() == ']' {
+				p.next()
+				return list, nil
+			}
+			if p.pos >= len(p.src) {
+				return nil, p.errorf("missing ']'")
+			}
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+	case r == '{':
+		p.next()
+		return p.parseBody(true)
+	}
+
+	start := p.pos
+	for p.pos < len(p.src) {
+		r := p.peek()
+		if unicode.IsSpace(r) || r == ',' || r == ']' || r == '}' || r == '#' {
+			break
+		}
+		p.next()
+	}
+	literal := string(p.src[start:p.pos])
+
+	switch literal {
+	case "":
+		return nil, p.errorf("missing value")
+	case "null":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if i, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(literal, 64); err == nil {
+		return f, nil
+	}
+
+	// Unsupported expressions (references, function calls, ...) are kept as plain text
+	return literal, nil
+}
+
+func (p *hclParser) parseString() (string, error) {
+	start := p.pos
+	p.next()
+	for p.pos < len(p.src) {
+		switch p.next() {
+		case '\\':
+			p.next()
+		case '"':
+			return strconv.Unquote(string(p.src[start:p.pos]))
+		case '\n':
+			return "", p.errorf("unterminated string")
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// encodeHCL writes objects as blocks and everything else as attributes.
+func encodeHCL(value interface{}) ([]byte, error) {
+	root, ok := value.(*Object)
+	if !ok {
+		return nil, fmt.Errorf("HCL requires an object at the top level")
+	}
+
+	var buf bytes.Buffer
+	writeHCLBody(&buf, root, 0)
+	return buf.Bytes(), nil
+}
+
+func writeHCLBody(buf *bytes.Buffer, o *Object, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	var blocks []string
+	written := false
+	for _, key := range o.keys {
+		value := o.values[key]
+		if isTOMLTable(value) || isTOMLTableArray(value) {
+			blocks = append(blocks, key)
+			continue
+		}
+		buf.WriteString(indent + hclKey(key) + " = " + hclValue(value) + "\n")
+		written = true
+	}
+
+	for _, key := range blocks {
+		var bodies []*Object
+		switch v := o.values[key].(type) {
+		case *Object:
+			bodies = []*Object{v}
+		case []interface{}:
+			for _, item := range v {
+				bodies = append(bodies, item.(*Object))
+			}
+		}
+		for _, body := range bodies {
+			if written {
+				buf.WriteString("\n")
+			}
+			written = true
+			buf.WriteString(indent + hclKey(key) + " {\n")
+			writeHCLBody(buf, body, depth+1)
+			buf.WriteString(indent + "}\n")
+		}
+	}
+}
+
+func hclKey(key string) string {
+	if hclIdentifier.MatchString(key) {
+		return key
+	}
+	return jsonString(key)
+}
+
+func hclValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return jsonString(v)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = hclValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case *Object:
+		items := make([]string, len(v.keys))
+		for i, key := range v.keys {
+			items[i] = hclKey(key) + " = " + hclValue(v.values[key])
+		}
+		if len(items) == 0 {
+			return "{}"
+		}
+		return "{ " + strings.Join(items, ", ") + " }"
+	}
+	return ToString(value)
+}
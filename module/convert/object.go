@@ -0,0 +1,68 @@
+package convert
+
+import "sort"
+
+// Object is a map, which keeps the order of its keys as they appeared in the input.
+// All decoders return objects as *Object, so that the output of a conversion keeps the original key order.
+type Object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewObject returns an empty object
+func NewObject() *Object {
+	return &Object{values: map[string]interface{}{}}
+}
+
+// Set sets the value of key. New keys are appended to the end of the object.
+func (o *Object) Set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// Get returns the value of key and whether the key exists
+func (o *Object) Get(key string) (interface{}, bool) {
+	value, ok := o.values[key]
+	return value, ok
+}
+
+// Delete removes key from the object
+func (o *Object) Delete(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns the keys of the object in order
+func (o *Object) Keys() []string {
+	return o.keys
+}
+
+// Len returns the amount of keys in the object
+func (o *Object) Len() int {
+	return len(o.keys)
+}
+
+// SortKeys sorts the keys of the object and all nested objects alphabetically
+func SortKeys(value interface{}) {
+	switch v := value.(type) {
+	case *Object:
+		sort.Strings(v.keys)
+		for _, child := range v.values {
+			SortKeys(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			SortKeys(child)
+		}
+	}
+}
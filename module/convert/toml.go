@@ -0,0 +1,229 @@
+package convert
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+var tomlBareKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func decodeTOML(data []byte) (interface{}, error) {
+	var m map[string]interface{}
+	meta, err := toml.Decode(string(data), &m)
+	if err != nil {
+		return nil, err
+	}
+
+	// The TOML decoder returns plain maps, so the original key order is restored from the metadata.
+	order := map[string]int{}
+	for i, key := range meta.Keys() {
+		path := strings.Join(key, "\x00")
+		if _, ok := order[path]; !ok {
+			order[path] = i
+		}
+	}
+
+	value := Normalize(m)
+	restoreTOMLOrder(value, "", order)
+	return value, nil
+}
+
+func restoreTOMLOrder(value interface{}, path string, order map[string]int) {
+	switch v := value.(type) {
+	case *Object:
+		prefix := path
+		if prefix != "" {
+			prefix += "\x00"
+		}
+		sort.SliceStable(v.keys, func(i, j int) bool {
+			return order[prefix+v.keys[i]] < order[prefix+v.keys[j]]
+		})
+		for _, key := range v.keys {
+			restoreTOMLOrder(v.values[key], prefix+key, order)
+		}
+	case []interface{}:
+		for _, item := range v {
+			restoreTOMLOrder(item, path, order)
+		}
+	}
+}
+
+func encodeTOML(value interface{}) ([]byte, error) {
+	root, ok := value.(*Object)
+	if !ok {
+		return nil, errors.New("TOML requires an object at the top level")
+	}
+
+	var buf bytes.Buffer
+	err := writeTOMLTable(&buf, root, nil)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimLeft(buf.Bytes(), "\n"), nil
+}
+
+// writeTOMLTable writes all plain key/value pairs of a table first, followed by its sub tables,
+// because TOML assigns every key after a table header to that table.
+func writeTOMLTable(buf *bytes.Buffer, o *Object, path []string) error {
+	var tables []string
+
+	for _, key := range o.keys {
+		value := o.values[key]
+		if value == nil {
+			continue
+		}
+		if isTOMLTable(value) || isTOMLTableArray(value) {
+			tables = append(tables, key)
+			continue
+		}
+		s, err := tomlValue(value)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(tomlKey(key) + " = " + s + "\n")
+	}
+
+	for _, key := range tables {
+		childPath := append(append([]string{}, path...), key)
+		header := make([]string, len(childPath))
+		for i, k := range childPath {
+			header[i] = tomlKey(k)
+		}
+
+		switch v := o.values[key].(type) {
+		case *Object:
+			buf.WriteString("\n[" + strings.Join(header, ".") + "]\n")
+			if err := writeTOMLTable(buf, v, childPath); err != nil {
+				return err
+			}
+		case []interface{}:
+			for _, item := range v {
+				buf.WriteString("\n[[" + strings.Join(header, ".") + "]]\n")
+				if err := writeTOMLTable(buf, item.(*Object), childPath); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func isTOMLTable(value interface{}) bool {
+	_, ok := value.(*Object)
+	return ok
+}
+
+func isTOMLTableArray(value interface{}) bool {
+	list, ok := value.([]interface{})
+	if !ok || len(list) == 0 {
+		return false
+	}
+	for _, item := range list {
+		if !isTOMLTable(item) {
+			return false
+		}
+	}
+	return true
+}
+
+func tomlKey(key string) string {
+	if tomlBareKey.MatchString(key) {
+		return key
+	}
+	return tomlString(key)
+}
+
+func tomlValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return tomlString(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "nan", nil
+		case math.IsInf(v, 1):
+			return "inf", nil
+		case math.IsInf(v, -1):
+			return "-inf", nil
+		}
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eEn") {
+			s += ".0"
+		}
+		return s, nil
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			if item == nil {
+				continue
+			}
+			s, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, s)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case *Object:
+		items := make([]string, 0, v.Len())
+		for _, key := range v.keys {
+			if v.values[key] == nil {
+				continue
+			}
+			s, err := tomlValue(v.values[key])
+			if err != nil {
+				return "", err
+			}
+			items = append(items, tomlKey(key)+" = "+s)
+		}
+		if len(items) == 0 {
+			return "{}", nil
+		}
+		return "{ " + strings.Join(items, ", ") + " }", nil
+	}
+	return "", fmt.Errorf("unsupported type in TOML: %T", value)
+}
+
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,204 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	// xmlAttributePrefix marks keys, which are written as XML attributes
+	xmlAttributePrefix = "-"
+	// xmlTextKey contains the text of an element, which also has attributes or child elements
+	xmlTextKey = "#text"
+	// xmlDefaultRoot is used as root element, if the value doesn't have exactly one top-level key
+	xmlDefaultRoot = "root"
+	// xmlDefaultItem is used as element name for items of top-level lists
+	xmlDefaultItem = "item"
+)
+
+var xmlInvalidNameChars = regexp.MustCompile(`[^A-Za-z0-9_.:-]`)
+
+// decodeXML converts an XML document into an object.
+// Attributes are prefixed with '-', repeated elements become lists and
+// elements without attributes and children are stored as plain text.
+func decodeXML(data []byte) (interface{}, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			return nil, errors.New("invalid XML: no root element found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			value, err := decodeXMLElement(dec, start)
+			if err != nil {
+				return nil, err
+			}
+			root := NewObject()
+			root.Set(start.Name.Local, value)
+			return root, nil
+		}
+	}
+}
+
+func decodeXMLElement(dec *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	o := NewObject()
+	for _, attr := range start.Attr {
+		o.Set(xmlAttributePrefix+attr.Name.Local, attr.Value)
+	}
+
+	var text strings.Builder
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(dec, t)
+			if err != nil {
+				return nil, err
+			}
+			name := t.Name.Local
+			if existing, ok := o.Get(name); ok {
+				if list, ok := existing.([]interface{}); ok {
+					o.Set(name, append(list, child))
+				} else {
+					o.Set(name, []interface{}{existing, child})
+				}
+			} else {
+				o.Set(name, child)
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			if o.Len() == 0 {
+				return content, nil
+			}
+			if content != "" {
+				o.Set(xmlTextKey, content)
+			}
+			return o, nil
+		}
+	}
+}
+
+func encodeXML(value interface{}, options EncodeOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	rootName := xmlDefaultRoot
+	if o, ok := value.(*Object); ok && o.Len() == 1 && !strings.HasPrefix(o.keys[0], xmlAttributePrefix) {
+		rootName = o.keys[0]
+		value = o.values[rootName]
+	}
+
+	if list, ok := value.([]interface{}); ok {
+		wrapper := NewObject()
+		wrapper.Set(xmlDefaultItem, list)
+		value = wrapper
+	}
+
+	err := writeXMLElement(&buf, rootName, value, options.Compact, 0)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+func writeXMLElement(buf *bytes.Buffer, name string, value interface{}, compact bool, depth int) error {
+	indent := func(depth int) {
+		if !compact {
+			buf.WriteString("\n" + strings.Repeat("  ", depth))
+		}
+	}
+
+	if list, ok := value.([]interface{}); ok {
+		for i, item := range list {
+			if i > 0 {
+				indent(depth)
+			}
+			if err := writeXMLElement(buf, name, item, compact, depth); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	name = xmlName(name)
+	buf.WriteString("<" + name)
+
+	o, ok := value.(*Object)
+	if !ok {
+		if value == nil {
+			buf.WriteString("/>")
+			return nil
+		}
+		buf.WriteByte('>')
+		if err := xml.EscapeText(buf, []byte(ToString(value))); err != nil {
+			return err
+		}
+		buf.WriteString("</" + name + ">")
+		return nil
+	}
+
+	var children []string
+	for _, key := range o.keys {
+		if strings.HasPrefix(key, xmlAttributePrefix) {
+			buf.WriteString(" " + xmlName(strings.TrimPrefix(key, xmlAttributePrefix)) + `="`)
+			if err := xml.EscapeText(buf, []byte(ToString(o.values[key]))); err != nil {
+				return err
+			}
+			buf.WriteByte('"')
+		} else if key != xmlTextKey {
+			children = append(children, key)
+		}
+	}
+
+	text, hasText := o.Get(xmlTextKey)
+	if len(children) == 0 && !hasText {
+		buf.WriteString("/>")
+		return nil
+	}
+	buf.WriteByte('>')
+
+	if hasText {
+		if err := xml.EscapeText(buf, []byte(ToString(text))); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range children {
+		indent(depth + 1)
+		if err := writeXMLElement(buf, key, o.values[key], compact, depth+1); err != nil {
+			return err
+		}
+	}
+
+	if len(children) > 0 {
+		indent(depth)
+	}
+	buf.WriteString("</" + name + ">")
+
+	return nil
+}
+
+// xmlName replaces all characters, which are not allowed in XML element and attribute names
+func xmlName(name string) string {
+	name = xmlInvalidNameChars.ReplaceAllString(name, "_")
+	if name == "" || strings.ContainsAny(name[:1], "0123456789.-") {
+		name = "_" + name
+	}
+	return name
+}
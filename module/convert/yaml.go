@@ -0,0 +1,222 @@
+package convert
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yaml3 "gopkg.in/yaml.v3"
+)
+
+// maxYAMLValues limits the values created by aliases, so that documents like "billion laughs" can't exhaust the memory
+const maxYAMLValues = 10000000
+
+var (
+	yamlInt   = regexp.MustCompile(`^[-+]?(0|[1-9][0-9]*)$`)
+	yamlOctal = regexp.MustCompile(`^0o[0-7]+$`)
+	yamlHex   = regexp.MustCompile(`^0x[0-9a-fA-F]+$`)
+	yamlFloat = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// yamlDecoder converts YAML nodes into values. Scalars are resolved like in the YAML 1.2 core schema,
+// because yaml.v2 uses YAML 1.1, which turns keys like 'on' and values like 'y' into booleans and '0123' into an octal number.
+// Unlike the core schema, integers with leading zeros like '012' are kept as strings, see yamlScalar.
+type yamlDecoder struct {
+	values int
+	// aliases contains the aliases, which are currently expanded, to detect recursive aliases
+	aliases map[*yaml3.Node]bool
+}
+
+// decodeYAML decodes a YAML stream. A stream with multiple documents is decoded into a list of the documents.
+func decodeYAML(data []byte) (interface{}, error) {
+	documents, err := decodeYAMLDocuments(data)
+	if err != nil || len(documents) == 0 {
+		return nil, err
+	}
+	if len(documents) == 1 {
+		return documents[0], nil
+	}
+	return documents, nil
+}
+
+// decodeYAMLDocuments decodes every document of a YAML stream
+func decodeYAMLDocuments(data []byte) ([]interface{}, error) {
+	decoder := yaml3.NewDecoder(bytes.NewReader(data))
+	d := &yamlDecoder{aliases: map[*yaml3.Node]bool{}}
+	var documents []interface{}
+	for {
+		var document yaml3.Node
+		err := decoder.Decode(&document)
+		if err == io.EOF {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(document.Content) == 0 || emptyYAML(document.Content[0]) {
+			continue
+		}
+		value, err := d.value(document.Content[0])
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, value)
+	}
+}
+
+// emptyYAML returns true for the content of an empty document, like the one after a trailing '---'
+func emptyYAML(n *yaml3.Node) bool {
+	return n.Kind == yaml3.ScalarNode && n.Value == "" && n.Style == 0 && n.ShortTag() == "!!null"
+}
+
+func (d *yamlDecoder) value(n *yaml3.Node) (interface{}, error) {
+	d.values++
+	if d.values > maxYAMLValues {
+		return nil, errors.New("the YAML document contains too many values, probably because of nested aliases")
+	}
+
+	switch n.Kind {
+	case yaml3.AliasNode:
+		if d.aliases[n] {
+			return nil, errors.New("the YAML alias *" + n.Value + " contains itself")
+		}
+		d.aliases[n] = true
+		defer delete(d.aliases, n)
+		return d.value(n.Alias)
+	case yaml3.SequenceNode:
+		list := make([]interface{}, len(n.Content))
+		for i, item := range n.Content {
+			value, err := d.value(item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = value
+		}
+		return list, nil
+	case yaml3.MappingNode:
+		o := NewObject()
+		return o, d.mapping(o, n)
+	}
+	return yamlScalar(n), nil
+}
+
+// mapping sets the pairs of a mapping node in o. Keys are always kept as strings.
+// The merge key '<<' adds the keys of other mappings, which are not set explicitly.
+func (d *yamlDecoder) mapping(o *Object, n *yaml3.Node) error {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if key.Kind == yaml3.ScalarNode && key.Value == "<<" && key.ShortTag() == "!!merge" {
+			if err := d.merge(o, value); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := key.Value
+		if key.Kind != yaml3.ScalarNode {
+			k, err := d.value(key)
+			if err != nil {
+				return err
+			}
+			name = ToString(k)
+		}
+		v, err := d.value(value)
+		if err != nil {
+			return err
+		}
+		o.Set(name, v)
+	}
+	return nil
+}
+
+// merge adds the keys of a mapping, which are missing in o. Keys set explicitly after the merge key replace them later.
+func (d *yamlDecoder) merge(o *Object, n *yaml3.Node) error {
+	if n.Kind == yaml3.SequenceNode {
+		for _, item := range n.Content {
+			if err := d.merge(o, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	value, err := d.value(n)
+	if err != nil {
+		return err
+	}
+	merged, ok := value.(*Object)
+	if !ok {
+		return errors.New("the YAML merge key '<<' needs a mapping")
+	}
+	for _, key := range merged.Keys() {
+		if _, ok := o.Get(key); !ok {
+			v, _ := merged.Get(key)
+			o.Set(key, v)
+		}
+	}
+	return nil
+}
+
+// yamlScalar resolves a scalar node. Quoted scalars and scalars tagged as !!str are always strings.
+// Numbers with leading zeros, timestamps and the special floats .inf and .nan are kept as strings, so that they don't change.
+func yamlScalar(n *yaml3.Node) interface{} {
+	if n.Style&(yaml3.DoubleQuotedStyle|yaml3.SingleQuotedStyle|yaml3.LiteralStyle|yaml3.FoldedStyle) != 0 {
+		return n.Value
+	}
+	if n.Style&yaml3.TaggedStyle != 0 && n.ShortTag() == "!!str" {
+		return n.Value
+	}
+
+	s := n.Value
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	switch {
+	case yamlInt.MatchString(s):
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	case yamlOctal.MatchString(s):
+		if i, err := strconv.ParseInt(s[2:], 8, 64); err == nil {
+			return i
+		}
+	case yamlHex.MatchString(s):
+		if i, err := strconv.ParseInt(s[2:], 16, 64); err == nil {
+			return i
+		}
+	case yamlFloat.MatchString(s) && strings.ContainsAny(s, ".eE"):
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	}
+	return s
+}
+
+func toYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *Object:
+		mapSlice := make(yaml.MapSlice, 0, v.Len())
+		for _, key := range v.keys {
+			mapSlice = append(mapSlice, yaml.MapItem{Key: key, Value: toYAML(v.values[key])})
+		}
+		return mapSlice
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = toYAML(item)
+		}
+		return list
+	}
+	return value
+}
@@ -0,0 +1,167 @@
+package cron
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/module/timetool"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:      "cron",
+			Usage:     "Validates and explains cron expressions and lists their next runs",
+			ArgsUsage: "EXPRESSION",
+			// Flags are also accepted after the expression
+			InterspersedFlags: true,
+			Description: `Cron validates a cron expression, explains it in plain English and lists the next times it runs,
+to double-check schedules of CI pipelines and Kubernetes CronJobs before they are deployed.
+
+Expressions have 5 fields (minute, hour, day of month, month, day of week), 6 fields with seconds first,
+or 7 fields with seconds and years like in Quartz. Macros like @daily, @hourly or @every 1h30m are supported as well.
+Fields can contain lists (1,15), ranges (1-5), steps (*/15, 0-30/10) and names (JAN, MON-FRI).
+The Quartz characters L (last), W (nearest weekday), # (nth day of week) and ? (no specific value) are supported.
+In Quartz expressions, which have 7 fields or contain a '?', the days of the week are numbered from 1 (Sunday) to 7 (Saturday),
+otherwise from 0 (Sunday) to 6 (Saturday). If both days of month and days of week are restricted, a day matches if either matches.
+
+A CRON_TZ= or TZ= prefix sets the timezone of the expression. Use --standard to reject syntax, which Kubernetes CronJobs and most CI systems don't support.
+The expression is read from stdin, if it's not given as argument.`,
+			Category: categories.DataAnalysis,
+			Examples: []cli.Example{
+				{
+					ShortDescription: "Explain a schedule and show its next 10 runs",
+					Usage:            "dops cron '*/15 9-17 * * 1-5'",
+				},
+				{
+					ShortDescription: "Show the next 3 runs of a Kubernetes CronJob in UTC and check that it uses standard syntax",
+					Usage:            "dops cron --standard --zone UTC --count 3 '0 2 * * SUN'",
+				},
+				{
+					ShortDescription: "Explain a Quartz expression, which runs on the last Friday of every month",
+					Usage:            "dops cron '0 0 18 ? * 6L *'",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				expression := strings.Join(c.Args().Slice(), " ")
+				if c.NArg() == 0 {
+					content, err := utils.ReadInput("")
+					if err != nil {
+						return err
+					}
+					expression = strings.TrimSpace(content)
+				}
+				if expression == "" {
+					return errors.New("no expression given")
+				}
+
+				schedule, err := Parse(expression, Options{Quartz: c.Bool("quartz"), Standard: c.Bool("standard")})
+				if err != nil {
+					return cli.Exit("invalid expression: "+err.Error(), 1)
+				}
+
+				loc := time.Local
+				if c.IsSet("zone") {
+					if loc, err = time.LoadLocation(c.String("zone")); err != nil {
+						return err
+					}
+				} else if schedule.Location != nil {
+					loc = schedule.Location
+				}
+				now := time.Now()
+				if t := c.Timestamp("from"); t != nil {
+					now = *t
+				}
+				// The schedule runs in the zone of its CRON_TZ prefix, but the runs are shown in --zone
+				scheduleLoc := loc
+				if schedule.Location != nil {
+					scheduleLoc = schedule.Location
+				}
+				runs := schedule.NextN(now.In(scheduleLoc), c.Int("count"))
+				for i := range runs {
+					runs[i] = runs[i].In(loc)
+				}
+
+				if c.Bool("json") {
+					times := make([]string, len(runs))
+					for i, run := range runs {
+						times[i] = run.Format(time.RFC3339)
+					}
+					data, err := json.MarshalIndent(map[string]interface{}{
+						"expression":  schedule.Expression,
+						"description": schedule.Describe(),
+						"timezone":    loc.String(),
+						"next":        times,
+					}, "", "  ")
+					if err != nil {
+						return err
+					}
+					return utils.WriteOutput(c, []string{string(data)})
+				}
+
+				lines := []string{schedule.Describe()}
+				if len(runs) > 0 {
+					data := [][]string{{"#", "Next run (" + loc.String() + ")", "Relative"}}
+					for i, run := range runs {
+						data = append(data, []string{strconv.Itoa(i + 1), run.Format("Mon 2006-01-02 15:04:05 MST"), timetool.RelativeTo(run, now)})
+					}
+					lines = append(lines, "", say.Table(data, true, color.NewColorizer(c.String("output") == "")))
+				}
+				if err := utils.WriteOutput(c, lines); err != nil {
+					return err
+				}
+				if len(runs) == 0 && c.Int("count") > 0 {
+					say.Warning("The expression never runs")
+				}
+				return nil
+			},
+			Flags: append([]cli.Flag{
+				&cli.IntFlag{
+					Name:    "count",
+					Aliases: []string{"n"},
+					Usage:   "Lists the next `N` runs",
+					Value:   10,
+				},
+				&cli.StringFlag{
+					Name:        "zone",
+					Aliases:     []string{"z"},
+					Usage:       "Lists the runs in the `ZONE`, like UTC or Europe/Berlin",
+					DefaultText: "the CRON_TZ of the expression or Local",
+				},
+				&cli.TimestampFlag{
+					Name:        "from",
+					Usage:       "Lists the runs after the RFC3339 `TIME`",
+					Layout:      time.RFC3339,
+					DefaultText: "the current time",
+				},
+				&cli.BoolFlag{
+					Name:    "standard",
+					Aliases: []string{"s"},
+					Usage:   "Allows only 5 fields, names and macros like @daily, which Kubernetes CronJobs and most CI systems support",
+				},
+				&cli.BoolFlag{
+					Name:    "quartz",
+					Aliases: []string{"q"},
+					Usage:   "Numbers the days of the week from 1 (Sunday) to 7 (Saturday) like Quartz",
+				},
+				&cli.BoolFlag{
+					Name:    "json",
+					Aliases: []string{"j"},
+					Usage:   "Outputs the description and the next runs as JSON",
+				},
+			}, utils.OutputFlags()...),
+		},
+	}
+}
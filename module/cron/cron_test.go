@@ -0,0 +1,120 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	from := time.Date(2020, 9, 13, 12, 0, 0, 0, time.UTC) // a Sunday
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"*/15 9-17 * * 1-5", []string{"2020-09-14T09:00:00Z", "2020-09-14T09:15:00Z"}},
+		{"@daily", []string{"2020-09-14T00:00:00Z", "2020-09-15T00:00:00Z"}},
+		{"0 0 * * 7", []string{"2020-09-20T00:00:00Z", "2020-09-27T00:00:00Z"}},
+		{"30 4 1,15 * FRI", []string{"2020-09-15T04:30:00Z", "2020-09-18T04:30:00Z"}},
+		{"0 0 */2 * *", []string{"2020-09-15T00:00:00Z", "2020-09-17T00:00:00Z"}},
+		{"0 0 1 JAN-MAR/2 *", []string{"2021-01-01T00:00:00Z", "2021-03-01T00:00:00Z"}},
+		{"*/20 * * * * *", []string{"2020-09-13T12:00:20Z", "2020-09-13T12:00:40Z"}},
+		{"0 0 18 ? * 6L *", []string{"2020-09-25T18:00:00Z", "2020-10-30T18:00:00Z"}},
+		{"0 0 12 ? * MON#2", []string{"2020-09-14T12:00:00Z", "2020-10-12T12:00:00Z"}},
+		{"0 0 12 LW * ?", []string{"2020-09-30T12:00:00Z", "2020-10-30T12:00:00Z"}},
+		{"0 0 12 L-1 * ?", []string{"2020-09-29T12:00:00Z", "2020-10-30T12:00:00Z"}},
+		{"0 0 0 1W * ?", []string{"2020-10-01T00:00:00Z", "2020-11-02T00:00:00Z"}},
+		{"0 0 0 29 2 ? 2024-2099", []string{"2024-02-29T00:00:00Z", "2028-02-29T00:00:00Z"}},
+		{"@every 1h30m", []string{"2020-09-13T13:30:00Z", "2020-09-13T15:00:00Z"}},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.expr, Options{})
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		runs := s.NextN(from, len(tt.want))
+		if len(runs) != len(tt.want) {
+			t.Errorf("%s: got %v", tt.expr, runs)
+			continue
+		}
+		for i, run := range runs {
+			if got := run.Format(time.RFC3339); got != tt.want[i] {
+				t.Errorf("%s: run %d is %s, want %s", tt.expr, i+1, got, tt.want[i])
+			}
+		}
+	}
+
+	s, _ := Parse("0 0 30 2 *", Options{})
+	if runs := s.NextN(from, 1); len(runs) != 0 {
+		t.Errorf("February 30 runs at %v", runs)
+	}
+}
+
+func TestNextDaylightSaving(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := Parse("30 2 * * *", Options{})
+	// 02:30 doesn't exist on 2021-03-14, because the clocks are set forward
+	runs := s.NextN(time.Date(2021, 3, 13, 12, 0, 0, 0, newYork), 2)
+	if len(runs) != 2 || runs[0].Day() != 15 || runs[1].Day() != 16 || runs[0].Hour() != 2 {
+		t.Errorf("got %v", runs)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8",
+		"5-1 * * * *", "*/0 * * * *", "* * * * MON#6", "@reboot", "@often", "0 0 12 1 * MON *", "? * * * *", "CRON_TZ=Mars/Olympus * * * * *"} {
+		if _, err := Parse(expr, Options{}); err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+
+	for _, expr := range []string{"0 0 * * * *", "0 0 L * *", "0 0 ? * MON", "@every 1h", "0 0 * * 5#2"} {
+		if _, err := Parse(expr, Options{Standard: true}); err == nil {
+			t.Errorf("%q: expected an error in standard mode", expr)
+		}
+	}
+	for _, expr := range []string{"0 0 * JUL WED", "@weekly", "CRON_TZ=UTC 0 0 * * SAT"} {
+		if _, err := Parse(expr, Options{Standard: true}); err != nil {
+			t.Errorf("%q: %v", expr, err)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := map[string]string{
+		"*/15 9-17 * * 1-5":    "Every 15 minutes, between 09:00 and 17:59, on Monday through Friday",
+		"@daily":               "At 00:00",
+		"0 9,17 * * *":         "At 09:00 and 17:00",
+		"5,35 * * * *":         "At minutes 5 and 35 of every hour",
+		"0 */2 * * *":          "At minute 0, every 2 hours",
+		"30 4 1,15 * 5":        "At 04:30, on days 1 and 15 of the month or on Friday",
+		"0 0 1 */3 *":          "At 00:00, on day 1 of the month, every 3 months",
+		"*/10 * * * * *":       "Every 10 seconds",
+		"0 0 18 ? * 6L *":      "At 18:00, on the last Friday of the month",
+		"0 0 12 ? * MON#2":     "At 12:00, on the second Monday of the month",
+		"0 0 12 15W * ?":       "At 12:00, on the weekday nearest day 15 of the month",
+		"0 0 0 1 1 ? 2030":     "At 00:00, on day 1 of the month, in January, in 2030",
+		"@every 36h":           "Every 1 day 12 hours",
+		"0 8-18/2 * * SAT,0":   "At minute 0, every 2 hours between 08:00 and 18:59, on Saturday and Sunday",
+		"0 0 * * */2":          "At 00:00, on Sunday, Tuesday, Thursday and Saturday",
+		"15 10 L * ?":          "At 10:15, on the last day of the month",
+		"0 0 L-3 * ?":          "At 00:00, 3 days before the last day of the month",
+		"0 12 * JAN-MAR MON":   "At 12:00, on Monday, in January through March",
+		"0 0 12 * * ?":         "At 12:00",
+		"30 0/20 * * * *":      "At second 30, every 20 minutes starting at minute 0",
+		"0 0 0,12 1 JAN,JUL *": "At 00:00 and 12:00, on day 1 of the month, in January and July",
+	}
+	for expr, want := range tests {
+		s, err := Parse(expr, Options{})
+		if err != nil {
+			t.Errorf("%s: %v", expr, err)
+			continue
+		}
+		if got := s.Describe(); got != want {
+			t.Errorf("%s:\ngot  %s\nwant %s", expr, got, want)
+		}
+	}
+}
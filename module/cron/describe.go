@@ -0,0 +1,282 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dops-cli/dops/module/timetool"
+)
+
+var ordinals = []string{"", "first", "second", "third", "fourth", "fifth"}
+
+// joinAnd joins words like 'a, b and c'
+func joinAnd(words []string) string {
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return unit
+	}
+	return strconv.Itoa(n) + " " + unit + "s"
+}
+
+// split returns the single values of a field and the other items, like ranges and steps
+func split(f *field) (values []int, rest []item) {
+	for _, i := range f.items {
+		if i.start == i.end && i.step == 1 && !i.star && !i.special() {
+			values = append(values, i.start)
+			continue
+		}
+		rest = append(rest, i)
+	}
+	return values, rest
+}
+
+func (s *Schedule) name(f *field, v int) string {
+	switch f.spec.index {
+	case month:
+		return monthNames[v]
+	case dayOfWeek:
+		return dayNames[s.normalize(f.spec, v)]
+	}
+	return strconv.Itoa(v)
+}
+
+func (s *Schedule) names(f *field, values []int) []string {
+	names := make([]string, len(values))
+	for i, v := range values {
+		names[i] = s.name(f, v)
+	}
+	return names
+}
+
+// isAll is true, if the field is * without a step
+func isAll(f *field) bool {
+	return len(f.items) == 1 && f.items[0].star && f.items[0].step == 1
+}
+
+// isZero is true, if the field is exactly 0
+func isZero(f *field) bool {
+	values, rest := split(f)
+	return len(rest) == 0 && len(values) == 1 && values[0] == 0
+}
+
+// Describe explains the schedule in plain English, like 'Every 15 minutes, between 09:00 and 17:59, on Monday through Friday'
+func (s *Schedule) Describe() string {
+	if s.Every > 0 {
+		return "Every " + timetool.HumanDuration(s.Every)
+	}
+
+	parts := s.describeTime()
+	parts = append(parts, s.describeDays()...)
+	for _, index := range []int{month, year} {
+		if f := s.fields[index]; !isAll(f) {
+			parts = append(parts, s.describeSteps(f, "in %s"))
+		}
+	}
+	if len(parts) == 0 {
+		parts = []string{"every second"}
+	}
+	sentence := strings.Join(parts, ", ")
+	return strings.ToUpper(sentence[:1]) + sentence[1:]
+}
+
+// clockTimes lists the times of the day, if the schedule runs at a few fixed times, like 09:00 and 17:30
+func (s *Schedule) clockTimes() []string {
+	seconds, secondRest := split(s.fields[second])
+	minutes, minuteRest := split(s.fields[minute])
+	hours, hourRest := split(s.fields[hour])
+	if len(secondRest)+len(minuteRest)+len(hourRest) > 0 || len(seconds)*len(minutes)*len(hours) > 8 {
+		return nil
+	}
+
+	var times []string
+	for h := range s.fields[hour].values {
+		for m := range s.fields[minute].values {
+			for sec := range s.fields[second].values {
+				if !s.fields[hour].has(h) || !s.fields[minute].has(m) || !s.fields[second].has(sec) {
+					continue
+				}
+				t := fmt.Sprintf("%02d:%02d", h, m)
+				if !isZero(s.fields[second]) {
+					t += fmt.Sprintf(":%02d", sec)
+				}
+				times = append(times, t)
+			}
+		}
+	}
+	return times
+}
+
+func (s *Schedule) describeTime() []string {
+	if times := s.clockTimes(); times != nil {
+		return []string{"at " + joinAnd(times)}
+	}
+
+	var parts []string
+	seconds := s.fields[second]
+	if !isZero(seconds) {
+		parts = append(parts, s.describeUnits(seconds, "second"))
+	}
+	minutes := s.fields[minute]
+	if !isAll(minutes) || len(parts) == 0 || !seconds.any {
+		parts = append(parts, s.describeUnits(minutes, "minute"))
+	}
+
+	hours := s.fields[hour]
+	if isAll(hours) {
+		if values, rest := split(minutes); len(values) > 0 && len(rest) == 0 {
+			parts[len(parts)-1] += " of every hour"
+		}
+		return parts
+	}
+	values, rest := split(hours)
+	var phrases []string
+	if len(values) == 1 {
+		phrases = append(phrases, fmt.Sprintf("between %02d:00 and %02d:59", values[0], values[0]))
+	} else if len(values) > 1 {
+		phrases = append(phrases, "during the hours "+joinAnd(s.names(hours, values)))
+	}
+	for _, i := range rest {
+		switch {
+		case i.star:
+			phrases = append(phrases, "every "+plural(i.step, "hour"))
+		case i.step == 1:
+			phrases = append(phrases, fmt.Sprintf("between %02d:00 and %02d:59", i.start, i.end))
+		case i.end == hours.spec.max:
+			phrases = append(phrases, fmt.Sprintf("every %s starting at %02d:00", plural(i.step, "hour"), i.start))
+		default:
+			phrases = append(phrases, fmt.Sprintf("every %s between %02d:00 and %02d:59", plural(i.step, "hour"), i.start, i.end))
+		}
+	}
+	return append(parts, strings.Join(phrases, " and "))
+}
+
+// describeUnits describes the seconds or minutes field, like 'every 15 minutes' or 'at minute 5 and 35'
+func (s *Schedule) describeUnits(f *field, unit string) string {
+	values, rest := split(f)
+	var phrases []string
+	if len(values) == 1 {
+		phrases = append(phrases, fmt.Sprintf("at %s %d", unit, values[0]))
+	} else if len(values) > 1 {
+		phrases = append(phrases, fmt.Sprintf("at %ss %s", unit, joinAnd(s.names(f, values))))
+	}
+	for _, i := range rest {
+		switch {
+		case i.star:
+			phrases = append(phrases, "every "+plural(i.step, unit))
+		case i.step == 1:
+			phrases = append(phrases, fmt.Sprintf("every %s from %d through %d", unit, i.start, i.end))
+		case i.end == f.spec.max:
+			phrases = append(phrases, fmt.Sprintf("every %s starting at %s %d", plural(i.step, unit), unit, i.start))
+		default:
+			phrases = append(phrases, fmt.Sprintf("every %s from %d through %d", plural(i.step, unit), i.start, i.end))
+		}
+	}
+	return strings.Join(phrases, " and ")
+}
+
+// describeSteps describes the month or year field, like 'in January and July' or 'every 3 months'
+func (s *Schedule) describeSteps(f *field, format string) string {
+	values, rest := split(f)
+	var phrases []string
+	if len(values) > 0 {
+		phrases = append(phrases, fmt.Sprintf(format, joinAnd(s.names(f, values))))
+	}
+	for _, i := range rest {
+		switch {
+		case i.star:
+			phrases = append(phrases, "every "+plural(i.step, f.spec.name))
+		case i.step == 1:
+			phrases = append(phrases, fmt.Sprintf(format, s.name(f, i.start)+" through "+s.name(f, i.end)))
+		case i.end == f.spec.max:
+			phrases = append(phrases, fmt.Sprintf("every %s starting "+format, plural(i.step, f.spec.name), s.name(f, i.start)))
+		default:
+			phrases = append(phrases, fmt.Sprintf("every %s from %s through %s", plural(i.step, f.spec.name), s.name(f, i.start), s.name(f, i.end)))
+		}
+	}
+	return strings.Join(phrases, " and ")
+}
+
+func (s *Schedule) describeDays() []string {
+	dom := s.fields[dayOfMonth]
+	dow := s.fields[dayOfWeek]
+	var parts []string
+	if !isAll(dom) {
+		parts = append(parts, s.describeDaysOfMonth(dom))
+	}
+	if !isAll(dow) {
+		parts = append(parts, s.describeDaysOfWeek(dow))
+	}
+	// Like in dayMatches, a day matches if either field matches
+	if !dom.any && !dow.any {
+		return []string{parts[0] + " or " + parts[1]}
+	}
+	return parts
+}
+
+func (s *Schedule) describeDaysOfMonth(f *field) string {
+	values, rest := split(f)
+	var phrases []string
+	if len(values) == 1 {
+		phrases = append(phrases, fmt.Sprintf("on day %d of the month", values[0]))
+	} else if len(values) > 1 {
+		phrases = append(phrases, "on days "+joinAnd(s.names(f, values))+" of the month")
+	}
+	for _, i := range rest {
+		switch {
+		case i.last && i.weekday:
+			phrases = append(phrases, "on the last weekday of the month")
+		case i.last && i.offset > 0:
+			days := "days"
+			if i.offset == 1 {
+				days = "day"
+			}
+			phrases = append(phrases, fmt.Sprintf("%d %s before the last day of the month", i.offset, days))
+		case i.last:
+			phrases = append(phrases, "on the last day of the month")
+		case i.weekday:
+			phrases = append(phrases, fmt.Sprintf("on the weekday nearest day %d of the month", i.start))
+		case i.star:
+			phrases = append(phrases, "every "+plural(i.step, "day"))
+		case i.step == 1:
+			phrases = append(phrases, fmt.Sprintf("on days %d through %d of the month", i.start, i.end))
+		case i.end == f.spec.max:
+			phrases = append(phrases, fmt.Sprintf("every %s starting on day %d of the month", plural(i.step, "day"), i.start))
+		default:
+			phrases = append(phrases, fmt.Sprintf("every %s from day %d through %d of the month", plural(i.step, "day"), i.start, i.end))
+		}
+	}
+	return strings.Join(phrases, " and ")
+}
+
+func (s *Schedule) describeDaysOfWeek(f *field) string {
+	values, rest := split(f)
+	var phrases []string
+	if len(values) > 0 {
+		phrases = append(phrases, "on "+joinAnd(s.names(f, values)))
+	}
+	for _, i := range rest {
+		switch {
+		case i.last:
+			phrases = append(phrases, "on the last "+s.name(f, i.start)+" of the month")
+		case i.nth > 0:
+			phrases = append(phrases, "on the "+ordinals[i.nth]+" "+s.name(f, i.start)+" of the month")
+		case i.step == 1:
+			phrases = append(phrases, "on "+s.name(f, i.start)+" through "+s.name(f, i.end))
+		default:
+			// Steps are rare for days of the week, so the days are listed
+			var days []int
+			for v := i.start; v <= i.end; v += i.step {
+				days = append(days, v)
+			}
+			phrases = append(phrases, "on "+joinAnd(s.names(f, days)))
+		}
+	}
+	return strings.Join(phrases, " and ")
+}
@@ -0,0 +1,504 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dops-cli/dops/module/timetool"
+)
+
+// Indexes of the fields of a schedule
+const (
+	second = iota
+	minute
+	hour
+	dayOfMonth
+	month
+	dayOfWeek
+	year
+)
+
+type fieldSpec struct {
+	index    int
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	monthNames = []string{"", "January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	dayNames   = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+)
+
+var specs = []fieldSpec{
+	{second, "second", 0, 59, nil},
+	{minute, "minute", 0, 59, nil},
+	{hour, "hour", 0, 23, nil},
+	{dayOfMonth, "day of month", 1, 31, nil},
+	{month, "month", 1, 12, monthNames},
+	// 7 is Sunday as well
+	{dayOfWeek, "day of week", 0, 7, dayNames},
+	{year, "year", 1970, 2099, nil},
+}
+
+// descriptors are the macros, which stand for a standard expression
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// item is one element of the comma separated list of a field, like 5, 1-5, */15, L or 5#3
+type item struct {
+	start, end, step int
+	// star is set for * and ?
+	star bool
+	// last is L in the day of month field, or nL in the day of week field
+	last bool
+	// offset is n of L-n
+	offset int
+	// weekday is W, which means the nearest weekday
+	weekday bool
+	// nth is k of n#k
+	nth int
+}
+
+func (i item) special() bool {
+	return i.last || i.weekday || i.nth > 0
+}
+
+type field struct {
+	spec   fieldSpec
+	text   string
+	items  []item
+	values []bool
+	// any is set, if the field starts with * or ?
+	any bool
+}
+
+func (f *field) has(v int) bool {
+	return v < len(f.values) && f.values[v]
+}
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	Expression string
+	// Location is set by a CRON_TZ= or TZ= prefix
+	Location *time.Location
+	// Every is set for @every expressions, which run in a fixed interval
+	Every time.Duration
+	// Quartz is set, if the day of week is numbered from 1 (Sunday) to 7 (Saturday)
+	Quartz bool
+	// Seconds is set, if the expression has a seconds field
+	Seconds bool
+	fields  [7]*field
+}
+
+// Options change how expressions are parsed
+type Options struct {
+	// Quartz numbers the days of the week from 1 (Sunday) to 7 (Saturday). It's set automatically for expressions with 7 fields or a '?'.
+	Quartz bool
+	// Standard allows only the five fields, names and macros, which Kubernetes CronJobs and most CI systems support
+	Standard bool
+}
+
+// Parse parses a cron expression with 5 fields (minute to day of week), 6 fields (with seconds) or 7 fields (Quartz with seconds and years),
+// or a macro like @daily or @every 1h30m
+func Parse(expression string, options Options) (*Schedule, error) {
+	s := &Schedule{Expression: strings.TrimSpace(expression)}
+	expr := s.Expression
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if strings.HasPrefix(expr, prefix) {
+			parts := strings.SplitN(expr[len(prefix):], " ", 2)
+			loc, err := time.LoadLocation(parts[0])
+			if err != nil {
+				return nil, err
+			}
+			s.Location = loc
+			expr = ""
+			if len(parts) == 2 {
+				expr = strings.TrimSpace(parts[1])
+			}
+		}
+	}
+	if expr == "" {
+		return nil, errors.New("the expression is empty")
+	}
+
+	if strings.HasPrefix(expr, "@") {
+		macro := strings.Fields(strings.ToLower(expr))
+		switch {
+		case macro[0] == "@reboot":
+			return nil, errors.New("@reboot runs only when cron starts and has no schedule")
+		case macro[0] == "@every" && !options.Standard:
+			if len(macro) != 2 {
+				return nil, errors.New("@every needs a duration like 1h30m")
+			}
+			every, err := timetool.ParseDuration(macro[1])
+			if err != nil {
+				return nil, err
+			}
+			if every < time.Second {
+				return nil, errors.New("@every needs a duration of at least one second")
+			}
+			s.Every = every
+			return s, nil
+		}
+		standard, ok := descriptors[macro[0]]
+		if !ok || len(macro) != 1 {
+			return nil, fmt.Errorf("unknown macro %q", expr)
+		}
+		expr = standard
+	}
+
+	fields := strings.Fields(expr)
+	s.Quartz = options.Quartz || len(fields) == 7 || strings.Contains(expr, "?")
+	if options.Standard {
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("standard expressions have 5 fields (minute, hour, day of month, month, day of week), not %d", len(fields))
+		}
+		if err := standardOnly(fields); err != nil {
+			return nil, err
+		}
+	}
+
+	var texts [7]string
+	switch len(fields) {
+	case 5:
+		texts = [7]string{"0", fields[0], fields[1], fields[2], fields[3], fields[4], "*"}
+	case 6:
+		s.Seconds = true
+		texts = [7]string{fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], "*"}
+	case 7:
+		s.Seconds = true
+		copy(texts[:], fields)
+	default:
+		return nil, fmt.Errorf("an expression has 5, 6 or 7 fields, not %d", len(fields))
+	}
+
+	for i, text := range texts {
+		f, err := s.parseField(specs[i], text)
+		if err != nil {
+			return nil, fmt.Errorf("%s field %q: %w", specs[i].name, text, err)
+		}
+		s.fields[i] = f
+	}
+	if s.Quartz && !s.fields[dayOfMonth].any && !s.fields[dayOfWeek].any {
+		return nil, errors.New("quartz expressions need a '?' in the day of month or day of week field")
+	}
+	return s, nil
+}
+
+// standardOnly rejects the Quartz characters L, W, # and ?, which are not part of standard cron
+func standardOnly(fields []string) error {
+	for i, f := range fields {
+		upper := strings.ToUpper(f)
+		// Names like JUL, WED or SAT contain L and W, so they are removed first
+		for _, names := range [][]string{monthNames[1:], dayNames} {
+			for _, name := range names {
+				upper = strings.ReplaceAll(upper, strings.ToUpper(name[:3]), "")
+			}
+		}
+		if strings.ContainsAny(upper, "?L#W") {
+			return fmt.Errorf("%s field %q: L, W, # and ? are no standard cron syntax", specs[i+1].name, f)
+		}
+	}
+	return nil
+}
+
+func (s *Schedule) parseField(spec fieldSpec, text string) (*field, error) {
+	f := &field{spec: spec, text: text, values: make([]bool, spec.max+1)}
+	f.any = strings.HasPrefix(text, "*") || strings.HasPrefix(text, "?")
+	for _, part := range strings.Split(text, ",") {
+		i, err := s.parseItem(spec, part)
+		if err != nil {
+			return nil, err
+		}
+		f.items = append(f.items, i)
+		if i.special() {
+			continue
+		}
+		for v := i.start; v <= i.end; v += i.step {
+			f.values[s.normalize(spec, v)] = true
+		}
+	}
+	return f, nil
+}
+
+// normalize converts days of the week to 0 (Sunday) to 6 (Saturday)
+func (s *Schedule) normalize(spec fieldSpec, v int) int {
+	if spec.index != dayOfWeek {
+		return v
+	}
+	if s.Quartz {
+		return v - 1
+	}
+	return v % 7
+}
+
+// bounds returns the range of * in a field
+func (s *Schedule) bounds(spec fieldSpec) (int, int) {
+	if spec.index == dayOfWeek {
+		if s.Quartz {
+			return 1, 7
+		}
+		return 0, 6
+	}
+	return spec.min, spec.max
+}
+
+func (s *Schedule) value(spec fieldSpec, text string) (int, error) {
+	min, max := s.bounds(spec)
+	if spec.index == dayOfWeek && !s.Quartz {
+		max = 7
+	}
+	upper := strings.ToUpper(text)
+	for i, name := range spec.names {
+		if name != "" && upper == strings.ToUpper(name[:3]) {
+			if spec.index == dayOfWeek && s.Quartz {
+				return i + 1, nil
+			}
+			return i, nil
+		}
+	}
+	v, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", text)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("%d is out of range %d-%d", v, min, max)
+	}
+	return v, nil
+}
+
+func (s *Schedule) parseItem(spec fieldSpec, text string) (item, error) {
+	i := item{step: 1}
+	upper := strings.ToUpper(text)
+
+	switch spec.index {
+	case dayOfMonth:
+		switch {
+		case upper == "L":
+			return item{last: true}, nil
+		case upper == "LW":
+			return item{last: true, weekday: true}, nil
+		case strings.HasPrefix(upper, "L-"):
+			offset, err := strconv.Atoi(upper[2:])
+			if err != nil || offset < 1 || offset > 30 {
+				return i, fmt.Errorf("%q needs an offset from 1 to 30", text)
+			}
+			return item{last: true, offset: offset}, nil
+		case strings.HasSuffix(upper, "W"):
+			day, err := s.value(spec, upper[:len(upper)-1])
+			if err != nil {
+				return i, err
+			}
+			return item{start: day, end: day, weekday: true}, nil
+		}
+	case dayOfWeek:
+		switch {
+		case upper == "L" && s.Quartz:
+			return item{start: 7, end: 7, step: 1}, nil
+		case strings.HasSuffix(upper, "L") && len(upper) > 1:
+			day, err := s.value(spec, upper[:len(upper)-1])
+			if err != nil {
+				return i, err
+			}
+			return item{start: day, end: day, last: true}, nil
+		case strings.Contains(upper, "#"):
+			parts := strings.SplitN(upper, "#", 2)
+			day, err := s.value(spec, parts[0])
+			if err != nil {
+				return i, err
+			}
+			nth, err := strconv.Atoi(parts[1])
+			if err != nil || nth < 1 || nth > 5 {
+				return i, fmt.Errorf("%q needs an occurrence from 1 to 5 after #", text)
+			}
+			return item{start: day, end: day, nth: nth}, nil
+		}
+	}
+
+	rangeText := text
+	if slash := strings.Index(text, "/"); slash >= 0 {
+		step, err := strconv.Atoi(text[slash+1:])
+		if err != nil || step < 1 {
+			return i, fmt.Errorf("%q needs a positive step", text)
+		}
+		i.step = step
+		rangeText = text[:slash]
+	}
+
+	min, max := s.bounds(spec)
+	switch {
+	case rangeText == "*" || rangeText == "?":
+		if rangeText == "?" && spec.index != dayOfMonth && spec.index != dayOfWeek {
+			return i, errors.New("'?' is only allowed for the day of month or day of week")
+		}
+		i.star = true
+		i.start, i.end = min, max
+	case strings.Contains(rangeText, "-"):
+		bounds := strings.SplitN(rangeText, "-", 2)
+		start, err := s.value(spec, bounds[0])
+		if err != nil {
+			return i, err
+		}
+		end, err := s.value(spec, bounds[1])
+		if err != nil {
+			return i, err
+		}
+		if start > end {
+			return i, fmt.Errorf("the range %q ends before it starts", rangeText)
+		}
+		i.start, i.end = start, end
+	default:
+		v, err := s.value(spec, rangeText)
+		if err != nil {
+			return i, err
+		}
+		i.start, i.end = v, v
+		// 5/15 means every 15 from 5 to the end of the range
+		if i.step > 1 {
+			i.end = max
+		}
+	}
+	return i, nil
+}
+
+// dayMatches checks the day of month and day of week fields. If both are restricted, a day matches if either matches, like in standard cron.
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.fields[dayOfMonth]
+	dow := s.fields[dayOfWeek]
+	domMatches := dom.has(t.Day()) || s.specialDayOfMonth(t)
+	dowMatches := dow.has(int(t.Weekday())) || s.specialDayOfWeek(t)
+	if dom.any || dow.any {
+		return domMatches && dowMatches
+	}
+	return domMatches || dowMatches
+}
+
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func (s *Schedule) specialDayOfMonth(t time.Time) bool {
+	last := daysIn(t)
+	for _, i := range s.fields[dayOfMonth].items {
+		switch {
+		case i.last && i.weekday:
+			if t.Day() == nearestWeekday(t, last) {
+				return true
+			}
+		case i.last:
+			if t.Day() == last-i.offset {
+				return true
+			}
+		case i.weekday:
+			if t.Day() == nearestWeekday(t, i.start) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nearestWeekday returns the weekday nearest to the day in the month of t, without leaving the month
+func nearestWeekday(t time.Time, day int) int {
+	last := daysIn(t)
+	if day > last {
+		day = last
+	}
+	switch time.Date(t.Year(), t.Month(), day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return 3
+		}
+		return day - 1
+	case time.Sunday:
+		if day == last {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
+
+func (s *Schedule) specialDayOfWeek(t time.Time) bool {
+	for _, i := range s.fields[dayOfWeek].items {
+		if !i.special() || s.normalize(specs[dayOfWeek], i.start) != int(t.Weekday()) {
+			continue
+		}
+		if i.last && t.Day()+7 > daysIn(t) {
+			return true
+		}
+		if i.nth > 0 && (t.Day()-1)/7+1 == i.nth {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the first time after the given time, at which the schedule runs, in the location of the given time.
+// It returns false, if the schedule never runs, like on February 30.
+func (s *Schedule) Next(after time.Time) (time.Time, bool) {
+	if s.Every > 0 {
+		return after.Add(s.Every), true
+	}
+
+	loc := after.Location()
+	t := after.Add(time.Second).Truncate(time.Second)
+	limit := t.Year() + 100
+	for t.Year() <= limit {
+		switch {
+		case !s.fields[year].has(t.Year()):
+			if t.Year() > specs[year].max {
+				return time.Time{}, false
+			}
+			t = forward(t, time.Date(t.Year()+1, 1, 1, 0, 0, 0, 0, loc))
+		case !s.fields[month].has(int(t.Month())):
+			t = forward(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc))
+		case !s.dayMatches(t):
+			t = forward(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc))
+		case !s.fields[hour].has(t.Hour()):
+			t = forward(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc))
+		case !s.fields[minute].has(t.Minute()):
+			t = t.Truncate(time.Minute).Add(time.Minute)
+		case !s.fields[second].has(t.Second()):
+			t = t.Add(time.Second)
+		default:
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// forward returns next, if it's after t. Otherwise next doesn't exist, because the clocks were set forward,
+// and time.Date normalized it to a time before the gap, so the start of the hour after t is returned.
+func forward(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+	next = t.Add(time.Hour)
+	return time.Date(next.Year(), next.Month(), next.Day(), next.Hour(), 0, 0, 0, next.Location())
+}
+
+// NextN returns up to n times after the given time, at which the schedule runs
+func (s *Schedule) NextN(after time.Time, n int) []time.Time {
+	var times []time.Time
+	for len(times) < n {
+		next, ok := s.Next(after)
+		if !ok {
+			break
+		}
+		times = append(times, next)
+		after = next
+	}
+	return times
+}
@@ -0,0 +1,245 @@
+package csvtool
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "csv",
+			Usage: "Select, filter, sort, join and analyze CSV and TSV files",
+			Description: `The csv module contains tools to work with CSV and TSV files.
+Rows are processed one at a time, so that large files are never loaded into memory completely.
+Only 'sort', 'join' (for the right file) and 'pretty' need to keep rows in memory.
+'stats' keeps the numbers of each column and 'dedupe' keeps the compared values of each distinct row.
+Columns can be referenced by their name from the header or by their position, starting at 1.
+Rows can be written as JSON lines with --jsonl or formatted with --template, like '{{.name}}: {{.age}}'.`,
+			Category: categories.DataAnalysis,
+			Subcommands: []*cli.Command{
+				Select(),
+				Filter(),
+				Sort(),
+				Join(),
+				Stats(),
+				Dedupe(),
+				Pretty(),
+			},
+		},
+	}
+}
+
+// table reads a CSV file row by row
+type table struct {
+	reader  *csv.Reader
+	closer  io.Closer
+	header  []string
+	pending []string
+}
+
+// openTable opens a CSV file from a file, URL or stdin and reads its header.
+// If the file has no header, the columns are named by their position.
+func openTable(c *cli.Context, path string) (*table, error) {
+	r, err := utils.InputReader(path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter(c)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = false
+
+	t := &table{reader: reader, closer: r}
+
+	first, err := reader.Read()
+	if err == io.EOF {
+		return t, nil
+	}
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+
+	if c.Bool("no-header") {
+		for i := range first {
+			t.header = append(t.header, strconv.Itoa(i+1))
+		}
+		t.pending = first
+	} else {
+		t.header = first
+	}
+
+	return t, nil
+}
+
+// next returns the next row or io.EOF
+func (t *table) next() ([]string, error) {
+	if t.pending != nil {
+		row := t.pending
+		t.pending = nil
+		return row, nil
+	}
+	return t.reader.Read()
+}
+
+// each calls cb for every remaining row
+func (t *table) each(cb func(row []string) error) error {
+	for {
+		row, err := t.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := cb(row); err != nil {
+			return err
+		}
+	}
+}
+
+// column returns the index of a column by its name or its position (starting at 1)
+func (t *table) column(name string) (int, error) {
+	for i, h := range t.header {
+		if h == name {
+			return i, nil
+		}
+	}
+	if i, err := strconv.Atoi(name); err == nil && i >= 1 && i <= len(t.header) {
+		return i - 1, nil
+	}
+	return 0, errors.New("unknown column: " + name)
+}
+
+// columns returns the indices of multiple columns
+func (t *table) columns(names []string) ([]int, error) {
+	indices := make([]int, len(names))
+	for i, name := range names {
+		index, err := t.column(name)
+		if err != nil {
+			return nil, err
+		}
+		indices[i] = index
+	}
+	return indices, nil
+}
+
+func (t *table) Close() error {
+	return t.closer.Close()
+}
+
+// cell returns the value of a column, or an empty string if the row is too short
+func cell(row []string, index int) string {
+	if index < len(row) {
+		return row[index]
+	}
+	return ""
+}
+
+// output writes CSV rows to a file or stdout
+type output struct {
+	*csv.Writer
+	sink   *utils.Sink
+	header []string
+}
+
+func openOutput(c *cli.Context) (*output, error) {
+	sink, err := utils.CommandOutput(c)
+	if err != nil {
+		return nil, err
+	}
+	o := &output{Writer: csv.NewWriter(sink), sink: sink}
+	o.Writer.Comma = delimiter(c)
+	return o, nil
+}
+
+// writeHeader writes the header, unless the input has no header or the rows are written as records
+func (o *output) writeHeader(c *cli.Context, header []string) error {
+	o.header = header
+	if c.Bool("no-header") || o.sink.Records() {
+		return nil
+	}
+	return o.Writer.Write(header)
+}
+
+// Write writes a row as CSV, or as a record of column names and values with --template or --jsonl
+func (o *output) Write(row []string) error {
+	if !o.sink.Records() {
+		return o.Writer.Write(row)
+	}
+	record := make(map[string]string, len(o.header))
+	for i, name := range o.header {
+		record[name] = cell(row, i)
+	}
+	return o.sink.WriteRecord("", record)
+}
+
+// finish writes the output if err is nil, otherwise the output is discarded and err is returned
+func (o *output) finish(err error) error {
+	o.Flush()
+	if err == nil {
+		err = o.Error()
+	}
+	if err != nil {
+		o.sink.Abort()
+		return err
+	}
+	return o.sink.Close()
+}
+
+func delimiter(c *cli.Context) rune {
+	if c.Bool("tsv") {
+		return '\t'
+	}
+	r, _ := utf8.DecodeRuneInString(c.String("delimiter"))
+	if r == utf8.RuneError {
+		return ','
+	}
+	return r
+}
+
+// tableFlags returns the flags, which are used by all subcommands
+func tableFlags(flags ...cli.Flag) []cli.Flag {
+	flags = append(utils.OutputFlags(), flags...)
+	return append([]cli.Flag{
+		&cli.PathFlag{
+			Name:      "input",
+			Aliases:   []string{"i"},
+			Usage:     "use `FILE` as input, accepts a file, URL or stdin if not set",
+			TakesFile: true,
+		},
+		&cli.StringFlag{
+			Name:    "delimiter",
+			Aliases: []string{"d"},
+			Usage:   "Separates columns with `CHAR`",
+			Value:   ",",
+		},
+		&cli.BoolFlag{
+			Name:  "tsv",
+			Usage: "Uses tabs to separate columns",
+		},
+		&cli.BoolFlag{
+			Name:  "no-header",
+			Usage: "The first row contains data instead of column names",
+		},
+	}, flags...)
+}
+
+// rowFlags returns the flags of subcommands, which output rows.
+// Rows can be formatted with --template or --jsonl as records of column names and values.
+func rowFlags(flags ...cli.Flag) []cli.Flag {
+	return tableFlags(append(utils.RecordFlags(), flags...)...)
+}
@@ -0,0 +1,184 @@
+package csvtool
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dops-cli/dops/cli"
+)
+
+const users = `id,name,age,country
+1,alice,31,de
+2,bob,25,us
+3,carol,42,de
+4,dave,25,
+`
+
+// run runs a csv subcommand with users.csv as input and returns its output
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	return runArgs(t, append([]string{args[0], "-i", "USERS"}, args[1:]...)...)
+}
+
+// runArgs runs a csv subcommand with the arguments in the given order and returns its output.
+// USERS and ORDERS are replaced with the paths of users.csv and orders.csv.
+func runArgs(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	dir := t.TempDir()
+	input := filepath.Join(dir, "users.csv")
+	output := filepath.Join(dir, "out")
+	if err := ioutil.WriteFile(input, []byte(users), 0600); err != nil {
+		t.Fatal(err)
+	}
+	orders := filepath.Join(dir, "orders.csv")
+	if err := ioutil.WriteFile(orders, []byte("order,user_id\na,2\nb,1\nc,9\nd,2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for i, arg := range args {
+		switch arg {
+		case "USERS":
+			args[i] = input
+		case "ORDERS":
+			args[i] = orders
+		}
+	}
+
+	app := &cli.App{Commands: Module{}.GetModuleCommands()}
+	err := app.Run(append([]string{"dops", "csv", args[0], "-o", output}, args[1:]...))
+	data, _ := ioutil.ReadFile(output)
+	return string(data), err
+}
+
+func TestSubcommands(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"select", "-c", "name,2", "-c", "4"}, "name,name,country\nalice,alice,de\nbob,bob,us\ncarol,carol,de\ndave,dave,\n"},
+		{[]string{"filter", ".age > 30"}, "id,name,age,country\n1,alice,31,de\n3,carol,42,de\n"},
+		{[]string{"filter", ".country == null"}, "id,name,age,country\n4,dave,25,\n"},
+		{[]string{"sort", "--by", "age,-name"}, "id,name,age,country\n4,dave,25,\n2,bob,25,us\n1,alice,31,de\n3,carol,42,de\n"},
+		{[]string{"sort", "--by", "country", "-r"}, "id,name,age,country\n2,bob,25,us\n1,alice,31,de\n3,carol,42,de\n4,dave,25,\n"},
+		{[]string{"dedupe", "-c", "age"}, "id,name,age,country\n1,alice,31,de\n2,bob,25,us\n3,carol,42,de\n"},
+		{[]string{"dedupe", "-c", "country"}, "id,name,age,country\n1,alice,31,de\n2,bob,25,us\n4,dave,25,\n"},
+		{[]string{"select", "-c", "name", "--jsonl"}, "{\"name\":\"alice\"}\n{\"name\":\"bob\"}\n{\"name\":\"carol\"}\n{\"name\":\"dave\"}\n"},
+	}
+	for _, tt := range tests {
+		got, err := run(t, tt.args...)
+		if err != nil {
+			t.Errorf("%v: %v", tt.args, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%v: got\n%s\nwant\n%s", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestJoin(t *testing.T) {
+	// The users are joined to the orders, so the orders file is the input
+	tests := map[string]string{
+		"inner": "id,name,age,country,order\n1,alice,31,de,b\n2,bob,25,us,a\n2,bob,25,us,d\n",
+		"left":  "id,name,age,country,order\n1,alice,31,de,b\n2,bob,25,us,a\n2,bob,25,us,d\n3,carol,42,de,\n4,dave,25,,\n",
+	}
+	for joinType, want := range tests {
+		got, err := run(t, "join", "--on", "id=user_id", "--type", joinType, "ORDERS")
+		if err != nil {
+			t.Errorf("%s: %v", joinType, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s: got\n%s\nwant\n%s", joinType, got, want)
+		}
+	}
+}
+
+func TestFlagsAfterArguments(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"filter", ".age > 30", "-i", "USERS"}, "id,name,age,country\n1,alice,31,de\n3,carol,42,de\n"},
+		{[]string{"join", "USERS", "--on", "user_id=id", "-i", "ORDERS"}, "order,user_id,name,age,country\na,2,bob,25,us\nb,1,alice,31,de\nd,2,bob,25,us\n"},
+	}
+	for _, tt := range tests {
+		got, err := runArgs(t, tt.args...)
+		if err != nil {
+			t.Errorf("%v: %v", tt.args, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%v: got\n%s\nwant\n%s", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	got, err := run(t, "stats", "-c", "age,country", "-p", "50,100", "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stats []map[string]interface{}
+	if err := json.Unmarshal([]byte(got), &stats); err != nil {
+		t.Fatalf("%v: %s", err, got)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("got %s", got)
+	}
+	age, country := stats[0], stats[1]
+	for key, want := range map[string]interface{}{"column": "age", "count": 4.0, "empty": 0.0, "min": 25.0, "max": 42.0, "mean": 30.75, "p50": 28.0, "p100": 42.0} {
+		if age[key] != want {
+			t.Errorf("age %s: got %v, want %v", key, age[key], want)
+		}
+	}
+	if country["empty"] != 1.0 || country["min"] != "de" || country["mean"] != nil {
+		t.Errorf("country: got %v", country)
+	}
+}
+
+func TestTablesInFilesAreNotColored(t *testing.T) {
+	for _, subcommand := range []string{"pretty", "stats"} {
+		got, err := run(t, subcommand)
+		if err != nil {
+			t.Errorf("%s: %v", subcommand, err)
+			continue
+		}
+		if !strings.Contains(got, "age") || strings.Contains(got, "\x1b[") {
+			t.Errorf("%s: got %q", subcommand, got)
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"select", "-c", "missing"},
+		{"select", "-c", "5"},
+		{"filter", ".age >"},
+		{"join", "--on", "id", "ORDERS"},
+	} {
+		if _, err := run(t, args...); err == nil {
+			t.Errorf("%v: expected an error", args)
+		}
+	}
+}
+
+func TestCompareCells(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"9", "10", -1},
+		{"10", "9", 1},
+		{"1.5", "1.50", 0},
+		{"a", "b", -1},
+		{"10", "a", -1},
+	}
+	for _, tt := range tests {
+		if got := compareCells(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareCells(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
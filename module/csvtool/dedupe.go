@@ -0,0 +1,80 @@
+package csvtool
+
+import (
+	"strings"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Dedupe returns the dedupe subcommand
+func Dedupe() *cli.Command {
+	return &cli.Command{
+		Name:    "dedupe",
+		Aliases: []string{"d", "unique", "uniq"},
+		Usage:   "Removes duplicate rows",
+		Description: `Dedupe outputs only the first occurrence of each row.
+If columns are given, rows are compared by these columns only.
+The order of the rows is kept. Only the compared values are kept in memory.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Remove duplicate rows",
+				Usage:            "dops csv dedupe -i users.csv",
+			},
+			{
+				ShortDescription: "Keep only the first user of each email address",
+				Usage:            "dops csv dedupe -c email -i users.csv",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			t, err := openTable(c, c.Path("input"))
+			if err != nil {
+				return err
+			}
+			defer t.Close()
+
+			var indices []int
+			if names := utils.StringList(c, "columns"); len(names) > 0 {
+				indices, err = t.columns(names)
+				if err != nil {
+					return err
+				}
+			}
+
+			out, err := openOutput(c)
+			if err != nil {
+				return err
+			}
+			if err := out.writeHeader(c, t.header); err != nil {
+				return out.finish(err)
+			}
+
+			seen := map[string]struct{}{}
+			err = t.each(func(row []string) error {
+				values := row
+				if indices != nil {
+					values = make([]string, len(indices))
+					for i, index := range indices {
+						values[i] = cell(row, index)
+					}
+				}
+
+				// The unit separator does not appear in regular text, so it can't create ambiguous keys
+				key := strings.Join(values, "\x1f")
+				if _, ok := seen[key]; ok {
+					return nil
+				}
+				seen[key] = struct{}{}
+				return out.Write(row)
+			})
+			return out.finish(err)
+		},
+		Flags: rowFlags(
+			&cli.StringSliceFlag{
+				Name:    "columns",
+				Aliases: []string{"c"},
+				Usage:   "Compares rows only by `COLUMNS`",
+			},
+		),
+	}
+}
@@ -0,0 +1,82 @@
+package csvtool
+
+import (
+	"errors"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/module/convert"
+	"github.com/dops-cli/dops/module/query"
+)
+
+// Filter returns the filter subcommand
+func Filter() *cli.Command {
+	return &cli.Command{
+		Name:      "filter",
+		Aliases:   []string{"f", "where"},
+		Usage:     "Outputs only rows matching an expression",
+		ArgsUsage: "EXPRESSION",
+		// The expression usually comes first, so flags are also accepted after it
+		InterspersedFlags: true,
+		Description: `Filter evaluates a query expression (see 'dops query') for every row and keeps the rows, for which it returns a truthy value.
+Each row is passed to the expression as an object, with the column names as keys.
+Numbers and booleans are converted automatically, so they can be compared numerically. Empty cells are null.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Output all users older than 30",
+				Usage:            "dops csv filter '.age > 30' -i users.csv",
+			},
+			{
+				ShortDescription: "Output all users with a gmail address",
+				Usage:            `dops csv filter '.email | endswith("@gmail.com")' -i users.csv`,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("filter expects exactly one expression")
+			}
+
+			q, err := query.Compile(c.Args().First())
+			if err != nil {
+				return err
+			}
+
+			t, err := openTable(c, c.Path("input"))
+			if err != nil {
+				return err
+			}
+			defer t.Close()
+
+			out, err := openOutput(c)
+			if err != nil {
+				return err
+			}
+			if err := out.writeHeader(c, t.header); err != nil {
+				return out.finish(err)
+			}
+
+			err = t.each(func(row []string) error {
+				object := convert.NewObject()
+				for i, name := range t.header {
+					if value := cell(row, i); value != "" {
+						object.Set(name, convert.ParseScalar(value))
+					} else {
+						object.Set(name, nil)
+					}
+				}
+
+				results, err := q.Run(object)
+				if err != nil {
+					return err
+				}
+				for _, result := range results {
+					if result != nil && result != false {
+						return out.Write(row)
+					}
+				}
+				return nil
+			})
+			return out.finish(err)
+		},
+		Flags: rowFlags(),
+	}
+}
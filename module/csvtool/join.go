@@ -0,0 +1,131 @@
+package csvtool
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/dops-cli/dops/cli"
+)
+
+// Join returns the join subcommand
+func Join() *cli.Command {
+	return &cli.Command{
+		Name:      "join",
+		Aliases:   []string{"j", "merge"},
+		Usage:     "Joins two CSV files on a key column",
+		ArgsUsage: "FILE",
+		// Flags are also accepted after FILE
+		InterspersedFlags: true,
+		Description: `Join combines the rows of the input with the rows of FILE, which have the same value in the key column.
+Use '--on id' if both files name the key column the same, or '--on user_id=id' if they don't.
+The output contains all columns of the input, followed by all columns of FILE except the key column.
+FILE is loaded into memory, the input is streamed. Pass the larger file as input.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Add the user data to every order",
+				Usage:            "dops csv join --on user_id=id -i orders.csv users.csv",
+			},
+			{
+				ShortDescription: "Keep orders without a matching user",
+				Usage:            "dops csv join --on user_id=id --type left -i orders.csv users.csv",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return errors.New("join expects exactly one FILE to join with")
+			}
+
+			on := c.String("on")
+			if on == "" {
+				return errors.New("no key column given, use --on")
+			}
+			leftKey, rightKey := on, on
+			if parts := strings.SplitN(on, "=", 2); len(parts) == 2 {
+				leftKey, rightKey = parts[0], parts[1]
+			}
+
+			left, err := openTable(c, c.Path("input"))
+			if err != nil {
+				return err
+			}
+			defer left.Close()
+
+			right, err := openTable(c, c.Args().First())
+			if err != nil {
+				return err
+			}
+			defer right.Close()
+
+			leftIndex, err := left.column(leftKey)
+			if err != nil {
+				return err
+			}
+			rightIndex, err := right.column(rightKey)
+			if err != nil {
+				return err
+			}
+
+			index := map[string][][]string{}
+			if err := right.each(func(row []string) error {
+				key := cell(row, rightIndex)
+				index[key] = append(index[key], withoutColumn(row, rightIndex, len(right.header)))
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			out, err := openOutput(c)
+			if err != nil {
+				return err
+			}
+			header := append(append([]string{}, left.header...), withoutColumn(right.header, rightIndex, len(right.header))...)
+			if err := out.writeHeader(c, header); err != nil {
+				return out.finish(err)
+			}
+
+			leftJoin := c.Option("type") == "left"
+			empty := make([]string, len(right.header)-1)
+			err = left.each(func(row []string) error {
+				row = padRow(row, len(left.header))
+				matches := index[cell(row, leftIndex)]
+				if len(matches) == 0 && leftJoin {
+					return out.Write(append(row, empty...))
+				}
+				for _, match := range matches {
+					if err := out.Write(append(append([]string{}, row...), match...)); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			return out.finish(err)
+		},
+		Flags: rowFlags(
+			&cli.StringFlag{
+				Name:  "on",
+				Usage: "Joins on the `COLUMN`, use 'left=right' if the columns are named differently",
+			},
+			&cli.OptionFlag{
+				Name:        "type",
+				Aliases:     []string{"t"},
+				Usage:       "Uses an inner or left `JOIN`",
+				Options:     []string{"inner", "left"},
+				DefaultText: "inner",
+			},
+		),
+	}
+}
+
+// padRow fills up a row with empty cells, so that it has as many cells as the header
+func padRow(row []string, length int) []string {
+	for len(row) < length {
+		row = append(row, "")
+	}
+	return row
+}
+
+// withoutColumn returns a copy of row, which has the length of the header, without the cell at index
+func withoutColumn(row []string, index, length int) []string {
+	row = padRow(append([]string{}, row...), length)
+	return append(row[:index], row[index+1:]...)
+}
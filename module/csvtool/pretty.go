@@ -0,0 +1,57 @@
+package csvtool
+
+import (
+	"io"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Pretty returns the pretty subcommand
+func Pretty() *cli.Command {
+	return &cli.Command{
+		Name:    "pretty",
+		Aliases: []string{"p", "table", "show"},
+		Usage:   "Renders rows as a table",
+		Description: `Pretty renders the rows as an aligned table.
+The table has to be kept in memory to align the columns, use --limit to render only the first rows of large files.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Show the first 20 rows as a table",
+				Usage:            "dops csv pretty -l 20 -i users.csv",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			t, err := openTable(c, c.Path("input"))
+			if err != nil {
+				return err
+			}
+			defer t.Close()
+
+			limit := c.Int("limit")
+			data := [][]string{t.header}
+			for limit <= 0 || len(data) <= limit {
+				row, err := t.next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return err
+				}
+				data = append(data, padRow(row, len(t.header)))
+			}
+
+			table := say.Table(data, true, color.NewColorizer(c.String("output") == ""))
+			return utils.WriteOutput(c, []string{table})
+		},
+		Flags: tableFlags(
+			&cli.IntFlag{
+				Name:    "limit",
+				Aliases: []string{"l"},
+				Usage:   "Renders only the first `N` rows, 0 renders all rows",
+			},
+		),
+	}
+}
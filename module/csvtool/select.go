@@ -0,0 +1,74 @@
+package csvtool
+
+import (
+	"errors"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Select returns the select subcommand
+func Select() *cli.Command {
+	return &cli.Command{
+		Name:        "select",
+		Aliases:     []string{"s", "columns", "cut"},
+		Usage:       "Outputs only specific columns",
+		Description: "Select outputs the given columns in the given order. Columns can be referenced by name or by position.",
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Output the name and email column",
+				Usage:            "dops csv select -c name,email -i users.csv",
+			},
+			{
+				ShortDescription: "Output the third and first column of a TSV file",
+				Usage:            "dops csv select --tsv -c 3,1 -i users.tsv",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			names := utils.StringList(c, "columns")
+			if len(names) == 0 {
+				return errors.New("no columns selected, use --columns")
+			}
+
+			t, err := openTable(c, c.Path("input"))
+			if err != nil {
+				return err
+			}
+			defer t.Close()
+
+			indices, err := t.columns(names)
+			if err != nil {
+				return err
+			}
+
+			out, err := openOutput(c)
+			if err != nil {
+				return err
+			}
+
+			header := make([]string, len(indices))
+			for i, index := range indices {
+				header[i] = t.header[index]
+			}
+			if err := out.writeHeader(c, header); err != nil {
+				return out.finish(err)
+			}
+
+			err = t.each(func(row []string) error {
+				selected := make([]string, len(indices))
+				for i, index := range indices {
+					selected[i] = cell(row, index)
+				}
+				return out.Write(selected)
+			})
+			return out.finish(err)
+		},
+		Flags: rowFlags(
+			&cli.StringSliceFlag{
+				Name:    "columns",
+				Aliases: []string{"c"},
+				Usage:   "Selects `COLUMNS` by name or position",
+			},
+		),
+	}
+}
@@ -0,0 +1,125 @@
+package csvtool
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/utils"
+)
+
+type sortKey struct {
+	index      int
+	descending bool
+}
+
+// Sort returns the sort subcommand
+func Sort() *cli.Command {
+	return &cli.Command{
+		Name:    "sort",
+		Aliases: []string{"o", "order"},
+		Usage:   "Sorts rows by one or more columns",
+		Description: `Sort sorts all rows by the given columns. Prefix a column with '-' to sort it in descending order.
+Values, which are numbers in both compared rows, are compared numerically, everything else is compared as text.
+If no column is given, rows are sorted by all columns from left to right.
+Sort has to load all rows into memory.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Sort users by country and then by age in descending order",
+				Usage:            "dops csv sort --by country,-age -i users.csv",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			t, err := openTable(c, c.Path("input"))
+			if err != nil {
+				return err
+			}
+			defer t.Close()
+
+			var keys []sortKey
+			for _, name := range utils.StringList(c, "by") {
+				key := sortKey{}
+				if strings.HasPrefix(name, "-") {
+					key.descending = true
+					name = name[1:]
+				}
+				key.index, err = t.column(name)
+				if err != nil {
+					return err
+				}
+				keys = append(keys, key)
+			}
+			if len(keys) == 0 {
+				for i := range t.header {
+					keys = append(keys, sortKey{index: i})
+				}
+			}
+
+			var rows [][]string
+			if err := t.each(func(row []string) error {
+				rows = append(rows, row)
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			reverse := c.Bool("reverse")
+			sort.SliceStable(rows, func(i, j int) bool {
+				for _, key := range keys {
+					result := compareCells(cell(rows[i], key.index), cell(rows[j], key.index))
+					if result == 0 {
+						continue
+					}
+					if key.descending != reverse {
+						return result > 0
+					}
+					return result < 0
+				}
+				return false
+			})
+
+			out, err := openOutput(c)
+			if err != nil {
+				return err
+			}
+			if err := out.writeHeader(c, t.header); err != nil {
+				return out.finish(err)
+			}
+			for _, row := range rows {
+				if err := out.Write(row); err != nil {
+					return out.finish(err)
+				}
+			}
+			return out.finish(nil)
+		},
+		Flags: rowFlags(
+			&cli.StringSliceFlag{
+				Name:    "by",
+				Aliases: []string{"b"},
+				Usage:   "Sorts by `COLUMNS`, prefix a column with '-' to sort descending",
+			},
+			&cli.BoolFlag{
+				Name:    "reverse",
+				Aliases: []string{"r"},
+				Usage:   "Reverses the sort order",
+			},
+		),
+	}
+}
+
+// compareCells compares two values numerically if both are numbers, otherwise as text
+func compareCells(a, b string) int {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
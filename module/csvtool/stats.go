@@ -0,0 +1,211 @@
+package csvtool
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/module/convert"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+	"github.com/dops-cli/dops/utils"
+)
+
+// columnStats contains the statistics of a single column
+type columnStats struct {
+	name    string
+	count   int
+	empty   int
+	numbers []float64
+	min     string
+	max     string
+}
+
+func (s *columnStats) add(value string) {
+	s.count++
+	if value == "" {
+		s.empty++
+		return
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(f) {
+		s.numbers = append(s.numbers, f)
+	}
+	if s.count-s.empty == 1 || compareCells(value, s.min) < 0 {
+		s.min = value
+	}
+	if s.count-s.empty == 1 || compareCells(value, s.max) > 0 {
+		s.max = value
+	}
+}
+
+// numeric returns true if all non-empty values of the column are numbers
+func (s *columnStats) numeric() bool {
+	return len(s.numbers) > 0 && len(s.numbers) == s.count-s.empty
+}
+
+func (s *columnStats) mean() float64 {
+	var sum float64
+	for _, n := range s.numbers {
+		sum += n
+	}
+	return sum / float64(len(s.numbers))
+}
+
+// percentile returns the p-th percentile of the sorted numbers, using linear interpolation
+func (s *columnStats) percentile(p float64) float64 {
+	rank := p / 100 * float64(len(s.numbers)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return s.numbers[lower] + (s.numbers[upper]-s.numbers[lower])*(rank-float64(lower))
+}
+
+// Stats returns the stats subcommand
+func Stats() *cli.Command {
+	return &cli.Command{
+		Name:    "stats",
+		Aliases: []string{"statistics", "summary"},
+		Usage:   "Shows statistics about each column",
+		Description: `Stats calculates the count, empty values, minimum, maximum, mean and percentiles of each column.
+Mean and percentiles are only calculated for columns, which contain only numbers.
+The numbers of each column are kept in memory to calculate the percentiles.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Show statistics of all columns",
+				Usage:            "dops csv stats -i users.csv",
+			},
+			{
+				ShortDescription: "Show the median and 95th percentile of the age column as JSON",
+				Usage:            "dops csv stats -c age -p 50,95 --json -i users.csv",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			var percentiles []float64
+			for _, p := range strings.Split(c.String("percentiles"), ",") {
+				p = strings.TrimSpace(p)
+				if p == "" {
+					continue
+				}
+				f, err := strconv.ParseFloat(p, 64)
+				if err != nil || f < 0 || f > 100 {
+					return errors.New("invalid percentile: " + p)
+				}
+				percentiles = append(percentiles, f)
+			}
+
+			t, err := openTable(c, c.Path("input"))
+			if err != nil {
+				return err
+			}
+			defer t.Close()
+
+			var indices []int
+			if names := utils.StringList(c, "columns"); len(names) > 0 {
+				indices, err = t.columns(names)
+				if err != nil {
+					return err
+				}
+			} else {
+				for i := range t.header {
+					indices = append(indices, i)
+				}
+			}
+
+			stats := make([]*columnStats, len(indices))
+			for i, index := range indices {
+				stats[i] = &columnStats{name: t.header[index]}
+			}
+
+			if err := t.each(func(row []string) error {
+				for i, index := range indices {
+					stats[i].add(cell(row, index))
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+
+			for _, s := range stats {
+				sort.Float64s(s.numbers)
+			}
+
+			if c.Bool("json") {
+				list := make([]interface{}, len(stats))
+				for i, s := range stats {
+					o := convert.NewObject()
+					o.Set("column", s.name)
+					o.Set("count", int64(s.count))
+					o.Set("empty", int64(s.empty))
+					if s.numeric() {
+						o.Set("min", s.numbers[0])
+						o.Set("max", s.numbers[len(s.numbers)-1])
+						o.Set("mean", s.mean())
+						for _, p := range percentiles {
+							o.Set("p"+formatNumber(p), s.percentile(p))
+						}
+					} else {
+						o.Set("min", s.min)
+						o.Set("max", s.max)
+					}
+					list[i] = o
+				}
+				data, err := convert.Encode(convert.JSON, list, convert.EncodeOptions{})
+				if err != nil {
+					return err
+				}
+				return utils.WriteOutput(c, []string{strings.TrimSuffix(string(data), "\n")})
+			}
+
+			header := []string{"Column", "Count", "Empty", "Min", "Max", "Mean"}
+			for _, p := range percentiles {
+				header = append(header, "P"+formatNumber(p))
+			}
+			data := [][]string{header}
+			for _, s := range stats {
+				row := []string{s.name, strconv.Itoa(s.count), strconv.Itoa(s.empty), s.min, s.max}
+				if s.numeric() {
+					row = append(row, formatNumber(round(s.mean())))
+					for _, p := range percentiles {
+						row = append(row, formatNumber(round(s.percentile(p))))
+					}
+				} else {
+					for i := 0; i <= len(percentiles); i++ {
+						row = append(row, "-")
+					}
+				}
+				data = append(data, row)
+			}
+
+			table := say.Table(data, true, color.NewColorizer(c.String("output") == ""))
+			return utils.WriteOutput(c, []string{table})
+		},
+		Flags: tableFlags(
+			&cli.StringSliceFlag{
+				Name:    "columns",
+				Aliases: []string{"c"},
+				Usage:   "Only shows statistics of `COLUMNS`",
+			},
+			&cli.StringFlag{
+				Name:    "percentiles",
+				Aliases: []string{"p"},
+				Usage:   "Calculates the comma separated `PERCENTILES`",
+				Value:   "50,90,99",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Outputs the statistics as JSON",
+			},
+		),
+	}
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// round rounds f to 4 decimal places, so that the table stays readable
+func round(f float64) float64 {
+	return math.Round(f*10000) / 10000
+}
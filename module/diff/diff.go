@@ -0,0 +1,167 @@
+package diff
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/pterm/pterm"
+
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/module/convert"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:      "diff",
+			Usage:     "Shows the differences between two files",
+			ArgsUsage: "A B",
+			// Flags are also accepted after the files
+			InterspersedFlags: true,
+			Description: `Diff compares two files line by line and shows the differences in the unified format or side by side.
+With --semantic, JSON, YAML and all other formats supported by 'dops convert' are compared by their content instead of their text.
+Changes are then reported by their key path, the order of keys and the formatting of the files are ignored.
+YAML files with multiple Kubernetes resources are compared resource by resource, regardless of their order.
+A and B can be files or URLs.`,
+			Category: categories.TextProcessing,
+			Examples: []cli.Example{
+				{
+					ShortDescription: "Show the differences of two files",
+					Usage:            "dops diff old.txt new.txt",
+				},
+				{
+					ShortDescription: "Show the differences side by side",
+					Usage:            "dops diff -y old.txt new.txt",
+				},
+				{
+					ShortDescription: "Compare the Kubernetes manifests of two environments",
+					Usage:            "dops diff --semantic --ignore .metadata.annotations staging.yaml production.yaml",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 2 {
+					return errors.New("diff expects exactly two files")
+				}
+				nameA, nameB := c.Args().Get(0), c.Args().Get(1)
+				dataA, dataB := utils.Input(nameA), utils.Input(nameB)
+				output := c.String("output")
+				colored := color.NewColorizer(output == "")
+
+				var lines []string
+				if c.Bool("semantic") {
+					a, err := decode(c.Option("format"), nameA, dataA)
+					if err != nil {
+						return err
+					}
+					b, err := decode(c.Option("format"), nameB, dataB)
+					if err != nil {
+						return err
+					}
+
+					var changes []Change
+					for _, change := range Compare(a, b) {
+						if !Ignored(change.Path, utils.StringList(c, "ignore")) {
+							changes = append(changes, change)
+						}
+					}
+					lines = FormatChanges(changes, colored)
+				} else {
+					a, b := splitLines(dataA), splitLines(dataB)
+					if c.Bool("side-by-side") {
+						width := c.Int("width")
+						if width <= 0 {
+							width = pterm.GetTerminalWidth()
+						}
+						lines = SideBySide(nameA, nameB, a, b, c.Int("context"), width, colored)
+					} else {
+						lines = Unified(nameA, nameB, a, b, c.Int("context"), colored)
+					}
+				}
+
+				if len(lines) == 0 {
+					if output == "" {
+						say.Success("The files are equal")
+					}
+					return nil
+				}
+
+				if err := utils.WriteOutput(c, lines); err != nil {
+					return err
+				}
+				if c.Bool("exit-code") {
+					return cli.Exit("", 1)
+				}
+				return nil
+			},
+			Flags: append([]cli.Flag{
+				&cli.BoolFlag{
+					Name:    "side-by-side",
+					Aliases: []string{"y"},
+					Usage:   "Shows the files in two columns",
+				},
+				&cli.IntFlag{
+					Name:    "context",
+					Aliases: []string{"U"},
+					Usage:   "Shows `N` unchanged lines around each change",
+					Value:   3,
+				},
+				&cli.IntFlag{
+					Name:    "width",
+					Aliases: []string{"w"},
+					Usage:   "Limits side by side output to `N` characters",
+					Value:   0,
+				},
+				&cli.BoolFlag{
+					Name:    "semantic",
+					Aliases: []string{"s"},
+					Usage:   "Compares structured data by key path",
+				},
+				&cli.OptionFlag{
+					Name:        "format",
+					Aliases:     []string{"f"},
+					Usage:       "Reads both files as `FORMAT` in semantic mode",
+					Options:     convert.Formats,
+					DefaultText: "detected by extension",
+				},
+				&cli.StringSliceFlag{
+					Name:    "ignore",
+					Aliases: []string{"I"},
+					Usage:   "Ignores changes at the key `PATHS` and below in semantic mode",
+				},
+				&cli.BoolFlag{
+					Name:    "exit-code",
+					Aliases: []string{"e"},
+					Usage:   "Exits with status 1 if the files differ",
+				},
+			}, utils.OutputFlags()...),
+		},
+	}
+}
+
+// decode decodes a file for the semantic mode. If format is empty, it's detected by the file name or the content.
+func decode(format, name, data string) (interface{}, error) {
+	if format == "" {
+		var err error
+		format, err = convert.DetectFormat(name)
+		if err != nil {
+			format = convert.DetectFormatFromContent([]byte(data))
+		}
+	}
+	return DecodeDocuments(format, []byte(data))
+}
+
+// splitLines splits a text into lines without line endings
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n"), "\n")
+}
@@ -0,0 +1,257 @@
+package diff
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/module/convert"
+)
+
+// apply rebuilds b from a and the edits, so that every edit script can be checked
+func apply(a, b []string, edits []Edit) []string {
+	var result []string
+	for _, e := range edits {
+		switch e.Op {
+		case Equal:
+			result = append(result, a[e.A])
+		case Insert:
+			result = append(result, b[e.B])
+		}
+	}
+	return result
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		changes int
+	}{
+		{"", "", 0},
+		{"a b c", "a b c", 0},
+		{"", "a b", 2},
+		{"a b", "", 2},
+		{"a b c a b b a", "c b a b a c", 5},
+		{"a b c d", "a x c d", 2},
+		{"x a b c", "a b c y", 2},
+	}
+	for _, tt := range tests {
+		a, b := strings.Fields(tt.a), strings.Fields(tt.b)
+		edits := Lines(a, b)
+		changes := 0
+		for _, e := range edits {
+			if e.Op != Equal {
+				changes++
+			}
+		}
+		if changes != tt.changes {
+			t.Errorf("%q -> %q: got %d changes, want %d: %v", tt.a, tt.b, changes, tt.changes, edits)
+		}
+		if got := apply(a, b, edits); strings.Join(got, " ") != strings.Join(b, " ") {
+			t.Errorf("%q -> %q: the edits build %q", tt.a, tt.b, got)
+		}
+	}
+}
+
+func TestLinesDeletesBeforeInserts(t *testing.T) {
+	want := []Edit{{Equal, 0, 0}, {Delete, 1, -1}, {Insert, -1, 1}, {Equal, 2, 2}}
+	if got := Lines([]string{"a", "b", "c"}, []string{"a", "x", "c"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	var a []string
+	for i := 1; i <= 12; i++ {
+		a = append(a, string(rune('a'+i-1)))
+	}
+	b := append([]string{}, a...)
+	b[1] = "B"
+	b = append(b[:10], b[11:]...)
+	b = append(b, "m")
+
+	want := []string{
+		"--- a.txt",
+		"+++ b.txt",
+		"@@ -1,4 +1,4 @@",
+		" a",
+		"-b",
+		"+B",
+		" c",
+		" d",
+		"@@ -9,4 +9,4 @@",
+		" i",
+		" j",
+		"-k",
+		" l",
+		"+m",
+	}
+	if got := Unified("a.txt", "b.txt", a, b, 2, false); !reflect.DeepEqual(got, want) {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// With more context, both changes are in the same hunk
+	if got := Unified("a.txt", "b.txt", a, b, 5, false); len(got) != 3+14 || got[2] != "@@ -1,12 +1,12 @@" {
+		t.Errorf("got\n%s", strings.Join(got, "\n"))
+	}
+	if got := Unified("a.txt", "b.txt", a, a, 3, false); got != nil {
+		t.Errorf("expected no output, got %v", got)
+	}
+}
+
+func TestHunkRanges(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"", "x", "@@ -0,0 +1 @@"},
+		{"x", "", "@@ -1 +0,0 @@"},
+		{"a b", "a b c", "@@ -2,0 +3 @@"},
+		{"a b c", "a c", "@@ -2 +1,0 @@"},
+	}
+	for _, tt := range tests {
+		got := Unified("a", "b", strings.Fields(tt.a), strings.Fields(tt.b), 0, false)
+		if len(got) < 3 || got[2] != tt.want {
+			t.Errorf("%q -> %q: got %v, want %s", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	got := SideBySide("a", "b", []string{"same", "old", "gone"}, []string{"same", "new"}, 3, 23, false)
+	want := []string{
+		"a            b",
+		"same         same",
+		"old        | new",
+		"gone       < ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got\n%q\nwant\n%q", got, want)
+	}
+	if got := truncate("abcdefghijkl", 10); got != "abcdefghi…" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func decodeJSON(t *testing.T, data string) interface{} {
+	t.Helper()
+	value, err := convert.Decode(convert.JSON, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestCompare(t *testing.T) {
+	a := decodeJSON(t, `{"name":"x","port":80,"tags":["a","b"],"meta":{"app.io/name":"x","old":1},"ratio":1}`)
+	b := decodeJSON(t, `{"meta":{"app.io/name":"y","new":true},"tags":["a"],"ratio":1.0,"port":"80","name":"x"}`)
+	want := []string{
+		`~ .meta["app.io/name"]: "x" -> "y"`,
+		`+ .meta.new: true`,
+		`- .meta.old: 1`,
+		`~ .port: 80 -> "80"`,
+		`- .tags[1]: "b"`,
+	}
+	if got := FormatChanges(Compare(a, b), false); !reflect.DeepEqual(got, want) {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if got := Compare(decodeJSON(t, `1`), decodeJSON(t, `[1]`)); len(got) != 1 || got[0].Path != "." {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	prefixes := []string{".metadata.annotations", ".spec"}
+	tests := map[string]bool{
+		".metadata.annotations":         true,
+		".metadata.annotations.version": true,
+		`.metadata.annotations["a/b"]`:  true,
+		".spec[0]":                      true,
+		".specification":                false,
+		".metadata":                     false,
+	}
+	for path, want := range tests {
+		if got := Ignored(path, prefixes); got != want {
+			t.Errorf("%s: got %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestDecodeDocuments(t *testing.T) {
+	a := `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+  namespace: prod
+data:
+  level: info
+`
+	b := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+  namespace: prod
+data:
+  level: debug
+---
+kind: Service
+apiVersion: v1
+metadata:
+  name: web
+`
+	documentsA, err := DecodeDocuments(convert.YAML, []byte(a))
+	if err != nil {
+		t.Fatal(err)
+	}
+	documentsB, err := DecodeDocuments(convert.YAML, []byte(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{`~ ["ConfigMap/prod/web"].data.level: "info" -> "debug"`}
+	if got := FormatChanges(Compare(documentsA, documentsB), false); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Documents without a kind are compared by their position
+	documents, err := DecodeDocuments(convert.YAML, []byte("a: 1\n---\nb: 2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := documents.([]interface{}); !ok {
+		t.Errorf("got %T", documents)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	dir := t.TempDir()
+	a, b, output := filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.json"), filepath.Join(dir, "out")
+	if err := ioutil.WriteFile(a, []byte("name: x\nversion: 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(b, []byte(`{"version": 2, "name": "x"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Flags are accepted before and after the files
+	for _, args := range [][]string{{"--semantic", "-o", output, a, b}, {a, b, "--semantic", "-o", output}} {
+		app := &cli.App{Commands: Module{}.GetModuleCommands()}
+		if err := app.Run(append([]string{"dops", "diff"}, args...)); err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "~ .version: 1 -> 2\n" {
+			t.Errorf("%v: got %q", args, data)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package diff
+
+// Operations of an edit
+const (
+	Equal = iota
+	Delete
+	Insert
+)
+
+// Edit is a single line of a diff
+type Edit struct {
+	Op int
+	// A is the index of the line in the old text, or -1 for inserted lines
+	A int
+	// B is the index of the line in the new text, or -1 for deleted lines
+	B int
+}
+
+// Lines returns the shortest edit script, which turns a into b, using the algorithm by Eugene W. Myers.
+// Deletions are placed before insertions.
+func Lines(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	var trace [][]int
+
+	for d := 0; d <= max; d++ {
+		// Only the diagonals -d..d can have been reached so far, so only these have to be saved
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m)
+			}
+		}
+	}
+
+	return nil
+}
+
+// backtrack walks through the saved states of each step backwards and builds the edit script
+func backtrack(trace [][]int, n, m int) []Edit {
+	var edits []Edit
+	x, y := n, m
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := func(k int) int {
+			if k < -d || k > d {
+				return 0
+			}
+			return trace[d][k+d]
+		}
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v(k-1) < v(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, Edit{Op: Equal, A: x, B: y})
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, Edit{Op: Insert, A: -1, B: y - 1})
+			} else {
+				edits = append(edits, Edit{Op: Delete, A: x - 1, B: -1})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
@@ -0,0 +1,222 @@
+package diff
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dops-cli/dops/module/convert"
+	"github.com/dops-cli/dops/say/color"
+)
+
+// Kinds of a change
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Change is a difference between two structured documents at a specific key path
+type Change struct {
+	Kind string
+	Path string
+	Old  interface{}
+	New  interface{}
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+var documentSeparator = regexp.MustCompile(`(?m)^---.*$`)
+
+// DecodeDocuments decodes structured data. YAML streams with multiple documents are decoded into an object.
+// If every document is a Kubernetes resource, the documents are keyed by kind, namespace and name,
+// so that the order of the resources does not matter. Otherwise they are keyed by their position.
+func DecodeDocuments(format string, data []byte) (interface{}, error) {
+	if format != convert.YAML || !documentSeparator.Match(data) {
+		return convert.Decode(format, data)
+	}
+
+	var documents []interface{}
+	for _, part := range documentSeparator.Split(string(data), -1) {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		document, err := convert.Decode(format, []byte(part))
+		if err != nil {
+			return nil, err
+		}
+		if document != nil {
+			documents = append(documents, document)
+		}
+	}
+	if len(documents) == 1 {
+		return documents[0], nil
+	}
+
+	result := convert.NewObject()
+	for i, document := range documents {
+		key, ok := resourceKey(document)
+		if !ok {
+			return documents, nil
+		}
+		if _, exists := result.Get(key); exists {
+			key += "#" + strconv.Itoa(i)
+		}
+		result.Set(key, document)
+	}
+	return result, nil
+}
+
+// resourceKey returns 'Kind/namespace/name' of a Kubernetes resource
+func resourceKey(document interface{}) (string, bool) {
+	object, ok := document.(*convert.Object)
+	if !ok {
+		return "", false
+	}
+	kind, _ := object.Get("kind")
+	metadata, _ := object.Get("metadata")
+	meta, ok := metadata.(*convert.Object)
+	if kind == nil || !ok {
+		return "", false
+	}
+	name, _ := meta.Get("name")
+	if name == nil {
+		return "", false
+	}
+	namespace, _ := meta.Get("namespace")
+	if namespace == nil {
+		namespace = "default"
+	}
+	return convert.ToString(kind) + "/" + convert.ToString(namespace) + "/" + convert.ToString(name), true
+}
+
+// Compare returns all differences between a and b by key path.
+// The order of object keys is ignored, the order of array items is not.
+func Compare(a, b interface{}) []Change {
+	var changes []Change
+	compare("", a, b, &changes)
+	return changes
+}
+
+func compare(path string, a, b interface{}, changes *[]Change) {
+	switch x := a.(type) {
+	case *convert.Object:
+		y, ok := b.(*convert.Object)
+		if !ok {
+			break
+		}
+		keys := append([]string{}, x.Keys()...)
+		for _, key := range y.Keys() {
+			if _, exists := x.Get(key); !exists {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			valueA, inA := x.Get(key)
+			valueB, inB := y.Get(key)
+			childPath := path + pathKey(key)
+			switch {
+			case !inB:
+				*changes = append(*changes, Change{Kind: Removed, Path: childPath, Old: valueA})
+			case !inA:
+				*changes = append(*changes, Change{Kind: Added, Path: childPath, New: valueB})
+			default:
+				compare(childPath, valueA, valueB, changes)
+			}
+		}
+		return
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(x) || i < len(y); i++ {
+			childPath := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(y):
+				*changes = append(*changes, Change{Kind: Removed, Path: childPath, Old: x[i]})
+			case i >= len(x):
+				*changes = append(*changes, Change{Kind: Added, Path: childPath, New: y[i]})
+			default:
+				compare(childPath, x[i], y[i], changes)
+			}
+		}
+		return
+	}
+
+	if !scalarEqual(a, b) {
+		if path == "" {
+			path = "."
+		}
+		*changes = append(*changes, Change{Kind: Changed, Path: path, Old: a, New: b})
+	}
+}
+
+// scalarEqual compares two values, numbers are compared by their value regardless of their type
+func scalarEqual(a, b interface{}) bool {
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return x == y
+		case float64:
+			return float64(x) == y
+		}
+		return false
+	case float64:
+		switch y := b.(type) {
+		case int64:
+			return x == float64(y)
+		case float64:
+			return x == y
+		}
+		return false
+	case *convert.Object, []interface{}:
+		// Objects and arrays are only passed here, if the other value has a different type
+		return false
+	}
+	return a == b
+}
+
+// pathKey formats an object key as part of a key path, like '.name' or '["app.kubernetes.io/name"]'
+func pathKey(key string) string {
+	if identifier.MatchString(key) {
+		return "." + key
+	}
+	return "[" + strconv.Quote(key) + "]"
+}
+
+// Ignored returns true if path is one of the prefixes or a child of them
+func Ignored(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+".") || strings.HasPrefix(path, prefix+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatChanges returns a line for every change
+func FormatChanges(changes []Change, c color.Colorizer) []string {
+	lines := make([]string, len(changes))
+	for i, change := range changes {
+		switch change.Kind {
+		case Added:
+			lines[i] = c.Paint(color.SGreen, "+ "+change.Path+": "+formatValue(change.New))
+		case Removed:
+			lines[i] = c.Paint(color.SRed, "- "+change.Path+": "+formatValue(change.Old))
+		case Changed:
+			lines[i] = c.Paint(color.SYellow, "~ "+change.Path+": "+formatValue(change.Old)+" -> "+formatValue(change.New))
+		}
+	}
+	return lines
+}
+
+func formatValue(value interface{}) string {
+	data, err := convert.Encode(convert.JSON, value, convert.EncodeOptions{Compact: true, SortKeys: true})
+	if err != nil {
+		return convert.ToString(value)
+	}
+	return strings.TrimSuffix(string(data), "\n")
+}
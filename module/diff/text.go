@@ -0,0 +1,189 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dops-cli/dops/say/color"
+)
+
+// hunk is a group of edits, which are close to each other
+type hunk struct {
+	edits []Edit
+	// aStart and bStart are the amount of lines in a and b before the hunk
+	aStart int
+	bStart int
+}
+
+// hunks groups the changed lines with context lines around them.
+// Changes, which are less than 2*context lines apart, are grouped into the same hunk.
+func hunks(edits []Edit, context int) []hunk {
+	var result []hunk
+	start, end := -1, -1
+
+	for i, e := range edits {
+		if e.Op == Equal {
+			continue
+		}
+		if start != -1 && i-context <= end+context {
+			end = i
+			continue
+		}
+		if start != -1 {
+			result = append(result, newHunk(edits, start, end, context))
+		}
+		start, end = i, i
+	}
+	if start != -1 {
+		result = append(result, newHunk(edits, start, end, context))
+	}
+
+	return result
+}
+
+func newHunk(edits []Edit, start, end, context int) hunk {
+	from := start - context
+	if from < 0 {
+		from = 0
+	}
+	to := end + context + 1
+	if to > len(edits) {
+		to = len(edits)
+	}
+
+	h := hunk{edits: edits[from:to]}
+	for _, e := range edits[:from] {
+		if e.A != -1 {
+			h.aStart++
+		}
+		if e.B != -1 {
+			h.bStart++
+		}
+	}
+	return h
+}
+
+// header returns the range information of a hunk, like '@@ -1,4 +1,5 @@'
+func (h hunk) header() string {
+	aCount, bCount := 0, 0
+	for _, e := range h.edits {
+		if e.A != -1 {
+			aCount++
+		}
+		if e.B != -1 {
+			bCount++
+		}
+	}
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.aStart, aCount), hunkRange(h.bStart, bCount))
+}
+
+// hunkRange formats a range of lines. Empty ranges point to the line before them, like in GNU diff.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// Unified returns the diff of a and b in the unified format
+func Unified(nameA, nameB string, a, b []string, context int, c color.Colorizer) []string {
+	edits := Lines(a, b)
+	groups := hunks(edits, context)
+	if len(groups) == 0 {
+		return nil
+	}
+
+	out := []string{
+		c.Paint(color.SHiWhite, "--- "+nameA),
+		c.Paint(color.SHiWhite, "+++ "+nameB),
+	}
+	for _, h := range groups {
+		out = append(out, c.Paint(color.SCyan, h.header()))
+		for _, e := range h.edits {
+			switch e.Op {
+			case Equal:
+				out = append(out, " "+a[e.A])
+			case Delete:
+				out = append(out, c.Paint(color.SRed, "-"+a[e.A]))
+			case Insert:
+				out = append(out, c.Paint(color.SGreen, "+"+b[e.B]))
+			}
+		}
+	}
+
+	return out
+}
+
+// SideBySide returns the diff of a and b in two columns, which fit into width characters
+func SideBySide(nameA, nameB string, a, b []string, context, width int, c color.Colorizer) []string {
+	edits := Lines(a, b)
+	groups := hunks(edits, context)
+	if len(groups) == 0 {
+		return nil
+	}
+
+	column := (width - 3) / 2
+	if column < 10 {
+		column = 10
+	}
+	row := func(left, separator, right string) string {
+		return pad(left, column) + " " + separator + " " + truncate(right, column)
+	}
+
+	out := []string{c.Paint(color.SHiWhite, row(nameA, " ", nameB))}
+	for i, h := range groups {
+		if i > 0 {
+			out = append(out, c.Paint(color.SCyan, row("...", " ", "...")))
+		}
+
+		// Deleted and inserted lines, which follow each other, are shown next to each other as changed lines
+		for j := 0; j < len(h.edits); {
+			e := h.edits[j]
+			if e.Op == Equal {
+				out = append(out, row(a[e.A], " ", b[e.B]))
+				j++
+				continue
+			}
+
+			var deleted, inserted []string
+			for ; j < len(h.edits) && h.edits[j].Op == Delete; j++ {
+				deleted = append(deleted, a[h.edits[j].A])
+			}
+			for ; j < len(h.edits) && h.edits[j].Op == Insert; j++ {
+				inserted = append(inserted, b[h.edits[j].B])
+			}
+
+			for k := 0; k < len(deleted) || k < len(inserted); k++ {
+				switch {
+				case k < len(deleted) && k < len(inserted):
+					out = append(out, c.Paint(color.SYellow, row(deleted[k], "|", inserted[k])))
+				case k < len(deleted):
+					out = append(out, c.Paint(color.SRed, row(deleted[k], "<", "")))
+				default:
+					out = append(out, c.Paint(color.SGreen, row("", ">", inserted[k])))
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+// truncate shortens s to width characters
+func truncate(s string, width int) string {
+	s = strings.ReplaceAll(s, "\t", "    ")
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width-1]) + "…"
+}
+
+// pad truncates s and fills it up with spaces to width characters
+func pad(s string, width int) string {
+	s = truncate(s, width)
+	return s + strings.Repeat(" ", width-utf8.RuneCountInString(s))
+}
@@ -0,0 +1,214 @@
+package dns
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Transports, which can be used to send queries
+const (
+	UDP   = "udp"
+	TCP   = "tcp"
+	HTTPS = "https"
+)
+
+// defaultServer is used, if no nameserver is found in /etc/resolv.conf
+const defaultServer = "1.1.1.1:53"
+
+// Server is a resolver, which answers queries
+type Server struct {
+	// Address is a HOST:PORT for UDP and TCP, or a URL for DNS over HTTPS
+	Address   string
+	Transport string
+}
+
+func (s Server) String() string {
+	if s.Transport == HTTPS {
+		return s.Address
+	}
+	return s.Transport + "://" + s.Address
+}
+
+// ParseServer parses resolvers like 1.1.1.1, 1.1.1.1:53, tcp://1.1.1.1:53 and https://cloudflare-dns.com/dns-query
+func ParseServer(spec string) (Server, error) {
+	switch {
+	case strings.HasPrefix(spec, "https://"):
+		return Server{Address: spec, Transport: HTTPS}, nil
+	case strings.HasPrefix(spec, "tcp://"):
+		return Server{Address: withPort(strings.TrimPrefix(spec, "tcp://")), Transport: TCP}, nil
+	case strings.HasPrefix(spec, "udp://"):
+		return Server{Address: withPort(strings.TrimPrefix(spec, "udp://")), Transport: UDP}, nil
+	case strings.Contains(spec, "://"):
+		return Server{}, errors.New("unsupported resolver: " + spec)
+	}
+	return Server{Address: withPort(spec), Transport: UDP}, nil
+}
+
+func withPort(address string) string {
+	if _, _, err := net.SplitHostPort(address); err == nil {
+		return address
+	}
+	return net.JoinHostPort(strings.Trim(address, "[]"), "53")
+}
+
+// SystemServer returns the first nameserver of /etc/resolv.conf, or 1.1.1.1 if there is none
+func SystemServer() Server {
+	f, err := os.Open("/etc/resolv.conf")
+	if err == nil {
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) >= 2 && fields[0] == "nameserver" {
+				return Server{Address: withPort(fields[1]), Transport: UDP}
+			}
+		}
+	}
+	return Server{Address: defaultServer, Transport: UDP}
+}
+
+// Response is the answer of a server to a query
+type Response struct {
+	Message
+	Server   Server
+	Duration time.Duration
+	// Transport is the transport, which was used. It's TCP, if a truncated UDP response was retried.
+	Transport string
+}
+
+// Query sends a recursive query to the server
+func Query(ctx context.Context, server Server, name string, t uint16) (*Response, error) {
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	// The ID must be unpredictable, so that spoofed responses can't guess it
+	var random [2]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return nil, err
+	}
+	id := binary.BigEndian.Uint16(random[:])
+	query, err := NewQuery(id, name, t).Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	transport := server.Transport
+	var answer []byte
+	switch transport {
+	case HTTPS:
+		// The ID should be 0 for DNS over HTTPS, so that responses can be cached
+		binary.BigEndian.PutUint16(query, 0)
+		id = 0
+		answer, err = exchangeHTTPS(ctx, server.Address, query)
+	case TCP:
+		answer, err = exchangeTCP(ctx, server.Address, query)
+	default:
+		answer, err = exchangeUDP(ctx, server.Address, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := Unpack(answer)
+	if err != nil {
+		return nil, err
+	}
+	if m.Truncated && transport == UDP {
+		transport = TCP
+		if answer, err = exchangeTCP(ctx, server.Address, query); err != nil {
+			return nil, err
+		}
+		if m, err = Unpack(answer); err != nil {
+			return nil, err
+		}
+	}
+	if m.ID != id || !m.Response {
+		return nil, errors.New("response does not match the query")
+	}
+	return &Response{Message: m, Server: server, Duration: time.Since(start), Transport: transport}, nil
+}
+
+func exchangeUDP(ctx context.Context, address string, query []byte) ([]byte, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		// Responses with a different ID are ignored, they could be late answers or spoofed
+		if n >= 2 && bytes.Equal(buf[:2], query[:2]) {
+			return buf[:n], nil
+		}
+	}
+}
+
+func exchangeTCP(ctx context.Context, address string, query []byte) ([]byte, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	// Messages over TCP are prefixed with their length
+	if _, err := conn.Write(append([]byte{byte(len(query) >> 8), byte(len(query))}, query...)); err != nil {
+		return nil, err
+	}
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return nil, err
+	}
+	answer := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, answer); err != nil {
+		return nil, err
+	}
+	return answer, nil
+}
+
+// exchangeHTTPS sends a query with DNS over HTTPS (RFC 8484)
+func exchangeHTTPS(ctx context.Context, url string, query []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return ioutil.ReadAll(io.LimitReader(resp.Body, 65535))
+}
@@ -0,0 +1,412 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:      "dns",
+			Aliases:   []string{"dig"},
+			Usage:     "Looks up DNS records with a chosen resolver",
+			ArgsUsage: "[NAMES...]",
+			// Flags are also accepted after the names
+			InterspersedFlags: true,
+			Description: `DNS queries the records of names and shows their TTLs and how long the resolver took to answer.
+IP addresses are looked up in reverse, so that their PTR records are shown. If no names are given, they are read line by line from --input or stdin,
+which makes it possible to look up the addresses found by 'dops extract text predefined ipaddress'.
+
+Resolvers are given with --server. They can be addresses like 1.1.1.1 or 1.1.1.1:53, tcp://1.1.1.1 to query over TCP
+or URLs like https://cloudflare-dns.com/dns-query for DNS over HTTPS. The first nameserver of /etc/resolv.conf is used by default.
+Truncated UDP responses are repeated over TCP.
+
+With --compare every resolver is queried and the answers are shown next to each other, ignoring TTLs,
+so that differences, like during the propagation of a change, can be spotted.`,
+			Category: categories.Network,
+			Examples: []cli.Example{
+				{
+					ShortDescription: "Look up the mail and text records of a domain with Cloudflare's resolver",
+					Usage:            "dops dns --type MX,TXT --server 1.1.1.1 example.com",
+				},
+				{
+					ShortDescription: "Look up records over DNS over HTTPS",
+					Usage:            "dops dns --server https://dns.google/dns-query example.com",
+				},
+				{
+					ShortDescription: "Compare the answers of multiple resolvers",
+					Usage:            "dops dns --compare --server 1.1.1.1,8.8.8.8,9.9.9.9 --type A,NS example.com",
+				},
+				{
+					ShortDescription: "Look up the hostnames of the IP addresses in a log file",
+					Usage:            "dops extract text predefined --input access.log ipaddress | dops dns",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				types, err := parseTypes(utils.StringList(c, "type"))
+				if err != nil {
+					return err
+				}
+				servers, err := parseServers(utils.StringList(c, "server"), c.Bool("tcp"))
+				if err != nil {
+					return err
+				}
+				names := c.Args().Slice()
+				if len(names) == 0 {
+					if names, err = readNames(c.String("input")); err != nil {
+						return err
+					}
+				}
+				if len(names) == 0 {
+					return errors.New("no names to look up")
+				}
+				for _, name := range names {
+					// Names can't start with a hyphen, so this is a misplaced or unknown flag
+					if strings.HasPrefix(name, "-") {
+						return fmt.Errorf("invalid name %q, names can't start with '-'", name)
+					}
+				}
+				compare := c.Bool("compare")
+				if compare && len(servers) < 2 {
+					return errors.New("--compare needs at least two resolvers")
+				}
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				interrupt := make(chan os.Signal, 1)
+				signal.Notify(interrupt, os.Interrupt)
+				defer signal.Stop(interrupt)
+				go func() {
+					select {
+					case <-interrupt:
+						cancel()
+					case <-ctx.Done():
+					}
+				}()
+
+				lookups := Lookups(ctx, questions(names, types), servers, c.Duration("timeout"), c.Int("concurrency"))
+
+				if c.Bool("json") {
+					data, err := json.MarshalIndent(toJSON(lookups), "", "  ")
+					if err != nil {
+						return err
+					}
+					return utils.WriteOutput(c, []string{string(data)})
+				}
+				colored := color.NewColorizer(c.String("output") == "")
+				if compare {
+					out, differences := compareTable(lookups, servers, colored)
+					if err := utils.WriteOutput(c, []string{out}); err != nil {
+						return err
+					}
+					if differences > 0 {
+						say.Warning(fmt.Sprintf("The resolvers answered %d of %d lookups differently", differences, len(lookups)/len(servers)))
+					}
+					return nil
+				}
+				return utils.WriteOutput(c, []string{table(lookups, len(servers) > 1, colored)})
+			},
+			Flags: append([]cli.Flag{
+				&cli.StringSliceFlag{
+					Name:    "type",
+					Aliases: []string{"t"},
+					Usage:   "Looks up records of the `TYPES`, separated by commas, like A,AAAA,MX,TXT,CNAME,NS,SRV,CAA",
+					Value:   cli.NewStringSlice("A", "AAAA"),
+				},
+				&cli.StringSliceFlag{
+					Name:    "server",
+					Aliases: []string{"s"},
+					Usage:   "Queries the `RESOLVERS`, separated by commas, like 1.1.1.1, tcp://1.1.1.1:53 or https://cloudflare-dns.com/dns-query",
+				},
+				&cli.BoolFlag{
+					Name:  "tcp",
+					Usage: "Queries over TCP instead of UDP",
+				},
+				&cli.BoolFlag{
+					Name:    "compare",
+					Aliases: []string{"c"},
+					Usage:   "Compares the answers of the resolvers",
+				},
+				&cli.PathFlag{
+					Name:      "input",
+					Aliases:   []string{"i"},
+					Usage:     "Reads the names line by line from `FILE`, reads stdin if not set and no names are given",
+					TakesFile: true,
+				},
+				&cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "Gives up waiting for an answer after `DURATION`",
+					Value: 5 * time.Second,
+				},
+				&cli.IntFlag{
+					Name:  "concurrency",
+					Usage: "Sends up to `N` queries at once",
+					Value: 10,
+				},
+				&cli.BoolFlag{
+					Name:    "json",
+					Aliases: []string{"j"},
+					Usage:   "Outputs the answers as JSON",
+				},
+			}, utils.OutputFlags()...),
+		},
+	}
+}
+
+func parseTypes(specs []string) ([]uint16, error) {
+	if len(specs) == 0 {
+		return []uint16{TypeA, TypeAAAA}, nil
+	}
+	types := make([]uint16, 0, len(specs))
+	for _, spec := range specs {
+		t, err := ParseType(spec)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+func parseServers(specs []string, tcp bool) ([]Server, error) {
+	if len(specs) == 0 {
+		specs = []string{SystemServer().String()}
+	}
+	servers := make([]Server, 0, len(specs))
+	for _, spec := range specs {
+		s, err := ParseServer(spec)
+		if err != nil {
+			return nil, err
+		}
+		if tcp && s.Transport == UDP {
+			s.Transport = TCP
+		}
+		servers = append(servers, s)
+	}
+	return servers, nil
+}
+
+// readNames reads one name per line and skips empty lines and comments
+func readNames(input string) ([]string, error) {
+	content, err := utils.ReadInput(input)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			names = append(names, line)
+		}
+	}
+	return utils.UniqueStringSlice(names), nil
+}
+
+// questions returns the questions for the names. IP addresses are asked for their PTR records.
+func questions(names []string, types []uint16) []Question {
+	var qs []Question
+	for _, name := range names {
+		if ip := net.ParseIP(name); ip != nil {
+			qs = append(qs, Question{Name: ReverseName(ip), Type: TypePTR})
+			continue
+		}
+		if !strings.HasSuffix(name, ".") {
+			name += "."
+		}
+		for _, t := range types {
+			qs = append(qs, Question{Name: name, Type: t})
+		}
+	}
+	return qs
+}
+
+// Lookup is the answer of a resolver to a question
+type Lookup struct {
+	Question
+	Server   Server
+	Response *Response
+	Err      error
+}
+
+// answers returns the data of the answers with the asked type, sorted and without TTLs
+func (l Lookup) answers() []string {
+	if l.Err != nil {
+		return []string{"error: " + l.Err.Error()}
+	}
+	if l.Response.RCode != 0 {
+		return []string{RCodeName(l.Response.RCode)}
+	}
+	var data []string
+	for _, r := range l.Response.Answers {
+		if r.Type == l.Type {
+			data = append(data, r.Data)
+		}
+	}
+	sort.Strings(data)
+	return data
+}
+
+// Lookups asks every server all questions and returns the answers in the order of the questions and servers
+func Lookups(ctx context.Context, questions []Question, servers []Server, timeout time.Duration, concurrency int) []Lookup {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	lookups := make([]Lookup, 0, len(questions)*len(servers))
+	for _, q := range questions {
+		for _, s := range servers {
+			lookups = append(lookups, Lookup{Question: q, Server: s})
+		}
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				l := &lookups[i]
+				queryCtx, cancel := context.WithTimeout(ctx, timeout)
+				l.Response, l.Err = Query(queryCtx, l.Server, l.Name, l.Type)
+				cancel()
+			}
+		}()
+	}
+	for i := range lookups {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return lookups
+}
+
+func table(lookups []Lookup, showServer bool, colored color.Colorizer) string {
+	header := []string{"Name", "Type", "TTL", "Data", "Time"}
+	if showServer {
+		header = append(header, "Server")
+	}
+	data := [][]string{header}
+	add := func(l Lookup, row []string) {
+		if showServer {
+			row = append(row, l.Server.String())
+		}
+		data = append(data, row)
+	}
+	for _, l := range lookups {
+		if l.Err != nil {
+			add(l, []string{l.Name, TypeName(l.Type), "", "error: " + l.Err.Error(), ""})
+			continue
+		}
+		took := l.Response.Duration.Round(10 * time.Microsecond).String()
+		if l.Response.Transport != l.Server.Transport {
+			took += " (" + l.Response.Transport + ")"
+		}
+		if l.Response.RCode != 0 || len(l.Response.Answers) == 0 {
+			status := RCodeName(l.Response.RCode)
+			if l.Response.RCode == 0 {
+				status = "no records"
+			}
+			add(l, []string{l.Name, TypeName(l.Type), "", status, took})
+			continue
+		}
+		for _, r := range l.Response.Answers {
+			add(l, []string{r.Name, TypeName(r.Type), strconv.FormatUint(uint64(r.TTL), 10), r.Data, took})
+			// The time is only shown once per lookup
+			took = ""
+		}
+	}
+	return say.Table(data, true, colored)
+}
+
+// compareTable shows the answers of the servers next to each other and returns how many questions were answered differently
+func compareTable(lookups []Lookup, servers []Server, colored color.Colorizer) (string, int) {
+	header := []string{"Name", "Type"}
+	for _, s := range servers {
+		header = append(header, s.String())
+	}
+	header = append(header, "Status")
+	data := [][]string{header}
+
+	differences := 0
+	for i := 0; i < len(lookups); i += len(servers) {
+		q := lookups[i].Question
+		row := []string{q.Name, TypeName(q.Type)}
+		status := "same"
+		var first string
+		for j, l := range lookups[i : i+len(servers)] {
+			answer := strings.Join(l.answers(), ", ")
+			if answer == "" {
+				answer = "no records"
+			}
+			if j == 0 {
+				first = answer
+			} else if answer != first {
+				status = "differs"
+			}
+			row = append(row, answer)
+		}
+		if status != "same" {
+			differences++
+		}
+		data = append(data, append(row, status))
+	}
+	return say.Table(data, true, colored), differences
+}
+
+type recordJSON struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	TTL  uint32 `json:"ttl"`
+	Data string `json:"data"`
+}
+
+// lookupJSON is the JSON form of a lookup
+type lookupJSON struct {
+	Name      string       `json:"name"`
+	Type      string       `json:"type"`
+	Server    string       `json:"server"`
+	Transport string       `json:"transport,omitempty"`
+	RCode     string       `json:"rcode,omitempty"`
+	Time      float64      `json:"time_ms,omitempty"`
+	Answers   []recordJSON `json:"answers"`
+	Error     string       `json:"error,omitempty"`
+}
+
+func toJSON(lookups []Lookup) []lookupJSON {
+	out := make([]lookupJSON, len(lookups))
+	for i, l := range lookups {
+		j := lookupJSON{Name: l.Name, Type: TypeName(l.Type), Server: l.Server.String(), Answers: []recordJSON{}}
+		if l.Err != nil {
+			j.Error = l.Err.Error()
+		} else {
+			j.Transport = l.Response.Transport
+			j.RCode = RCodeName(l.Response.RCode)
+			j.Time = float64(l.Response.Duration) / float64(time.Millisecond)
+			for _, r := range l.Response.Answers {
+				j.Answers = append(j.Answers, recordJSON{r.Name, TypeName(r.Type), r.TTL, r.Data})
+			}
+		}
+		out[i] = j
+	}
+	return out
+}
@@ -0,0 +1,275 @@
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/dops-cli/dops/cli"
+)
+
+// stub answers queries for example.com. and truncates UDP responses for big.example.com.
+func stub(q Question) Message {
+	m := Message{Response: true, Authoritative: true, Questions: []Question{q}}
+	a := &dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}}
+	target := dnsmessage.MustNewName("example.com.")
+	switch {
+	case q.Name == "example.com." && q.Type == TypeA:
+		m.Answers = []Record{{Name: q.Name, TTL: 300, Body: a}}
+	case q.Name == "example.com." && q.Type == TypeMX:
+		m.Answers = []Record{{Name: q.Name, TTL: 60, Body: &dnsmessage.MXResource{Pref: 10, MX: dnsmessage.MustNewName("mail.example.com.")}}}
+	case q.Name == "example.com." && q.Type == TypeCAA:
+		caa := &dnsmessage.UnknownResource{Type: dnsmessage.Type(TypeCAA), Data: append([]byte{0, 5}, "issueletsencrypt.org"...)}
+		m.Answers = []Record{{Name: q.Name, TTL: 60, Body: caa}}
+	case q.Name == "www.example.com.":
+		m.Answers = []Record{
+			{Name: q.Name, TTL: 60, Body: &dnsmessage.CNAMEResource{CNAME: target}},
+			{Name: "example.com.", TTL: 300, Body: a},
+		}
+	case q.Name == "1.2.0.192.in-addr.arpa." && q.Type == TypePTR:
+		m.Answers = []Record{{Name: q.Name, TTL: 60, Body: &dnsmessage.PTRResource{PTR: target}}}
+	case q.Name == "big.example.com.":
+		for i := 0; i < 100; i++ {
+			m.Answers = append(m.Answers, Record{Name: q.Name, TTL: 60, Body: &dnsmessage.AResource{A: [4]byte{10, 0, 0, byte(i)}}})
+		}
+	default:
+		m.RCode = 3
+	}
+	return m
+}
+
+func answer(t *testing.T, query []byte, udp bool) []byte {
+	m, err := Unpack(query)
+	if err != nil {
+		t.Error(err)
+		return nil
+	}
+	r := stub(m.Questions[0])
+	r.ID = m.ID
+	if udp && len(r.Answers) > 20 {
+		r.Answers = nil
+		r.Truncated = true
+	}
+	b, err := r.Pack()
+	if err != nil {
+		t.Error(err)
+	}
+	return b
+}
+
+func startStub(t *testing.T) (string, func()) {
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := udp.LocalAddr().String()
+	tcp, err := net.Listen("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := udp.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = udp.WriteTo(answer(t, buf[:n], true), addr)
+		}
+	}()
+	go func() {
+		for {
+			conn, err := tcp.Accept()
+			if err != nil {
+				return
+			}
+			var length [2]byte
+			if _, err := io.ReadFull(conn, length[:]); err == nil {
+				query := make([]byte, binary.BigEndian.Uint16(length[:]))
+				if _, err := io.ReadFull(conn, query); err == nil {
+					b := answer(t, query, false)
+					_, _ = conn.Write(append([]byte{byte(len(b) >> 8), byte(len(b))}, b...))
+				}
+			}
+			conn.Close()
+		}
+	}()
+	return address, func() {
+		udp.Close()
+		tcp.Close()
+	}
+}
+
+func TestQuery(t *testing.T) {
+	address, stop := startStub(t)
+	defer stop()
+
+	tests := []struct {
+		name string
+		t    uint16
+		want []string
+	}{
+		{"example.com", TypeA, []string{"192.0.2.1"}},
+		{"example.com", TypeMX, []string{"10 mail.example.com."}},
+		{"example.com", TypeCAA, []string{`0 issue "letsencrypt.org"`}},
+		{"www.example.com", TypeA, []string{"example.com.", "192.0.2.1"}},
+		{"1.2.0.192.in-addr.arpa", TypePTR, []string{"example.com."}},
+	}
+	for _, transport := range []string{UDP, TCP} {
+		server := Server{Address: address, Transport: transport}
+		for _, tt := range tests {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			r, err := Query(ctx, server, tt.name, tt.t)
+			cancel()
+			if err != nil {
+				t.Errorf("%s %s over %s: %v", tt.name, TypeName(tt.t), transport, err)
+				continue
+			}
+			var got []string
+			for _, a := range r.Answers {
+				got = append(got, a.Data)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s %s over %s: got %q, want %q", tt.name, TypeName(tt.t), transport, got, tt.want)
+			}
+		}
+	}
+
+	r, err := Query(context.Background(), Server{Address: address, Transport: UDP}, "big.example.com", TypeA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Transport != TCP || len(r.Answers) != 100 {
+		t.Errorf("truncated response was not repeated over TCP: %s, %d answers", r.Transport, len(r.Answers))
+	}
+
+	r, err = Query(context.Background(), Server{Address: address, Transport: UDP}, "missing.example.com", TypeA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if RCodeName(r.RCode) != "NXDOMAIN" {
+		t.Errorf("got %s, want NXDOMAIN", RCodeName(r.RCode))
+	}
+}
+
+func TestLookupsCompare(t *testing.T) {
+	address, stop := startStub(t)
+	defer stop()
+
+	servers := []Server{{Address: address, Transport: UDP}, {Address: address, Transport: TCP}}
+	lookups := Lookups(context.Background(), questions([]string{"example.com", "192.0.2.1"}, []uint16{TypeA}), servers, time.Second, 4)
+	if len(lookups) != 4 {
+		t.Fatalf("got %d lookups, want 4", len(lookups))
+	}
+	if lookups[2].Name != "1.2.0.192.in-addr.arpa." || lookups[2].Type != TypePTR {
+		t.Errorf("IP address was not looked up in reverse: %s %s", lookups[2].Name, TypeName(lookups[2].Type))
+	}
+	if _, differences := compareTable(lookups, servers, false); differences != 0 {
+		t.Errorf("got %d differences, want 0", differences)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	address, stop := startStub(t)
+	defer stop()
+	output := filepath.Join(t.TempDir(), "answers.json")
+
+	app := &cli.App{Commands: Module{}.GetModuleCommands()}
+	if err := app.Run([]string{"dops", "dns", "example.com", "--type", "MX", "--server", address, "--json", "-o", output}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "mail.example.com.") || strings.Contains(string(data), "192.0.2.1") {
+		t.Errorf("got %s, want only the MX record", data)
+	}
+
+	if err := app.Run([]string{"dops", "dns", "--server", address, "--", "-example.com"}); err == nil {
+		t.Error("name starting with '-' was looked up")
+	}
+}
+
+func TestParseServer(t *testing.T) {
+	tests := map[string]Server{
+		"1.1.1.1":                    {"1.1.1.1:53", UDP},
+		"tcp://1.1.1.1:5353":         {"1.1.1.1:5353", TCP},
+		"udp://[::1]":                {"[::1]:53", UDP},
+		"https://dns.test/dns-query": {"https://dns.test/dns-query", HTTPS},
+	}
+	for spec, want := range tests {
+		if got, err := ParseServer(spec); err != nil || got != want {
+			t.Errorf("%s: got %+v, %v", spec, got, err)
+		}
+	}
+	if _, err := ParseServer("tls://1.1.1.1"); err == nil {
+		t.Error("expected an error for an unsupported scheme")
+	}
+}
+
+func TestPackUnpack(t *testing.T) {
+	name := dnsmessage.MustNewName("example.com.")
+	records := []Record{
+		{Name: "example.com", Body: &dnsmessage.AAAAResource{AAAA: [16]byte{0x20, 0x01, 0x0d, 0xb8, 15: 1}}},
+		{Name: "example.com", Body: &dnsmessage.NSResource{NS: name}},
+		{Name: "example.com", Body: &dnsmessage.SOAResource{NS: name, MBox: name, Serial: 1, Refresh: 2, Retry: 3, Expire: 4, MinTTL: 5}},
+		{Name: "example.com", Body: &dnsmessage.TXTResource{TXT: []string{"v=spf1", `a "b"`}}},
+		{Name: "_sip._tcp.example.com", Body: &dnsmessage.SRVResource{Priority: 1, Weight: 2, Port: 5060, Target: name}},
+		{Name: "example.com", Body: &dnsmessage.UnknownResource{Type: 65, Data: []byte{1, 2}}},
+	}
+	want := []string{
+		"2001:db8::1",
+		"example.com.",
+		"example.com. example.com. 1 2 3 4 5",
+		`"v=spf1" "a \"b\""`,
+		"1 2 5060 example.com.",
+		`\# 2 0102`,
+	}
+	b, err := Message{ID: 7, Response: true, RCode: 3, Answers: records}.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Unpack(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ID != 7 || !m.Response || RCodeName(m.RCode) != "NXDOMAIN" || len(m.Answers) != len(want) {
+		t.Fatalf("got %+v", m)
+	}
+	for i, r := range m.Answers {
+		if r.Data != want[i] || r.Class != ClassINET {
+			t.Errorf("%s: got %q, want %q", TypeName(r.Type), r.Data, want[i])
+		}
+	}
+	if m.Answers[5].Type != 65 || TypeName(m.Answers[5].Type) != "TYPE65" {
+		t.Errorf("got type %d", m.Answers[5].Type)
+	}
+
+	if _, err := (Message{Answers: []Record{{Name: "example.com", Type: TypeA}}}).Pack(); err == nil {
+		t.Error("expected an error for a record without data")
+	}
+	if _, err := NewQuery(1, "a..example.com", TypeA).Pack(); err == nil {
+		t.Error("expected an error for an empty label")
+	}
+	caa := &dnsmessage.UnknownResource{Type: dnsmessage.Type(TypeCAA), Data: []byte{0, 9, 'i'}}
+	if b, err = (Message{Answers: []Record{{Name: "example.com", Body: caa}}}).Pack(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Unpack(b); err == nil {
+		t.Error("expected an error for an invalid CAA record")
+	}
+	if _, err := Unpack(b[:5]); err == nil {
+		t.Error("expected an error for a short message")
+	}
+}
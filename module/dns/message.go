@@ -0,0 +1,275 @@
+package dns
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// Record types, which are parsed into their presentation format. Other types are shown as hex.
+const (
+	TypeA     = uint16(dnsmessage.TypeA)
+	TypeNS    = uint16(dnsmessage.TypeNS)
+	TypeCNAME = uint16(dnsmessage.TypeCNAME)
+	TypeSOA   = uint16(dnsmessage.TypeSOA)
+	TypePTR   = uint16(dnsmessage.TypePTR)
+	TypeMX    = uint16(dnsmessage.TypeMX)
+	TypeTXT   = uint16(dnsmessage.TypeTXT)
+	TypeAAAA  = uint16(dnsmessage.TypeAAAA)
+	TypeSRV   = uint16(dnsmessage.TypeSRV)
+	TypeOPT   = uint16(dnsmessage.TypeOPT)
+	TypeANY   = uint16(dnsmessage.TypeALL)
+	// TypeCAA is not parsed by dnsmessage, its data is read from an UnknownResource
+	TypeCAA uint16 = 257
+)
+
+// ClassINET is the internet class, the only class used by dns
+const ClassINET = uint16(dnsmessage.ClassINET)
+
+var typeNames = map[uint16]string{
+	TypeA:     "A",
+	TypeNS:    "NS",
+	TypeCNAME: "CNAME",
+	TypeSOA:   "SOA",
+	TypePTR:   "PTR",
+	TypeMX:    "MX",
+	TypeTXT:   "TXT",
+	TypeAAAA:  "AAAA",
+	TypeSRV:   "SRV",
+	TypeOPT:   "OPT",
+	TypeANY:   "ANY",
+	TypeCAA:   "CAA",
+}
+
+var rcodeNames = []string{"NOERROR", "FORMERR", "SERVFAIL", "NXDOMAIN", "NOTIMP", "REFUSED"}
+
+// TypeName returns the name of a record type, like AAAA or TYPE65
+func TypeName(t uint16) string {
+	if name, ok := typeNames[t]; ok {
+		return name
+	}
+	return "TYPE" + strconv.Itoa(int(t))
+}
+
+// ParseType parses record types like AAAA or TYPE65
+func ParseType(s string) (uint16, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	for t, name := range typeNames {
+		if name == s {
+			return t, nil
+		}
+	}
+	if strings.HasPrefix(s, "TYPE") {
+		if t, err := strconv.ParseUint(s[4:], 10, 16); err == nil {
+			return uint16(t), nil
+		}
+	}
+	return 0, errors.New("unknown record type: " + s)
+}
+
+// RCodeName returns the name of a response code, like NXDOMAIN
+func RCodeName(rcode int) string {
+	if rcode >= 0 && rcode < len(rcodeNames) {
+		return rcodeNames[rcode]
+	}
+	return "RCODE" + strconv.Itoa(rcode)
+}
+
+// Question is the question of a message
+type Question struct {
+	Name string
+	Type uint16
+}
+
+// Record is a resource record. Data is the record data in presentation format, like '10 mail.example.com.' for MX records.
+type Record struct {
+	Name  string
+	Type  uint16
+	Class uint16
+	TTL   uint32
+	Data  string
+	// Body is the parsed record data, it's packed as is. Its type overrides Type.
+	Body dnsmessage.ResourceBody
+}
+
+// Message is a DNS message
+type Message struct {
+	ID                 uint16
+	Response           bool
+	Authoritative      bool
+	Truncated          bool
+	RecursionDesired   bool
+	RecursionAvailable bool
+	RCode              int
+	Questions          []Question
+	Answers            []Record
+	Authorities        []Record
+	Additionals        []Record
+}
+
+// NewQuery returns a recursive query for name, which announces a UDP payload size of 4096 bytes with EDNS0
+func NewQuery(id uint16, name string, t uint16) Message {
+	return Message{
+		ID:               id,
+		RecursionDesired: true,
+		Questions:        []Question{{Name: name, Type: t}},
+		// The class of an OPT record is the UDP payload size
+		Additionals: []Record{{Name: ".", Type: TypeOPT, Class: 4096, Body: &dnsmessage.OPTResource{}}},
+	}
+}
+
+// newName returns the dnsmessage name of a domain name, which can be written without the trailing dot
+func newName(name string) (dnsmessage.Name, error) {
+	n, err := dnsmessage.NewName(strings.TrimSuffix(name, ".") + ".")
+	if err != nil {
+		return n, errors.New("invalid domain name: " + name)
+	}
+	return n, nil
+}
+
+// Pack encodes the message
+func (m Message) Pack() ([]byte, error) {
+	msg := dnsmessage.Message{Header: dnsmessage.Header{
+		ID:                 m.ID,
+		Response:           m.Response,
+		Authoritative:      m.Authoritative,
+		Truncated:          m.Truncated,
+		RecursionDesired:   m.RecursionDesired,
+		RecursionAvailable: m.RecursionAvailable,
+		RCode:              dnsmessage.RCode(m.RCode),
+	}}
+	for _, q := range m.Questions {
+		name, err := newName(q.Name)
+		if err != nil {
+			return nil, err
+		}
+		msg.Questions = append(msg.Questions, dnsmessage.Question{Name: name, Type: dnsmessage.Type(q.Type), Class: dnsmessage.ClassINET})
+	}
+
+	sections := []*[]dnsmessage.Resource{&msg.Answers, &msg.Authorities, &msg.Additionals}
+	for i, records := range [][]Record{m.Answers, m.Authorities, m.Additionals} {
+		for _, r := range records {
+			name, err := newName(r.Name)
+			if err != nil {
+				return nil, err
+			}
+			if r.Body == nil {
+				return nil, fmt.Errorf("the %s record %s has no data", TypeName(r.Type), r.Name)
+			}
+			class := dnsmessage.Class(r.Class)
+			if class == 0 {
+				class = dnsmessage.ClassINET
+			}
+			header := dnsmessage.ResourceHeader{Name: name, Class: class, TTL: r.TTL}
+			*sections[i] = append(*sections[i], dnsmessage.Resource{Header: header, Body: r.Body})
+		}
+	}
+	return msg.Pack()
+}
+
+// Unpack decodes a message and the data of its records
+func Unpack(b []byte) (Message, error) {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(b); err != nil {
+		return Message{}, err
+	}
+	m := Message{
+		ID:                 msg.Header.ID,
+		Response:           msg.Header.Response,
+		Authoritative:      msg.Header.Authoritative,
+		Truncated:          msg.Header.Truncated,
+		RecursionDesired:   msg.Header.RecursionDesired,
+		RecursionAvailable: msg.Header.RecursionAvailable,
+		RCode:              int(msg.Header.RCode),
+	}
+	for _, q := range msg.Questions {
+		m.Questions = append(m.Questions, Question{Name: q.Name.String(), Type: uint16(q.Type)})
+	}
+
+	sections := []*[]Record{&m.Answers, &m.Authorities, &m.Additionals}
+	for i, resources := range [][]dnsmessage.Resource{msg.Answers, msg.Authorities, msg.Additionals} {
+		for _, r := range resources {
+			data, err := formatBody(r.Body)
+			if err != nil {
+				return m, fmt.Errorf("invalid %s record: %w", TypeName(uint16(r.Header.Type)), err)
+			}
+			*sections[i] = append(*sections[i], Record{
+				Name:  r.Header.Name.String(),
+				Type:  uint16(r.Header.Type),
+				Class: uint16(r.Header.Class),
+				TTL:   r.Header.TTL,
+				Data:  data,
+				Body:  r.Body,
+			})
+		}
+	}
+	return m, nil
+}
+
+// formatBody returns the record data in presentation format
+func formatBody(body dnsmessage.ResourceBody) (string, error) {
+	switch b := body.(type) {
+	case *dnsmessage.AResource:
+		return net.IP(b.A[:]).String(), nil
+	case *dnsmessage.AAAAResource:
+		return net.IP(b.AAAA[:]).String(), nil
+	case *dnsmessage.NSResource:
+		return b.NS.String(), nil
+	case *dnsmessage.CNAMEResource:
+		return b.CNAME.String(), nil
+	case *dnsmessage.PTRResource:
+		return b.PTR.String(), nil
+	case *dnsmessage.MXResource:
+		return fmt.Sprintf("%d %s", b.Pref, b.MX), nil
+	case *dnsmessage.SRVResource:
+		return fmt.Sprintf("%d %d %d %s", b.Priority, b.Weight, b.Port, b.Target), nil
+	case *dnsmessage.SOAResource:
+		return fmt.Sprintf("%s %s %d %d %d %d %d", b.NS, b.MBox, b.Serial, b.Refresh, b.Retry, b.Expire, b.MinTTL), nil
+	case *dnsmessage.TXTResource:
+		parts := make([]string, len(b.TXT))
+		for i, txt := range b.TXT {
+			parts[i] = strconv.Quote(txt)
+		}
+		return strings.Join(parts, " "), nil
+	case *dnsmessage.OPTResource:
+		var data []byte
+		for _, o := range b.Options {
+			data = append(data, byte(o.Code>>8), byte(o.Code), byte(len(o.Data)>>8), byte(len(o.Data)))
+			data = append(data, o.Data...)
+		}
+		return unknownData(data), nil
+	case *dnsmessage.UnknownResource:
+		if uint16(b.Type) != TypeCAA {
+			return unknownData(b.Data), nil
+		}
+		if len(b.Data) < 2 || 2+int(b.Data[1]) > len(b.Data) {
+			return "", errors.New("the tag is longer than the record")
+		}
+		tag := string(b.Data[2 : 2+b.Data[1]])
+		return fmt.Sprintf("%d %s %s", b.Data[0], tag, strconv.Quote(string(b.Data[2+int(b.Data[1]):]))), nil
+	}
+	return body.GoString(), nil
+}
+
+// unknownData returns record data like in RFC 3597
+func unknownData(data []byte) string {
+	return fmt.Sprintf("\\# %d %s", len(data), hex.EncodeToString(data))
+}
+
+// ReverseName returns the name for reverse lookups of an IP address, like 4.3.2.1.in-addr.arpa.
+func ReverseName(ip net.IP) string {
+	if v4 := ip.To4(); v4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa.", v4[3], v4[2], v4[1], v4[0])
+	}
+	digits := hex.EncodeToString(ip.To16())
+	labels := make([]string, 0, len(digits))
+	for i := len(digits) - 1; i >= 0; i-- {
+		labels = append(labels, string(digits[i]))
+	}
+	return strings.Join(labels, ".") + ".ip6.arpa."
+}
@@ -19,26 +19,30 @@ func (Module) GetModuleCommands() []*cli.Command {
 			Name:        "echo",
 			Usage:       "Displays input text in the console",
 			Examples:    nil,
-			Description: "Echo displays text from stdin, a file or from the arguments, to the user.",
+			Description: "Echo displays text from a file, an URL or from the arguments, to the user. Compressed files are decompressed transparently.",
 			Category:    categories.TextProcessing,
 			Action: func(context *cli.Context) error {
-				var input string
-
-				if context.String("input") != "" {
-					input = utils.Input(context.String("input"))
-				} else {
-					input = strings.Join(context.Args().Slice(), " ")
+				if context.String("input") == "" {
+					say.Text(strings.Join(context.Args().Slice(), " "))
+					return nil
 				}
 
-				say.Text(input)
+				r, err := utils.InputReader(context.String("input"))
+				if err != nil {
+					return err
+				}
+				defer r.Close()
 
-				return nil
+				return utils.ForEachLine(r, func(line string) error {
+					say.Text(line)
+					return nil
+				})
 			},
 			Flags: []cli.Flag{
 				&cli.StringFlag{
 					Aliases: []string{"i"},
 					Name:    "input",
-					Usage:   "Input accepts a file or an URL",
+					Usage:   "Input accepts a file or an URL, which may be compressed with gzip, bzip2, xz or zstd",
 				},
 			},
 		},
@@ -18,6 +18,9 @@ func (Module) GetModuleCommands() []*cli.Command {
 			Category:    categories.DataAnalysis,
 			Subcommands: []*cli.Command{
 				Text(),
+				HTML(),
+				Secrets(),
+				Test(),
 			},
 		},
 	}
@@ -0,0 +1,448 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/utils"
+)
+
+// urlAttributes are resolved against the base URL of a document
+var urlAttributes = []string{"href", "src", "action", "formaction", "cite", "poster", "data", "background"}
+
+// attrSuffix splits an expression like 'a@href' or './/a/@href' into the selector and the attribute
+var attrSuffix = regexp.MustCompile(`^(.*?)/?@([A-Za-z_:][\w:.-]*)$`)
+
+// htmlField is a named column of an HTML table, which is evaluated relative to every selected element
+type htmlField struct {
+	Name     string
+	Selector string
+	Attr     string
+}
+
+// HTMLQuery selects elements of an HTML document and extracts values from them
+type HTMLQuery struct {
+	// Selector is a CSS selector, XPath is used if it is empty
+	Selector string
+	XPath    string
+	// Attr extracts an attribute instead of the text
+	Attr string
+	// Mode is one of "text", "html" (inner HTML) or "outer-html"
+	Mode   string
+	Base   *url.URL
+	Fields []htmlField
+}
+
+// ParseHTMLField parses a field like 'name=selector', 'name=selector@attr' or 'name=@attr'
+func ParseHTMLField(field string) (htmlField, error) {
+	parts := strings.SplitN(field, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return htmlField{}, errors.New("field must be NAME=SELECTOR[@ATTR]: " + field)
+	}
+	f := htmlField{Name: strings.TrimSpace(parts[0]), Selector: strings.TrimSpace(parts[1])}
+	if m := attrSuffix.FindStringSubmatch(f.Selector); m != nil {
+		f.Selector, f.Attr = m[1], m[2]
+	}
+	return f, nil
+}
+
+// find returns all elements below node, which match the CSS selector or XPath expression
+func (q *HTMLQuery) find(node *html.Node, selector string) ([]*html.Node, error) {
+	if q.Selector != "" {
+		sel, err := cascadia.Compile(selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSS selector %q: %w", selector, err)
+		}
+		return sel.MatchAll(node), nil
+	}
+	nodes, err := htmlquery.QueryAll(node, selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid XPath expression %q: %w", selector, err)
+	}
+	return nodes, nil
+}
+
+// Run selects all matching elements of doc and extracts a value from each of them
+func (q *HTMLQuery) Run(doc *html.Node) ([]string, error) {
+	selector, attr := q.Selector, q.Attr
+	if selector == "" {
+		selector = q.XPath
+		if m := attrSuffix.FindStringSubmatch(selector); m != nil && m[1] != "" {
+			selector, attr = m[1], m[2]
+		}
+	}
+
+	nodes, err := q.find(doc, selector)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for _, n := range nodes {
+		if attr != "" {
+			if v, ok := q.attribute(n, attr); ok {
+				values = append(values, v)
+			}
+			continue
+		}
+		values = append(values, q.value(n))
+	}
+	return values, nil
+}
+
+// Table selects all matching elements of doc and evaluates the fields for each of them
+func (q *HTMLQuery) Table(doc *html.Node) ([]map[string]string, error) {
+	selector := q.Selector
+	if selector == "" {
+		selector = q.XPath
+	}
+	nodes, err := q.find(doc, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []map[string]string{}
+	for _, n := range nodes {
+		row := map[string]string{}
+		for _, f := range q.Fields {
+			target := n
+			if f.Selector != "" {
+				found, err := q.find(n, f.Selector)
+				if err != nil {
+					return nil, err
+				}
+				// the element itself is matched by CSS selectors as well
+				target = nil
+				for _, candidate := range found {
+					if candidate != n || q.Selector == "" {
+						target = candidate
+						break
+					}
+				}
+			}
+			if target == nil {
+				row[f.Name] = ""
+				continue
+			}
+			if f.Attr != "" {
+				row[f.Name], _ = q.attribute(target, f.Attr)
+				continue
+			}
+			row[f.Name] = q.value(target)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// value returns the text or HTML of a node, depending on the mode
+func (q *HTMLQuery) value(n *html.Node) string {
+	switch q.Mode {
+	case "html":
+		var buf bytes.Buffer
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			_ = html.Render(&buf, child)
+		}
+		return buf.String()
+	case "outer-html":
+		var buf bytes.Buffer
+		_ = html.Render(&buf, n)
+		return buf.String()
+	default:
+		return nodeText(n)
+	}
+}
+
+// attribute returns an attribute of a node, URLs are resolved against the base URL
+func (q *HTMLQuery) attribute(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if !strings.EqualFold(a.Key, name) {
+			continue
+		}
+		value := strings.TrimSpace(a.Val)
+		if q.Base != nil && utils.SliceContainsString(urlAttributes, strings.ToLower(name)) {
+			if ref, err := url.Parse(value); err == nil {
+				value = q.Base.ResolveReference(ref).String()
+			}
+		}
+		return value, true
+	}
+	return "", false
+}
+
+// nodeText returns the visible text of a node with collapsed whitespace
+func nodeText(n *html.Node) string {
+	var words []string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			words = append(words, strings.Fields(n.Data)...)
+		case n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style"):
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return strings.Join(words, " ")
+}
+
+// BaseURL returns the URL relative links of a document are resolved against.
+// An explicit base wins over the <base href> of the document, which is resolved against the input URL.
+func BaseURL(doc *html.Node, input, base string) (*url.URL, error) {
+	if base != "" {
+		return url.Parse(base)
+	}
+
+	var result *url.URL
+	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
+		u, err := url.Parse(input)
+		if err != nil {
+			return nil, err
+		}
+		result = u
+	}
+
+	if tag := cascadia.MustCompile("base[href]").MatchFirst(doc); tag != nil {
+		href, err := url.Parse(strings.TrimSpace(htmlquery.SelectAttr(tag, "href")))
+		if err != nil {
+			return result, nil
+		}
+		if result == nil {
+			return href, nil
+		}
+		return result.ResolveReference(href), nil
+	}
+	return result, nil
+}
+
+// HTML returns the html subcommand
+func HTML() *cli.Command {
+	return &cli.Command{
+		Name:  "html",
+		Usage: "Extracts text, attributes or HTML from HTML documents",
+		Description: `HTML parses an HTML document from a file, URL or stdin and selects elements with a CSS selector or an XPath expression.
+For every selected element the text is returned, or an attribute with --attr or the inner HTML with --html.
+Relative URLs in attributes like href and src are resolved against the base URL, which is --base, the <base href> of the document or the input URL.
+An XPath expression can select an attribute directly, like '//a/@href'.
+
+With --field the selected elements are turned into a table. Each field is 'NAME=SELECTOR', 'NAME=SELECTOR@ATTR' or 'NAME=@ATTR', where SELECTOR is relative to the selected element.
+Tables are written as JSON, CSV or tab separated text.
+
+With --template or --jsonl every value is a record with the field .Value, every table row is a record with the names of the fields, like '{{.name}}: {{.link}}'.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Extract all links of a website",
+				Usage:            "dops extract html --selector 'a[href]' --attr href -i https://example.com",
+			},
+			{
+				ShortDescription: "Extract the titles of articles with XPath",
+				Usage:            "dops extract html --xpath '//article/h2' -i index.html",
+			},
+			{
+				ShortDescription: "Extract a table of products as JSON",
+				Usage:            "dops extract html -s '.product' --field name=h3 --field price=.price --field link=a@href --format json -i shop.html",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			q := &HTMLQuery{
+				Selector: c.String("selector"),
+				XPath:    c.String("xpath"),
+				Attr:     c.String("attr"),
+				Mode:     "text",
+			}
+			if (q.Selector == "") == (q.XPath == "") {
+				return errors.New("either --selector or --xpath is required")
+			}
+			if c.Bool("html") {
+				q.Mode = "html"
+			}
+			if c.Bool("outer-html") {
+				q.Mode = "outer-html"
+			}
+			for _, field := range c.StringSlice("field") {
+				f, err := ParseHTMLField(field)
+				if err != nil {
+					return err
+				}
+				q.Fields = append(q.Fields, f)
+			}
+
+			input := c.String("input")
+			r, err := utils.InputReader(input)
+			if err != nil {
+				return err
+			}
+			doc, err := html.Parse(r)
+			r.Close()
+			if err != nil {
+				return err
+			}
+			if !c.Bool("no-resolve") {
+				if q.Base, err = BaseURL(doc, input, c.String("base")); err != nil {
+					return fmt.Errorf("invalid base URL: %w", err)
+				}
+			}
+
+			out, err := utils.CommandOutput(c)
+			if err != nil {
+				return err
+			}
+			if err := writeHTMLResults(c, q, doc, out); err != nil {
+				out.Abort()
+				return err
+			}
+			return out.Close()
+		},
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:    "input",
+				Aliases: []string{"i"},
+				Usage:   "Reads the document from a `FILE` or URL, reads stdin if not set",
+			},
+			&cli.StringFlag{
+				Name:    "selector",
+				Aliases: []string{"s"},
+				Usage:   "Selects elements with a CSS `SELECTOR`",
+			},
+			&cli.StringFlag{
+				Name:    "xpath",
+				Aliases: []string{"x"},
+				Usage:   "Selects elements with an XPath `EXPRESSION`",
+			},
+			&cli.StringFlag{
+				Name:  "attr",
+				Usage: "Extracts the attribute `NAME` instead of the text",
+			},
+			&cli.BoolFlag{
+				Name:  "html",
+				Usage: "Extracts the inner HTML instead of the text",
+			},
+			&cli.BoolFlag{
+				Name:  "outer-html",
+				Usage: "Extracts the HTML of the element including its tag",
+			},
+			&cli.StringSliceFlag{
+				Name:    "field",
+				Aliases: []string{"F"},
+				Usage:   "Adds a table column `NAME=SELECTOR[@ATTR]`, evaluated for each selected element",
+			},
+			&cli.StringFlag{
+				Name:  "base",
+				Usage: "Resolves relative URLs against `URL`",
+			},
+			&cli.BoolFlag{
+				Name:  "no-resolve",
+				Usage: "Does not resolve relative URLs",
+			},
+			&cli.BoolFlag{
+				Name:    "unique",
+				Aliases: []string{"u"},
+				Usage:   "Outputs every value only once",
+			},
+			&cli.OptionFlag{
+				Name:        "format",
+				Aliases:     []string{"f"},
+				Usage:       "Writes the values as `FORMAT`",
+				Options:     []string{"text", "json", "csv"},
+				DefaultText: "text",
+			},
+		}, append(utils.OutputFlags(), utils.RecordFlags()...)...),
+	}
+}
+
+// htmlValue is the representation of a value in output templates and JSON lines
+type htmlValue struct {
+	Value string `json:"value"`
+}
+
+// writeHTMLResults writes the extracted values or the table rows in the requested format
+func writeHTMLResults(c *cli.Context, q *HTMLQuery, doc *html.Node, out *utils.Sink) error {
+	format := c.Option("format")
+	if len(q.Fields) == 0 {
+		values, err := q.Run(doc)
+		if err != nil {
+			return err
+		}
+		if c.Bool("unique") {
+			values = utils.UniqueStringSlice(values)
+		}
+		switch {
+		case out.Records():
+			for _, v := range values {
+				if err := out.WriteRecord(v, htmlValue{Value: v}); err != nil {
+					return err
+				}
+			}
+			return nil
+		case format == "json":
+			if values == nil {
+				values = []string{}
+			}
+			data, err := json.MarshalIndent(values, "", "  ")
+			if err != nil {
+				return err
+			}
+			return out.WriteLine(string(data))
+		}
+		return out.WriteLines(values)
+	}
+
+	rows, err := q.Table(doc)
+	if err != nil {
+		return err
+	}
+	record := func(row map[string]string) []string {
+		values := make([]string, len(q.Fields))
+		for i, f := range q.Fields {
+			values[i] = row[f.Name]
+		}
+		return values
+	}
+
+	switch {
+	case out.Records():
+		for _, row := range rows {
+			if err := out.WriteRecord(strings.Join(record(row), "\t"), row); err != nil {
+				return err
+			}
+		}
+	case format == "json":
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		return out.WriteLine(string(data))
+	case format == "csv":
+		w := csv.NewWriter(out)
+		header := make([]string, len(q.Fields))
+		for i, f := range q.Fields {
+			header[i] = f.Name
+		}
+		_ = w.Write(header)
+		for _, row := range rows {
+			_ = w.Write(record(row))
+		}
+		w.Flush()
+		return w.Error()
+	default:
+		for _, row := range rows {
+			if err := out.WriteLine(strings.Join(record(row), "\t")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package extract
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func loadHTMLFixture(t *testing.T, name string) *html.Node {
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	doc, err := html.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestHTMLQuery(t *testing.T) {
+	doc := loadHTMLFixture(t, "shop.html")
+	base, err := BaseURL(doc, "https://example.com/index.html", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		query HTMLQuery
+		want  []string
+	}{
+		{
+			name:  "css text",
+			query: HTMLQuery{Selector: ".product h3", Mode: "text"},
+			want:  []string{"Coffee mug", "Tea pot"},
+		},
+		{
+			name:  "css attribute resolved against base",
+			query: HTMLQuery{Selector: "nav a[href]", Attr: "href", Base: base},
+			want:  []string{"https://example.com/shop/index.html", "https://example.org/about", "https://example.com/shop/#top"},
+		},
+		{
+			name:  "css attribute without base",
+			query: HTMLQuery{Selector: "img", Attr: "src"},
+			want:  []string{"img/mug.png"},
+		},
+		{
+			name:  "xpath text",
+			query: HTMLQuery{XPath: "//span[@class='price']", Mode: "text"},
+			want:  []string{"9.99", "24.50"},
+		},
+		{
+			name:  "xpath attribute",
+			query: HTMLQuery{XPath: "//div[@class='product']/@data-id"},
+			want:  []string{"1", "2"},
+		},
+		{
+			name:  "inner html",
+			query: HTMLQuery{Selector: ".product:nth-of-type(2) h3", Mode: "html"},
+			want:  []string{"Tea <em>pot</em>"},
+		},
+		{
+			name:  "text skips scripts and styles",
+			query: HTMLQuery{Selector: "head", Mode: "text"},
+			want:  []string{"Shop"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.query.Run(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTMLTable(t *testing.T) {
+	doc := loadHTMLFixture(t, "shop.html")
+	base, err := BaseURL(doc, "", "https://shop.example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []map[string]string{
+		{"id": "1", "name": "Coffee mug", "price": "9.99", "link": "https://shop.example.com/products/mug.html"},
+		{"id": "2", "name": "Tea pot", "price": "24.50", "link": "https://shop.example.com/products/pot.html"},
+	}
+
+	for _, q := range []HTMLQuery{
+		{Selector: ".product", Fields: []htmlField{{"id", "", "data-id"}, {"name", "h3", ""}, {"price", ".price", ""}, {"link", "a", "href"}}},
+		{XPath: "//div[@class='product']", Fields: []htmlField{{"id", "", "data-id"}, {"name", "./h3", ""}, {"price", ".//span", ""}, {"link", "./a", "href"}}},
+	} {
+		q.Base = base
+		got, err := q.Table(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s%s: got %v, want %v", q.Selector, q.XPath, got, want)
+		}
+	}
+
+	for _, field := range []string{"name=h3", "link=a@href", "id=@data-id", "link=.//a/@href"} {
+		if _, err := ParseHTMLField(field); err != nil {
+			t.Errorf("%s: %v", field, err)
+		}
+	}
+	if _, err := ParseHTMLField("h3"); err == nil {
+		t.Error("expected an error for a field without name")
+	}
+}
@@ -0,0 +1,621 @@
+package extract
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/module/convert"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Match is a single match of a regex in an input
+type Match struct {
+	File string
+	// Line is the number of the line, starting at 1
+	Line int
+	// Offset is the byte offset of the match in the input, starting at 0
+	Offset int64
+	Text   string
+	// Content is the whole line, which contains the match
+	Content string
+	// Groups contains the capture groups of the match
+	Groups []string
+	Before []string
+	After  []string
+}
+
+// matchOptions configure how matches are searched and written
+type matchOptions struct {
+	before int
+	after  int
+	// multiline searches the whole input at once instead of line by line
+	multiline bool
+}
+
+// spansLines returns true if re can match a line break, which is only possible if the whole input is searched at once
+func spansLines(re *regexp.Regexp) bool {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return false
+	}
+	var walk func(*syntax.Regexp) bool
+	walk = func(r *syntax.Regexp) bool {
+		switch r.Op {
+		case syntax.OpAnyChar:
+			return true
+		case syntax.OpLiteral:
+			for _, c := range r.Rune {
+				if c == '\n' {
+					return true
+				}
+			}
+		}
+		for _, sub := range r.Sub {
+			if walk(sub) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(parsed)
+}
+
+// matchWhole searches the whole input of r at once, so that matches can span multiple lines.
+// Content contains all lines of a match.
+func matchWhole(name string, r io.Reader, re *regexp.Regexp, validate func(string) bool, options matchOptions, cb func(Match) error) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	text := string(data)
+
+	// starts contains the offset of every line
+	starts := []int{0}
+	for i := 0; i < len(text)-1; i++ {
+		if text[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	lineOf := func(offset int) int {
+		return sort.Search(len(starts), func(i int) bool { return starts[i] > offset })
+	}
+	lines := func(from, to int) []string {
+		var result []string
+		for n := from; n <= to; n++ {
+			end := len(text)
+			if n < len(starts) {
+				end = starts[n]
+			}
+			result = append(result, strings.TrimSuffix(strings.TrimSuffix(text[starts[n-1]:end], "\n"), "\r"))
+		}
+		return result
+	}
+
+	for _, index := range re.FindAllStringSubmatchIndex(text, -1) {
+		match := text[index[0]:index[1]]
+		if validate != nil && !validate(match) {
+			continue
+		}
+		first, last := lineOf(index[0]), lineOf(index[0])
+		if index[1] > index[0] {
+			last = lineOf(index[1] - 1)
+		}
+		m := Match{
+			File:    name,
+			Line:    first,
+			Offset:  int64(index[0]),
+			Text:    match,
+			Content: strings.Join(lines(first, last), "\n"),
+			Before:  lines(maxInt(1, first-options.before), first-1),
+			After:   lines(last+1, minInt(len(starts), last+options.after)),
+		}
+		for g := 1; g < len(index)/2; g++ {
+			if index[2*g] == -1 {
+				m.Groups = append(m.Groups, "")
+			} else {
+				m.Groups = append(m.Groups, text[index[2*g]:index[2*g+1]])
+			}
+		}
+		if err := cb(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// matchLines searches r line by line and calls cb for each match.
+// Matches are only passed to cb, after their context lines were read.
+func matchLines(name string, r io.Reader, re *regexp.Regexp, validate func(string) bool, options matchOptions, cb func(Match) error) error {
+	lines := utils.NewLineReader(r)
+	var before []string
+	var pending []*Match
+
+	flush := func(all bool) error {
+		for len(pending) > 0 && (all || len(pending[0].After) >= options.after) {
+			if err := cb(*pending[0]); err != nil {
+				return err
+			}
+			pending = pending[1:]
+		}
+		return nil
+	}
+
+	for lines.Next() {
+		line := lines.Text()
+
+		for _, m := range pending {
+			if len(m.After) < options.after {
+				m.After = append(m.After, line)
+			}
+		}
+		if err := flush(false); err != nil {
+			return err
+		}
+
+		for _, index := range re.FindAllStringSubmatchIndex(line, -1) {
+			text := line[index[0]:index[1]]
+			if validate != nil && !validate(text) {
+				continue
+			}
+			m := &Match{
+				File:    name,
+				Line:    lines.Number(),
+				Offset:  lines.Offset() + int64(index[0]),
+				Text:    text,
+				Content: line,
+				Before:  append([]string{}, before...),
+			}
+			for g := 1; g < len(index)/2; g++ {
+				if index[2*g] == -1 {
+					m.Groups = append(m.Groups, "")
+				} else {
+					m.Groups = append(m.Groups, line[index[2*g]:index[2*g+1]])
+				}
+			}
+			pending = append(pending, m)
+		}
+		if err := flush(false); err != nil {
+			return err
+		}
+
+		if options.before > 0 {
+			before = append(before, line)
+			if len(before) > options.before {
+				before = before[1:]
+			}
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return err
+	}
+	return flush(true)
+}
+
+// expandInputs resolves globs and directories into a list of files.
+// URLs are returned unchanged, an empty list means stdin.
+func expandInputs(inputs []string, recursive bool) ([]string, error) {
+	var files []string
+	for _, input := range inputs {
+		if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
+			files = append(files, input)
+			continue
+		}
+
+		matches, err := filepath.Glob(input)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, errors.New("no such file: " + input)
+		}
+
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				files = append(files, match)
+				continue
+			}
+			if !recursive {
+				return nil, errors.New(match + " is a directory, use --recursive to search it")
+			}
+			err = filepath.Walk(match, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				if info.IsDir() && info.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				if info.Mode().IsRegular() {
+					files = append(files, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// extractMatches runs re over all inputs of the command and writes the matches in the requested format
+func extractMatches(c *cli.Context, re *regexp.Regexp, validate func(string) bool) error {
+	options := matchOptions{before: c.Int("before-context"), after: c.Int("after-context"), multiline: c.Bool("multiline")}
+	if !options.multiline && spansLines(re) {
+		return errors.New("the regex can match line breaks, but the input is searched line by line, use --multiline to search the whole input at once")
+	}
+
+	inputs, err := expandInputs(append(c.StringSlice("input"), c.Args().Slice()...), c.Bool("recursive"))
+	if err != nil {
+		return err
+	}
+	multiple := len(inputs) > 1
+	if len(inputs) == 0 {
+		inputs = []string{""}
+	}
+
+	out, err := utils.CommandOutput(c)
+	if err != nil {
+		return err
+	}
+
+	w := newMatchWriter(c, re, multiple, out)
+
+	for _, input := range inputs {
+		r, err := utils.InputReader(input)
+		if err != nil {
+			out.Abort()
+			return err
+		}
+		name := input
+		if name == "" {
+			name = "stdin"
+		}
+		if options.multiline {
+			err = matchWhole(name, r, re, validate, options, w.add)
+		} else {
+			err = matchLines(name, r, re, validate, options, w.add)
+		}
+		r.Close()
+		if err != nil {
+			out.Abort()
+			return err
+		}
+	}
+
+	if err := w.finish(); err != nil {
+		out.Abort()
+		return err
+	}
+	return out.Close()
+}
+
+// matchRecord is the representation of a match in output templates and JSON lines
+type matchRecord struct {
+	File    string            `json:"file"`
+	Line    int               `json:"line"`
+	Offset  int64             `json:"offset"`
+	Match   string            `json:"match"`
+	Content string            `json:"content"`
+	Groups  map[string]string `json:"groups,omitempty"`
+	Before  []string          `json:"before,omitempty"`
+	After   []string          `json:"after,omitempty"`
+}
+
+// matchWriter formats matches and writes them to the output as soon as possible.
+// Counts and JSON arrays are written, when all matches were found.
+type matchWriter struct {
+	out         *utils.Sink
+	format      string
+	groups      bool
+	groupNames  []string
+	unique      bool
+	count       bool
+	lineNumbers bool
+	multiple    bool
+	context     bool
+	lastLine    string
+	written     bool
+
+	seen   map[string]bool
+	counts map[string]int
+	order  []string
+	json   []interface{}
+	csv    *csv.Writer
+}
+
+func newMatchWriter(c *cli.Context, re *regexp.Regexp, multiple bool, out *utils.Sink) *matchWriter {
+	w := &matchWriter{
+		out:         out,
+		format:      c.Option("format"),
+		groups:      c.Bool("groups"),
+		unique:      c.Bool("unique"),
+		count:       c.Bool("count"),
+		lineNumbers: c.Bool("line-numbers"),
+		multiple:    multiple,
+		context:     c.Int("before-context") > 0 || c.Int("after-context") > 0,
+		seen:        map[string]bool{},
+		counts:      map[string]int{},
+	}
+
+	// Unnamed groups are named by their index
+	for i, name := range re.SubexpNames()[1:] {
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+		w.groupNames = append(w.groupNames, name)
+	}
+	return w
+}
+
+// value returns the text, which represents a match in the output
+func (w *matchWriter) value(m Match) string {
+	if w.groups && len(m.Groups) > 0 {
+		return strings.Join(m.Groups, "\t")
+	}
+	return m.Text
+}
+
+// record returns the representation of a match in templates and JSON lines
+func (w *matchWriter) record(m Match) matchRecord {
+	r := matchRecord{
+		File:    m.File,
+		Line:    m.Line,
+		Offset:  m.Offset,
+		Match:   m.Text,
+		Content: m.Content,
+		Before:  m.Before,
+		After:   m.After,
+	}
+	if len(w.groupNames) > 0 {
+		r.Groups = map[string]string{}
+		for i, name := range w.groupNames {
+			r.Groups[name] = m.Groups[i]
+		}
+	}
+	return r
+}
+
+func (w *matchWriter) add(m Match) error {
+	value := w.value(m)
+	if w.count {
+		key := value
+		if !w.unique {
+			key = m.File
+		}
+		if _, ok := w.counts[key]; !ok {
+			w.order = append(w.order, key)
+		}
+		w.counts[key]++
+		return nil
+	}
+
+	if w.unique {
+		if w.seen[value] {
+			return nil
+		}
+		w.seen[value] = true
+	}
+
+	if w.out.Records() {
+		return w.out.WriteRecord(value, w.record(m))
+	}
+
+	switch w.format {
+	case "json":
+		o := convert.NewObject()
+		o.Set("file", m.File)
+		o.Set("line", int64(m.Line))
+		o.Set("offset", m.Offset)
+		o.Set("match", m.Text)
+		if len(w.groupNames) > 0 {
+			groups := convert.NewObject()
+			for i, name := range w.groupNames {
+				groups.Set(name, m.Groups[i])
+			}
+			o.Set("groups", groups)
+		}
+		if w.context {
+			o.Set("before", stringsToValues(m.Before))
+			o.Set("after", stringsToValues(m.After))
+		}
+		w.json = append(w.json, o)
+	case "csv":
+		if w.csv == nil {
+			w.csv = csv.NewWriter(w.out)
+			if err := w.csv.Write(append([]string{"file", "line", "offset", "match"}, w.groupNames...)); err != nil {
+				return err
+			}
+		}
+		row := []string{m.File, strconv.Itoa(m.Line), strconv.FormatInt(m.Offset, 10), m.Text}
+		return w.csv.Write(append(row, m.Groups...))
+	default:
+		if w.context {
+			// The whole line is shown with context, so further matches in the same line are not shown again
+			location := m.File + ":" + strconv.Itoa(m.Line)
+			if location == w.lastLine {
+				return nil
+			}
+			w.lastLine = location
+			var lines []string
+			if w.written {
+				lines = append(lines, "--")
+			}
+			for i, line := range m.Before {
+				lines = append(lines, w.prefix(m, m.Line-len(m.Before)+i, "-")+line)
+			}
+			// The content of multiline matches can contain multiple lines
+			content := strings.Split(m.Content, "\n")
+			for i, line := range content {
+				lines = append(lines, w.prefix(m, m.Line+i, ":")+line)
+			}
+			for i, line := range m.After {
+				lines = append(lines, w.prefix(m, m.Line+len(content)+i, "-")+line)
+			}
+			w.written = true
+			return w.out.WriteLines(lines)
+		}
+		return w.out.WriteLine(w.prefix(m, m.Line, ":") + value)
+	}
+	return nil
+}
+
+// prefix returns the location of a match, like 'file.txt:12:', if it's requested or multiple files are searched.
+// Context lines are separated with '-' instead of ':', like in grep.
+func (w *matchWriter) prefix(m Match, line int, separator string) string {
+	var prefix string
+	if w.multiple {
+		prefix += m.File + separator
+	}
+	if w.lineNumbers {
+		prefix += strconv.Itoa(line) + separator
+	}
+	if w.multiple && separator == ":" {
+		prefix += strconv.FormatInt(m.Offset, 10) + separator
+	}
+	return prefix
+}
+
+// finish writes the output, which depends on all matches
+func (w *matchWriter) finish() error {
+	if w.count {
+		if w.unique {
+			// Unique values are sorted by their count, so that the most frequent values are shown first
+			sort.SliceStable(w.order, func(i, j int) bool {
+				return w.counts[w.order[i]] > w.counts[w.order[j]]
+			})
+		}
+		var out []string
+		total := 0
+		for _, key := range w.order {
+			total += w.counts[key]
+			if w.unique || w.multiple {
+				out = append(out, fmt.Sprintf("%d\t%s", w.counts[key], key))
+			}
+		}
+		if !w.unique {
+			out = append(out, strconv.Itoa(total))
+		}
+		return w.out.WriteLines(out)
+	}
+
+	if w.out.Records() {
+		return nil
+	}
+	switch w.format {
+	case "json":
+		if w.json == nil {
+			w.json = []interface{}{}
+		}
+		data, err := convert.Encode(convert.JSON, w.json, convert.EncodeOptions{})
+		if err != nil {
+			return err
+		}
+		return w.out.WriteLine(strings.TrimSuffix(string(data), "\n"))
+	case "csv":
+		if w.csv == nil {
+			w.csv = csv.NewWriter(w.out)
+			if err := w.csv.Write(append([]string{"file", "line", "offset", "match"}, w.groupNames...)); err != nil {
+				return err
+			}
+		}
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	return nil
+}
+
+func stringsToValues(s []string) []interface{} {
+	values := make([]interface{}, len(s))
+	for i, v := range s {
+		values[i] = v
+	}
+	return values
+}
+
+// MatchFlags returns the flags, which are used by all regex based extractors
+func MatchFlags() []cli.Flag {
+	flags := []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "input",
+			Aliases: []string{"i"},
+			Usage:   "Searches `FILES`, globs, directories or URLs, reads stdin if not set",
+		},
+		&cli.BoolFlag{
+			Name:    "multiline",
+			Aliases: []string{"M"},
+			Usage:   "Searches the whole input at once, so that matches can span multiple lines, like '(?s)BEGIN.*?END'",
+		},
+		&cli.BoolFlag{
+			Name:    "recursive",
+			Aliases: []string{"R"},
+			Usage:   "Searches directories recursively",
+		},
+		&cli.BoolFlag{
+			Name:    "groups",
+			Aliases: []string{"g"},
+			Usage:   "Outputs the capture groups instead of the whole match, separated by tabs",
+		},
+		&cli.OptionFlag{
+			Name:        "format",
+			Aliases:     []string{"f"},
+			Usage:       "Writes matches as `FORMAT`, json and csv include the location and capture groups of each match",
+			Options:     []string{"text", "json", "csv"},
+			DefaultText: "text",
+		},
+		&cli.BoolFlag{
+			Name:    "unique",
+			Aliases: []string{"u"},
+			Usage:   "Outputs every match only once",
+		},
+		&cli.BoolFlag{
+			Name:    "count",
+			Aliases: []string{"c"},
+			Usage:   "Outputs the amount of matches, combined with --unique it counts each unique match",
+		},
+		&cli.BoolFlag{
+			Name:    "line-numbers",
+			Aliases: []string{"n"},
+			Usage:   "Prefixes each match with its line number",
+		},
+		&cli.IntFlag{
+			Name:    "before-context",
+			Aliases: []string{"B"},
+			Usage:   "Outputs `N` lines before each match",
+		},
+		&cli.IntFlag{
+			Name:    "after-context",
+			Aliases: []string{"A"},
+			Usage:   "Outputs `N` lines after each match",
+		},
+	}
+	return append(append(flags, utils.OutputFlags()...), utils.RecordFlags()...)
+}
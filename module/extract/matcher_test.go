@@ -0,0 +1,184 @@
+package extract
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dops-cli/dops/cli"
+)
+
+const logFixture = `INFO start
+WARN disk 91%
+INFO id=7
+ERROR failed id=8
+INFO stop
+`
+
+// find returns all matches of regex in text
+func find(t *testing.T, text, regex string, validate func(string) bool, options matchOptions) []Match {
+	t.Helper()
+	var matches []Match
+	add := func(m Match) error {
+		matches = append(matches, m)
+		return nil
+	}
+	var err error
+	if options.multiline {
+		err = matchWhole("test", strings.NewReader(text), regexp.MustCompile(regex), validate, options, add)
+	} else {
+		err = matchLines("test", strings.NewReader(text), regexp.MustCompile(regex), validate, options, add)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestMatchLines(t *testing.T) {
+	matches := find(t, logFixture, `id=(\d+)`, nil, matchOptions{before: 1, after: 2})
+	want := []Match{
+		{File: "test", Line: 3, Offset: 30, Text: "id=7", Content: "INFO id=7", Groups: []string{"7"}, Before: []string{"WARN disk 91%"}, After: []string{"ERROR failed id=8", "INFO stop"}},
+		{File: "test", Line: 4, Offset: 48, Text: "id=8", Content: "ERROR failed id=8", Groups: []string{"8"}, Before: []string{"INFO id=7"}, After: []string{"INFO stop"}},
+	}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("got %+v\nwant %+v", matches, want)
+	}
+
+	// Offsets count the line endings of the input
+	matches = find(t, "a\r\nb a\n", `a`, nil, matchOptions{})
+	if len(matches) != 2 || matches[1].Offset != 5 || matches[1].Line != 2 {
+		t.Errorf("got %+v", matches)
+	}
+
+	matches = find(t, logFixture, `[A-Z]+`, func(s string) bool { return s != "INFO" }, matchOptions{})
+	if len(matches) != 2 || matches[0].Text != "WARN" || matches[1].Text != "ERROR" {
+		t.Errorf("got %+v", matches)
+	}
+}
+
+func TestMatchWhole(t *testing.T) {
+	text := "a\r\n-----BEGIN KEY-----\r\nabc\r\n-----END KEY-----\r\nb\nBEGIN x END\n"
+	matches := find(t, text, `(?s)BEGIN.*?END`, nil, matchOptions{multiline: true, before: 1, after: 1})
+	want := []Match{
+		{File: "test", Line: 2, Offset: 8, Text: "BEGIN KEY-----\r\nabc\r\n-----END", Content: "-----BEGIN KEY-----\nabc\n-----END KEY-----", Before: []string{"a"}, After: []string{"b"}},
+		{File: "test", Line: 6, Offset: 50, Text: "BEGIN x END", Content: "BEGIN x END", Before: []string{"b"}},
+	}
+	if !reflect.DeepEqual(matches, want) {
+		t.Errorf("got %+v\nwant %+v", matches, want)
+	}
+
+	// Without line breaks in the regex, the results are the same as line by line
+	options := matchOptions{before: 2, after: 1}
+	lines := find(t, logFixture, `id=(\d+)`, nil, options)
+	options.multiline = true
+	if whole := find(t, logFixture, `id=(\d+)`, nil, options); !reflect.DeepEqual(whole, lines) {
+		t.Errorf("got %+v\nwant %+v", whole, lines)
+	}
+
+	if got := find(t, "", `x*`, nil, matchOptions{multiline: true}); len(got) != 1 || got[0].Line != 1 {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestSpansLines(t *testing.T) {
+	tests := map[string]bool{
+		`(?s)BEGIN.*?END`: true,
+		`a\nb`:            true,
+		`(?s:.)`:          true,
+		`BEGIN.*?END`:     false,
+		`[^,]+`:           false,
+		`\s+`:             false,
+	}
+	for regex, want := range tests {
+		if got := spansLines(regexp.MustCompile(regex)); got != want {
+			t.Errorf("%s: got %v, want %v", regex, got, want)
+		}
+	}
+	for _, cmd := range RegexList {
+		if spansLines(regexp.MustCompile(cmd.Regex)) {
+			t.Errorf("the predefined regex %s can match line breaks", cmd.Name)
+		}
+	}
+}
+
+func TestExpandInputs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.log", "b.log", "c.txt", "sub/d.log", ".git/e.log"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := expandInputs([]string{filepath.Join(dir, "*.log"), "https://example.com/x"}, false)
+	want := []string{filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log"), "https://example.com/x"}
+	if err != nil || !reflect.DeepEqual(files, want) {
+		t.Errorf("got %v %v", files, err)
+	}
+
+	files, err = expandInputs([]string{dir}, true)
+	if err != nil || len(files) != 4 {
+		t.Errorf("got %v %v", files, err)
+	}
+	if _, err := expandInputs([]string{dir}, false); err == nil {
+		t.Error("expected an error for a directory")
+	}
+	if _, err := expandInputs([]string{filepath.Join(dir, "missing")}, false); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+// extract runs 'extract text' with the arguments and returns its output
+func extract(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	output := filepath.Join(t.TempDir(), "out")
+	app := &cli.App{Commands: Module{}.GetModuleCommands()}
+	err := app.Run(append([]string{"dops", "extract", "text", "-o", output}, args...))
+	data, _ := ioutil.ReadFile(output)
+	return string(data), err
+}
+
+func TestExtractText(t *testing.T) {
+	dir := t.TempDir()
+	// Commas in paths are kept, because the input is not split at commas
+	input := filepath.Join(dir, "app,1.log")
+	if err := ioutil.WriteFile(input, []byte(logFixture), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-r", `id=\d+`}, "id=7\nid=8\n"},
+		{[]string{"-r", `id=(\d+)`, "-g", "-n"}, "3:7\n4:8\n"},
+		{[]string{"-r", `^[A-Z]+`, "-u", "-c"}, "3\tINFO\n1\tWARN\n1\tERROR\n"},
+		{[]string{"-r", `^[A-Z]+`, "-c"}, "5\n"},
+		{[]string{"-r", `id=(?P<id>\d+)`, "-f", "csv"}, "file,line,offset,match,id\n\"" + input + "\",3,30,id=7,7\n\"" + input + "\",4,48,id=8,8\n"},
+		{[]string{"-r", `WARN`, "-B", "1", "-A", "1", "-n"}, "1-INFO start\n2:WARN disk 91%\n3-INFO id=7\n"},
+		{[]string{"-r", `(?s)WARN.*?id=7`, "-M", "-n", "-A", "1"}, "2:WARN disk 91%\n3:INFO id=7\n4-ERROR failed id=8\n"},
+		{[]string{"-r", `start\nWARN`, "-M"}, "start\nWARN\n"},
+	}
+	for _, tt := range tests {
+		got, err := extract(t, append(tt.args, "-i", input)...)
+		if err != nil {
+			t.Errorf("%v: %v", tt.args, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%v: got\n%s\nwant\n%s", tt.args, got, tt.want)
+		}
+	}
+
+	if _, err := extract(t, "-r", `(?s)WARN.*?id=7`, "-i", input); err == nil || !strings.Contains(err.Error(), "--multiline") {
+		t.Errorf("expected an error for a regex, which matches line breaks, got %v", err)
+	}
+}
@@ -6,7 +6,7 @@ import (
 
 	"github.com/dops-cli/dops/categories"
 	"github.com/dops-cli/dops/cli"
-	"github.com/dops-cli/dops/utils"
+	"github.com/dops-cli/dops/say"
 )
 
 type PredefinedRegexCommand struct {
@@ -16,12 +16,54 @@ type PredefinedRegexCommand struct {
 	Regex   string
 	Matches []string
 	Fails   []string
+	// Validate is optional and checks matches, which can't be verified by a regex alone (e.g. checksums).
+	// Matches, for which Validate returns false, are dropped.
+	Validate func(match string) bool
 }
 
-func GeneratePredefinedRegexCommands() []*cli.Command {
+// Find returns all matches of the regex in input, which pass the validation
+func (p PredefinedRegexCommand) Find(r *regexp.Regexp, input string) []string {
+	found := r.FindAllString(input, -1)
+	if p.Validate == nil {
+		return found
+	}
+
+	var valid []string
+	for _, match := range found {
+		if p.Validate(match) {
+			valid = append(valid, match)
+		}
+	}
+	return valid
+}
+
+// RegexCommands returns the built-in extractors merged with the user-defined extractors.
+// A warning is shown for every user-defined extractor, which can't be loaded.
+func RegexCommands() []PredefinedRegexCommand {
+	user, errs := LoadUserExtractors(UserExtractorsPath())
+	for _, err := range errs {
+		say.Warning(err)
+	}
+	return MergeRegexCommands(RegexList, user)
+}
+
+// addUserExtractors replaces the subcommands of the predefined command with the built-in and user-defined extractors.
+// It's called when the text command runs, so that other commands don't load the user-defined extractors.
+func addUserExtractors(predefined *cli.Command) {
+	predefined.Subcommands = GeneratePredefinedRegexCommands(RegexCommands())
+}
+
+// GeneratePredefinedRegexCommands returns a command for every extractor
+func GeneratePredefinedRegexCommands(extractors []PredefinedRegexCommand) []*cli.Command {
 	var list []*cli.Command
 
-	for _, c := range RegexList {
+	for _, c := range extractors {
+		c := c
+		description := fmt.Sprintf("The %s command finds all %s`s in the input and returns them.\n\n", c.Name, c.Name) + "Regex: \n" + c.Regex
+		if c.Validate != nil {
+			description += "\n\nMatches of the regex are validated additionally, invalid matches are not returned."
+		}
+
 		cmd := &cli.Command{
 			Name:    c.Name,
 			Aliases: c.Aliases,
@@ -32,49 +74,20 @@ func GeneratePredefinedRegexCommands() []*cli.Command {
 					Usage:            fmt.Sprintf("dops extract text predefined %s --input file.txt", c.Name),
 				},
 			},
-			Description: fmt.Sprintf("The %s command finds all %s`s in the input and returns them.\n\n", c.Name, c.Name) + "Regex: \n" + c.Regex,
+			Description: description,
 			Category:    categories.TextProcessing,
 			Action: func(context *cli.Context) error {
-				input := utils.Input(context.Path("input"))
-				output := context.String("output")
-				appendFlag := context.Bool("appendFlag")
-
-				var foundStrings []string
-
 				r, err := regexp.Compile(c.Regex)
 				if err != nil {
 					return err
 				}
 
-				foundStrings = r.FindAllString(input, -1)
-				utils.Output(output, foundStrings, appendFlag)
-
-				return nil
+				return extractMatches(context, r, c.Validate)
 			},
-			Flags: InputOutputFlags(),
+			Flags: MatchFlags(),
 		}
 		list = append(list, cmd)
 	}
 
 	return list
 }
-
-func InputOutputFlags() []cli.Flag {
-	return []cli.Flag{
-		&cli.StringFlag{
-			Aliases: []string{"i"},
-			Name:    "input",
-			Usage:   "Input accepts a file, URL or stdin if not set",
-		},
-		&cli.StringFlag{
-			Aliases: []string{"o"},
-			Name:    "output",
-			Usage:   "Writes to a file, if not set it writes to stdout",
-		},
-		&cli.BoolFlag{
-			Name:    "append",
-			Aliases: []string{"a"},
-			Usage:   "append instead of overriding output",
-		},
-	}
-}
@@ -1,33 +1,43 @@
 package extract
 
 import (
+	"reflect"
 	"regexp"
 	"testing"
 )
 
 func TestPredefinedRegexps(t *testing.T) {
 	for _, cmd := range RegexList {
+		cmd := cmd
 		t.Run(cmd.Name, func(t *testing.T) {
-			r, err := regexp.Compile(cmd.Regex)
-			if err != nil {
-				t.Error(err)
-			}
-
-			for _, match := range cmd.Matches {
-				if !r.MatchString(match) {
-					t.Error("regex does not match, but should: " + match)
-				}
-			}
-
-			for _, fail := range cmd.Fails {
-				if r.MatchString(fail) {
-					t.Error("regex does match, but should not: " + fail)
-				}
+			for _, failure := range cmd.Test() {
+				t.Error(failure)
 			}
 		})
 	}
 }
 
+func TestCreditCardSpans(t *testing.T) {
+	var cmd PredefinedRegexCommand
+	for _, c := range RegexList {
+		if c.Name == "credit-card" {
+			cmd = c
+		}
+	}
+	r := regexp.MustCompile(cmd.Regex)
+	tests := map[string][]string{
+		"Order 12 4111 1111 1111 1111":           {"4111 1111 1111 1111"},
+		"4111 1111 1111 1111 22":                 {"4111 1111 1111 1111"},
+		"card 4111111111111111 3":                {"4111111111111111"},
+		"3782-822463-10005, 4111 1111 1111 1111": {"3782-822463-10005", "4111 1111 1111 1111"},
+	}
+	for input, want := range tests {
+		if got := cmd.Find(r, input); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %q, want %q", input, got, want)
+		}
+	}
+}
+
 func Benchmark(b *testing.B) {
 	for _, cmd := range RegexList {
 		for _, match := range cmd.Matches {
@@ -0,0 +1,114 @@
+package extract
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// digitsOnly removes everything except digits from s
+func digitsOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// validLuhn checks the Luhn checksum, which is used by credit card numbers
+func validLuhn(s string) bool {
+	digits := digitsOnly(s)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// validIBAN checks the length and the mod 97 checksum of an IBAN
+func validIBAN(s string) bool {
+	iban := strings.ReplaceAll(s, " ", "")
+	if len(iban) < 15 || len(iban) > 34 {
+		return false
+	}
+
+	// The country code and check digits are moved to the end and all letters are replaced by numbers (A = 10, B = 11, ...)
+	var numeric strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		switch {
+		case unicode.IsDigit(r):
+			numeric.WriteRune(r)
+		case r >= 'A' && r <= 'Z':
+			numeric.WriteString(strconv.Itoa(int(r-'A') + 10))
+		default:
+			return false
+		}
+	}
+
+	n, ok := new(big.Int).SetString(numeric.String(), 10)
+	return ok && new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+// validDate checks if an ISO 8601 or RFC 2822 date exists in the calendar
+func validDate(s string) bool {
+	if len(s) >= 10 && s[4] == '-' {
+		_, err := time.Parse("2006-01-02", s[:10])
+		return err == nil
+	}
+
+	if i := strings.Index(s, ", "); i != -1 {
+		s = s[i+2:]
+	}
+	fields := strings.Fields(s)
+	if len(fields) < 3 {
+		return false
+	}
+	_, err := time.Parse("2 Jan 2006", strings.Join(fields[:3], " "))
+	return err == nil
+}
+
+// validPhoneNumber checks if a phone number has a plausible amount of digits (E.164 allows up to 15)
+func validPhoneNumber(s string) bool {
+	digits := digitsOnly(strings.TrimPrefix(s, "00"))
+	return len(digits) >= 7 && len(digits) <= 15
+}
+
+// validCIDR checks if a CIDR can be parsed
+func validCIDR(s string) bool {
+	_, _, err := net.ParseCIDR(s)
+	return err == nil
+}
+
+// validJWT checks if the header of a JWT is a JSON object containing an algorithm
+func validJWT(s string) bool {
+	header, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(strings.SplitN(s, ".", 2)[0], "="))
+	if err != nil {
+		return false
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(header, &fields); err != nil {
+		return false
+	}
+	_, ok := fields["alg"]
+	return ok
+}
@@ -7,11 +7,12 @@ import (
 
 func Predefined() *cli.Command {
 	return &cli.Command{
-		Name:        "predefined",
-		Usage:       "Use a predefined regex to extract strings",
-		Examples:    []cli.Example{},
-		Description: `Use the predefined submodule to choose from a set of regexes, which you can use to extract strings either from a website, a file or stdin.`,
+		Name:     "predefined",
+		Usage:    "Use a predefined regex to extract strings",
+		Examples: []cli.Example{},
+		Description: `Use the predefined submodule to choose from a set of regexes, which you can use to extract strings either from a website, a file or stdin.
+You can add your own regexes in ~/.config/dops/extractors.yaml, see 'dops extract test' to validate them.`,
 		Category:    categories.TextProcessing,
-		Subcommands: GeneratePredefinedRegexCommands(),
+		Subcommands: GeneratePredefinedRegexCommands(RegexList),
 	}
 }
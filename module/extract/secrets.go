@@ -0,0 +1,417 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/utils"
+)
+
+// defaultAllowlist is loaded from the scanned directory, if no allowlist is given
+const defaultAllowlist = ".dops-secrets-allowlist"
+
+// inlineAllow can be added as a comment to a line, to ignore all findings in it
+const inlineAllow = "dops:allow"
+
+// Finding is a secret, which was found by the secrets scanner
+type Finding struct {
+	Rule    *SecretRule
+	File    string
+	Line    int
+	Column  int
+	Secret  string
+	Entropy float64
+}
+
+// Redacted returns the secret with all but the first four characters hidden
+func (f Finding) Redacted() string {
+	if len(f.Secret) <= 8 {
+		return strings.Repeat("*", len(f.Secret))
+	}
+	hidden := len(f.Secret) - 4
+	if hidden > 16 {
+		hidden = 16
+	}
+	return f.Secret[:4] + strings.Repeat("*", hidden)
+}
+
+// Allowlist contains findings, which should be ignored
+type Allowlist struct {
+	paths    []string
+	rules    map[string]bool
+	patterns []*regexp.Regexp
+}
+
+// LoadAllowlist reads an allowlist file. Every line is one of:
+//
+//	path:GLOB   ignores files matching GLOB (matched against the path and the file name)
+//	rule:ID     disables a rule
+//	REGEX       ignores secrets or lines matching REGEX
+//
+// Empty lines and lines starting with '#' are ignored.
+func LoadAllowlist(path string) (*Allowlist, error) {
+	a := &Allowlist{rules: map[string]bool{}}
+	if path == "" {
+		return a, nil
+	}
+
+	err := utils.ForEachLineInFile(path, func(line string) error {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "path:"):
+			a.paths = append(a.paths, strings.TrimSpace(strings.TrimPrefix(line, "path:")))
+		case strings.HasPrefix(line, "rule:"):
+			a.rules[strings.TrimSpace(strings.TrimPrefix(line, "rule:"))] = true
+		default:
+			r, err := regexp.Compile(line)
+			if err != nil {
+				return fmt.Errorf("invalid allowlist entry %q: %w", line, err)
+			}
+			a.patterns = append(a.patterns, r)
+		}
+		return nil
+	})
+	return a, err
+}
+
+// AllowsPath returns true if a file should not be scanned
+func (a *Allowlist) AllowsPath(path string) bool {
+	slashed := filepath.ToSlash(path)
+	for _, glob := range a.paths {
+		if ok, _ := filepath.Match(glob, slashed); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(glob, filepath.Base(path)); ok {
+			return true
+		}
+		if strings.HasPrefix(slashed, strings.TrimSuffix(glob, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows returns true if a finding should be ignored
+func (a *Allowlist) Allows(rule *SecretRule, secret, line string) bool {
+	if a.rules[rule.ID] || strings.Contains(line, inlineAllow) {
+		return true
+	}
+	for _, r := range a.patterns {
+		if r.MatchString(secret) || r.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScanSecrets scans r line by line with all secret rules
+func ScanSecrets(name string, r io.Reader, allowlist *Allowlist) ([]Finding, error) {
+	var findings []Finding
+	lines := utils.NewLineReader(r)
+	for lines.Next() {
+		line := lines.Text()
+		for _, rule := range SecretRules {
+			secrets, offsets := rule.Find(line)
+			for i, secret := range secrets {
+				if allowlist.Allows(rule, secret, line) {
+					continue
+				}
+				findings = append(findings, Finding{
+					Rule:    rule,
+					File:    name,
+					Line:    lines.Number(),
+					Column:  offsets[i] + 1,
+					Secret:  secret,
+					Entropy: Entropy(secret),
+				})
+			}
+		}
+	}
+	return findings, lines.Err()
+}
+
+// secretFiles returns all files in root, which should be scanned
+func secretFiles(root string, git bool, maxSize int64, allowlist *Allowlist) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{root}, nil
+	}
+
+	var candidates []string
+	if git {
+		out, err := exec.Command("git", "-C", root, "ls-files", "-z", "--cached", "--others", "--exclude-standard").Output()
+		if err != nil {
+			return nil, fmt.Errorf("could not list files of git working tree %s: %w", root, err)
+		}
+		for _, file := range strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00") {
+			if file != "" {
+				candidates = append(candidates, filepath.Join(root, file))
+			}
+		}
+	} else {
+		err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() && info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			if info.Mode().IsRegular() {
+				candidates = append(candidates, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var files []string
+	for _, file := range candidates {
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			rel = file
+		}
+		if allowlist.AllowsPath(rel) {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil || !info.Mode().IsRegular() || info.Size() > maxSize {
+			continue
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// isBinary checks the beginning of a file for NUL bytes, like git does
+func isBinary(f *os.File) bool {
+	head := make([]byte, 8000)
+	n, _ := f.Read(head)
+	_, _ = f.Seek(0, io.SeekStart)
+	return bytes.IndexByte(head[:n], 0) != -1
+}
+
+// Secrets returns the secrets subcommand
+func Secrets() *cli.Command {
+	return &cli.Command{
+		Name:      "secrets",
+		Aliases:   []string{"secret", "scan-secrets"},
+		Usage:     "Scans files for credentials",
+		ArgsUsage: "[PATHS...]",
+		Description: `Secrets scans files and directories for credentials, like AWS keys, GitHub and GitLab tokens, private keys, Slack webhooks and generic high entropy secrets.
+Generic rules use the Shannon entropy of a match to filter out placeholders like 'changeme'.
+Directories are scanned recursively, '.git' directories and binary files are skipped.
+With --git only files tracked by git or not ignored by .gitignore are scanned.
+
+Findings can be ignored with an allowlist file (default: ` + defaultAllowlist + ` in the scanned directory).
+Each line of the allowlist is either 'path:GLOB', 'rule:ID' or a regex, which is matched against the secret and its line.
+Lines containing '` + inlineAllow + `' are ignored as well.
+
+The report can be written as text, JSON or SARIF, which can be uploaded to code scanning tools.
+With --template or --jsonl every finding is a record with the fields .Rule, .Description, .File, .Line, .Column, .Secret and .Entropy.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Scan the current directory",
+				Usage:            "dops extract secrets",
+			},
+			{
+				ShortDescription: "Scan a git working tree and write a SARIF report",
+				Usage:            "dops extract secrets --git --format sarif -o secrets.sarif .",
+			},
+			{
+				ShortDescription: "List all rules",
+				Usage:            "dops extract secrets --list-rules",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.Bool("list-rules") {
+				var lines []string
+				for _, rule := range SecretRules {
+					lines = append(lines, fmt.Sprintf("%-28s %s", rule.ID, rule.Description))
+				}
+				return utils.WriteOutput(c, lines)
+			}
+
+			roots := c.Args().Slice()
+			if len(roots) == 0 {
+				roots = []string{"."}
+			}
+
+			var findings []Finding
+			for _, root := range roots {
+				allowlistPath := c.Path("allowlist")
+				if allowlistPath == "" {
+					if _, err := os.Stat(filepath.Join(root, defaultAllowlist)); err == nil {
+						allowlistPath = filepath.Join(root, defaultAllowlist)
+					}
+				}
+				allowlist, err := LoadAllowlist(allowlistPath)
+				if err != nil {
+					return err
+				}
+
+				files, err := secretFiles(root, c.Bool("git"), int64(c.Int("max-size"))*1024, allowlist)
+				if err != nil {
+					return err
+				}
+
+				for _, file := range files {
+					f, err := os.Open(file)
+					if err != nil {
+						say.Warning(err)
+						continue
+					}
+					if !isBinary(f) {
+						found, err := ScanSecrets(filepath.ToSlash(file), f, allowlist)
+						if err != nil {
+							say.Warning(err)
+						}
+						findings = append(findings, found...)
+					}
+					f.Close()
+				}
+			}
+
+			out, err := utils.CommandOutput(c)
+			if err != nil {
+				return err
+			}
+			if err := writeFindings(out, findings, c.Option("format"), c.Bool("show-secrets")); err != nil {
+				out.Abort()
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+
+			if len(findings) > 0 && c.Bool("exit-code") {
+				return cli.Exit("", 1)
+			}
+			return nil
+		},
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "git",
+				Usage: "Scans only files of the git working tree, which are not ignored",
+			},
+			&cli.PathFlag{
+				Name:      "allowlist",
+				Aliases:   []string{"w"},
+				Usage:     "Ignores findings listed in `FILE`",
+				TakesFile: true,
+			},
+			&cli.IntFlag{
+				Name:  "max-size",
+				Usage: "Skips files larger than `KB` kilobytes",
+				Value: 1024,
+			},
+			&cli.OptionFlag{
+				Name:        "format",
+				Aliases:     []string{"f"},
+				Usage:       "Writes the report as `FORMAT`",
+				Options:     []string{"text", "json", "sarif"},
+				DefaultText: "text",
+			},
+			&cli.BoolFlag{
+				Name:  "show-secrets",
+				Usage: "Shows the secrets instead of redacting them (SARIF reports are always redacted)",
+			},
+			&cli.BoolFlag{
+				Name:  "list-rules",
+				Usage: "Lists all rules and exits",
+			},
+			&cli.BoolFlag{
+				Name:    "exit-code",
+				Aliases: []string{"e"},
+				Usage:   "Exits with status 1 if secrets were found",
+			},
+		}, append(utils.OutputFlags(), utils.RecordFlags()...)...),
+	}
+}
+
+// writeFindings writes the report in the requested format
+func writeFindings(out *utils.Sink, findings []Finding, format string, showSecrets bool) error {
+	if out.Records() {
+		for _, f := range findingsJSON(findings, showSecrets) {
+			if err := out.WriteRecord("", f); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(findingsJSON(findings, showSecrets), "", "  ")
+		if err != nil {
+			return err
+		}
+		return out.WriteLine(string(data))
+	case "sarif":
+		data, err := json.MarshalIndent(sarifReport(findings), "", "  ")
+		if err != nil {
+			return err
+		}
+		return out.WriteLine(string(data))
+	}
+
+	if len(findings) == 0 {
+		say.Success("No secrets found")
+		return nil
+	}
+	for _, f := range findings {
+		secret := f.Redacted()
+		if showSecrets {
+			secret = f.Secret
+		}
+		line := fmt.Sprintf("%s:%d:%d: [%s] %s: %s (entropy %.2f)", f.File, f.Line, f.Column, f.Rule.ID, f.Rule.Description, secret, f.Entropy)
+		if err := out.WriteLine(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type findingJSON struct {
+	Rule        string  `json:"rule"`
+	Description string  `json:"description"`
+	File        string  `json:"file"`
+	Line        int     `json:"line"`
+	Column      int     `json:"column"`
+	Secret      string  `json:"secret"`
+	Entropy     float64 `json:"entropy"`
+}
+
+func findingsJSON(findings []Finding, showSecrets bool) []findingJSON {
+	list := make([]findingJSON, len(findings))
+	for i, f := range findings {
+		secret := f.Redacted()
+		if showSecrets {
+			secret = f.Secret
+		}
+		list[i] = findingJSON{
+			Rule:        f.Rule.ID,
+			Description: f.Rule.Description,
+			File:        f.File,
+			Line:        f.Line,
+			Column:      f.Column,
+			Secret:      secret,
+			Entropy:     f.Entropy,
+		}
+	}
+	return list
+}
@@ -0,0 +1,100 @@
+package extract
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Test returns the test subcommand
+func Test() *cli.Command {
+	return &cli.Command{
+		Name:      "test",
+		Usage:     "Validates the examples of extractors",
+		ArgsUsage: "[EXTRACTORS...]",
+		Description: `Test checks that every extractor matches all of its 'matches' examples and none of its 'fails' examples.
+By default, all built-in and user-defined extractors are tested. Pass names to test only specific extractors,
+then invalid user-defined extractors with other names are only reported as warnings.
+User-defined extractors are loaded from ` + "`" + `~/.config/dops/extractors.yaml` + "`" + ` (or $XDG_CONFIG_HOME/dops/extractors.yaml), the path can be changed with DOPS_EXTRACTORS or --file.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Test all extractors",
+				Usage:            "dops extract test",
+			},
+			{
+				ShortDescription: "Test the extractors of a team file before installing it",
+				Usage:            "dops extract test --file team-extractors.yaml --user-only",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			path := c.Path("file")
+			if path == "" {
+				path = UserExtractorsPath()
+			}
+			names := c.Args().Slice()
+			user, errs := LoadUserExtractors(path)
+			// Errors of extractors, which are not tested, don't fail the test
+			failed := 0
+			for _, err := range errs {
+				var extractorErr *UserExtractorError
+				if len(names) > 0 && !(errors.As(err, &extractorErr) && utils.SliceContainsString(names, extractorErr.Name)) {
+					say.Warning(err)
+					continue
+				}
+				say.Error(err)
+				failed++
+			}
+			loadFailures := failed
+
+			extractors := MergeRegexCommands(RegexList, user)
+			if c.Bool("user-only") {
+				extractors = user
+			}
+			if len(names) > 0 {
+				var selected []PredefinedRegexCommand
+				for _, e := range extractors {
+					if utils.SliceContainsString(names, e.Name) {
+						selected = append(selected, e)
+					}
+				}
+				extractors = selected
+			}
+			if len(extractors) == 0 && loadFailures == 0 {
+				say.Warning("No extractors to test")
+				return nil
+			}
+
+			for _, e := range extractors {
+				failures := e.Test()
+				if len(failures) == 0 {
+					say.Success(fmt.Sprintf("%s: %d examples passed", e.Name, len(e.Matches)+len(e.Fails)))
+					continue
+				}
+				failed++
+				say.Error(e.Name + ":\n  " + strings.Join(failures, "\n  "))
+			}
+
+			if failed > 0 {
+				return cli.Exit(fmt.Sprintf("%d of %d extractors failed", failed, len(extractors)+loadFailures), 1)
+			}
+			return nil
+		},
+		Flags: []cli.Flag{
+			&cli.PathFlag{
+				Name:      "file",
+				Aliases:   []string{"f"},
+				Usage:     "Loads user-defined extractors from `FILE`",
+				TakesFile: true,
+			},
+			&cli.BoolFlag{
+				Name:    "user-only",
+				Aliases: []string{"u"},
+				Usage:   "Tests only user-defined extractors",
+			},
+		},
+	}
+}
@@ -1,63 +1,64 @@
 package extract
 
 import (
+	"errors"
 	"regexp"
 
 	"github.com/dops-cli/dops/cli"
-	"github.com/dops-cli/dops/utils"
 )
 
 // Text module
 func Text() *cli.Command {
+	predefined := Predefined()
 	return &cli.Command{
-		Name:        "text",
-		Aliases:     []string{"t", "string", "strings", "s"},
-		Usage:       "Extracts text from data",
-		Examples:    nil,
-		Description: "This can be used to extract text using a predefined or a custom regex.",
+		Name:    "text",
+		Aliases: []string{"t", "string", "strings", "s"},
+		Usage:   "Extracts text from data",
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Extract all ticket IDs from the markdown files in docs",
+				Usage:            `dops extract text -r '[A-Z]+-\d+' -i 'docs/*.md' --unique`,
+			},
+			{
+				ShortDescription: "Output the key and value of all assignments as JSON",
+				Usage:            `dops extract text -r '(?P<key>\w+)=(?P<value>\S+)' -f json -i config.env`,
+			},
+			{
+				ShortDescription: "Count the occurrences of each log level in all logs",
+				Usage:            `dops extract text -r 'INFO|WARN|ERROR' -R -i logs --unique --count`,
+			},
+		},
+		Description: `This can be used to extract text using a predefined or a custom regex.
+The input is searched line by line, so a match can't span multiple lines, unless --multiline is set to search the whole input at once.
+Multiple files, globs, directories (with --recursive) and URLs can be searched at once, if no input is given stdin is used.
+When multiple files are searched, each match is prefixed with its file and byte offset.
+With --template or --jsonl every match is a record with the fields .File, .Line, .Offset, .Match, .Content, .Groups, .Before and .After, like '{{.File}}:{{.Match}}'.`,
+		Before: func(c *cli.Context) error {
+			addUserExtractors(predefined)
+			return nil
+		},
 		Subcommands: []*cli.Command{
-			Predefined(),
+			predefined,
 		},
 		Action: func(c *cli.Context) error {
 			regex := c.String("regex")
-			input := utils.Input(c.Path("input"))
-			output := c.String("output")
-			appendFlag := c.Bool("appendFlag")
-
-			var foundStrings []string
+			if regex == "" {
+				return errors.New("no regex given, use --regex or a predefined regex")
+			}
 
 			r, err := regexp.Compile(regex)
 			if err != nil {
 				return err
 			}
 
-			foundStrings = r.FindAllString(input, -1)
-			utils.Output(output, foundStrings, appendFlag)
-
-			return nil
+			return extractMatches(c, r, nil)
 		},
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
 				Name:    "regex",
 				Aliases: []string{"r"},
 				Usage:   "extracts matching strings with `PATTERN`",
 			},
-			&cli.PathFlag{
-				Name:      "input",
-				Aliases:   []string{"i"},
-				Usage:     "use `FILE` as input",
-				TakesFile: true,
-			},
-			&cli.StringFlag{
-				Name:    "output",
-				Aliases: []string{"o"},
-				Usage:   "outputs to directory `DIR`",
-			},
-			&cli.BoolFlag{
-				Name:    "append",
-				Aliases: []string{"a"},
-				Usage:   "append instead of overriding output",
-			},
-		},
+		}, MatchFlags()...),
 	}
 }
@@ -0,0 +1,189 @@
+package extract
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v2"
+)
+
+// userExtractorsFile is the file in the dops config directory, which contains user-defined extractors
+const userExtractorsFile = "extractors.yaml"
+
+// userExtractor is the YAML representation of a PredefinedRegexCommand
+type userExtractor struct {
+	Name    string   `yaml:"name"`
+	Usage   string   `yaml:"usage"`
+	Aliases []string `yaml:"aliases"`
+	Regex   string   `yaml:"regex"`
+	Matches []string `yaml:"matches"`
+	Fails   []string `yaml:"fails"`
+}
+
+// UserExtractorError is returned for an invalid user-defined extractor, which is skipped
+type UserExtractorError struct {
+	Path string
+	// Index is the position of the extractor in the file, starting at 1
+	Index int
+	// Name is the name of the extractor, it's empty if the extractor has no name
+	Name string
+	Err  error
+}
+
+func (e *UserExtractorError) Error() string {
+	return fmt.Sprintf("skipping extractor %d in %s: %v", e.Index, e.Path, e.Err)
+}
+
+func (e *UserExtractorError) Unwrap() error {
+	return e.Err
+}
+
+// UserExtractorsPath returns the path of the user-defined extractors.
+// It can be changed with the DOPS_EXTRACTORS environment variable,
+// otherwise it's extractors.yaml in $XDG_CONFIG_HOME/dops or ~/.config/dops.
+func UserExtractorsPath() string {
+	if path := os.Getenv("DOPS_EXTRACTORS"); path != "" {
+		return path
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "dops", userExtractorsFile)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "dops", userExtractorsFile)
+}
+
+// LoadUserExtractors reads user-defined extractors from a YAML file like:
+//
+//	extractors:
+//	  - name: ticket
+//	    usage: returns ticket IDs
+//	    aliases: [jira]
+//	    regex: '\b[A-Z]{2,10}-\d+\b'
+//	    matches: [DOPS-123]
+//	    fails: [dops-123]
+//
+// A missing file is not an error. Invalid extractors are skipped and an error is returned for each of them.
+func LoadUserExtractors(path string) ([]PredefinedRegexCommand, []error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	var file struct {
+		Extractors []yaml.MapSlice `yaml:"extractors"`
+	}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, []error{fmt.Errorf("could not parse %s: %w", path, err)}
+	}
+
+	var list []PredefinedRegexCommand
+	var errs []error
+	for i, entry := range file.Extractors {
+		e, err := parseUserExtractor(entry)
+		if err != nil {
+			errs = append(errs, &UserExtractorError{Path: path, Index: i + 1, Name: entryName(entry), Err: err})
+			continue
+		}
+		list = append(list, e)
+	}
+
+	return list, errs
+}
+
+// entryName returns the name of an extractor, which could not be parsed
+func entryName(entry yaml.MapSlice) string {
+	for _, item := range entry {
+		if key, ok := item.Key.(string); ok && key == "name" {
+			name, _ := item.Value.(string)
+			return name
+		}
+	}
+	return ""
+}
+
+// parseUserExtractor decodes and validates a single user-defined extractor, so that an invalid extractor doesn't affect the others
+func parseUserExtractor(entry yaml.MapSlice) (PredefinedRegexCommand, error) {
+	data, err := yaml.Marshal(entry)
+	if err != nil {
+		return PredefinedRegexCommand{}, err
+	}
+	var e userExtractor
+	if err := yaml.UnmarshalStrict(data, &e); err != nil {
+		return PredefinedRegexCommand{}, err
+	}
+
+	if e.Name == "" {
+		return PredefinedRegexCommand{}, errors.New("it has no name")
+	}
+	if e.Regex == "" {
+		return PredefinedRegexCommand{}, errors.New(e.Name + " has no regex")
+	}
+	if _, err := regexp.Compile(e.Regex); err != nil {
+		return PredefinedRegexCommand{}, fmt.Errorf("%s has an invalid regex: %w", e.Name, err)
+	}
+	if e.Usage == "" {
+		e.Usage = "returns " + e.Name + " (user-defined)"
+	}
+	return PredefinedRegexCommand{
+		Name:    e.Name,
+		Usage:   e.Usage,
+		Aliases: e.Aliases,
+		Regex:   e.Regex,
+		Matches: e.Matches,
+		Fails:   e.Fails,
+	}, nil
+}
+
+// MergeRegexCommands returns the built-in extractors together with user-defined ones.
+// User-defined extractors replace built-in extractors with the same name.
+func MergeRegexCommands(builtin, user []PredefinedRegexCommand) []PredefinedRegexCommand {
+	merged := append([]PredefinedRegexCommand{}, builtin...)
+	for _, u := range user {
+		replaced := false
+		for i := range merged {
+			if merged[i].Name == u.Name {
+				merged[i] = u
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, u)
+		}
+	}
+	return merged
+}
+
+// Test checks the examples of an extractor and returns a message for every example, which is not handled correctly
+func (p PredefinedRegexCommand) Test() []string {
+	r, err := regexp.Compile(p.Regex)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var failures []string
+	for _, match := range p.Matches {
+		if len(p.Find(r, match)) == 0 {
+			failures = append(failures, "regex does not match, but should: "+match)
+		}
+	}
+	for _, fail := range p.Fails {
+		if len(p.Find(r, fail)) != 0 {
+			failures = append(failures, "regex does match, but should not: "+fail)
+		}
+	}
+	return failures
+}
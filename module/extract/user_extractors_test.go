@@ -0,0 +1,147 @@
+package extract
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dops-cli/dops/cli"
+)
+
+func writeExtractors(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), userExtractorsFile)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func names(extractors []PredefinedRegexCommand) []string {
+	var list []string
+	for _, e := range extractors {
+		list = append(list, e.Name)
+	}
+	return list
+}
+
+func TestLoadUserExtractors(t *testing.T) {
+	path := writeExtractors(t, `extractors:
+  - name: ticket
+    aliases: [jira]
+    regex: '\b[A-Z]{2,10}-\d+\b'
+    matches: [DOPS-123]
+    fails: [dops-123]
+  - name: broken
+    regex: '[a-'
+  - regex: 'x'
+  - name: typo
+    regx: 'x'
+  - name: noregex
+  - name: build
+    usage: returns build numbers
+    regex: '#\d+'
+`)
+	extractors, errs := LoadUserExtractors(path)
+	if got := names(extractors); !reflect.DeepEqual(got, []string{"ticket", "build"}) {
+		t.Errorf("got %v", got)
+	}
+	if len(errs) != 4 {
+		t.Fatalf("got %v", errs)
+	}
+	for i, want := range []string{"extractor 2", "extractor 3", "extractor 4", "extractor 5"} {
+		if !strings.Contains(errs[i].Error(), want) {
+			t.Errorf("got %v, want %s", errs[i], want)
+		}
+	}
+
+	ticket := extractors[0]
+	if ticket.Usage != "returns ticket (user-defined)" || !reflect.DeepEqual(ticket.Aliases, []string{"jira"}) || len(ticket.Test()) != 0 {
+		t.Errorf("got %+v", ticket)
+	}
+	if extractors[1].Usage != "returns build numbers" {
+		t.Errorf("got %+v", extractors[1])
+	}
+}
+
+func TestLoadUserExtractorsFiles(t *testing.T) {
+	if extractors, errs := LoadUserExtractors(filepath.Join(t.TempDir(), "missing.yaml")); extractors != nil || errs != nil {
+		t.Errorf("a missing file should be ignored, got %v %v", extractors, errs)
+	}
+	if extractors, errs := LoadUserExtractors(""); extractors != nil || errs != nil {
+		t.Errorf("got %v %v", extractors, errs)
+	}
+	if _, errs := LoadUserExtractors(writeExtractors(t, "extractors: {")); len(errs) != 1 {
+		t.Errorf("got %v", errs)
+	}
+	if _, errs := LoadUserExtractors(writeExtractors(t, "extractor: []")); len(errs) != 1 {
+		t.Errorf("got %v", errs)
+	}
+}
+
+func TestUserExtractorsPath(t *testing.T) {
+	os.Setenv("DOPS_EXTRACTORS", "/tmp/custom.yaml")
+	if got := UserExtractorsPath(); got != "/tmp/custom.yaml" {
+		t.Errorf("got %s", got)
+	}
+	os.Unsetenv("DOPS_EXTRACTORS")
+
+	config := os.Getenv("XDG_CONFIG_HOME")
+	defer os.Setenv("XDG_CONFIG_HOME", config)
+	os.Setenv("XDG_CONFIG_HOME", "/config")
+	if got := UserExtractorsPath(); got != filepath.Join("/config", "dops", userExtractorsFile) {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestMergeRegexCommands(t *testing.T) {
+	builtin := []PredefinedRegexCommand{{Name: "ip", Regex: "a"}, {Name: "email", Regex: "b"}}
+	user := []PredefinedRegexCommand{{Name: "ticket", Regex: "c"}, {Name: "ip", Regex: "d"}}
+
+	merged := MergeRegexCommands(builtin, user)
+	want := []PredefinedRegexCommand{{Name: "ip", Regex: "d"}, {Name: "email", Regex: "b"}, {Name: "ticket", Regex: "c"}}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("got %v, want %v", merged, want)
+	}
+	if builtin[0].Regex != "a" {
+		t.Error("the built-in extractors were changed")
+	}
+	if got := MergeRegexCommands(builtin, nil); !reflect.DeepEqual(got, builtin) {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestUserExtractorCommands(t *testing.T) {
+	os.Setenv("DOPS_EXTRACTORS", writeExtractors(t, "extractors:\n  - name: ticket\n    regex: 'x'\n  - name: email\n    regex: 'y'\n"))
+	defer os.Unsetenv("DOPS_EXTRACTORS")
+
+	predefined := Predefined()
+	if got := len(predefined.Subcommands); got != len(RegexList) {
+		t.Errorf("the user-defined extractors should not be loaded yet, got %d commands", got)
+	}
+	addUserExtractors(predefined)
+	addUserExtractors(predefined)
+	if got := len(predefined.Subcommands); got != len(RegexList)+1 {
+		t.Errorf("got %d commands, want %d", got, len(RegexList)+1)
+	}
+}
+
+func TestTestCommandIgnoresErrorsOfOtherExtractors(t *testing.T) {
+	path := writeExtractors(t, "extractors:\n  - name: ticket\n    regex: '[A-Z]+-\\d+'\n    matches: [DOPS-1]\n  - name: broken\n    regex: '[a-'\n")
+	app := &cli.App{
+		Commands:       []*cli.Command{Test()},
+		ExitErrHandler: func(*cli.Context, error) {},
+	}
+	if err := app.Run([]string{"dops", "test", "--file", path, "ticket", "email"}); err != nil {
+		t.Errorf("the broken extractor was not selected, but the test failed: %v", err)
+	}
+	if err := app.Run([]string{"dops", "test", "--file", path, "ticket", "broken"}); err == nil {
+		t.Error("the selected broken extractor didn't fail the test")
+	}
+	if err := app.Run([]string{"dops", "test", "--file", path}); err == nil {
+		t.Error("the broken extractor didn't fail the test of all extractors")
+	}
+}
@@ -0,0 +1,104 @@
+package healthcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// actionTimeout limits how long commands and webhooks of actions can run
+const actionTimeout = 30 * time.Second
+
+// Change is passed to actions, when the state of a check changed
+type Change struct {
+	Name     string    `json:"name"`
+	Target   string    `json:"target"`
+	State    State     `json:"state"`
+	Previous State     `json:"previous"`
+	Error    string    `json:"error,omitempty"`
+	Latency  float64   `json:"latency_ms"`
+	Time     time.Time `json:"time"`
+}
+
+// matches returns true, if the action is fired for the change
+func (a Action) matches(change Change) bool {
+	switch a.On {
+	case "up", "down":
+		return string(change.State) == a.On
+	}
+	return true
+}
+
+// Fire runs the command and sends the webhook of the action
+func (a Action) Fire(ctx context.Context, change Change) error {
+	ctx, cancel := context.WithTimeout(ctx, actionTimeout)
+	defer cancel()
+
+	var errs []string
+	if a.Command != "" {
+		if err := runCommand(ctx, a.Command, change); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if a.Webhook != "" {
+		if err := postWebhook(ctx, a.Webhook, change); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("action for %s failed: %s", change.Name, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func runCommand(ctx context.Context, command string, change Change) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Env = append(os.Environ(),
+		"DOPS_CHECK_NAME="+change.Name,
+		"DOPS_CHECK_TARGET="+change.Target,
+		"DOPS_CHECK_STATE="+string(change.State),
+		"DOPS_CHECK_PREVIOUS="+string(change.Previous),
+		"DOPS_CHECK_ERROR="+change.Error,
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if out := strings.TrimSpace(string(output)); out != "" {
+			return fmt.Errorf("command %q: %w: %s", command, err, out)
+		}
+		return fmt.Errorf("command %q: %w", command, err)
+	}
+	return nil
+}
+
+func postWebhook(ctx context.Context, url string, change Change) error {
+	body, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("webhook %s returned %s", url, resp.Status)
+	}
+	return nil
+}
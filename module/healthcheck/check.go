@@ -0,0 +1,190 @@
+package healthcheck
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// State is the state of a check
+type State string
+
+// The states of a check. A check is pending until it ran once.
+const (
+	Pending State = "pending"
+	Up      State = "up"
+	Down    State = "down"
+)
+
+// maxBodySize limits how much of a response body is matched against the body regex
+const maxBodySize = 1 << 20
+
+// Result is the outcome of running a check once
+type Result struct {
+	State   State
+	Latency time.Duration
+	// Status is the status code of URL checks
+	Status int
+	// CertExpiry is the expiry of the TLS certificate, if the check used TLS
+	CertExpiry time.Time
+	Error      string
+	Time       time.Time
+}
+
+// Run runs the check once
+func (check *Check) Run(ctx context.Context) Result {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(check.Timeout))
+	defer cancel()
+
+	start := time.Now()
+	var result Result
+	var err error
+	if check.URL != "" {
+		result, err = check.runHTTP(ctx)
+	} else {
+		result, err = check.runTCP(ctx)
+	}
+	result.Time = start
+	if result.Latency == 0 {
+		result.Latency = time.Since(start)
+	}
+
+	if err == nil && check.MaxLatency > 0 && result.Latency > time.Duration(check.MaxLatency) {
+		err = fmt.Errorf("latency %s exceeds %s", formatLatency(result.Latency), time.Duration(check.MaxLatency))
+	}
+	if err == nil && check.TLSExpiryDays > 0 {
+		if result.CertExpiry.IsZero() {
+			err = errors.New("no TLS certificate to check")
+		} else if days := int(time.Until(result.CertExpiry).Hours() / 24); days < check.TLSExpiryDays {
+			err = fmt.Errorf("certificate expires in %d days", days)
+		}
+	}
+
+	result.State = Up
+	if err != nil {
+		result.State = Down
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (check *Check) runHTTP(ctx context.Context) (Result, error) {
+	var result Result
+	req, err := http.NewRequestWithContext(ctx, check.Method, check.URL, nil)
+	if err != nil {
+		return result, err
+	}
+	for name, value := range check.Headers {
+		req.Header.Set(name, value)
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:             http.ProxyFromEnvironment,
+			DisableKeepAlives: true,
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: check.Insecure}, //nolint:gosec
+		},
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return result, err
+	}
+	defer resp.Body.Close()
+	result.Latency = time.Since(start)
+	result.Status = resp.StatusCode
+	if resp.TLS != nil {
+		result.CertExpiry = earliestExpiry(resp.TLS.PeerCertificates)
+	}
+
+	if !check.Status.Accepts(resp.StatusCode) {
+		_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxBodySize))
+		return result, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if check.body != nil {
+		body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+		if err != nil {
+			return result, err
+		}
+		if !check.body.Match(body) {
+			return result, fmt.Errorf("body does not match %s", check.Body)
+		}
+	}
+	return result, nil
+}
+
+func (check *Check) runTCP(ctx context.Context) (Result, error) {
+	var result Result
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", check.TCP)
+	if err != nil {
+		return result, err
+	}
+	defer conn.Close()
+	result.Latency = time.Since(start)
+
+	if check.TLS {
+		host, _, _ := net.SplitHostPort(check.TCP)
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host, InsecureSkipVerify: check.Insecure}) //nolint:gosec
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = tlsConn.SetDeadline(deadline)
+		}
+		if err := tlsConn.Handshake(); err != nil {
+			return result, err
+		}
+		result.Latency = time.Since(start)
+		result.CertExpiry = earliestExpiry(tlsConn.ConnectionState().PeerCertificates)
+	}
+	return result, nil
+}
+
+// earliestExpiry returns the earliest expiry of a certificate chain, because the chain breaks with the first expired certificate
+func earliestExpiry(certs []*x509.Certificate) time.Time {
+	var earliest time.Time
+	for _, cert := range certs {
+		if earliest.IsZero() || cert.NotAfter.Before(earliest) {
+			earliest = cert.NotAfter
+		}
+	}
+	return earliest
+}
+
+// summary describes a result in a few words
+func (r Result) summary() string {
+	var parts []string
+	if r.Status != 0 {
+		parts = append(parts, fmt.Sprint(r.Status))
+	}
+	if r.Latency > 0 {
+		parts = append(parts, formatLatency(r.Latency))
+	}
+	if !r.CertExpiry.IsZero() {
+		parts = append(parts, fmt.Sprintf("cert %dd", int(time.Until(r.CertExpiry).Hours()/24)))
+	}
+	if r.Error != "" {
+		parts = append(parts, r.Error)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatLatency rounds a latency to a readable precision
+func formatLatency(d time.Duration) string {
+	if d >= time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(10 * time.Microsecond).String()
+}
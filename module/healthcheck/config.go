@@ -0,0 +1,199 @@
+package healthcheck
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the content of an endpoints file
+type Config struct {
+	// Interval is the default interval of all checks
+	Interval Duration `yaml:"interval"`
+	// Timeout is the default timeout of all checks
+	Timeout Duration `yaml:"timeout"`
+	Checks  []*Check `yaml:"checks"`
+	// Actions are fired for all checks, in addition to the actions of each check
+	Actions []Action `yaml:"actions"`
+}
+
+// Check describes an endpoint and what's expected from it. Either URL or TCP is set.
+type Check struct {
+	Name    string            `yaml:"name"`
+	URL     string            `yaml:"url"`
+	Method  string            `yaml:"method"`
+	Headers map[string]string `yaml:"headers"`
+	// TCP is a HOST:PORT, which must accept connections
+	TCP string `yaml:"tcp"`
+	// TLS makes TCP checks do a TLS handshake, which is needed for TLSExpiryDays
+	TLS bool `yaml:"tls"`
+	// Status lists the accepted status codes, by default all codes below 400 are accepted
+	Status StatusCodes `yaml:"status"`
+	// Body is a regular expression, which must match the response body
+	Body       string   `yaml:"body"`
+	MaxLatency Duration `yaml:"max_latency"`
+	// TLSExpiryDays fails the check, if the certificate expires in less days
+	TLSExpiryDays int      `yaml:"tls_expiry_days"`
+	Interval      Duration `yaml:"interval"`
+	Timeout       Duration `yaml:"timeout"`
+	Insecure      bool     `yaml:"insecure"`
+	Actions       []Action `yaml:"actions"`
+
+	body *regexp.Regexp
+}
+
+// Action is fired, when the state of a check changes
+type Action struct {
+	// On is 'down', 'up' or 'change' (default)
+	On string `yaml:"on"`
+	// Command is run by the shell with DOPS_CHECK_NAME, DOPS_CHECK_TARGET, DOPS_CHECK_STATE and DOPS_CHECK_ERROR set
+	Command string `yaml:"command"`
+	// Webhook receives a JSON POST with the name, target, state, previous state and error of the check
+	Webhook string `yaml:"webhook"`
+}
+
+// Duration is a time.Duration, which is written like 30s in YAML
+type Duration time.Duration
+
+// UnmarshalYAML parses durations like 30s or 1m30s
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// StatusCodes can be written as a single code or a list of codes in YAML
+type StatusCodes []int
+
+// UnmarshalYAML parses a single status code or a list of status codes
+func (s *StatusCodes) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var code int
+	if err := unmarshal(&code); err == nil {
+		*s = StatusCodes{code}
+		return nil
+	}
+	var codes []int
+	if err := unmarshal(&codes); err != nil {
+		return err
+	}
+	*s = codes
+	return nil
+}
+
+// Accepts returns true, if the status code is expected
+func (s StatusCodes) Accepts(code int) bool {
+	if len(s) == 0 {
+		return code < 400
+	}
+	for _, c := range s {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadConfig reads an endpoints file and applies the defaults to all checks
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	if err := config.prepare(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// prepare validates the checks and sets their defaults
+func (config *Config) prepare() error {
+	if len(config.Checks) == 0 {
+		return errors.New("no checks defined")
+	}
+	if config.Interval == 0 {
+		config.Interval = Duration(30 * time.Second)
+	}
+	if config.Timeout == 0 {
+		config.Timeout = Duration(10 * time.Second)
+	}
+	if err := validateActions(config.Actions); err != nil {
+		return err
+	}
+
+	names := map[string]bool{}
+	for i, check := range config.Checks {
+		if (check.URL == "") == (check.TCP == "") {
+			return fmt.Errorf("check %d must have either url or tcp", i+1)
+		}
+		if check.Name == "" {
+			check.Name = check.target()
+		}
+		if names[check.Name] {
+			return errors.New("duplicate check name: " + check.Name)
+		}
+		names[check.Name] = true
+
+		if check.Interval == 0 {
+			check.Interval = config.Interval
+		}
+		if check.Timeout == 0 {
+			check.Timeout = config.Timeout
+		}
+		if check.Method == "" {
+			check.Method = "GET"
+		}
+		if check.Body != "" {
+			var err error
+			if check.body, err = regexp.Compile(check.Body); err != nil {
+				return fmt.Errorf("check %s has an invalid body regex: %w", check.Name, err)
+			}
+		}
+		if check.TCP != "" && (check.Body != "" || len(check.Status) > 0) {
+			return fmt.Errorf("check %s: body and status can only be used with url", check.Name)
+		}
+		if check.TCP != "" && check.TLSExpiryDays > 0 && !check.TLS {
+			return fmt.Errorf("check %s: tls_expiry_days needs tls: true for tcp checks", check.Name)
+		}
+		if err := validateActions(check.Actions); err != nil {
+			return fmt.Errorf("check %s: %w", check.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateActions(actions []Action) error {
+	for _, a := range actions {
+		switch a.On {
+		case "", "change", "up", "down":
+		default:
+			return errors.New("action 'on' must be 'change', 'up' or 'down', not " + a.On)
+		}
+		if a.Command == "" && a.Webhook == "" {
+			return errors.New("action needs a command or webhook")
+		}
+	}
+	return nil
+}
+
+// target returns the URL or TCP address of the check
+func (check *Check) target() string {
+	if check.URL != "" {
+		return check.URL
+	}
+	return check.TCP
+}
@@ -0,0 +1,140 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/say/color"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:    "healthcheck",
+			Aliases: []string{"uptime"},
+			Usage:   "Monitors HTTP endpoints and TCP ports",
+			Description: `Healthcheck runs the checks of an endpoints file periodically and shows their states on a live status board.
+When the state of a check changes, its actions are fired. With --once all checks run once and healthcheck exits with status 1 if any check failed,
+which can be used to verify a deployment.
+
+The endpoints file is written in YAML:
+
+  interval: 30s
+  timeout: 10s
+  checks:
+    - name: api
+      url: https://api.example.com/health
+      status: [200, 204]
+      body: '"status":\s*"ok"'
+      max_latency: 500ms
+      tls_expiry_days: 14
+    - name: database
+      tcp: db.internal:5432
+      interval: 10s
+  actions:
+    - on: down
+      command: notify-send "$DOPS_CHECK_NAME is $DOPS_CHECK_STATE" "$DOPS_CHECK_ERROR"
+    - webhook: https://hooks.example.com/alerts
+
+URL checks accept all status codes below 400, unless 'status' is set. 'body' is a regular expression, which must match the response body.
+TCP checks only connect, with 'tls: true' they do a TLS handshake as well, so that 'tls_expiry_days' can be used.
+Actions fire 'on' change (default), 'up' or 'down'. Commands get the change in the environment variables
+DOPS_CHECK_NAME, DOPS_CHECK_TARGET, DOPS_CHECK_STATE, DOPS_CHECK_PREVIOUS and DOPS_CHECK_ERROR.
+Webhooks receive the change as JSON POST. Actions can be set for all checks or for a single check.`,
+			Category: categories.Web,
+			Examples: []cli.Example{
+				{
+					ShortDescription: "Monitor the endpoints of a file",
+					Usage:            "dops healthcheck -f endpoints.yaml",
+				},
+				{
+					ShortDescription: "Verify all endpoints after a deployment",
+					Usage:            "dops healthcheck --once -f endpoints.yaml",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				path := c.Path("file")
+				if path == "" {
+					return errors.New("no endpoints file given, use --file")
+				}
+				config, err := LoadConfig(path)
+				if err != nil {
+					return err
+				}
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				interrupt := make(chan os.Signal, 1)
+				signal.Notify(interrupt, os.Interrupt)
+				defer signal.Stop(interrupt)
+				go func() {
+					select {
+					case <-interrupt:
+						cancel()
+					case <-ctx.Done():
+					}
+				}()
+
+				if !c.Bool("once") {
+					monitor(ctx, config)
+					return nil
+				}
+
+				states, failed := runOnce(ctx, config)
+				if err := utils.WriteOutput(c, []string{stateTable(states, color.NewColorizer(c.String("output") == ""))}); err != nil {
+					return err
+				}
+				if failed > 0 {
+					return cli.Exit(fmt.Sprintf("%d of %d checks failed", failed, len(states)), 1)
+				}
+				return nil
+			},
+			Flags: append([]cli.Flag{
+				&cli.PathFlag{
+					Name:      "file",
+					Aliases:   []string{"f"},
+					Usage:     "Reads the checks from `FILE`",
+					TakesFile: true,
+				},
+				&cli.BoolFlag{
+					Name:  "once",
+					Usage: "Runs all checks once and exits with status 1 if any check failed",
+				},
+			}, utils.OutputFlags()...),
+		},
+	}
+}
+
+// runOnce runs all checks concurrently and returns their states and the number of failed checks
+func runOnce(ctx context.Context, config *Config) ([]*checkState, int) {
+	states := make([]*checkState, len(config.Checks))
+	var wg sync.WaitGroup
+	for i, check := range config.Checks {
+		states[i] = &checkState{check: check, state: Pending}
+		wg.Add(1)
+		go func(s *checkState) {
+			defer wg.Done()
+			s.update(s.check.Run(ctx))
+		}(states[i])
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, s := range states {
+		if s.state != Up {
+			failed++
+		}
+	}
+	return states, failed
+}
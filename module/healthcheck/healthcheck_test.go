@@ -0,0 +1,156 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints.yaml")
+	content := `interval: 5s
+checks:
+  - url: http://localhost/health
+    status: 204
+  - name: db
+    tcp: localhost:5432
+    interval: 1m
+    timeout: 3s
+  - name: api
+    url: http://localhost/api
+    status: [200, 201]
+`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	health, db, api := config.Checks[0], config.Checks[1], config.Checks[2]
+	if health.Name != "http://localhost/health" || time.Duration(health.Interval) != 5*time.Second || time.Duration(health.Timeout) != 10*time.Second {
+		t.Errorf("defaults were not applied: %+v", health)
+	}
+	if time.Duration(db.Interval) != time.Minute || time.Duration(db.Timeout) != 3*time.Second {
+		t.Errorf("unexpected durations: %+v", db)
+	}
+	if !health.Status.Accepts(204) || health.Status.Accepts(200) || !api.Status.Accepts(201) || api.Status.Accepts(302) {
+		t.Errorf("unexpected status codes: %v and %v", health.Status, api.Status)
+	}
+
+	for _, invalid := range []string{
+		"checks: []",
+		"checks:\n  - name: both\n    url: http://localhost\n    tcp: localhost:80",
+		"checks:\n  - tcp: localhost:80\n    status: 200",
+		"checks:\n  - url: http://localhost\n    body: '('",
+		"checks:\n  - url: http://localhost\nactions:\n  - on: sometimes\n    command: 'true'",
+	} {
+		if err := ioutil.WriteFile(path, []byte(invalid), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfig(path); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(50 * time.Millisecond)
+		case "/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		_, _ = w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer server.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := listener.Addr().String()
+	listener.Close()
+
+	tests := []struct {
+		check Check
+		want  State
+		error string
+	}{
+		{Check{URL: server.URL + "/health", Body: `"status":\s*"ok"`}, Up, ""},
+		{Check{URL: server.URL + "/health", Body: `"status":\s*"down"`}, Down, "body does not match"},
+		{Check{URL: server.URL + "/broken"}, Down, "unexpected status 500"},
+		{Check{URL: server.URL + "/broken", Status: StatusCodes{500}}, Up, ""},
+		{Check{URL: server.URL + "/slow", MaxLatency: Duration(10 * time.Millisecond)}, Down, "exceeds 10ms"},
+		{Check{URL: server.URL + "/health", TLSExpiryDays: 7}, Down, "no TLS certificate"},
+		{Check{TCP: strings.TrimPrefix(server.URL, "http://")}, Up, ""},
+		{Check{TCP: closed}, Down, "refused"},
+	}
+	for _, tt := range tests {
+		config := Config{Checks: []*Check{&tt.check}}
+		if err := config.prepare(); err != nil {
+			t.Fatal(err)
+		}
+		result := tt.check.Run(context.Background())
+		if result.State != tt.want || !strings.Contains(result.Error, tt.error) {
+			t.Errorf("%s: got %s %q, want %s %q", tt.check.Name, result.State, result.Error, tt.want, tt.error)
+		}
+	}
+}
+
+func TestStateChanges(t *testing.T) {
+	received := make(chan Change, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var change Change
+		if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+			t.Error(err)
+		}
+		received <- change
+	}))
+	defer hook.Close()
+
+	s := &checkState{check: &Check{Name: "api", URL: "http://localhost/api"}, state: Pending}
+	if _, changed := s.update(Result{State: Up}); changed {
+		t.Error("the first successful run must not be a change")
+	}
+	if _, changed := s.update(Result{State: Up}); changed {
+		t.Error("an unchanged state must not be a change")
+	}
+	change, changed := s.update(Result{State: Down, Error: "timeout"})
+	if !changed || change.Previous != Up || change.State != Down || change.Error != "timeout" {
+		t.Fatalf("unexpected change: %v %+v", changed, change)
+	}
+	if s.uptime() != "66.7%" {
+		t.Errorf("got uptime %s", s.uptime())
+	}
+
+	down := Action{On: "down", Webhook: hook.URL}
+	up := Action{On: "up", Webhook: hook.URL}
+	if !down.matches(change) || up.matches(change) {
+		t.Error("actions matched the wrong states")
+	}
+	if err := down.Fire(context.Background(), change); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-received; got.Name != "api" || got.State != Down || got.Previous != Up {
+		t.Errorf("webhook received %+v", got)
+	}
+}
+
+func TestBoardWithoutColors(t *testing.T) {
+	states := []*checkState{{check: &Check{Name: "api"}, state: Down}}
+	b := &board{states: states, live: true, colored: false}
+	b.event("api is down", true)
+	if strings.Contains(b.events[0], "\x1b[") || strings.Contains(stateTable(states, b.colored), "\x1b[") {
+		t.Errorf("got colors in raw mode: %q", b.events[0])
+	}
+}
@@ -0,0 +1,210 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dops-cli/dops/progressbar/cwriter"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+)
+
+// maxEvents is the number of events, which are shown below the status board
+const maxEvents = 10
+
+// checkState is the state of a check over all of its runs
+type checkState struct {
+	check  *Check
+	state  State
+	since  time.Time
+	last   Result
+	runs   int
+	upRuns int
+}
+
+// update records a result. It returns the change, if the state of the check changed.
+// A check, which is up at its first run, is not reported as a change.
+func (s *checkState) update(r Result) (Change, bool) {
+	s.runs++
+	if r.State == Up {
+		s.upRuns++
+	}
+	s.last = r
+
+	previous := s.state
+	if r.State == previous {
+		return Change{}, false
+	}
+	s.state = r.State
+	s.since = r.Time
+	if previous == Pending && r.State == Up {
+		return Change{}, false
+	}
+	return Change{
+		Name:     s.check.Name,
+		Target:   s.check.target(),
+		State:    r.State,
+		Previous: previous,
+		Error:    r.Error,
+		Latency:  float64(r.Latency) / float64(time.Millisecond),
+		Time:     r.Time,
+	}, true
+}
+
+func (s *checkState) uptime() string {
+	if s.runs == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(float64(s.upRuns)*100/float64(s.runs), 'f', 1, 64) + "%"
+}
+
+type checkResult struct {
+	state  *checkState
+	result Result
+}
+
+// monitor runs all checks in their interval until ctx is done. The states are shown on a live board,
+// or logged line by line, if stdout is not a terminal.
+func monitor(ctx context.Context, config *Config) {
+	states := make([]*checkState, len(config.Checks))
+	results := make(chan checkResult)
+	var wg sync.WaitGroup
+	for i, check := range config.Checks {
+		state := &checkState{check: check, state: Pending}
+		states[i] = state
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(time.Duration(state.check.Interval))
+			defer ticker.Stop()
+			for {
+				result := state.check.Run(ctx)
+				select {
+				case results <- checkResult{state, result}:
+				case <-ctx.Done():
+					return
+				}
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	b := newBoard(states)
+	events := make(chan string)
+	var actions sync.WaitGroup
+	for {
+		select {
+		case r := <-results:
+			change, changed := r.state.update(r.result)
+			if changed {
+				b.event(fmt.Sprintf("%s is %s %s", change.Name, change.State, r.result.summary()), change.State == Down)
+				for _, action := range append(config.Actions, r.state.check.Actions...) {
+					if !action.matches(change) {
+						continue
+					}
+					action := action
+					actions.Add(1)
+					go func() {
+						defer actions.Done()
+						if err := action.Fire(ctx, change); err != nil {
+							select {
+							case events <- err.Error():
+							case <-ctx.Done():
+							}
+						}
+					}()
+				}
+			}
+			b.render()
+		case message := <-events:
+			b.event(message, true)
+			b.render()
+		case <-ctx.Done():
+			wg.Wait()
+			actions.Wait()
+			return
+		}
+	}
+}
+
+// board shows the states of all checks and the latest events
+type board struct {
+	states  []*checkState
+	events  []string
+	live    bool
+	colored color.Colorizer
+	writer  *cwriter.Writer
+}
+
+func newBoard(states []*checkState) *board {
+	return &board{
+		states:  states,
+		live:    cwriter.IsTerminal(int(os.Stdout.Fd())),
+		colored: color.NewColorizer(true),
+		writer:  cwriter.New(os.Stdout),
+	}
+}
+
+func (b *board) event(message string, bad bool) {
+	line := time.Now().Format("15:04:05") + " " + message
+	if !b.live {
+		if bad {
+			say.Error(line)
+		} else {
+			say.Success(line)
+		}
+		return
+	}
+	if bad {
+		line = b.colored.Paint(color.SRed, line)
+	}
+	b.events = append(b.events, line)
+	if len(b.events) > maxEvents {
+		b.events = b.events[len(b.events)-maxEvents:]
+	}
+}
+
+func (b *board) render() {
+	if !b.live {
+		return
+	}
+	content := stateTable(b.states, b.colored)
+	if len(b.events) > 0 {
+		content += "\n\n" + strings.Join(b.events, "\n")
+	}
+	content += "\n"
+	_, _ = b.writer.WriteString(content)
+	_ = b.writer.Flush(strings.Count(content, "\n"))
+}
+
+// stateTable renders the states of the checks as table
+func stateTable(states []*checkState, colored color.Colorizer) string {
+	data := [][]string{{"Check", "State", "Since", "Target", "Uptime", "Last result"}}
+	for _, s := range states {
+		var state string
+		switch s.state {
+		case Up:
+			state = colored.Paint(color.SGreen, string(s.state))
+		case Down:
+			state = colored.Paint(color.SRed, string(s.state))
+		default:
+			state = colored.Paint(color.SYellow, string(s.state))
+		}
+		since, last := "-", "-"
+		if s.runs > 0 {
+			since = s.since.Format("15:04:05")
+			last = s.last.summary() + " at " + s.last.Time.Format("15:04:05")
+		}
+		data = append(data, []string{s.check.Name, state, since, s.check.target(), s.uptime(), last})
+	}
+	return say.Table(data, true, colored)
+}
@@ -0,0 +1,174 @@
+package httpclient
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"time"
+)
+
+// Request is an HTTP request, which is sent by a Client
+type Request struct {
+	// Name identifies requests in request files
+	Name   string
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// NewRequest returns a request without headers and body
+func NewRequest(method, url string) *Request {
+	return &Request{Method: strings.ToUpper(method), URL: url, Header: http.Header{}}
+}
+
+// SetBasicAuth sets the Authorization header for basic authentication
+func (r *Request) SetBasicAuth(user, password string) {
+	r.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user+":"+password)))
+}
+
+// SetBearer sets the Authorization header for a bearer token
+func (r *Request) SetBearer(token string) {
+	r.Header.Set("Authorization", "Bearer "+token)
+}
+
+// Options configure a Client
+type Options struct {
+	// MaxRedirects is the amount of redirects, which are followed. Redirects are not followed, if it's 0.
+	MaxRedirects int
+	Timeout      time.Duration
+	Insecure     bool
+	Jar          http.CookieJar
+}
+
+// Timing contains the duration of each phase of a request
+type Timing struct {
+	DNS      time.Duration
+	Connect  time.Duration
+	TLS      time.Duration
+	TTFB     time.Duration
+	Transfer time.Duration
+	Total    time.Duration
+	// Reused is true if an existing connection was used, DNS, Connect and TLS are zero then
+	Reused bool
+}
+
+// Response is the response to a request with its body already read
+type Response struct {
+	*http.Response
+	Body   []byte
+	Timing Timing
+	// Redirects contains the URLs, which redirected to the final URL
+	Redirects []string
+}
+
+// Client sends requests
+type Client struct {
+	client *http.Client
+}
+
+// NewClient returns a client with the given options
+func NewClient(o Options) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if o.Insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   o.Timeout,
+		Jar:       o.Jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if o.MaxRedirects == 0 {
+				return http.ErrUseLastResponse
+			}
+			if len(via) > o.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", o.MaxRedirects)
+			}
+			return nil
+		},
+	}
+	return &Client{client: client}
+}
+
+// Do sends a request and reads the response
+func (c *Client) Do(r *Request) (*Response, error) {
+	if r.URL == "" {
+		return nil, errors.New("request has no URL")
+	}
+	if !strings.Contains(r.URL, "://") {
+		r.URL = "http://" + r.URL
+	}
+
+	req, err := http.NewRequest(r.Method, r.URL, bytes.NewReader(r.Body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range r.Header {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+	if host := r.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
+
+	var timing Timing
+	var start, dnsStart, connectStart, tlsStart, wrote time.Time
+	var firstByte time.Time
+	trace := &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone:           func(httptrace.DNSDoneInfo) { timing.DNS = time.Since(dnsStart) },
+		ConnectStart:      func(string, string) { connectStart = time.Now() },
+		ConnectDone:       func(string, string, error) { timing.Connect = time.Since(connectStart) },
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { timing.TLS = time.Since(tlsStart) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			timing.Reused = info.Reused
+			if info.Reused {
+				timing.DNS, timing.Connect, timing.TLS = 0, 0, 0
+			}
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { wrote = time.Now() },
+		GotFirstResponseByte: func() { firstByte = time.Now() },
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	var redirects []string
+	checkRedirect := c.client.CheckRedirect
+	client := *c.client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if err := checkRedirect(req, via); err != nil {
+			return err
+		}
+		redirects = append(redirects, via[len(via)-1].URL.String())
+		return nil
+	}
+
+	start = time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	end := time.Now()
+
+	if !firstByte.IsZero() {
+		timing.TTFB = firstByte.Sub(wrote)
+		timing.Transfer = end.Sub(firstByte)
+	}
+	timing.Total = end.Sub(start)
+
+	return &Response{Response: resp, Body: body, Timing: timing, Redirects: redirects}, nil
+}
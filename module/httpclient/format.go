@@ -0,0 +1,153 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dops-cli/dops/say/color"
+)
+
+// statusLine returns the status line of a response, colored by the status class
+func statusLine(resp *Response, c color.Colorizer) string {
+	paint := color.SGreen
+	switch {
+	case resp.StatusCode >= 400:
+		paint = color.SRed
+	case resp.StatusCode >= 300:
+		paint = color.SYellow
+	}
+	return c.Paint(color.SHiWhite, resp.Proto+" ") + c.Paint(paint, resp.Status)
+}
+
+// headerLines returns the headers sorted by name
+func headerLines(header http.Header, c color.Colorizer) []string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines []string
+	for _, name := range names {
+		for _, value := range header[name] {
+			lines = append(lines, c.Paint(color.SCyan, name)+": "+value)
+		}
+	}
+	return lines
+}
+
+// isJSON returns true if the content type or the content indicates JSON
+func isJSON(contentType string, body []byte) bool {
+	if strings.Contains(contentType, "json") {
+		return true
+	}
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed)
+}
+
+// formatBody indents and highlights JSON bodies, other bodies are returned unchanged
+func formatBody(contentType string, body []byte, c color.Colorizer) string {
+	if !isJSON(contentType, body) {
+		return string(body)
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		return string(body)
+	}
+	if !c {
+		return indented.String()
+	}
+	return highlightJSON(indented.Bytes())
+}
+
+// highlightJSON colors the keys, strings, numbers and literals of valid JSON
+func highlightJSON(data []byte) string {
+	var out strings.Builder
+	for i := 0; i < len(data); {
+		switch ch := data[i]; {
+		case ch == '"':
+			end := i + 1
+			for end < len(data) && data[end] != '"' {
+				if data[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end++
+			if end > len(data) {
+				end = len(data)
+			}
+			s := string(data[i:end])
+
+			// A string followed by a colon is a key
+			next := end
+			for next < len(data) && (data[next] == ' ' || data[next] == '\n') {
+				next++
+			}
+			if next < len(data) && data[next] == ':' {
+				out.WriteString(color.SHiBlue("%s", s))
+			} else {
+				out.WriteString(color.SGreen("%s", s))
+			}
+			i = end
+		case ch == '-' || (ch >= '0' && ch <= '9'):
+			end := i
+			for end < len(data) && strings.IndexByte("+-0123456789.eE", data[end]) != -1 {
+				end++
+			}
+			out.WriteString(color.SCyan("%s", string(data[i:end])))
+			i = end
+		case ch == 't' || ch == 'f' || ch == 'n':
+			end := i
+			for end < len(data) && data[end] >= 'a' && data[end] <= 'z' {
+				end++
+			}
+			out.WriteString(color.SYellow("%s", string(data[i:end])))
+			i = end
+		default:
+			out.WriteByte(ch)
+			i++
+		}
+	}
+	return out.String()
+}
+
+// timingLines returns the timing breakdown of a request
+func timingLines(t Timing, c color.Colorizer) []string {
+	phases := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"DNS lookup", t.DNS},
+		{"TCP connect", t.Connect},
+		{"TLS handshake", t.TLS},
+		{"Time to first byte", t.TTFB},
+		{"Content transfer", t.Transfer},
+		{"Total", t.Total},
+	}
+
+	var lines []string
+	for _, p := range phases {
+		lines = append(lines, fmt.Sprintf("%s %s", c.Paint(color.SHiWhite, fmt.Sprintf("%-19s", p.name)), formatDuration(p.duration)))
+	}
+	if t.Reused {
+		lines = append(lines, "(connection reused)")
+	}
+	return lines
+}
+
+// formatDuration rounds a duration to make it readable
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	}
+	return d.Round(time.Microsecond).String()
+}
@@ -0,0 +1,389 @@
+package httpclient
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:      "http",
+			Aliases:   []string{"request", "curl"},
+			Usage:     "Sends HTTP requests and shows the responses",
+			ArgsUsage: "[METHOD] URL",
+			// Flags are also accepted after the URL
+			InterspersedFlags: true,
+			Description: `HTTP sends a request and shows the status, headers and body of the response.
+JSON responses are indented and highlighted. The method defaults to GET, or POST if a body is given.
+
+The body can be set with --json, --data or --form (multipart, 'name=value' or 'name=@file' for files).
+With --timing the duration of DNS lookup, TCP connect, TLS handshake, time to first byte and content transfer is shown.
+Cookies can be kept between requests with --cookie-jar.
+
+Requests can be stored in .http files and sent with 'dops http run', see 'dops http run --help'.`,
+			Category: categories.Web,
+			Examples: []cli.Example{
+				{
+					ShortDescription: "Send a GET request with a custom header",
+					Usage:            "dops http -H 'X-Foo: bar' GET https://api.example.com/users",
+				},
+				{
+					ShortDescription: "POST a JSON file and show the timing breakdown",
+					Usage:            "dops http --json body.json --timing POST https://api.example.com/users",
+				},
+				{
+					ShortDescription: "Upload a file with basic authentication",
+					Usage:            "dops http -u admin:secret -F file=@report.pdf -F comment=weekly PUT https://example.com/upload",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				method, url := "", ""
+				switch c.NArg() {
+				case 1:
+					url = c.Args().Get(0)
+				case 2:
+					method, url = c.Args().Get(0), c.Args().Get(1)
+				default:
+					return errors.New("expected [METHOD] URL")
+				}
+
+				r, err := requestFromFlags(c, method, url)
+				if err != nil {
+					return err
+				}
+				client, jar, err := clientFromFlags(c)
+				if err != nil {
+					return err
+				}
+
+				resp, err := client.Do(r)
+				if err != nil {
+					return err
+				}
+				if jar != nil {
+					if err := jar.Save(); err != nil {
+						return err
+					}
+				}
+
+				if err := writeResponse(c, r, resp); err != nil {
+					return err
+				}
+				if c.Bool("fail") && resp.StatusCode >= 400 {
+					return cli.Exit("", 1)
+				}
+				return nil
+			},
+			Subcommands: []*cli.Command{
+				Run(),
+			},
+			Flags: append(append([]cli.Flag{
+				&cli.StringSliceFlag{
+					Name:    "header",
+					Aliases: []string{"H"},
+					Usage:   "Adds a `HEADER` like 'Name: value', can be used multiple times",
+				},
+				&cli.StringFlag{
+					Name:  "json",
+					Usage: "Sends JSON from a `FILE`, stdin with '-' or inline, like '{\"a\": 1}'",
+				},
+				&cli.StringFlag{
+					Name:    "data",
+					Aliases: []string{"d"},
+					Usage:   "Sends `DATA` as body, '@FILE' reads the body from a file",
+				},
+				&cli.StringSliceFlag{
+					Name:    "form",
+					Aliases: []string{"F"},
+					Usage:   "Adds a multipart `FIELD` like 'name=value' or 'name=@file'",
+				},
+				&cli.StringFlag{
+					Name:    "user",
+					Aliases: []string{"u"},
+					Usage:   "Uses basic authentication with `USER:PASSWORD`",
+				},
+				&cli.StringFlag{
+					Name:  "bearer",
+					Usage: "Uses bearer authentication with `TOKEN`",
+				},
+				&cli.BoolFlag{
+					Name:    "body",
+					Aliases: []string{"b"},
+					Usage:   "Shows only the body of the response",
+				},
+				&cli.BoolFlag{
+					Name:    "fail",
+					Aliases: []string{"f"},
+					Usage:   "Exits with status 1 if the response status is 400 or higher",
+				},
+			}, clientFlags()...), utils.OutputFlags()...),
+		},
+	}
+}
+
+// clientFlags returns the flags, which configure the client and how responses are shown
+func clientFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:  "max-redirects",
+			Usage: "Follows up to `N` redirects, 0 disables following redirects",
+			Value: 10,
+		},
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "Cancels requests after `DURATION`",
+			Value: 30 * time.Second,
+		},
+		&cli.BoolFlag{
+			Name:    "insecure",
+			Aliases: []string{"k"},
+			Usage:   "Does not verify TLS certificates",
+		},
+		&cli.PathFlag{
+			Name:      "cookie-jar",
+			Aliases:   []string{"c"},
+			Usage:     "Loads cookies from and saves cookies to `FILE`",
+			TakesFile: true,
+		},
+		&cli.BoolFlag{
+			Name:    "timing",
+			Aliases: []string{"t"},
+			Usage:   "Shows the timing breakdown of requests",
+		},
+		&cli.BoolFlag{
+			Name:    "verbose",
+			Aliases: []string{"v"},
+			Usage:   "Shows the request headers and redirects as well",
+		},
+		&cli.BoolFlag{
+			Name:  "raw",
+			Usage: "Does not indent and highlight responses",
+		},
+	}
+}
+
+// clientFromFlags returns a client configured by clientFlags. The jar is nil, if no cookie jar is used.
+func clientFromFlags(c *cli.Context) (*Client, *Jar, error) {
+	o := Options{
+		MaxRedirects: c.Int("max-redirects"),
+		Timeout:      c.Duration("timeout"),
+		Insecure:     c.Bool("insecure"),
+	}
+	var jar *Jar
+	if path := c.Path("cookie-jar"); path != "" {
+		var err error
+		if jar, err = LoadJar(path); err != nil {
+			return nil, nil, fmt.Errorf("could not load cookie jar: %w", err)
+		}
+		o.Jar = jar
+	}
+	return NewClient(o), jar, nil
+}
+
+// requestFromFlags builds a request from the flags of the http command
+func requestFromFlags(c *cli.Context, method, url string) (*Request, error) {
+	bodies := 0
+	for _, name := range []string{"json", "data", "form"} {
+		if c.IsSet(name) {
+			bodies++
+		}
+	}
+	if bodies > 1 {
+		return nil, errors.New("only one of --json, --data and --form can be used")
+	}
+
+	if method == "" {
+		method = "GET"
+		if bodies > 0 {
+			method = "POST"
+		}
+	}
+	r := NewRequest(method, url)
+
+	for _, header := range c.StringSlice("header") {
+		parts := strings.SplitN(header, ":", 2)
+		if len(parts) != 2 {
+			return nil, errors.New("header must be 'Name: value': " + header)
+		}
+		r.Header.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+
+	if user := c.String("user"); user != "" {
+		credentials := strings.SplitN(user, ":", 2)
+		if len(credentials) == 1 {
+			credentials = append(credentials, "")
+		}
+		r.SetBasicAuth(credentials[0], credentials[1])
+	}
+	if token := c.String("bearer"); token != "" {
+		r.SetBearer(token)
+	}
+
+	var contentType string
+	switch {
+	case c.IsSet("json"):
+		body := []byte(strings.TrimSpace(c.String("json")))
+		if !bytes.HasPrefix(body, []byte("{")) && !bytes.HasPrefix(body, []byte("[")) {
+			var err error
+			if body, err = readBody(string(body)); err != nil {
+				return nil, err
+			}
+		}
+		r.Body = body
+		contentType = "application/json"
+		if r.Header.Get("Accept") == "" {
+			r.Header.Set("Accept", "application/json")
+		}
+	case c.IsSet("data"):
+		body := []byte(c.String("data"))
+		if bytes.HasPrefix(body, []byte("@")) {
+			var err error
+			if body, err = readBody(string(body[1:])); err != nil {
+				return nil, err
+			}
+		}
+		r.Body = body
+		contentType = "application/x-www-form-urlencoded"
+	case c.IsSet("form"):
+		body, formType, err := multipartBody(c.StringSlice("form"))
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+		contentType = formType
+	}
+	if contentType != "" && r.Header.Get("Content-Type") == "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+
+	return r, nil
+}
+
+// readBody reads a body from a file, '-' reads stdin.
+// The body is sent as it is, so that compressed files are not decompressed like other inputs.
+func readBody(path string) ([]byte, error) {
+	if path == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+// multipartBody encodes fields like 'name=value' and 'name=@file' as multipart form
+func multipartBody(fields []string) ([]byte, string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	for _, field := range fields {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 {
+			return nil, "", errors.New("form field must be 'name=value' or 'name=@file': " + field)
+		}
+		name, value := parts[0], parts[1]
+		if !strings.HasPrefix(value, "@") {
+			if err := w.WriteField(name, value); err != nil {
+				return nil, "", err
+			}
+			continue
+		}
+
+		path := strings.TrimPrefix(value, "@")
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, "", err
+		}
+		part, err := w.CreateFormFile(name, filepath.Base(path))
+		if err == nil {
+			_, err = io.Copy(part, f)
+		}
+		f.Close()
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return body.Bytes(), w.FormDataContentType(), nil
+}
+
+// responseLines returns the request (with --verbose), the response and the timing (with --timing) as text
+func responseLines(c *cli.Context, r *Request, resp *Response, colored color.Colorizer) (meta []string, body string) {
+	if c.Bool("verbose") {
+		meta = append(meta, colored.Paint(color.SMagenta, r.Method+" "+r.URL))
+		meta = append(meta, headerLines(resp.Request.Header, colored)...)
+		for _, redirect := range resp.Redirects {
+			meta = append(meta, colored.Paint(color.SMagenta, "redirected from "+redirect))
+		}
+		meta = append(meta, "")
+	}
+	if !c.Bool("body") {
+		meta = append(meta, statusLine(resp, colored))
+		meta = append(meta, headerLines(resp.Header, colored)...)
+		meta = append(meta, "")
+	}
+	if c.Bool("timing") {
+		meta = append(meta, timingLines(resp.Timing, colored)...)
+		meta = append(meta, "")
+	}
+
+	if c.Bool("raw") || c.String("output") != "" {
+		return meta, string(resp.Body)
+	}
+	return meta, formatBody(resp.Header.Get("Content-Type"), resp.Body, colored)
+}
+
+// writeResponse shows the response. If the output is written to a file, only the body is written to it.
+func writeResponse(c *cli.Context, r *Request, resp *Response) error {
+	toFile := c.String("output") != ""
+	colored := color.NewColorizer(!toFile && !c.Bool("raw"))
+
+	meta, body := responseLines(c, r, resp, colored)
+	if toFile {
+		for _, line := range meta {
+			say.Text(line)
+		}
+		meta = nil
+	}
+
+	out, err := utils.CommandOutput(c)
+	if err != nil {
+		return err
+	}
+	if err := out.WriteLines(meta); err != nil {
+		out.Abort()
+		return err
+	}
+	if _, err := io.WriteString(out, body); err != nil {
+		out.Abort()
+		return err
+	}
+	if !toFile && body != "" && !strings.HasSuffix(body, "\n") {
+		_ = out.WriteLine("")
+	}
+	return out.Close()
+}
@@ -0,0 +1,235 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"method":        r.Method,
+			"body":          string(body),
+			"authorization": r.Header.Get("Authorization"),
+			"foo":           r.Header.Get("X-Foo"),
+		})
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/echo", http.StatusFound)
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret", Path: "/"})
+	})
+	mux.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("session"); err != nil || c.Value != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		f, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		defer f.Close()
+		content, _ := ioutil.ReadAll(f)
+		_, _ = w.Write([]byte(r.FormValue("comment") + " " + header.Filename + " " + string(content)))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient(t *testing.T) {
+	server := testServer(t)
+
+	r := NewRequest("post", server.URL+"/echo")
+	r.Header.Set("X-Foo", "bar")
+	r.SetBasicAuth("admin", "secret")
+	r.Body = []byte(`{"a":1}`)
+
+	resp, err := NewClient(Options{MaxRedirects: 10}).Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]string
+	if err := json.Unmarshal(resp.Body, &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"method": "POST", "body": `{"a":1}`, "authorization": "Basic YWRtaW46c2VjcmV0", "foo": "bar"}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s: got %q, want %q", k, got[k], v)
+		}
+	}
+	if resp.Timing.Total <= 0 || resp.Timing.Total < resp.Timing.TTFB {
+		t.Errorf("invalid timing: %+v", resp.Timing)
+	}
+}
+
+func TestRedirects(t *testing.T) {
+	server := testServer(t)
+
+	resp, err := NewClient(Options{MaxRedirects: 10}).Do(NewRequest("GET", server.URL+"/redirect"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || len(resp.Redirects) != 1 {
+		t.Errorf("got status %d and redirects %v", resp.StatusCode, resp.Redirects)
+	}
+
+	resp, err = NewClient(Options{MaxRedirects: 0}).Do(NewRequest("GET", server.URL+"/redirect"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("redirect was followed, got status %d", resp.StatusCode)
+	}
+}
+
+func TestMultipart(t *testing.T) {
+	server := testServer(t)
+
+	path := filepath.Join(t.TempDir(), "report.txt")
+	if err := ioutil.WriteFile(path, []byte("content"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	body, contentType, err := multipartBody([]string{"comment=weekly", "file=@" + path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRequest("POST", server.URL+"/upload")
+	r.Header.Set("Content-Type", contentType)
+	r.Body = body
+	resp, err := NewClient(Options{}).Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Body) != "weekly report.txt content" {
+		t.Errorf("got %q", resp.Body)
+	}
+}
+
+func TestJar(t *testing.T) {
+	server := testServer(t)
+	path := filepath.Join(t.TempDir(), "cookies.json")
+
+	jar, err := LoadJar(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewClient(Options{Jar: jar}).Do(NewRequest("GET", server.URL+"/login")); err != nil {
+		t.Fatal(err)
+	}
+	if err := jar.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new jar loaded from the file sends the saved session cookie
+	jar, err = LoadJar(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := NewClient(Options{Jar: jar}).Do(NewRequest("GET", server.URL+"/private"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("cookie was not sent, got status %d", resp.StatusCode)
+	}
+}
+
+func TestParseRequestFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "body.json"), []byte(`{"file": true}`+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("DOPS_TEST_TOKEN", "from-env")
+	defer os.Unsetenv("DOPS_TEST_TOKEN")
+
+	content := `@host = http://localhost
+@api = {{host}}/api
+
+### login
+POST {{api}}/login HTTP/1.1
+Content-Type: application/json
+Authorization: Basic admin:secret
+
+{"user": "{{user}}"}
+
+###
+# @name users
+GET {{api}}/users
+Authorization: Bearer {{DOPS_TEST_TOKEN}}
+
+###
+PUT {{api}}/file
+
+< ./body.json
+
+### only comments
+# nothing to send
+`
+	requests, err := ParseRequestFile(content, dir, map[string]string{"user": "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 3 {
+		t.Fatalf("got %d requests, want 3", len(requests))
+	}
+
+	login := requests[0]
+	if login.Name != "login" || login.Method != "POST" || login.URL != "http://localhost/api/login" {
+		t.Errorf("unexpected login request: %s %s %s", login.Name, login.Method, login.URL)
+	}
+	if string(login.Body) != `{"user": "bob"}` {
+		t.Errorf("unexpected body: %q", login.Body)
+	}
+	if login.Header.Get("Authorization") != "Basic YWRtaW46c2VjcmV0" {
+		t.Errorf("basic auth was not encoded: %s", login.Header.Get("Authorization"))
+	}
+
+	users := requests[1]
+	if users.Name != "users" || users.Method != "GET" || users.Header.Get("Authorization") != "Bearer from-env" {
+		t.Errorf("unexpected users request: %s %s %v", users.Name, users.Method, users.Header)
+	}
+
+	file := requests[2]
+	if file.Name != "#3" || string(file.Body) != `{"file": true}` {
+		t.Errorf("unexpected file request: %s %q", file.Name, file.Body)
+	}
+
+	if _, err := ParseRequestFile("GET {{undefined_variable}}/", dir, nil); err == nil || !strings.Contains(err.Error(), "undefined_variable") {
+		t.Errorf("expected an error for an undefined variable, got %v", err)
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	// Compressed bodies are sent as they are, like the files of multipart forms
+	compressed := []byte{0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff}
+	path := filepath.Join(t.TempDir(), "body.json.gz")
+	if err := ioutil.WriteFile(path, compressed, 0600); err != nil {
+		t.Fatal(err)
+	}
+	body, err := readBody(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(body, compressed) {
+		t.Errorf("got %v, want %v", body, compressed)
+	}
+	if _, err := readBody(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
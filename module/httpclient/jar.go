@@ -0,0 +1,88 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/dops-cli/dops/utils"
+)
+
+// savedCookie is a cookie together with the URL, which has set it
+type savedCookie struct {
+	URL    string       `json:"url"`
+	Cookie *http.Cookie `json:"cookie"`
+}
+
+// Jar is a cookie jar, which can be loaded from and saved to a JSON file, so that cookies are kept between runs
+type Jar struct {
+	*cookiejar.Jar
+	path    string
+	cookies []savedCookie
+}
+
+// LoadJar loads a cookie jar from path. A missing file results in an empty jar.
+func LoadJar(path string) (*Jar, error) {
+	inner, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	j := &Jar{Jar: inner, path: path}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return j, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var saved []savedCookie
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, err
+	}
+	for _, s := range saved {
+		u, err := url.Parse(s.URL)
+		if err != nil || s.Cookie == nil {
+			continue
+		}
+		j.SetCookies(u, []*http.Cookie{s.Cookie})
+	}
+	return j, nil
+}
+
+// SetCookies stores the cookies in the jar and remembers them for Save
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.Jar.SetCookies(u, cookies)
+
+	for _, c := range cookies {
+		kept := j.cookies[:0]
+		for _, s := range j.cookies {
+			if s.Cookie.Name != c.Name || s.Cookie.Domain != c.Domain || s.Cookie.Path != c.Path || (c.Domain == "" && s.URL != u.String()) {
+				kept = append(kept, s)
+			}
+		}
+		j.cookies = kept
+
+		expired := c.MaxAge < 0 || (!c.Expires.IsZero() && c.Expires.Before(time.Now()))
+		if !expired {
+			j.cookies = append(j.cookies, savedCookie{URL: u.String(), Cookie: c})
+		}
+	}
+}
+
+// Save writes all cookies to the file of the jar
+func (j *Jar) Save() error {
+	if j.cookies == nil {
+		j.cookies = []savedCookie{}
+	}
+	data, err := json.MarshalIndent(j.cookies, "", "  ")
+	if err != nil {
+		return err
+	}
+	return utils.WriteFile(j.path, append(data, '\n'), false)
+}
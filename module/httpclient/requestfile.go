@@ -0,0 +1,218 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	variableDefinition = regexp.MustCompile(`^@([\w.-]+)\s*=\s*(.*)$`)
+	variableReference  = regexp.MustCompile(`{{\s*([\w.$-]+)\s*}}`)
+	nameComment        = regexp.MustCompile(`^(?:#|//)\s*@name\s+(\S+)`)
+	requestLine        = regexp.MustCompile(`^(?:([A-Za-z]+)\s+)?(\S+)(?:\s+HTTP/[\d.]+)?$`)
+)
+
+// maxVariableDepth limits how deep variables can reference other variables
+const maxVariableDepth = 10
+
+// ParseRequestFile parses a request file in the format of .http files, which are known from editor REST clients:
+//
+//	@host = https://api.example.com
+//
+//	### login
+//	POST {{host}}/login
+//	Content-Type: application/json
+//
+//	{"user": "{{user}}"}
+//
+//	###
+//	# @name users
+//	GET {{host}}/users
+//
+// Requests are separated by lines starting with '###', the rest of the line is the name of the request.
+// Variables are defined with '@name = value' and referenced with '{{name}}'. Values in vars override
+// the variables of the file, environment variables are used for variables, which are not defined.
+// A body line like '< ./body.json' is replaced by the content of the file, relative to dir.
+func ParseRequestFile(content, dir string, vars map[string]string) ([]*Request, error) {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+
+	// Variables are collected first, so that they can be used before their definition
+	variables := map[string]string{}
+	for _, line := range lines {
+		if m := variableDefinition.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			variables[m[1]] = strings.TrimSpace(m[2])
+		}
+	}
+	for name, value := range vars {
+		variables[name] = value
+	}
+
+	var requests []*Request
+	var block []string
+	var blockName string
+	blockStart := 1
+	flush := func() error {
+		r, err := parseRequestBlock(block, blockName, dir)
+		if err != nil {
+			return fmt.Errorf("request at line %d: %w", blockStart, err)
+		}
+		if r == nil {
+			return nil
+		}
+		if err := r.expand(variables); err != nil {
+			return fmt.Errorf("request at line %d: %w", blockStart, err)
+		}
+		requests = append(requests, r)
+		return nil
+	}
+
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "###") {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			block = nil
+			blockName = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+			blockStart = i + 1
+			continue
+		}
+		block = append(block, line)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	for i, r := range requests {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("#%d", i+1)
+		}
+	}
+	return requests, nil
+}
+
+// parseRequestBlock parses the lines between two separators. It returns nil, if the block contains no request.
+func parseRequestBlock(lines []string, name, dir string) (*Request, error) {
+	var r *Request
+	var body []string
+	inBody := false
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if inBody {
+			if strings.HasPrefix(trimmed, "< ") {
+				data, err := ioutil.ReadFile(filepath.Join(dir, strings.TrimSpace(trimmed[2:])))
+				if err != nil {
+					return nil, err
+				}
+				body = append(body, strings.TrimSuffix(string(data), "\n"))
+				continue
+			}
+			body = append(body, line)
+			continue
+		}
+
+		if r == nil {
+			if m := nameComment.FindStringSubmatch(trimmed); m != nil {
+				name = m[1]
+				continue
+			}
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") || variableDefinition.MatchString(trimmed) {
+				continue
+			}
+			m := requestLine.FindStringSubmatch(trimmed)
+			if m == nil {
+				return nil, errors.New("invalid request line: " + trimmed)
+			}
+			method := m[1]
+			if method == "" {
+				method = "GET"
+			}
+			r = NewRequest(method, m[2])
+			r.Name = name
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			inBody = true
+		case strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//"):
+		default:
+			parts := strings.SplitN(trimmed, ":", 2)
+			if len(parts) != 2 {
+				return nil, errors.New("invalid header: " + trimmed)
+			}
+			r.Header.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+		}
+	}
+
+	if r == nil {
+		return nil, nil
+	}
+	if b := strings.TrimRight(strings.Join(body, "\n"), " \t\n"); b != "" {
+		r.Body = []byte(b)
+	}
+	return r, nil
+}
+
+// expand replaces all variable references in the URL, headers and body of the request
+func (r *Request) expand(variables map[string]string) error {
+	var err error
+	if r.URL, err = expandVariables(r.URL, variables, 0); err != nil {
+		return err
+	}
+	for name, values := range r.Header {
+		for i, v := range values {
+			if values[i], err = expandVariables(v, variables, 0); err != nil {
+				return err
+			}
+		}
+		r.Header[name] = values
+	}
+
+	// Editor REST clients accept 'Basic user:password', which is encoded here
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Basic ") && strings.Contains(auth, ":") {
+		credentials := strings.SplitN(strings.TrimPrefix(auth, "Basic "), ":", 2)
+		r.SetBasicAuth(strings.TrimSpace(credentials[0]), strings.TrimSpace(credentials[1]))
+	}
+
+	if r.Body != nil {
+		body, err := expandVariables(string(r.Body), variables, 0)
+		if err != nil {
+			return err
+		}
+		r.Body = []byte(body)
+	}
+	return nil
+}
+
+func expandVariables(s string, variables map[string]string, depth int) (string, error) {
+	if depth > maxVariableDepth {
+		return "", errors.New("variables reference each other too deeply: " + s)
+	}
+
+	var err error
+	result := variableReference.ReplaceAllStringFunc(s, func(ref string) string {
+		name := variableReference.FindStringSubmatch(ref)[1]
+		value, ok := variables[name]
+		if !ok {
+			value, ok = os.LookupEnv(name)
+		}
+		if !ok {
+			if err == nil {
+				err = errors.New("undefined variable: " + name)
+			}
+			return ref
+		}
+		expanded, expandErr := expandVariables(value, variables, depth+1)
+		if expandErr != nil && err == nil {
+			err = expandErr
+		}
+		return expanded
+	})
+	return result, err
+}
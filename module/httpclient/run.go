@@ -0,0 +1,172 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http/cookiejar"
+	"path/filepath"
+	"strings"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Run returns the run subcommand
+func Run() *cli.Command {
+	return &cli.Command{
+		Name:      "run",
+		Usage:     "Sends the requests of a .http file",
+		ArgsUsage: "FILE [NAMES...]",
+		// Flags are also accepted after the file and the names
+		InterspersedFlags: true,
+		Description: `Run sends all requests of a request file in order, or only the requests with the given names.
+A request fails if it can't be sent or the response status is 400 or higher, run exits with status 1 if any request failed.
+This way request files can be used as API smoke tests in a repository.
+
+Request files use the format of editor REST clients:
+
+  @host = https://api.example.com
+
+  ### login
+  POST {{host}}/login
+  Content-Type: application/json
+
+  {"user": "{{user}}", "password": "{{PASSWORD}}"}
+
+  ### users
+  GET {{host}}/users
+  Authorization: Basic admin:secret
+
+Requests are separated by '###', followed by the name of the request. '# @name NAME' can be used as well.
+Variables are defined with '@name = value', can be set with --var and fall back to environment variables.
+A body line like '< ./body.json' is replaced by the content of the file.
+Cookies are shared between the requests of a run.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Send all requests of a file",
+				Usage:            "dops http run api.http",
+			},
+			{
+				ShortDescription: "Send only the login request against a local server",
+				Usage:            "dops http run --var host=http://localhost:8080 api.http login",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() == 0 {
+				return errors.New("no request file given")
+			}
+			path := c.Args().First()
+			content, err := utils.ReadInput(path)
+			if err != nil {
+				return err
+			}
+
+			vars := map[string]string{}
+			for _, v := range c.StringSlice("var") {
+				parts := strings.SplitN(v, "=", 2)
+				if len(parts) != 2 {
+					return errors.New("variable must be NAME=VALUE: " + v)
+				}
+				vars[parts[0]] = parts[1]
+			}
+
+			requests, err := ParseRequestFile(content, filepath.Dir(path), vars)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if names := c.Args().Tail(); len(names) > 0 {
+				var selected []*Request
+				for _, r := range requests {
+					if utils.SliceContainsString(names, r.Name) {
+						selected = append(selected, r)
+					}
+				}
+				requests = selected
+			}
+			if len(requests) == 0 {
+				return errors.New("no requests found in " + path)
+			}
+
+			client, jar, err := clientFromFlags(c)
+			if err != nil {
+				return err
+			}
+			if jar == nil {
+				memory, err := cookiejar.New(nil)
+				if err != nil {
+					return err
+				}
+				client.client.Jar = memory
+			}
+
+			failed := 0
+			for _, r := range requests {
+				if !sendRequest(c, client, r) {
+					failed++
+					if c.Bool("fail-fast") {
+						break
+					}
+				}
+			}
+			if jar != nil {
+				if err := jar.Save(); err != nil {
+					return err
+				}
+			}
+
+			if failed > 0 {
+				return cli.Exit(fmt.Sprintf("%d of %d requests failed", failed, len(requests)), 1)
+			}
+			return nil
+		},
+		Flags: append([]cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "var",
+				Aliases: []string{"V"},
+				Usage:   "Sets the variable `NAME=VALUE`, can be used multiple times",
+			},
+			&cli.BoolFlag{
+				Name:    "fail-fast",
+				Aliases: []string{"x"},
+				Usage:   "Stops at the first failed request",
+			},
+		}, clientFlags()...),
+	}
+}
+
+// sendRequest sends a request of a request file and shows the result. It returns false if the request failed.
+func sendRequest(c *cli.Context, client *Client, r *Request) bool {
+	summary := fmt.Sprintf("%s: %s %s", r.Name, r.Method, r.URL)
+	resp, err := client.Do(r)
+	if err != nil {
+		say.Error(summary + ": " + err.Error())
+		return false
+	}
+
+	summary += fmt.Sprintf(" -> %s (%s)", resp.Status, formatDuration(resp.Timing.Total))
+	ok := resp.StatusCode < 400
+	if ok {
+		say.Success(summary)
+	} else {
+		say.Error(summary)
+	}
+
+	colored := color.NewColorizer(!c.Bool("raw"))
+	switch {
+	case c.Bool("verbose"):
+		meta, body := responseLines(c, r, resp, colored)
+		for _, line := range meta {
+			say.Text(line)
+		}
+		if body != "" {
+			say.Text(strings.TrimSuffix(body, "\n"))
+		}
+	case c.Bool("timing"):
+		for _, line := range timingLines(resp.Timing, colored) {
+			say.Text(line)
+		}
+	}
+	return ok
+}
@@ -0,0 +1,141 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/dops-cli/dops/module/cert"
+	"github.com/dops-cli/dops/utils"
+)
+
+// jwk is a JSON Web Key (RFC 7517)
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC and OKP
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	// oct
+	K string `json:"k"`
+}
+
+// LoadJWKS reads the keys of a JSON Web Key Set, or a single JSON Web Key, from a file or URL
+func LoadJWKS(path string) ([]Key, error) {
+	content, err := utils.ReadInput(path)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal([]byte(content), &set); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if set.Keys == nil {
+		var single jwk
+		if err := json.Unmarshal([]byte(content), &single); err != nil || single.Kty == "" {
+			return nil, fmt.Errorf("%s contains no JSON Web Keys", path)
+		}
+		set.Keys = []jwk{single}
+	}
+
+	keys := make([]Key, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		value, err := k.key()
+		if err != nil {
+			return nil, fmt.Errorf("%s: key %q: %w", path, k.Kid, err)
+		}
+		keys = append(keys, Key{ID: k.Kid, Value: value})
+	}
+	return keys, nil
+}
+
+func decodeInt(s string) (*big.Int, error) {
+	b, err := encoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func (k jwk) key() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		curves := map[string]elliptic.Curve{"P-256": elliptic.P256(), "P-384": elliptic.P384(), "P-521": elliptic.P521()}
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("the point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := encoding.DecodeString(strings.TrimRight(k.X, "="))
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	case "oct":
+		return encoding.DecodeString(strings.TrimRight(k.K, "="))
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// LoadKey reads a key from a PEM or DER file. Certificates and private keys are accepted as well as public keys.
+func LoadKey(path string, private bool) (interface{}, error) {
+	objects, err := cert.LoadObjects(path, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range objects {
+		if private {
+			if o.Key != nil {
+				return o.Key, nil
+			}
+			continue
+		}
+		if pub := o.PublicKey(); pub != nil {
+			return pub, nil
+		}
+	}
+	if private {
+		return nil, fmt.Errorf("%s contains no private key", path)
+	}
+	return nil, fmt.Errorf("%s contains no key", path)
+}
@@ -0,0 +1,334 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256" // registers SHA-256 for crypto.Hash
+	_ "crypto/sha512" // registers SHA-384 and SHA-512 for crypto.Hash
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Token is a decoded JSON Web Token
+type Token struct {
+	Header    map[string]interface{}
+	Claims    map[string]interface{}
+	Signature []byte
+	// signed is the encoded header and claims, which the signature covers
+	signed string
+}
+
+var encoding = base64.RawURLEncoding
+
+// Parse decodes a token without verifying it
+func Parse(token string) (*Token, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("a token has 3 parts separated by dots, not %d", len(parts))
+	}
+	t := &Token{signed: parts[0] + "." + parts[1]}
+	if err := decodePart(parts[0], &t.Header); err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+	if err := decodePart(parts[1], &t.Claims); err != nil {
+		return nil, fmt.Errorf("invalid claims: %w", err)
+	}
+	signature, err := encoding.DecodeString(strings.TrimRight(parts[2], "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	t.Signature = signature
+	return t, nil
+}
+
+func decodePart(part string, v interface{}) error {
+	data, err := encoding.DecodeString(strings.TrimRight(part, "="))
+	if err != nil {
+		return err
+	}
+	// Numbers are kept as they are, so that large IDs don't lose precision
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// Algorithm returns the alg header
+func (t *Token) Algorithm() string {
+	alg, _ := t.Header["alg"].(string)
+	return alg
+}
+
+// KeyID returns the kid header
+func (t *Token) KeyID() string {
+	kid, _ := t.Header["kid"].(string)
+	return kid
+}
+
+// Time returns a time claim like exp, iat or nbf
+func (t *Token) Time(claim string) (time.Time, bool) {
+	n, ok := t.Claims[claim].(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
+
+// ValidateTime checks, that the token is not expired and already valid. Leeway allows for clock skew.
+func (t *Token) ValidateTime(now time.Time, leeway time.Duration) error {
+	if exp, ok := t.Time("exp"); ok && !now.Before(exp.Add(leeway)) {
+		return fmt.Errorf("the token expired %s", relative(exp, now))
+	}
+	if nbf, ok := t.Time("nbf"); ok && now.Before(nbf.Add(-leeway)) {
+		return fmt.Errorf("the token is not valid before %s", nbf.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Key is a key, which verifies or signs tokens. Value is a []byte secret for HMAC, a public key for verifying or a private key for signing.
+type Key struct {
+	ID    string
+	Value interface{}
+}
+
+// Verify checks the signature with the keys. If the token has a key ID, keys with another ID are skipped.
+// It returns the key, which verified the signature.
+func (t *Token) Verify(keys []Key) (Key, error) {
+	alg := t.Algorithm()
+	if alg == "" || strings.EqualFold(alg, "none") {
+		return Key{}, errors.New("unsigned tokens are never valid")
+	}
+	var lastErr error
+	tried := 0
+	for _, key := range keys {
+		if kid := t.KeyID(); kid != "" && key.ID != "" && key.ID != kid {
+			continue
+		}
+		tried++
+		if lastErr = verify(alg, key.Value, t.signed, t.Signature); lastErr == nil {
+			return key, nil
+		}
+	}
+	if tried == 0 {
+		return Key{}, fmt.Errorf("no key has the ID %q", t.KeyID())
+	}
+	return Key{}, lastErr
+}
+
+var errInvalidSignature = errors.New("the signature is invalid")
+
+// algorithm is a signature algorithm. Family is HS, RS, PS, ES or EdDSA.
+type algorithm struct {
+	family string
+	hash   crypto.Hash
+	// curve is the curve, which ES algorithms require
+	curve elliptic.Curve
+}
+
+// algorithms are the supported algorithms by their alg header
+var algorithms = map[string]algorithm{
+	"HS256": {"HS", crypto.SHA256, nil},
+	"HS384": {"HS", crypto.SHA384, nil},
+	"HS512": {"HS", crypto.SHA512, nil},
+	"RS256": {"RS", crypto.SHA256, nil},
+	"RS384": {"RS", crypto.SHA384, nil},
+	"RS512": {"RS", crypto.SHA512, nil},
+	"PS256": {"PS", crypto.SHA256, nil},
+	"PS384": {"PS", crypto.SHA384, nil},
+	"PS512": {"PS", crypto.SHA512, nil},
+	"ES256": {"ES", crypto.SHA256, elliptic.P256()},
+	"ES384": {"ES", crypto.SHA384, elliptic.P384()},
+	"ES512": {"ES", crypto.SHA512, elliptic.P521()},
+	"EdDSA": {family: "EdDSA"},
+}
+
+func algorithmOf(alg string) (algorithm, error) {
+	a, ok := algorithms[alg]
+	if !ok {
+		return a, fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	return a, nil
+}
+
+func digest(hash crypto.Hash, input string) []byte {
+	h := hash.New()
+	h.Write([]byte(input))
+	return h.Sum(nil)
+}
+
+func verify(alg string, key interface{}, input string, signature []byte) error {
+	a, err := algorithmOf(alg)
+	if err != nil {
+		return err
+	}
+	hash := a.hash
+	switch a.family {
+	case "EdDSA":
+		pub, ok := publicKey(key).(ed25519.PublicKey)
+		if !ok {
+			return errors.New("EdDSA needs an Ed25519 key")
+		}
+		if !ed25519.Verify(pub, []byte(input), signature) {
+			return errInvalidSignature
+		}
+		return nil
+	case "HS":
+		secret, ok := key.([]byte)
+		if !ok {
+			return fmt.Errorf("%s needs a secret", alg)
+		}
+		mac := hmac.New(hash.New, secret)
+		mac.Write([]byte(input))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errInvalidSignature
+		}
+		return nil
+	case "RS", "PS":
+		pub, ok := publicKey(key).(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s needs an RSA key", alg)
+		}
+		if a.family == "PS" {
+			err = rsa.VerifyPSS(pub, hash, digest(hash, input), signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+		} else {
+			err = rsa.VerifyPKCS1v15(pub, hash, digest(hash, input), signature)
+		}
+		if err != nil {
+			return errInvalidSignature
+		}
+		return nil
+	case "ES":
+		pub, ok := publicKey(key).(*ecdsa.PublicKey)
+		if !ok || pub.Curve != a.curve {
+			return fmt.Errorf("%s needs an ECDSA key with the curve %s", alg, a.curve.Params().Name)
+		}
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errInvalidSignature
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest(hash, input), r, s) {
+			return errInvalidSignature
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported algorithm %q", alg)
+}
+
+// publicKey returns the public key of private keys, so that a private key can verify its own tokens
+func publicKey(key interface{}) interface{} {
+	if signer, ok := key.(crypto.Signer); ok {
+		return signer.Public()
+	}
+	return key
+}
+
+// Sign creates a token with the claims, which is signed with the key
+func Sign(alg string, key Key, claims map[string]interface{}) (string, error) {
+	header := map[string]interface{}{"alg": alg, "typ": "JWT"}
+	if key.ID != "" {
+		header["kid"] = key.ID
+	}
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	input := encoding.EncodeToString(headerJSON) + "." + encoding.EncodeToString(claimsJSON)
+	signature, err := sign(alg, key.Value, input)
+	if err != nil {
+		return "", err
+	}
+	return input + "." + encoding.EncodeToString(signature), nil
+}
+
+func sign(alg string, key interface{}, input string) ([]byte, error) {
+	a, err := algorithmOf(alg)
+	if err != nil {
+		return nil, err
+	}
+	hash := a.hash
+	switch a.family {
+	case "EdDSA":
+		private, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("EdDSA needs an Ed25519 private key")
+		}
+		return ed25519.Sign(private, []byte(input)), nil
+	case "HS":
+		secret, ok := key.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("%s needs a secret", alg)
+		}
+		mac := hmac.New(hash.New, secret)
+		mac.Write([]byte(input))
+		return mac.Sum(nil), nil
+	case "RS", "PS":
+		private, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s needs an RSA private key", alg)
+		}
+		if a.family == "PS" {
+			return rsa.SignPSS(rand.Reader, private, hash, digest(hash, input), &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.SignPKCS1v15(rand.Reader, private, hash, digest(hash, input))
+	case "ES":
+		private, ok := key.(*ecdsa.PrivateKey)
+		if !ok || private.Curve != a.curve {
+			return nil, fmt.Errorf("%s needs an ECDSA private key with the curve %s", alg, a.curve.Params().Name)
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, private, digest(hash, input))
+		if err != nil {
+			return nil, err
+		}
+		// JWS signatures are the fixed size concatenation of r and s, not ASN.1
+		size := (private.Curve.Params().BitSize + 7) / 8
+		signature := make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+		return signature, nil
+	}
+	return nil, fmt.Errorf("unsupported algorithm %q", alg)
+}
+
+// relative describes a time relative to now, like 'in 3h0m' or '2 days ago'
+func relative(t, now time.Time) string {
+	d := t.Sub(now)
+	if d > -time.Second && d < time.Second {
+		return "now"
+	}
+	format := "in %s"
+	if d < 0 {
+		d = -d
+		format = "%s ago"
+	}
+	var s string
+	switch {
+	case d >= 48*time.Hour:
+		s = fmt.Sprintf("%d days", int(d/(24*time.Hour)))
+	case d >= time.Minute:
+		s = d.Truncate(time.Minute).String()
+		s = strings.TrimSuffix(s, "0s")
+	default:
+		s = d.Truncate(time.Second).String()
+	}
+	return fmt.Sprintf(format, s)
+}
@@ -2,8 +2,6 @@ package module
 
 import (
 	"errors"
-	"os"
-	"os/exec"
 	"strings"
 
 	"github.com/pterm/pterm"
@@ -22,10 +20,29 @@ import (
 	"github.com/dops-cli/dops/flags/debug"
 	"github.com/dops-cli/dops/flags/raw"
 	"github.com/dops-cli/dops/global"
+	"github.com/dops-cli/dops/module/archive"
+	"github.com/dops-cli/dops/module/bench"
 	"github.com/dops-cli/dops/module/bulkdownload"
+	"github.com/dops-cli/dops/module/cert"
+	"github.com/dops-cli/dops/module/convert"
+	"github.com/dops-cli/dops/module/cron"
+	"github.com/dops-cli/dops/module/csvtool"
+	"github.com/dops-cli/dops/module/diff"
+	"github.com/dops-cli/dops/module/dns"
 	"github.com/dops-cli/dops/module/extract"
+	"github.com/dops-cli/dops/module/healthcheck"
+	"github.com/dops-cli/dops/module/httpclient"
+	"github.com/dops-cli/dops/module/jwt"
+	"github.com/dops-cli/dops/module/portscan"
+	"github.com/dops-cli/dops/module/query"
 	"github.com/dops-cli/dops/module/renamefiles"
+	"github.com/dops-cli/dops/module/serve"
+	"github.com/dops-cli/dops/module/textstats"
+	"github.com/dops-cli/dops/module/timetool"
+	"github.com/dops-cli/dops/module/tlstool"
 	"github.com/dops-cli/dops/module/update"
+	"github.com/dops-cli/dops/module/watch"
+	"github.com/dops-cli/dops/utils"
 )
 
 // * <<< Add modules and global flags here! >>> *
@@ -42,11 +59,29 @@ func init() {
 	addModule(update.Module{})
 	// addModule(demo.Module{})
 	addModule(renamefiles.Module{})
+	addModule(archive.Module{})
 	addModule(ping.Module{})
+	addModule(portscan.Module{})
+	addModule(dns.Module{})
+	addModule(tlstool.Module{})
+	addModule(cert.Module{})
+	addModule(jwt.Module{})
 	addModule(randomgenerator.Module{})
 	addModule(open.Module{})
 	addModule(echo.Module{})
 	addModule(image.Module{})
+	addModule(convert.Module{})
+	addModule(query.Module{})
+	addModule(csvtool.Module{})
+	addModule(textstats.Module{})
+	addModule(timetool.Module{})
+	addModule(cron.Module{})
+	addModule(diff.Module{})
+	addModule(httpclient.Module{})
+	addModule(serve.Module{})
+	addModule(bench.Module{})
+	addModule(healthcheck.Module{})
+	addModule(watch.Module{})
 
 	addModule(ci.Module{})
 }
@@ -57,10 +92,7 @@ var CliApp *cli.App
 // Run runs a specific module with specific flags
 func Run(flags []string) error {
 
-	pterm.Println("\033[2J")
-	clear := exec.Command("clear")
-	clear.Stdout = os.Stdout
-	_ = clear.Run()
+	utils.ClearScreen()
 
 	args := []string{"dops"}
 	args = append(args, flags...)
@@ -22,7 +22,7 @@ func (Module) GetModuleCommands() []*cli.Command {
 			Name:        "ping",
 			Usage:       "Ping a host",
 			Description: "Ping pings a host on the web via ICMP",
-			Category:    categories.Statistics,
+			Category:    categories.Network,
 			Action: func(context *cli.Context) error {
 				host := context.String("host")
 				count := context.Int("count")
@@ -0,0 +1,196 @@
+package portscan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/global/options"
+	"github.com/dops-cli/dops/progressbar"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:      "portscan",
+			Aliases:   []string{"scan"},
+			Usage:     "Scans hosts for open TCP ports",
+			ArgsUsage: "[TARGETS...]",
+			// Flags are also accepted after the targets
+			InterspersedFlags: true,
+			Description: `Portscan connects to the ports of the targets and reports the open ports with the service, which usually listens on them.
+Targets can be hostnames, IP addresses, CIDRs like 10.0.0.0/24 and ranges like 10.0.0.1-20 or 10.0.0.1-10.0.1.5.
+They can be given with --target or as arguments. Ports are lists like 22,80,8000-8100, 'top' stands for the ports of about 80 well known services.
+
+With --banner the first line, which a service sends, is read. Services, which wait for the client, get an HTTP request,
+so that web servers answer with their status line.
+
+Only scan hosts, which you are allowed to scan.`,
+			Category: categories.Network,
+			Examples: []cli.Example{
+				{
+					ShortDescription: "Scan the first 1024 ports and 8080 of a network",
+					Usage:            "dops portscan --target 10.0.0.0/24 --ports 1-1024,8080",
+				},
+				{
+					ShortDescription: "Scan the well known ports of localhost and grab banners",
+					Usage:            "dops portscan --banner localhost",
+				},
+				{
+					ShortDescription: "Scan slowly with at most 50 connections per second and output JSON",
+					Usage:            "dops portscan --rate 50 --json --target 192.168.1.1-50",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				hosts, err := ExpandTargets(append(utils.StringList(c, "target"), c.Args().Slice()...))
+				if err != nil {
+					return err
+				}
+				ports, err := ParsePorts(c.String("ports"))
+				if err != nil {
+					return err
+				}
+				if c.Float64("rate") < 0 {
+					return errors.New("--rate must not be negative")
+				}
+				o := Options{
+					Timeout:       c.Duration("timeout"),
+					Concurrency:   c.Int("concurrency"),
+					Rate:          c.Float64("rate"),
+					Banner:        c.Bool("banner"),
+					BannerTimeout: c.Duration("banner-timeout"),
+				}
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				interrupt := make(chan os.Signal, 1)
+				signal.Notify(interrupt, os.Interrupt)
+				defer signal.Stop(interrupt)
+				go func() {
+					select {
+					case <-interrupt:
+						cancel()
+					case <-ctx.Done():
+					}
+				}()
+
+				// The bar is not rendered with --raw or --ci, so it would never finish
+				var bar *progressbar.Bar
+				if !c.Bool("quiet") && !options.Raw && !options.CI {
+					bar = say.ProgressBar(int64(len(hosts) * len(ports)))
+					o.OnAttempt = bar.Increment
+				}
+				results := Scan(ctx, hosts, ports, o)
+				if bar != nil {
+					bar.SetTotal(0, true)
+					bar.GetContainer().Wait()
+				}
+
+				if c.Bool("json") {
+					data, err := json.MarshalIndent(toJSON(results), "", "  ")
+					if err != nil {
+						return err
+					}
+					return utils.WriteOutput(c, []string{string(data)})
+				}
+				if len(results) == 0 {
+					say.Info("No open ports found")
+					return nil
+				}
+				return utils.WriteOutput(c, []string{table(results, o.Banner, color.NewColorizer(c.String("output") == ""))})
+			},
+			Flags: append([]cli.Flag{
+				&cli.StringSliceFlag{
+					Name:    "target",
+					Aliases: []string{"t"},
+					Usage:   "Scans `TARGETS`, separated by commas, can be used multiple times",
+				},
+				&cli.StringFlag{
+					Name:    "ports",
+					Aliases: []string{"p"},
+					Usage:   "Scans the `PORTS`, like 22,80,8000-8100 or top",
+					Value:   "top",
+				},
+				&cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "Gives up connecting after `DURATION`",
+					Value: time.Second,
+				},
+				&cli.IntFlag{
+					Name:    "concurrency",
+					Aliases: []string{"c"},
+					Usage:   "Opens up to `N` connections at once",
+					Value:   100,
+				},
+				&cli.Float64Flag{
+					Name:    "rate",
+					Aliases: []string{"r"},
+					Usage:   "Opens at most `N` connections per second, 0 is unlimited",
+				},
+				&cli.BoolFlag{
+					Name:    "banner",
+					Aliases: []string{"b"},
+					Usage:   "Grabs the banners of open ports",
+				},
+				&cli.DurationFlag{
+					Name:  "banner-timeout",
+					Usage: "Waits up to `DURATION` for a banner",
+					Value: 2 * time.Second,
+				},
+				&cli.BoolFlag{
+					Name:    "json",
+					Aliases: []string{"j"},
+					Usage:   "Outputs the open ports as JSON",
+				},
+				&cli.BoolFlag{
+					Name:    "quiet",
+					Aliases: []string{"q"},
+					Usage:   "Does not show a progress bar",
+				},
+			}, utils.OutputFlags()...),
+		},
+	}
+}
+
+func table(results []Result, banner bool, colored color.Colorizer) string {
+	header := []string{"Host", "Port", "Service", "Latency"}
+	if banner {
+		header = append(header, "Banner")
+	}
+	data := [][]string{header}
+	for _, r := range results {
+		row := []string{r.Host, strconv.Itoa(r.Port), r.Service, r.Latency.Round(10 * time.Microsecond).String()}
+		if banner {
+			row = append(row, r.Banner)
+		}
+		data = append(data, row)
+	}
+	return say.Table(data, true, colored)
+}
+
+// resultJSON is the JSON form of an open port
+type resultJSON struct {
+	Result
+	Latency float64 `json:"latency_ms"`
+}
+
+func toJSON(results []Result) []resultJSON {
+	out := make([]resultJSON, len(results))
+	for i, r := range results {
+		out[i] = resultJSON{r, float64(r.Latency) / float64(time.Millisecond)}
+	}
+	return out
+}
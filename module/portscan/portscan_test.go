@@ -0,0 +1,153 @@
+package portscan
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/global/options"
+)
+
+func TestExpandTargets(t *testing.T) {
+	tests := []struct {
+		specs []string
+		want  []string
+	}{
+		{[]string{"10.0.0.0/30"}, []string{"10.0.0.1", "10.0.0.2"}},
+		{[]string{"10.0.0.7/32", "example.com"}, []string{"10.0.0.7", "example.com"}},
+		{[]string{"10.0.0.254-10.0.1.1"}, []string{"10.0.0.254", "10.0.0.255", "10.0.1.0", "10.0.1.1"}},
+		{[]string{"192.168.1.3-5", "192.168.1.4"}, []string{"192.168.1.3", "192.168.1.4", "192.168.1.5"}},
+		{[]string{"2001:db8::/127"}, []string{"2001:db8::", "2001:db8::1"}},
+		{[]string{"my-host.local"}, []string{"my-host.local"}},
+	}
+	for _, tt := range tests {
+		got, err := ExpandTargets(tt.specs)
+		if err != nil {
+			t.Errorf("%v: %v", tt.specs, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: got %v, want %v", tt.specs, got, tt.want)
+		}
+	}
+
+	for _, invalid := range []string{"10.0.0.0/8", "10.0.0.5-3", "10.0.0.1-300", "10.0.0.0/33"} {
+		if _, err := ExpandTargets([]string{invalid}); err == nil {
+			t.Errorf("expected an error for %s", invalid)
+		}
+	}
+}
+
+func TestParsePorts(t *testing.T) {
+	got, err := ParsePorts("8080, 22,80-82,81")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{22, 80, 81, 82, 8080}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if top, err := ParsePorts("top"); err != nil || len(top) != len(services) {
+		t.Errorf("top has %d ports, %v", len(top), err)
+	}
+	for _, invalid := range []string{"0", "65536", "90-80", "http", ""} {
+		if _, err := ParsePorts(invalid); err == nil {
+			t.Errorf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	listen := func(banner string) (net.Listener, int) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			for {
+				conn, err := l.Accept()
+				if err != nil {
+					return
+				}
+				if banner != "" {
+					_, _ = conn.Write([]byte(banner))
+				}
+				conn.Close()
+			}
+		}()
+		return l, l.Addr().(*net.TCPAddr).Port
+	}
+	ssh, sshPort := listen("SSH-2.0-Test\r\nmore data")
+	defer ssh.Close()
+	silent, silentPort := listen("")
+	defer silent.Close()
+	closed, closedPort := listen("")
+	closed.Close()
+
+	results := Scan(context.Background(), []string{"127.0.0.1"}, []int{closedPort, sshPort, silentPort}, Options{
+		Timeout:       time.Second,
+		Concurrency:   2,
+		Banner:        true,
+		BannerTimeout: 200 * time.Millisecond,
+	})
+	if len(results) != 2 {
+		t.Fatalf("got %d open ports, want 2: %+v", len(results), results)
+	}
+	for _, r := range results {
+		switch r.Port {
+		case sshPort:
+			if r.Banner != "SSH-2.0-Test" {
+				t.Errorf("got banner %q", r.Banner)
+			}
+		case silentPort:
+			if r.Banner != "" {
+				t.Errorf("got banner %q from a silent port", r.Banner)
+			}
+		default:
+			t.Errorf("port %d is not open", r.Port)
+		}
+	}
+}
+
+func TestActionInRawMode(t *testing.T) {
+	// The progress bar isn't rendered in raw mode, waiting for it would never return
+	options.Raw = true
+	defer func() { options.Raw = false }()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	port := strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+	output := filepath.Join(t.TempDir(), "ports.json")
+
+	// Flags are accepted before and after the targets
+	for _, args := range [][]string{{"-p", port, "--json", "-o", output, "127.0.0.1"}, {"127.0.0.1", "--ports", port, "--json", "-o", output}} {
+		app := &cli.App{Commands: Module{}.GetModuleCommands()}
+		done := make(chan error, 1)
+		go func() { done <- app.Run(append([]string{"dops", "portscan"}, args...)) }()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("portscan did not return in raw mode")
+		}
+
+		data, err := ioutil.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), `"port": `+port) {
+			t.Errorf("%v: open port is missing in %s", args, data)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package portscan
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// maxBannerLength limits the length of grabbed banners
+const maxBannerLength = 80
+
+// Options configure a scan
+type Options struct {
+	Timeout     time.Duration
+	Concurrency int
+	// Rate limits the connection attempts per second, 0 means unlimited
+	Rate float64
+	// Banner reads the first line, which the service sends after connecting
+	Banner        bool
+	BannerTimeout time.Duration
+	// OnAttempt is called after every connection attempt, it must be safe for concurrent use
+	OnAttempt func()
+}
+
+// Result is an open port
+type Result struct {
+	Host    string        `json:"host"`
+	Port    int           `json:"port"`
+	Service string        `json:"service,omitempty"`
+	Banner  string        `json:"banner,omitempty"`
+	Latency time.Duration `json:"-"`
+}
+
+type job struct {
+	host string
+	port int
+}
+
+// Scan connects to all ports of all hosts and returns the open ports, sorted by host and port
+func Scan(ctx context.Context, hosts []string, ports []int, o Options) []Result {
+	if o.Concurrency < 1 {
+		o.Concurrency = 1
+	}
+
+	jobs := make(chan job)
+	go func() {
+		defer close(jobs)
+		var tokens <-chan time.Time
+		if o.Rate > 0 {
+			ticker := time.NewTicker(time.Duration(float64(time.Second) / o.Rate))
+			defer ticker.Stop()
+			tokens = ticker.C
+		}
+		// Ports are the outer loop, so that a host doesn't get all connections at once
+		for _, port := range ports {
+			for _, host := range hosts {
+				if tokens != nil {
+					select {
+					case <-tokens:
+					case <-ctx.Done():
+						return
+					}
+				}
+				select {
+				case jobs <- job{host, port}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	var mutex sync.Mutex
+	var results []Result
+	var wg sync.WaitGroup
+	for i := 0; i < o.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if r, open := probe(ctx, j, o); open {
+					mutex.Lock()
+					results = append(results, r)
+					mutex.Unlock()
+				}
+				if o.OnAttempt != nil {
+					o.OnAttempt()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Host != results[j].Host {
+			return hostLess(results[i].Host, results[j].Host)
+		}
+		return results[i].Port < results[j].Port
+	})
+	return results
+}
+
+// hostLess sorts IP addresses numerically and before hostnames
+func hostLess(a, b string) bool {
+	ipA, ipB := normalizeIP(net.ParseIP(a)), normalizeIP(net.ParseIP(b))
+	switch {
+	case ipA != nil && ipB != nil:
+		return compareIPs(ipA, ipB) < 0
+	case ipA != nil || ipB != nil:
+		return ipA != nil
+	}
+	return a < b
+}
+
+// probe connects to a port and grabs its banner
+func probe(ctx context.Context, j job, o Options) (Result, bool) {
+	dialer := net.Dialer{Timeout: o.Timeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(j.host, strconv.Itoa(j.port)))
+	if err != nil {
+		return Result{}, false
+	}
+	defer conn.Close()
+
+	r := Result{Host: j.host, Port: j.port, Service: Service(j.port), Latency: time.Since(start)}
+	if o.Banner {
+		r.Banner = grabBanner(conn, o.BannerTimeout)
+	}
+	return r, true
+}
+
+// grabBanner waits for the service to send a banner, like SSH, SMTP and FTP servers do.
+// If the service stays silent, it sends an HTTP request, so that web servers answer with their status line.
+func grabBanner(conn net.Conn, timeout time.Duration) string {
+	read := func() string {
+		_ = conn.SetReadDeadline(time.Now().Add(timeout))
+		buf := make([]byte, 512)
+		n, _ := conn.Read(buf)
+		return cleanBanner(string(buf[:n]))
+	}
+
+	if banner := read(); banner != "" {
+		return banner
+	}
+	_ = conn.SetWriteDeadline(time.Now().Add(timeout))
+	if _, err := conn.Write([]byte("HEAD / HTTP/1.0\r\n\r\n")); err != nil {
+		return ""
+	}
+	return read()
+}
+
+// cleanBanner returns the first line of a banner without control characters
+func cleanBanner(banner string) string {
+	if i := strings.IndexAny(banner, "\r\n"); i >= 0 {
+		banner = banner[:i]
+	}
+	banner = strings.Map(func(r rune) rune {
+		if unicode.IsPrint(r) {
+			return r
+		}
+		return -1
+	}, banner)
+	banner = strings.TrimSpace(banner)
+	if runes := []rune(banner); len(runes) > maxBannerLength {
+		banner = string(runes[:maxBannerLength]) + "..."
+	}
+	return banner
+}
@@ -0,0 +1,89 @@
+package portscan
+
+// services maps well known TCP ports to the service, which usually listens on them.
+// It's used to guess the service of open ports and as port list for 'top'.
+var services = map[int]string{
+	20:    "ftp-data",
+	21:    "ftp",
+	22:    "ssh",
+	23:    "telnet",
+	25:    "smtp",
+	53:    "dns",
+	80:    "http",
+	88:    "kerberos",
+	110:   "pop3",
+	111:   "rpcbind",
+	135:   "msrpc",
+	139:   "netbios-ssn",
+	143:   "imap",
+	161:   "snmp",
+	179:   "bgp",
+	389:   "ldap",
+	443:   "https",
+	445:   "smb",
+	465:   "smtps",
+	514:   "syslog",
+	515:   "printer",
+	548:   "afp",
+	554:   "rtsp",
+	587:   "submission",
+	631:   "ipp",
+	636:   "ldaps",
+	873:   "rsync",
+	993:   "imaps",
+	995:   "pop3s",
+	1080:  "socks",
+	1194:  "openvpn",
+	1433:  "mssql",
+	1521:  "oracle",
+	1723:  "pptp",
+	1883:  "mqtt",
+	2049:  "nfs",
+	2181:  "zookeeper",
+	2375:  "docker",
+	2376:  "docker-tls",
+	2379:  "etcd",
+	3000:  "http-dev",
+	3128:  "squid",
+	3306:  "mysql",
+	3389:  "rdp",
+	4222:  "nats",
+	4369:  "epmd",
+	5000:  "http-alt",
+	5060:  "sip",
+	5432:  "postgresql",
+	5601:  "kibana",
+	5672:  "amqp",
+	5900:  "vnc",
+	5984:  "couchdb",
+	6379:  "redis",
+	6443:  "kubernetes",
+	6667:  "irc",
+	7001:  "weblogic",
+	8000:  "http-alt",
+	8008:  "http-alt",
+	8080:  "http-proxy",
+	8081:  "http-alt",
+	8086:  "influxdb",
+	8443:  "https-alt",
+	8500:  "consul",
+	8883:  "mqtts",
+	8888:  "http-alt",
+	9000:  "http-alt",
+	9042:  "cassandra",
+	9090:  "prometheus",
+	9092:  "kafka",
+	9100:  "node-exporter",
+	9200:  "elasticsearch",
+	9300:  "elasticsearch-nodes",
+	9418:  "git",
+	10250: "kubelet",
+	11211: "memcached",
+	15672: "rabbitmq-mgmt",
+	27017: "mongodb",
+}
+
+// Service guesses the service of a port from the built-in port table
+func Service(port int) string {
+	return services[port]
+}
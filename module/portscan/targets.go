@@ -0,0 +1,179 @@
+package portscan
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxTargets limits how many hosts a scan can expand to, so that a typo like /8 doesn't start a scan of millions of hosts
+const MaxTargets = 65536
+
+// ExpandTargets expands target specifications to hosts. A target can be a hostname, an IP address,
+// a CIDR like 10.0.0.0/24, or a range like 10.0.0.1-20 or 10.0.0.1-10.0.1.5.
+// The network and broadcast addresses of IPv4 networks larger than /31 are skipped.
+func ExpandTargets(specs []string) ([]string, error) {
+	var hosts []string
+	seen := map[string]bool{}
+	add := func(host string) error {
+		if seen[host] {
+			return nil
+		}
+		if len(hosts) >= MaxTargets {
+			return fmt.Errorf("targets expand to more than %d hosts", MaxTargets)
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+		return nil
+	}
+
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+
+		var first, last net.IP
+		switch {
+		case strings.Contains(spec, "/"):
+			ip, network, err := net.ParseCIDR(spec)
+			if err != nil {
+				return nil, err
+			}
+			first, last = networkRange(network)
+			ones, bits := network.Mask.Size()
+			if ip.To4() != nil && bits-ones > 1 {
+				first, last = addToIP(first, 1), addToIP(last, -1)
+			}
+		case strings.Contains(spec, "-") && net.ParseIP(strings.SplitN(spec, "-", 2)[0]) != nil:
+			var err error
+			if first, last, err = parseRange(spec); err != nil {
+				return nil, err
+			}
+		default:
+			if err := add(spec); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		for ip := first; compareIPs(ip, last) <= 0; ip = addToIP(ip, 1) {
+			if err := add(ip.String()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, errors.New("no targets given")
+	}
+	return hosts, nil
+}
+
+// parseRange parses ranges like 10.0.0.1-20 and 10.0.0.1-10.0.1.5
+func parseRange(spec string) (net.IP, net.IP, error) {
+	parts := strings.SplitN(spec, "-", 2)
+	first := normalizeIP(net.ParseIP(parts[0]))
+	last := normalizeIP(net.ParseIP(parts[1]))
+	if last == nil && first.To4() != nil {
+		// The end of the range is the last octet
+		n, err := strconv.Atoi(parts[1])
+		if err != nil || n < 0 || n > 255 {
+			return nil, nil, errors.New("invalid range: " + spec)
+		}
+		last = append(net.IP{}, first...)
+		last[3] = byte(n)
+	}
+	if last == nil || len(first) != len(last) || compareIPs(first, last) > 0 {
+		return nil, nil, errors.New("invalid range: " + spec)
+	}
+	return first, last, nil
+}
+
+// normalizeIP returns 4 byte IPv4 addresses, so that IPv4 and IPv6 addresses can be told apart by their length
+func normalizeIP(ip net.IP) net.IP {
+	if v4 := ip.To4(); v4 != nil {
+		return v4
+	}
+	return ip
+}
+
+func networkRange(network *net.IPNet) (net.IP, net.IP) {
+	first := normalizeIP(network.IP.Mask(network.Mask))
+	last := make(net.IP, len(first))
+	for i := range first {
+		last[i] = first[i] | ^network.Mask[len(network.Mask)-len(first)+i]
+	}
+	return first, last
+}
+
+func addToIP(ip net.IP, n int64) net.IP {
+	v := new(big.Int).SetBytes(ip)
+	v.Add(v, big.NewInt(n))
+	bytes := v.Bytes()
+	result := make(net.IP, len(ip))
+	if len(bytes) > len(ip) {
+		// Overflow past the highest address ends the iteration
+		for i := range result {
+			result[i] = 0xff
+		}
+		return append(result, 0)
+	}
+	copy(result[len(ip)-len(bytes):], bytes)
+	return result
+}
+
+func compareIPs(a, b net.IP) int {
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	return new(big.Int).SetBytes(a).Cmp(new(big.Int).SetBytes(b))
+}
+
+// ParsePorts parses a port list like 22,80,8000-8100. 'top' stands for all ports of the built-in service table.
+func ParsePorts(spec string) ([]int, error) {
+	seen := map[int]bool{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+			continue
+		case part == "top":
+			for port := range services {
+				seen[port] = true
+			}
+			continue
+		}
+
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, errors.New("invalid port: " + part)
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil {
+				return nil, errors.New("invalid port range: " + part)
+			}
+		}
+		if first < 1 || last > 65535 || first > last {
+			return nil, errors.New("ports must be between 1 and 65535: " + part)
+		}
+		for port := first; port <= last; port++ {
+			seen[port] = true
+		}
+	}
+	if len(seen) == 0 {
+		return nil, errors.New("no ports given")
+	}
+
+	ports := make([]int, 0, len(seen))
+	for port := range seen {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	return ports, nil
+}
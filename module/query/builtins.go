@@ -0,0 +1,1075 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dops-cli/dops/module/convert"
+)
+
+type function func(input interface{}, args []node, e *env) ([]interface{}, error)
+
+var functions = map[string]function{}
+
+func lookupFunction(name string, arity int) (function, bool) {
+	f, ok := functions[name+"/"+strconv.Itoa(arity)]
+	return f, ok
+}
+
+// FunctionNames returns the names of all builtin functions with their arity, e.g. "map/1"
+func FunctionNames() []string {
+	var names []string
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// simple registers a function without arguments, which returns exactly one value
+func simple(name string, f func(input interface{}) (interface{}, error)) {
+	functions[name+"/0"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		value, err := f(input)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{value}, nil
+	}
+}
+
+// withArgument registers a function with one argument. The function is called for every result of the argument.
+func withArgument(name string, f func(input, arg interface{}) (interface{}, error)) {
+	functions[name+"/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		values, err := args[0].eval(input, e)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]interface{}, 0, len(values))
+		for _, value := range values {
+			result, err := f(input, value)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+		return results, nil
+	}
+}
+
+// byType registers a function, which selects inputs of specific types
+func byType(name string, types ...string) {
+	functions[name+"/0"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		for _, t := range types {
+			if typeName(input) == t {
+				return []interface{}{input}, nil
+			}
+		}
+		return nil, nil
+	}
+}
+
+func requireArray(name string, input interface{}) ([]interface{}, error) {
+	list, ok := input.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s requires an array, got %s", name, typeName(input))
+	}
+	return list, nil
+}
+
+func requireString(name string, value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s requires a string, got %s", name, typeName(value))
+	}
+	return s, nil
+}
+
+// first evaluates n and returns its first result
+func first(n node, input interface{}, e *env) (interface{}, error) {
+	values, err := n.eval(input, e)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return values[0], nil
+}
+
+func keysOf(input interface{}, sorted bool) (interface{}, error) {
+	switch v := input.(type) {
+	case *convert.Object:
+		keys := append([]string{}, v.Keys()...)
+		if sorted {
+			sort.Strings(keys)
+		}
+		return stringsToValues(keys), nil
+	case []interface{}:
+		keys := make([]interface{}, len(v))
+		for i := range v {
+			keys[i] = int64(i)
+		}
+		return keys, nil
+	}
+	return nil, fmt.Errorf("%s has no keys", typeName(input))
+}
+
+func recurse(value interface{}, f func(interface{}) ([]interface{}, error)) ([]interface{}, error) {
+	results := []interface{}{value}
+	children, err := f(value)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		r, err := recurse(child, f)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r...)
+	}
+	return results, nil
+}
+
+func children(value interface{}) ([]interface{}, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, nil
+	case *convert.Object:
+		var values []interface{}
+		for _, key := range v.Keys() {
+			child, _ := v.Get(key)
+			values = append(values, child)
+		}
+		return values, nil
+	}
+	return nil, nil
+}
+
+// paths returns the paths to all values below value
+func paths(value interface{}, prefix []interface{}, leafsOnly bool) []interface{} {
+	var results []interface{}
+	add := func(key interface{}, child interface{}) {
+		path := append(append([]interface{}{}, prefix...), key)
+		_, isList := child.([]interface{})
+		_, isObject := child.(*convert.Object)
+		if !leafsOnly || (!isList && !isObject) {
+			results = append(results, path)
+		}
+		results = append(results, paths(child, path, leafsOnly)...)
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		for i, child := range v {
+			add(int64(i), child)
+		}
+	case *convert.Object:
+		for _, key := range v.Keys() {
+			child, _ := v.Get(key)
+			add(key, child)
+		}
+	}
+	return results
+}
+
+// sortBy sorts list by the results of f
+func sortBy(list []interface{}, f node, e *env) ([]interface{}, [][]interface{}, error) {
+	type item struct {
+		value interface{}
+		key   []interface{}
+	}
+	items := make([]item, len(list))
+	for i, value := range list {
+		key, err := f.eval(value, e)
+		if err != nil {
+			return nil, nil, err
+		}
+		items[i] = item{value: value, key: key}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return compareValues(items[i].key, items[j].key) < 0
+	})
+
+	values := make([]interface{}, len(items))
+	keys := make([][]interface{}, len(items))
+	for i, it := range items {
+		values[i] = it.value
+		keys[i] = it.key
+	}
+	return values, keys, nil
+}
+
+func compileRegex(pattern interface{}, flags string) (*regexp.Regexp, error) {
+	s, err := requireString("regex", pattern)
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(flags, "i") {
+		s = "(?i)" + s
+	}
+	if strings.Contains(flags, "x") {
+		s = "(?x)" + s
+	}
+	return regexp.Compile(s)
+}
+
+// captures returns an object with the named groups of a match
+func captures(r *regexp.Regexp, s string, match []int) *convert.Object {
+	o := convert.NewObject()
+	for i, name := range r.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if match[2*i] < 0 {
+			o.Set(name, nil)
+		} else {
+			o.Set(name, s[match[2*i]:match[2*i+1]])
+		}
+	}
+	return o
+}
+
+// substitute implements sub and gsub. The replacement is evaluated with the named captures as input.
+func substitute(input interface{}, args []node, e *env, global bool) ([]interface{}, error) {
+	s, err := requireString("sub", input)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := first(args[0], input, e)
+	if err != nil {
+		return nil, err
+	}
+	flags := ""
+	if len(args) == 3 {
+		f, err := first(args[2], input, e)
+		if err != nil {
+			return nil, err
+		}
+		flags, _ = f.(string)
+		global = global || strings.Contains(flags, "g")
+	}
+	r, err := compileRegex(pattern, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := 1
+	if global {
+		limit = -1
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, match := range r.FindAllStringSubmatchIndex(s, limit) {
+		replacement, err := first(args[1], captures(r, s, match), e)
+		if err != nil {
+			return nil, err
+		}
+		rs, err := requireString("sub replacement", replacement)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(s[last:match[0]])
+		b.WriteString(rs)
+		last = match[1]
+	}
+	b.WriteString(s[last:])
+
+	return []interface{}{b.String()}, nil
+}
+
+func init() {
+	functions["empty/0"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		return nil, nil
+	}
+	functions["error/0"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		return nil, errors.New(convert.ToString(input))
+	}
+	functions["error/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		msg, err := first(args[0], input, e)
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New(convert.ToString(msg))
+	}
+	functions["env/0"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		value, _ := e.lookup("ENV")
+		return []interface{}{value}, nil
+	}
+
+	simple("not", func(input interface{}) (interface{}, error) {
+		return !isTruthy(input), nil
+	})
+	simple("length", func(input interface{}) (interface{}, error) {
+		switch v := input.(type) {
+		case nil:
+			return int64(0), nil
+		case bool:
+			return nil, errors.New("boolean has no length")
+		case int64:
+			if v < 0 {
+				return -v, nil
+			}
+			return v, nil
+		case float64:
+			return math.Abs(v), nil
+		case string:
+			return int64(utf8.RuneCountInString(v)), nil
+		case []interface{}:
+			return int64(len(v)), nil
+		case *convert.Object:
+			return int64(v.Len()), nil
+		}
+		return nil, fmt.Errorf("%s has no length", typeName(input))
+	})
+	simple("utf8bytelength", func(input interface{}) (interface{}, error) {
+		s, err := requireString("utf8bytelength", input)
+		return int64(len(s)), err
+	})
+	simple("keys", func(input interface{}) (interface{}, error) {
+		return keysOf(input, true)
+	})
+	simple("keys_unsorted", func(input interface{}) (interface{}, error) {
+		return keysOf(input, false)
+	})
+	simple("type", func(input interface{}) (interface{}, error) {
+		return typeName(input), nil
+	})
+	simple("tostring", func(input interface{}) (interface{}, error) {
+		if s, ok := input.(string); ok {
+			return s, nil
+		}
+		return jsonString(input), nil
+	})
+	simple("tonumber", func(input interface{}) (interface{}, error) {
+		switch v := input.(type) {
+		case int64, float64:
+			return v, nil
+		case string:
+			if i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+				return i, nil
+			}
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse %q as number", v)
+			}
+			return f, nil
+		}
+		return nil, fmt.Errorf("%s cannot be parsed as a number", typeName(input))
+	})
+	simple("tojson", func(input interface{}) (interface{}, error) {
+		return jsonString(input), nil
+	})
+	simple("fromjson", func(input interface{}) (interface{}, error) {
+		s, err := requireString("fromjson", input)
+		if err != nil {
+			return nil, err
+		}
+		return convert.Decode(convert.JSON, []byte(s))
+	})
+	simple("ascii_downcase", func(input interface{}) (interface{}, error) {
+		s, err := requireString("ascii_downcase", input)
+		return strings.ToLower(s), err
+	})
+	simple("ascii_upcase", func(input interface{}) (interface{}, error) {
+		s, err := requireString("ascii_upcase", input)
+		return strings.ToUpper(s), err
+	})
+	simple("trim", func(input interface{}) (interface{}, error) {
+		s, err := requireString("trim", input)
+		return strings.TrimSpace(s), err
+	})
+	simple("ltrim", func(input interface{}) (interface{}, error) {
+		s, err := requireString("ltrim", input)
+		return strings.TrimLeft(s, " \t\r\n"), err
+	})
+	simple("rtrim", func(input interface{}) (interface{}, error) {
+		s, err := requireString("rtrim", input)
+		return strings.TrimRight(s, " \t\r\n"), err
+	})
+
+	for name, f := range map[string]func(float64) float64{"floor": math.Floor, "ceil": math.Ceil, "round": math.Round, "sqrt": math.Sqrt, "abs": math.Abs} {
+		f := f
+		name := name
+		simple(name, func(input interface{}) (interface{}, error) {
+			n, ok := toFloat(input)
+			if !ok {
+				return nil, fmt.Errorf("%s requires a number, got %s", name, typeName(input))
+			}
+			return numberValue(f(n)), nil
+		})
+	}
+
+	simple("add", func(input interface{}) (interface{}, error) {
+		list, err := requireArray("add", input)
+		if err != nil {
+			return nil, err
+		}
+		var sum interface{}
+		for _, item := range list {
+			sum, err = binaryOperation("+", sum, item)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return sum, nil
+	})
+	simple("any", func(input interface{}) (interface{}, error) {
+		list, err := requireArray("any", input)
+		for _, item := range list {
+			if isTruthy(item) {
+				return true, nil
+			}
+		}
+		return false, err
+	})
+	simple("all", func(input interface{}) (interface{}, error) {
+		list, err := requireArray("all", input)
+		for _, item := range list {
+			if !isTruthy(item) {
+				return false, nil
+			}
+		}
+		return true, err
+	})
+	simple("reverse", func(input interface{}) (interface{}, error) {
+		if s, ok := input.(string); ok {
+			runes := []rune(s)
+			for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+				runes[i], runes[j] = runes[j], runes[i]
+			}
+			return string(runes), nil
+		}
+		if input == nil {
+			return []interface{}{}, nil
+		}
+		list, err := requireArray("reverse", input)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]interface{}, len(list))
+		for i, item := range list {
+			result[len(list)-1-i] = item
+		}
+		return result, nil
+	})
+	simple("sort", func(input interface{}) (interface{}, error) {
+		list, err := requireArray("sort", input)
+		if err != nil {
+			return nil, err
+		}
+		result := append([]interface{}{}, list...)
+		sort.SliceStable(result, func(i, j int) bool {
+			return compareValues(result[i], result[j]) < 0
+		})
+		return result, nil
+	})
+	simple("unique", func(input interface{}) (interface{}, error) {
+		list, err := requireArray("unique", input)
+		if err != nil {
+			return nil, err
+		}
+		sorted := append([]interface{}{}, list...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return compareValues(sorted[i], sorted[j]) < 0
+		})
+		result := []interface{}{}
+		for i, item := range sorted {
+			if i == 0 || compareValues(item, sorted[i-1]) != 0 {
+				result = append(result, item)
+			}
+		}
+		return result, nil
+	})
+	for _, name := range []string{"min", "max"} {
+		name := name
+		simple(name, func(input interface{}) (interface{}, error) {
+			list, err := requireArray(name, input)
+			if err != nil || len(list) == 0 {
+				return nil, err
+			}
+			result := list[0]
+			for _, item := range list[1:] {
+				c := compareValues(item, result)
+				if (name == "min" && c < 0) || (name == "max" && c >= 0) {
+					result = item
+				}
+			}
+			return result, nil
+		})
+	}
+	simple("flatten", func(input interface{}) (interface{}, error) {
+		list, err := requireArray("flatten", input)
+		if err != nil {
+			return nil, err
+		}
+		return flatten(list, -1), nil
+	})
+	withArgument("flatten", func(input, depth interface{}) (interface{}, error) {
+		list, err := requireArray("flatten", input)
+		if err != nil {
+			return nil, err
+		}
+		d, ok := toInt(depth)
+		if !ok || d < 0 {
+			return nil, errors.New("flatten depth must be a positive number")
+		}
+		return flatten(list, d), nil
+	})
+	simple("to_entries", func(input interface{}) (interface{}, error) {
+		o, ok := input.(*convert.Object)
+		if !ok {
+			return nil, fmt.Errorf("to_entries requires an object, got %s", typeName(input))
+		}
+		entries := []interface{}{}
+		for _, key := range o.Keys() {
+			value, _ := o.Get(key)
+			entry := convert.NewObject()
+			entry.Set("key", key)
+			entry.Set("value", value)
+			entries = append(entries, entry)
+		}
+		return entries, nil
+	})
+	simple("from_entries", func(input interface{}) (interface{}, error) {
+		list, err := requireArray("from_entries", input)
+		if err != nil {
+			return nil, err
+		}
+		return fromEntries(list)
+	})
+	simple("first", func(input interface{}) (interface{}, error) {
+		return indexValue(input, int64(0))
+	})
+	simple("last", func(input interface{}) (interface{}, error) {
+		return indexValue(input, int64(-1))
+	})
+	functions["paths/0"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		return paths(input, nil, false), nil
+	}
+	functions["leaf_paths/0"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		return paths(input, nil, true), nil
+	}
+	withArgument("getpath", func(input, path interface{}) (interface{}, error) {
+		list, err := requireArray("getpath", path)
+		if err != nil {
+			return nil, err
+		}
+		value := input
+		for _, key := range list {
+			value, err = indexValue(value, key)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return value, nil
+	})
+
+	functions["recurse/0"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		return recurse(input, children)
+	}
+	functions["recurse/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		return recurse(input, func(value interface{}) ([]interface{}, error) {
+			return args[0].eval(value, e)
+		})
+	}
+
+	byType("arrays", "array")
+	byType("objects", "object")
+	byType("iterables", "array", "object")
+	byType("booleans", "boolean")
+	byType("numbers", "number")
+	byType("strings", "string")
+	byType("nulls", "null")
+	byType("values", "boolean", "number", "string", "array", "object")
+	byType("scalars", "null", "boolean", "number", "string")
+
+	functions["select/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		conds, err := args[0].eval(input, e)
+		if err != nil {
+			return nil, err
+		}
+		var results []interface{}
+		for _, cond := range conds {
+			if isTruthy(cond) {
+				results = append(results, input)
+			}
+		}
+		return results, nil
+	}
+	functions["map/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		values, err := (&iterateNode{target: &identityNode{}}).eval(input, e)
+		if err != nil {
+			return nil, err
+		}
+		result := []interface{}{}
+		for _, value := range values {
+			r, err := args[0].eval(value, e)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, r...)
+		}
+		return []interface{}{result}, nil
+	}
+	functions["map_values/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		switch v := input.(type) {
+		case *convert.Object:
+			result := convert.NewObject()
+			for _, key := range v.Keys() {
+				value, _ := v.Get(key)
+				r, err := args[0].eval(value, e)
+				if err != nil {
+					return nil, err
+				}
+				if len(r) > 0 {
+					result.Set(key, r[0])
+				}
+			}
+			return []interface{}{result}, nil
+		case []interface{}:
+			result := []interface{}{}
+			for _, value := range v {
+				r, err := args[0].eval(value, e)
+				if err != nil {
+					return nil, err
+				}
+				if len(r) > 0 {
+					result = append(result, r[0])
+				}
+			}
+			return []interface{}{result}, nil
+		}
+		return nil, fmt.Errorf("cannot iterate over %s", typeName(input))
+	}
+	functions["with_entries/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		entries, err := functions["to_entries/0"](input, nil, e)
+		if err != nil {
+			return nil, err
+		}
+		mapped, err := functions["map/1"](entries[0], args, e)
+		if err != nil {
+			return nil, err
+		}
+		o, err := fromEntries(mapped[0].([]interface{}))
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{o}, nil
+	}
+	functions["any/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		list, err := requireArray("any", input)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			v, err := first(args[0], item, e)
+			if err != nil {
+				return nil, err
+			}
+			if isTruthy(v) {
+				return []interface{}{true}, nil
+			}
+		}
+		return []interface{}{false}, nil
+	}
+	functions["all/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		list, err := requireArray("all", input)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			v, err := first(args[0], item, e)
+			if err != nil {
+				return nil, err
+			}
+			if !isTruthy(v) {
+				return []interface{}{false}, nil
+			}
+		}
+		return []interface{}{true}, nil
+	}
+	functions["range/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		return rangeValues(input, &literalNode{value: int64(0)}, args[0], e)
+	}
+	functions["range/2"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		return rangeValues(input, args[0], args[1], e)
+	}
+	functions["first/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		values, err := args[0].eval(input, e)
+		if err != nil || len(values) == 0 {
+			return nil, err
+		}
+		return values[:1], nil
+	}
+	functions["last/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		values, err := args[0].eval(input, e)
+		if err != nil || len(values) == 0 {
+			return nil, err
+		}
+		return values[len(values)-1:], nil
+	}
+	functions["limit/2"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		n, err := first(args[0], input, e)
+		if err != nil {
+			return nil, err
+		}
+		limit, ok := toInt(n)
+		if !ok {
+			return nil, errors.New("limit requires a number")
+		}
+		if limit <= 0 {
+			return nil, nil
+		}
+		values, err := args[1].eval(input, e)
+		if err != nil {
+			return nil, err
+		}
+		if limit < len(values) {
+			values = values[:limit]
+		}
+		return values, nil
+	}
+	functions["isempty/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		values, err := args[0].eval(input, e)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{len(values) == 0}, nil
+	}
+
+	functions["sort_by/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		list, err := requireArray("sort_by", input)
+		if err != nil {
+			return nil, err
+		}
+		sorted, _, err := sortBy(list, args[0], e)
+		return []interface{}{sorted}, err
+	}
+	functions["group_by/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		list, err := requireArray("group_by", input)
+		if err != nil {
+			return nil, err
+		}
+		sorted, keys, err := sortBy(list, args[0], e)
+		if err != nil {
+			return nil, err
+		}
+		groups := []interface{}{}
+		for i, value := range sorted {
+			if i == 0 || compareValues(keys[i], keys[i-1]) != 0 {
+				groups = append(groups, []interface{}{value})
+				continue
+			}
+			groups[len(groups)-1] = append(groups[len(groups)-1].([]interface{}), value)
+		}
+		return []interface{}{groups}, nil
+	}
+	functions["unique_by/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		list, err := requireArray("unique_by", input)
+		if err != nil {
+			return nil, err
+		}
+		sorted, keys, err := sortBy(list, args[0], e)
+		if err != nil {
+			return nil, err
+		}
+		result := []interface{}{}
+		for i, value := range sorted {
+			if i == 0 || compareValues(keys[i], keys[i-1]) != 0 {
+				result = append(result, value)
+			}
+		}
+		return []interface{}{result}, nil
+	}
+	for _, name := range []string{"min_by", "max_by"} {
+		name := name
+		functions[name+"/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+			list, err := requireArray(name, input)
+			if err != nil {
+				return nil, err
+			}
+			if len(list) == 0 {
+				return []interface{}{nil}, nil
+			}
+			sorted, _, err := sortBy(list, args[0], e)
+			if err != nil {
+				return nil, err
+			}
+			if name == "min_by" {
+				return sorted[:1], nil
+			}
+			return sorted[len(sorted)-1:], nil
+		}
+	}
+
+	withArgument("has", func(input, key interface{}) (interface{}, error) {
+		switch v := input.(type) {
+		case *convert.Object:
+			k, err := requireString("has", key)
+			if err != nil {
+				return nil, err
+			}
+			_, ok := v.Get(k)
+			return ok, nil
+		case []interface{}:
+			i, ok := toInt(key)
+			if !ok {
+				return nil, errors.New("has requires a number for arrays")
+			}
+			return i >= 0 && i < len(v), nil
+		}
+		return nil, fmt.Errorf("cannot check whether %s has a key", typeName(input))
+	})
+	withArgument("contains", func(input, value interface{}) (interface{}, error) {
+		return contains(input, value), nil
+	})
+	withArgument("startswith", func(input, prefix interface{}) (interface{}, error) {
+		s, err := requireString("startswith", input)
+		if err != nil {
+			return nil, err
+		}
+		p, err := requireString("startswith", prefix)
+		return strings.HasPrefix(s, p), err
+	})
+	withArgument("endswith", func(input, suffix interface{}) (interface{}, error) {
+		s, err := requireString("endswith", input)
+		if err != nil {
+			return nil, err
+		}
+		p, err := requireString("endswith", suffix)
+		return strings.HasSuffix(s, p), err
+	})
+	withArgument("ltrimstr", func(input, prefix interface{}) (interface{}, error) {
+		s, ok := input.(string)
+		p, pok := prefix.(string)
+		if !ok || !pok {
+			return input, nil
+		}
+		return strings.TrimPrefix(s, p), nil
+	})
+	withArgument("rtrimstr", func(input, suffix interface{}) (interface{}, error) {
+		s, ok := input.(string)
+		p, pok := suffix.(string)
+		if !ok || !pok {
+			return input, nil
+		}
+		return strings.TrimSuffix(s, p), nil
+	})
+	withArgument("split", func(input, sep interface{}) (interface{}, error) {
+		s, err := requireString("split", input)
+		if err != nil {
+			return nil, err
+		}
+		p, err := requireString("split", sep)
+		return stringsToValues(strings.Split(s, p)), err
+	})
+	withArgument("join", func(input, sep interface{}) (interface{}, error) {
+		list, err := requireArray("join", input)
+		if err != nil {
+			return nil, err
+		}
+		p, err := requireString("join", sep)
+		if err != nil {
+			return nil, err
+		}
+		parts := make([]string, len(list))
+		for i, item := range list {
+			switch item.(type) {
+			case *convert.Object, []interface{}:
+				return nil, fmt.Errorf("cannot join %s", typeName(item))
+			}
+			parts[i] = convert.ToString(item)
+		}
+		return strings.Join(parts, p), nil
+	})
+	withArgument("index", func(input, needle interface{}) (interface{}, error) {
+		s, err := requireString("index", input)
+		if err != nil {
+			return nil, err
+		}
+		n, err := requireString("index", needle)
+		if err != nil {
+			return nil, err
+		}
+		i := strings.Index(s, n)
+		if i < 0 {
+			return nil, nil
+		}
+		return int64(utf8.RuneCountInString(s[:i])), nil
+	})
+	withArgument("test", func(input, pattern interface{}) (interface{}, error) {
+		s, err := requireString("test", input)
+		if err != nil {
+			return nil, err
+		}
+		r, err := compileRegex(pattern, "")
+		if err != nil {
+			return nil, err
+		}
+		return r.MatchString(s), nil
+	})
+	functions["test/2"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		s, err := requireString("test", input)
+		if err != nil {
+			return nil, err
+		}
+		pattern, err := first(args[0], input, e)
+		if err != nil {
+			return nil, err
+		}
+		flags, err := first(args[1], input, e)
+		if err != nil {
+			return nil, err
+		}
+		f, _ := flags.(string)
+		r, err := compileRegex(pattern, f)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{r.MatchString(s)}, nil
+	}
+	functions["capture/1"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		s, err := requireString("capture", input)
+		if err != nil {
+			return nil, err
+		}
+		pattern, err := first(args[0], input, e)
+		if err != nil {
+			return nil, err
+		}
+		r, err := compileRegex(pattern, "")
+		if err != nil {
+			return nil, err
+		}
+		var results []interface{}
+		for _, match := range r.FindAllStringSubmatchIndex(s, 1) {
+			results = append(results, captures(r, s, match))
+		}
+		return results, nil
+	}
+	functions["sub/2"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		return substitute(input, args, e, false)
+	}
+	functions["sub/3"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		return substitute(input, args, e, false)
+	}
+	functions["gsub/2"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		return substitute(input, args, e, true)
+	}
+	functions["gsub/3"] = func(input interface{}, args []node, e *env) ([]interface{}, error) {
+		return substitute(input, args, e, true)
+	}
+}
+
+func flatten(list []interface{}, depth int) []interface{} {
+	result := []interface{}{}
+	for _, item := range list {
+		if sub, ok := item.([]interface{}); ok && depth != 0 {
+			result = append(result, flatten(sub, depth-1)...)
+		} else {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func fromEntries(list []interface{}) (*convert.Object, error) {
+	o := convert.NewObject()
+	for _, item := range list {
+		entry, ok := item.(*convert.Object)
+		if !ok {
+			return nil, fmt.Errorf("from_entries requires objects, got %s", typeName(item))
+		}
+		var key interface{}
+		for _, name := range []string{"key", "k", "name", "Name", "Key", "K"} {
+			if k, ok := entry.Get(name); ok && k != nil {
+				key = k
+				break
+			}
+		}
+		var value interface{}
+		for _, name := range []string{"value", "v", "Value", "V"} {
+			if v, ok := entry.Get(name); ok {
+				value = v
+				break
+			}
+		}
+		o.Set(convert.ToString(key), value)
+	}
+	return o, nil
+}
+
+func contains(a, b interface{}) bool {
+	switch av := a.(type) {
+	case string:
+		bs, ok := b.(string)
+		return ok && strings.Contains(av, bs)
+	case []interface{}:
+		bl, ok := b.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, bItem := range bl {
+			found := false
+			for _, aItem := range av {
+				if contains(aItem, bItem) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	case *convert.Object:
+		bo, ok := b.(*convert.Object)
+		if !ok {
+			return false
+		}
+		for _, key := range bo.Keys() {
+			aValue, ok := av.Get(key)
+			bValue, _ := bo.Get(key)
+			if !ok || !contains(aValue, bValue) {
+				return false
+			}
+		}
+		return true
+	}
+	return compareValues(a, b) == 0
+}
+
+// maxRange is the maximum number of values of range. Generators are evaluated completely,
+// before limit or first see their values, so larger ranges would only exhaust the memory.
+const maxRange = 1 << 20
+
+func rangeValues(input interface{}, from, to node, e *env) ([]interface{}, error) {
+	start, err := first(from, input, e)
+	if err != nil {
+		return nil, err
+	}
+	end, err := first(to, input, e)
+	if err != nil {
+		return nil, err
+	}
+	s, ok1 := toInt(start)
+	t, ok2 := toInt(end)
+	if !ok1 || !ok2 {
+		return nil, errors.New("range requires numbers")
+	}
+	if t-s > maxRange {
+		return nil, fmt.Errorf("range of %d values is larger than the maximum of %d", t-s, maxRange)
+	}
+	var results []interface{}
+	for i := s; i < t; i++ {
+		results = append(results, int64(i))
+	}
+	return results, nil
+}
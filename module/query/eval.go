@@ -0,0 +1,563 @@
+package query
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dops-cli/dops/module/convert"
+)
+
+// Query is a compiled query expression
+type Query struct {
+	root node
+	env  *env
+}
+
+// Run evaluates the query against input and returns all results
+func (q *Query) Run(input interface{}) ([]interface{}, error) {
+	if q.env == nil {
+		envObject := convert.NewObject()
+		for _, e := range os.Environ() {
+			parts := strings.SplitN(e, "=", 2)
+			envObject.Set(parts[0], parts[1])
+		}
+		q.env = &env{name: "ENV", value: envObject}
+	}
+
+	return q.root.eval(convert.Normalize(input), q.env)
+}
+
+// env contains the variables, which are bound with 'as $name'
+type env struct {
+	name   string
+	value  interface{}
+	parent *env
+}
+
+func (e *env) lookup(name string) (interface{}, bool) {
+	for ; e != nil; e = e.parent {
+		if e.name == name {
+			return e.value, true
+		}
+	}
+	return nil, false
+}
+
+type node interface {
+	eval(input interface{}, e *env) ([]interface{}, error)
+}
+
+type identityNode struct{}
+
+func (n *identityNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	return []interface{}{input}, nil
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (n *literalNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	return []interface{}{n.value}, nil
+}
+
+type variableNode struct {
+	name string
+}
+
+func (n *variableNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	value, ok := e.lookup(n.name)
+	if !ok {
+		return nil, fmt.Errorf("$%s is not defined", n.name)
+	}
+	return []interface{}{value}, nil
+}
+
+type pipeNode struct {
+	left, right node
+}
+
+func (n *pipeNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	lefts, err := n.left.eval(input, e)
+	if err != nil {
+		return nil, err
+	}
+	var results []interface{}
+	for _, left := range lefts {
+		rights, err := n.right.eval(left, e)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rights...)
+	}
+	return results, nil
+}
+
+type commaNode struct {
+	left, right node
+}
+
+func (n *commaNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	lefts, err := n.left.eval(input, e)
+	if err != nil {
+		return nil, err
+	}
+	rights, err := n.right.eval(input, e)
+	if err != nil {
+		return nil, err
+	}
+	return append(lefts, rights...), nil
+}
+
+type bindNode struct {
+	source node
+	name   string
+	body   node
+}
+
+func (n *bindNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	values, err := n.source.eval(input, e)
+	if err != nil {
+		return nil, err
+	}
+	var results []interface{}
+	for _, value := range values {
+		r, err := n.body.eval(input, &env{name: n.name, value: value, parent: e})
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r...)
+	}
+	return results, nil
+}
+
+type indexNode struct {
+	target node
+	index  node
+}
+
+func (n *indexNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	targets, err := n.target.eval(input, e)
+	if err != nil {
+		return nil, err
+	}
+	indices, err := n.index.eval(input, e)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []interface{}
+	for _, target := range targets {
+		for _, index := range indices {
+			value, err := indexValue(target, index)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, value)
+		}
+	}
+	return results, nil
+}
+
+func indexValue(target, index interface{}) (interface{}, error) {
+	if target == nil {
+		return nil, nil
+	}
+
+	switch t := target.(type) {
+	case *convert.Object:
+		if key, ok := index.(string); ok {
+			value, _ := t.Get(key)
+			return value, nil
+		}
+	case []interface{}:
+		if i, ok := toInt(index); ok {
+			if i < 0 {
+				i += len(t)
+			}
+			if i < 0 || i >= len(t) {
+				return nil, nil
+			}
+			return t[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot index %s with %s", typeName(target), typeName(index))
+}
+
+type sliceNode struct {
+	target, from, to node
+}
+
+func (n *sliceNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	targets, err := n.target.eval(input, e)
+	if err != nil {
+		return nil, err
+	}
+
+	bound := func(b node, length, fallback int) (int, error) {
+		if b == nil {
+			return fallback, nil
+		}
+		values, err := b.eval(input, e)
+		if err != nil {
+			return 0, err
+		}
+		if len(values) != 1 {
+			return 0, fmt.Errorf("slice bounds must be a single number")
+		}
+		if values[0] == nil {
+			return fallback, nil
+		}
+		i, ok := toInt(values[0])
+		if !ok {
+			return 0, fmt.Errorf("slice bounds must be numbers")
+		}
+		if i < 0 {
+			i += length
+		}
+		if i < 0 {
+			i = 0
+		}
+		if i > length {
+			i = length
+		}
+		return i, nil
+	}
+
+	var results []interface{}
+	for _, target := range targets {
+		var length int
+		switch t := target.(type) {
+		case nil:
+			results = append(results, nil)
+			continue
+		case []interface{}:
+			length = len(t)
+		case string:
+			length = len([]rune(t))
+		default:
+			return nil, fmt.Errorf("cannot slice %s", typeName(target))
+		}
+
+		from, err := bound(n.from, length, 0)
+		if err != nil {
+			return nil, err
+		}
+		to, err := bound(n.to, length, length)
+		if err != nil {
+			return nil, err
+		}
+		if to < from {
+			to = from
+		}
+
+		switch t := target.(type) {
+		case []interface{}:
+			results = append(results, append([]interface{}{}, t[from:to]...))
+		case string:
+			results = append(results, string([]rune(t)[from:to]))
+		}
+	}
+	return results, nil
+}
+
+type iterateNode struct {
+	target node
+}
+
+func (n *iterateNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	targets, err := n.target.eval(input, e)
+	if err != nil {
+		return nil, err
+	}
+	var results []interface{}
+	for _, target := range targets {
+		switch t := target.(type) {
+		case []interface{}:
+			results = append(results, t...)
+		case *convert.Object:
+			for _, key := range t.Keys() {
+				value, _ := t.Get(key)
+				results = append(results, value)
+			}
+		default:
+			return nil, fmt.Errorf("cannot iterate over %s", typeName(target))
+		}
+	}
+	return results, nil
+}
+
+// tryNode suppresses errors of its body, which is used by the '?' operator
+type tryNode struct {
+	body node
+}
+
+func (n *tryNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	results, err := n.body.eval(input, e)
+	if err != nil {
+		return nil, nil
+	}
+	return results, nil
+}
+
+type arrayNode struct {
+	value node
+}
+
+func (n *arrayNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	list := []interface{}{}
+	if n.value != nil {
+		values, err := n.value.eval(input, e)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, values...)
+	}
+	return []interface{}{list}, nil
+}
+
+type objectEntry struct {
+	key, value node
+}
+
+type objectNode struct {
+	entries []objectEntry
+}
+
+// eval builds all combinations of keys and values, like jq does for entries producing multiple results
+func (n *objectNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	results := []*convert.Object{convert.NewObject()}
+
+	for _, entry := range n.entries {
+		keys, err := entry.key.eval(input, e)
+		if err != nil {
+			return nil, err
+		}
+		values, err := entry.value.eval(input, e)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []*convert.Object
+		for _, o := range results {
+			for _, key := range keys {
+				k, ok := key.(string)
+				if !ok {
+					return nil, fmt.Errorf("object keys must be strings, got %s", typeName(key))
+				}
+				for _, value := range values {
+					c := copyObject(o)
+					c.Set(k, value)
+					next = append(next, c)
+				}
+			}
+		}
+		results = next
+	}
+
+	values := make([]interface{}, len(results))
+	for i, o := range results {
+		values[i] = o
+	}
+	return values, nil
+}
+
+type alternativeNode struct {
+	left, right node
+}
+
+func (n *alternativeNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	lefts, err := n.left.eval(input, e)
+	var results []interface{}
+	if err == nil {
+		for _, left := range lefts {
+			if isTruthy(left) {
+				results = append(results, left)
+			}
+		}
+	}
+	if len(results) > 0 {
+		return results, nil
+	}
+	return n.right.eval(input, e)
+}
+
+type logicNode struct {
+	and         bool
+	left, right node
+}
+
+func (n *logicNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	lefts, err := n.left.eval(input, e)
+	if err != nil {
+		return nil, err
+	}
+	var results []interface{}
+	for _, left := range lefts {
+		if n.and != isTruthy(left) {
+			results = append(results, !n.and)
+			continue
+		}
+		rights, err := n.right.eval(input, e)
+		if err != nil {
+			return nil, err
+		}
+		for _, right := range rights {
+			results = append(results, isTruthy(right))
+		}
+	}
+	return results, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	rights, err := n.right.eval(input, e)
+	if err != nil {
+		return nil, err
+	}
+	lefts, err := n.left.eval(input, e)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []interface{}
+	for _, right := range rights {
+		for _, left := range lefts {
+			value, err := binaryOperation(n.op, left, right)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, value)
+		}
+	}
+	return results, nil
+}
+
+type negateNode struct {
+	value node
+}
+
+func (n *negateNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	values, err := n.value.eval(input, e)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]interface{}, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case int64:
+			results[i] = -v
+		case float64:
+			results[i] = -v
+		default:
+			return nil, fmt.Errorf("cannot negate %s", typeName(value))
+		}
+	}
+	return results, nil
+}
+
+type ifNode struct {
+	cond, then, otherwise node
+}
+
+func (n *ifNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	conds, err := n.cond.eval(input, e)
+	if err != nil {
+		return nil, err
+	}
+	var results []interface{}
+	for _, cond := range conds {
+		var branch []interface{}
+		switch {
+		case isTruthy(cond):
+			branch, err = n.then.eval(input, e)
+		case n.otherwise != nil:
+			branch, err = n.otherwise.eval(input, e)
+		default:
+			branch = []interface{}{input}
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, branch...)
+	}
+	return results, nil
+}
+
+type stringNode struct {
+	format string
+	parts  []node
+}
+
+func (n *stringNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	results := []string{""}
+	for _, part := range n.parts {
+		values, err := part.eval(input, e)
+		if err != nil {
+			return nil, err
+		}
+		var next []string
+		for _, prefix := range results {
+			for _, value := range values {
+				next = append(next, prefix+value.(string))
+			}
+		}
+		results = next
+	}
+
+	values := make([]interface{}, len(results))
+	for i, s := range results {
+		values[i] = s
+	}
+	return values, nil
+}
+
+type formatNode struct {
+	name string
+}
+
+func (n *formatNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	s, err := applyFormat(n.name, input)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{s}, nil
+}
+
+// formatApplyNode formats the results of an interpolated expression
+type formatApplyNode struct {
+	format string
+	value  node
+}
+
+func (n *formatApplyNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	values, err := n.value.eval(input, e)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]interface{}, len(values))
+	for i, value := range values {
+		s, err := applyFormat(n.format, value)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = s
+	}
+	return results, nil
+}
+
+type callNode struct {
+	name string
+	args []node
+}
+
+func (n *callNode) eval(input interface{}, e *env) ([]interface{}, error) {
+	f, _ := lookupFunction(n.name, len(n.args))
+	return f(input, n.args, e)
+}
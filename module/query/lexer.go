@@ -0,0 +1,211 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenField
+	tokenVariable
+	tokenFormat
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// parts contains the literal parts and interpolated expressions of a string token.
+	// Literal parts are stored as string, expressions as source code wrapped in *interpolation.
+	parts []interface{}
+	pos   int
+}
+
+type interpolation struct {
+	source string
+}
+
+// operators are sorted by length, so that longer operators are matched first
+var operators = []string{
+	"==", "!=", "<=", ">=", "//", "..",
+	"|", ",", ".", "[", "]", "(", ")", "{", "}", ":", ";", "?", "<", ">", "+", "-", "*", "/", "%",
+}
+
+func lex(source string) ([]token, error) {
+	var tokens []token
+	src := []rune(source)
+	pos := 0
+
+	for pos < len(src) {
+		r := src[pos]
+
+		switch {
+		case unicode.IsSpace(r):
+			pos++
+			continue
+		case r == '#':
+			for pos < len(src) && src[pos] != '\n' {
+				pos++
+			}
+			continue
+		case r == '"':
+			t, end, err := lexString(src, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, t)
+			pos = end
+			continue
+		case unicode.IsDigit(r):
+			start := pos
+			for pos < len(src) && (unicode.IsDigit(src[pos]) || src[pos] == '.' || src[pos] == 'e' || src[pos] == 'E' ||
+				((src[pos] == '+' || src[pos] == '-') && (src[pos-1] == 'e' || src[pos-1] == 'E'))) {
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(src[start:pos]), pos: start})
+			continue
+		case r == '.' && pos+1 < len(src) && isIdentStart(src[pos+1]):
+			start := pos
+			pos++
+			for pos < len(src) && isIdentPart(src[pos]) {
+				pos++
+			}
+			tokens = append(tokens, token{kind: tokenField, text: string(src[start+1 : pos]), pos: start})
+			continue
+		case r == '$' || r == '@' || isIdentStart(r):
+			start := pos
+			pos++
+			for pos < len(src) && isIdentPart(src[pos]) {
+				pos++
+			}
+			kind := tokenIdent
+			text := string(src[start:pos])
+			switch r {
+			case '$':
+				kind = tokenVariable
+				text = text[1:]
+			case '@':
+				kind = tokenFormat
+				text = text[1:]
+			}
+			tokens = append(tokens, token{kind: kind, text: text, pos: start})
+			continue
+		}
+
+		matched := false
+		for _, op := range operators {
+			if strings.HasPrefix(string(src[pos:min(pos+len(op), len(src))]), op) {
+				tokens = append(tokens, token{kind: tokenOperator, text: op, pos: pos})
+				pos += len(op)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, pos)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: pos}), nil
+}
+
+// lexString reads a string literal, which can contain interpolations like "\(.name)"
+func lexString(src []rune, pos int) (token, int, error) {
+	start := pos
+	pos++
+
+	var parts []interface{}
+	var literal strings.Builder
+
+	for pos < len(src) {
+		r := src[pos]
+		switch r {
+		case '"':
+			parts = append(parts, literal.String())
+			return token{kind: tokenString, parts: parts, pos: start}, pos + 1, nil
+		case '\\':
+			if pos+1 >= len(src) {
+				return token{}, 0, fmt.Errorf("unterminated string at position %d", start)
+			}
+			next := src[pos+1]
+			if next == '(' {
+				depth := 1
+				exprStart := pos + 2
+				pos += 2
+				for pos < len(src) && depth > 0 {
+					switch src[pos] {
+					case '(':
+						depth++
+					case ')':
+						depth--
+					case '"':
+						_, end, err := lexString(src, pos)
+						if err != nil {
+							return token{}, 0, err
+						}
+						pos = end - 1
+					}
+					pos++
+				}
+				if depth > 0 {
+					return token{}, 0, fmt.Errorf("unterminated interpolation at position %d", exprStart)
+				}
+				parts = append(parts, literal.String(), &interpolation{source: string(src[exprStart : pos-1])})
+				literal.Reset()
+				continue
+			}
+			if next == 'u' && pos+5 < len(src) {
+				code, err := strconv.ParseUint(string(src[pos+2:pos+6]), 16, 32)
+				if err != nil {
+					return token{}, 0, fmt.Errorf("invalid unicode escape at position %d", pos)
+				}
+				literal.WriteRune(rune(code))
+				pos += 6
+				continue
+			}
+			switch next {
+			case 'n':
+				literal.WriteByte('\n')
+			case 't':
+				literal.WriteByte('\t')
+			case 'r':
+				literal.WriteByte('\r')
+			case 'b':
+				literal.WriteByte('\b')
+			case 'f':
+				literal.WriteByte('\f')
+			default:
+				literal.WriteRune(next)
+			}
+			pos += 2
+		default:
+			literal.WriteRune(r)
+			pos++
+		}
+	}
+
+	return token{}, 0, fmt.Errorf("unterminated string at position %d", start)
+}
+
+func isIdentStart(r rune) bool {
+	return unicode.IsLetter(r) || r == '_'
+}
+
+func isIdentPart(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
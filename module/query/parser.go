@@ -0,0 +1,542 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// Compile parses a query expression
+func Compile(source string) (*Query, error) {
+	root, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+	return &Query{root: root}, nil
+}
+
+func parse(source string) (node, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	n, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.errorf("unexpected %q", p.peek().text)
+	}
+
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOperator(text string) bool {
+	t := p.peek()
+	return t.kind == tokenOperator && t.text == text
+}
+
+func (p *parser) isKeyword(text string) bool {
+	t := p.peek()
+	return t.kind == tokenIdent && t.text == text
+}
+
+func (p *parser) expect(text string) error {
+	t := p.next()
+	if (t.kind != tokenOperator && t.kind != tokenIdent) || t.text != text {
+		if t.kind == tokenEOF {
+			return p.errorf("expected %q, but the query ended", text)
+		}
+		return p.errorf("expected %q, got %q", text, t.text)
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, a ...interface{}) error {
+	return fmt.Errorf("invalid query at position %d: %s", p.peek().pos, fmt.Sprintf(format, a...))
+}
+
+func (p *parser) parsePipe() (node, error) {
+	left, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.isKeyword("as") {
+		p.next()
+		t := p.next()
+		if t.kind != tokenVariable {
+			return nil, p.errorf("expected variable after 'as'")
+		}
+		if err := p.expect("|"); err != nil {
+			return nil, err
+		}
+		body, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return &bindNode{source: left, name: t.text, body: body}, nil
+	}
+
+	if p.isOperator("|") {
+		p.next()
+		right, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return &pipeNode{left: left, right: right}, nil
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseComma() (node, error) {
+	left, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator(",") {
+		p.next()
+		right, err := p.parseAlternative()
+		if err != nil {
+			return nil, err
+		}
+		left = &commaNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAlternative() (node, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("//") {
+		p.next()
+		right, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		left = &alternativeNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicNode{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicNode{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.isOperator(op) {
+			p.next()
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return &binaryNode{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("+") || p.isOperator("-") {
+		op := p.next().text
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("*") || p.isOperator("/") || p.isOperator("%") {
+		op := p.next().text
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOperator("-") {
+		p.next()
+		value, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		return &negateNode{value: value}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenField:
+			p.next()
+			n = &indexNode{target: n, index: &literalNode{value: t.text}}
+		case t.kind == tokenOperator && t.text == "." && p.tokens[p.pos+1].kind == tokenString:
+			p.next()
+			key, err := p.parseString(p.next())
+			if err != nil {
+				return nil, err
+			}
+			n = &indexNode{target: n, index: key}
+		case t.kind == tokenOperator && t.text == "." && p.tokens[p.pos+1].kind == tokenOperator && p.tokens[p.pos+1].text == "[":
+			p.next()
+		case t.kind == tokenOperator && t.text == "[":
+			p.next()
+			n, err = p.parseBracket(n)
+			if err != nil {
+				return nil, err
+			}
+		case t.kind == tokenOperator && t.text == "?":
+			p.next()
+			n = &tryNode{body: n}
+		default:
+			return n, nil
+		}
+	}
+}
+
+// parseBracket parses the suffixes [], [index] and [from:to]
+func (p *parser) parseBracket(target node) (node, error) {
+	if p.isOperator("]") {
+		p.next()
+		return &iterateNode{target: target}, nil
+	}
+
+	var from, to node
+	var err error
+
+	if !p.isOperator(":") {
+		from, err = p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.isOperator(":") {
+		p.next()
+		if !p.isOperator("]") {
+			to, err = p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return &sliceNode{target: target, from: from, to: to}, nil
+	}
+
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return &indexNode{target: target, index: from}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenEOF:
+		return nil, p.errorf("unexpected end of query")
+	case tokenField:
+		return &indexNode{target: &identityNode{}, index: &literalNode{value: t.text}}, nil
+	case tokenNumber:
+		if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return &literalNode{value: i}, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", t.text)
+		}
+		return &literalNode{value: f}, nil
+	case tokenString:
+		return p.parseString(t)
+	case tokenVariable:
+		return &variableNode{name: t.text}, nil
+	case tokenFormat:
+		if p.peek().kind == tokenString {
+			return p.parseFormattedString(t.text, p.next())
+		}
+		return &formatNode{name: t.text}, nil
+	case tokenIdent:
+		return p.parseIdent(t)
+	}
+
+	switch t.text {
+	case ".":
+		if p.peek().kind == tokenString {
+			key, err := p.parseString(p.next())
+			if err != nil {
+				return nil, err
+			}
+			return &indexNode{target: &identityNode{}, index: key}, nil
+		}
+		return &identityNode{}, nil
+	case "..":
+		return &callNode{name: "recurse"}, nil
+	case "(":
+		n, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(")")
+	case "[":
+		if p.isOperator("]") {
+			p.next()
+			return &arrayNode{}, nil
+		}
+		n, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return &arrayNode{value: n}, p.expect("]")
+	case "{":
+		return p.parseObject()
+	}
+
+	return nil, p.errorf("unexpected %q", t.text)
+}
+
+func (p *parser) parseIdent(t token) (node, error) {
+	switch t.text {
+	case "true":
+		return &literalNode{value: true}, nil
+	case "false":
+		return &literalNode{value: false}, nil
+	case "null":
+		return &literalNode{value: nil}, nil
+	case "if":
+		return p.parseIf()
+	}
+
+	call := &callNode{name: t.text}
+	if p.isOperator("(") {
+		p.next()
+		for {
+			arg, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+			if p.isOperator(";") {
+				p.next()
+				continue
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	if _, ok := lookupFunction(call.name, len(call.args)); !ok {
+		return nil, p.errorf("unknown function %s/%d", call.name, len(call.args))
+	}
+
+	return call, nil
+}
+
+func (p *parser) parseIf() (node, error) {
+	cond, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("then"); err != nil {
+		return nil, err
+	}
+	then, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+
+	n := &ifNode{cond: cond, then: then}
+
+	switch {
+	case p.isKeyword("elif"):
+		p.next()
+		n.otherwise, err = p.parseIf()
+		return n, err
+	case p.isKeyword("else"):
+		p.next()
+		n.otherwise, err = p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return n, p.expect("end")
+}
+
+func (p *parser) parseObject() (node, error) {
+	n := &objectNode{}
+
+	for !p.isOperator("}") {
+		var entry objectEntry
+		t := p.next()
+
+		switch {
+		case t.kind == tokenIdent || t.kind == tokenField:
+			entry.key = &literalNode{value: t.text}
+			entry.value = &indexNode{target: &identityNode{}, index: entry.key}
+		case t.kind == tokenVariable:
+			entry.key = &literalNode{value: t.text}
+			entry.value = &variableNode{name: t.text}
+		case t.kind == tokenString:
+			key, err := p.parseString(t)
+			if err != nil {
+				return nil, err
+			}
+			entry.key = key
+			entry.value = &indexNode{target: &identityNode{}, index: key}
+		case t.kind == tokenOperator && t.text == "(":
+			key, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			entry.key = key
+		default:
+			return nil, p.errorf("unexpected %q in object", t.text)
+		}
+
+		if p.isOperator(":") {
+			p.next()
+			value, err := p.parseObjectValue()
+			if err != nil {
+				return nil, err
+			}
+			entry.value = value
+		} else if entry.value == nil {
+			return nil, p.errorf("expected ':' in object")
+		}
+
+		n.entries = append(n.entries, entry)
+
+		if !p.isOperator(",") {
+			break
+		}
+		p.next()
+	}
+
+	return n, p.expect("}")
+}
+
+// parseObjectValue parses the value of an object entry, which can't contain ',' without parentheses
+func (p *parser) parseObjectValue() (node, error) {
+	left, err := p.parseAlternative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("|") {
+		p.next()
+		right, err := p.parseAlternative()
+		if err != nil {
+			return nil, err
+		}
+		left = &pipeNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseString(t token) (node, error) {
+	return p.parseFormattedString("text", t)
+}
+
+// parseFormattedString parses a string and its interpolations. Interpolated values are formatted with format.
+func (p *parser) parseFormattedString(format string, t token) (node, error) {
+	if len(t.parts) == 1 {
+		return &literalNode{value: t.parts[0].(string)}, nil
+	}
+
+	n := &stringNode{format: format}
+	for _, part := range t.parts {
+		switch v := part.(type) {
+		case string:
+			n.parts = append(n.parts, &literalNode{value: v})
+		case *interpolation:
+			expr, err := parse(v.source)
+			if err != nil {
+				return nil, err
+			}
+			n.parts = append(n.parts, &formatApplyNode{format: format, value: expr})
+		}
+	}
+	return n, nil
+}
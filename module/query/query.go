@@ -0,0 +1,158 @@
+package query
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/module/convert"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:      "query",
+			Aliases:   []string{"q", "jq"},
+			Usage:     "Queries JSON, YAML and TOML data with a jq-like expression",
+			ArgsUsage: "EXPRESSION",
+			// The expression usually comes first, so flags are also accepted after it
+			InterspersedFlags: true,
+			Description: `Query evaluates a jq-like expression against structured data from a file, URL or stdin.
+The input format is detected by the file extension, or can be set with --from.
+YAML streams with multiple documents are queried document by document.
+
+Supported syntax:
+  .key, ."key", .[0], .[1:3], .[], .., .key?    paths, slices, iteration and optional access
+  |  ,  //                                      pipes, multiple outputs and alternatives
+  + - * / %  == != < <= > >=  and or not         arithmetic, comparison and logic
+  [ ... ], { key: ..., (expr): ... }            array and object construction
+  if ... then ... elif ... else ... end          conditionals
+  expr as $name | ...                           variables ($ENV contains all environment variables)
+  "Hello \(.name)"                              string interpolation
+  @csv @tsv @json @text @base64 @base64d @uri @html @sh   format strings
+
+Generators are evaluated completely before their values are passed on, so limit(1; range(1e12)) doesn't stop early.
+Ranges are limited to 1048576 values.
+
+Functions: ` + strings.Join(FunctionNames(), ", "),
+			Category: categories.DataAnalysis,
+			Examples: []cli.Example{
+				{
+					ShortDescription: "Print the names of all items in a Kubernetes manifest",
+					Usage:            "dops query '.items[].metadata.name' -i deploy.yaml -r",
+				},
+				{
+					ShortDescription: "Select all users older than 30 and print them as compact JSON",
+					Usage:            `dops query '.users[] | select(.age > 30) | {name, age}' -i users.json -c`,
+				},
+				{
+					ShortDescription: "Count the entries of a list from stdin",
+					Usage:            `curl -s https://api.github.com/repos/dops-cli/dops/issues | dops query 'map(.title) | length'`,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.Args().Len() == 0 {
+					return errors.New("missing query expression")
+				}
+
+				q, err := Compile(c.Args().First())
+				if err != nil {
+					return err
+				}
+
+				input := c.Path("input")
+				from := c.Option("from")
+				to := c.Option("to")
+				if to == "" {
+					to = convert.JSON
+				}
+
+				content := utils.Input(input)
+				if from == "" {
+					from, err = convert.DetectFormat(input)
+					if err != nil {
+						from = convert.DetectFormatFromContent([]byte(content))
+					}
+				}
+
+				// Like jq, the query runs once for every document of a YAML stream
+				documents, err := convert.DecodeDocuments(from, []byte(content))
+				if err != nil {
+					return err
+				}
+
+				var results []interface{}
+				for _, document := range documents {
+					values, err := q.Run(document)
+					if err != nil {
+						return err
+					}
+					results = append(results, values...)
+				}
+
+				options := convert.EncodeOptions{
+					Compact:  c.Bool("compact"),
+					SortKeys: c.Bool("sort-keys"),
+				}
+
+				var lines []string
+				for _, result := range results {
+					if s, ok := result.(string); ok && c.Bool("raw-output") {
+						lines = append(lines, s)
+						continue
+					}
+					data, err := convert.Encode(to, result, options)
+					if err != nil {
+						return err
+					}
+					lines = append(lines, strings.TrimSuffix(string(data), "\n"))
+				}
+
+				return utils.WriteOutput(c, lines)
+			},
+			Flags: append([]cli.Flag{
+				&cli.PathFlag{
+					Name:      "input",
+					Aliases:   []string{"i"},
+					Usage:     "use `FILE` as input, accepts a file, URL or stdin if not set",
+					TakesFile: true,
+				},
+				&cli.OptionFlag{
+					Name:        "from",
+					Aliases:     []string{"f"},
+					Usage:       "Reads the input as `FORMAT`",
+					Options:     convert.Formats,
+					DefaultText: "detected by extension",
+				},
+				&cli.OptionFlag{
+					Name:        "to",
+					Aliases:     []string{"t"},
+					Usage:       "Writes the results as `FORMAT`",
+					Options:     convert.Formats,
+					DefaultText: convert.JSON,
+				},
+				&cli.BoolFlag{
+					Name:    "raw-output",
+					Aliases: []string{"r"},
+					Usage:   "Writes strings without quotes",
+				},
+				&cli.BoolFlag{
+					Name:    "compact",
+					Aliases: []string{"c"},
+					Usage:   "Writes every result in a single line",
+				},
+				&cli.BoolFlag{
+					Name:    "sort-keys",
+					Aliases: []string{"S"},
+					Usage:   "Sorts the keys of all objects alphabetically",
+				},
+			}, utils.OutputFlags()...),
+		},
+	}
+}
@@ -0,0 +1,280 @@
+package query
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/module/convert"
+)
+
+const input = `{
+  "name": "dops",
+  "version": 2,
+  "tags": ["cli", "devops", "go"],
+  "users": [
+    {"name": "alice", "age": 31, "admin": true},
+    {"name": "bob", "age": 25, "admin": false},
+    {"name": "carol", "age": 42}
+  ],
+  "nested": {"a": {"b": null}}
+}`
+
+// run evaluates the expression and returns every result as compact JSON in its own line
+func run(t *testing.T, expression, data string) (string, error) {
+	t.Helper()
+	q, err := Compile(expression)
+	if err != nil {
+		return "", err
+	}
+	value, err := convert.Decode(convert.JSON, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := q.Run(value)
+	if err != nil {
+		return "", err
+	}
+	lines := make([]string, len(results))
+	for i, result := range results {
+		encoded, err := convert.Encode(convert.JSON, result, convert.EncodeOptions{Compact: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines[i] = strings.TrimSuffix(string(encoded), "\n")
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func TestPaths(t *testing.T) {
+	tests := map[string]string{
+		`.`:                        `{"name":"dops","version":2,"tags":["cli","devops","go"],"users":[{"name":"alice","age":31,"admin":true},{"name":"bob","age":25,"admin":false},{"name":"carol","age":42}],"nested":{"a":{"b":null}}}`,
+		`.name`:                    `"dops"`,
+		`."name"`:                  `"dops"`,
+		`.["name"]`:                `"dops"`,
+		`.tags[0]`:                 `"cli"`,
+		`.tags[-1]`:                `"go"`,
+		`.tags[1:]`:                `["devops","go"]`,
+		`.tags[:2]`:                `["cli","devops"]`,
+		`.tags[]`:                  "\"cli\"\n\"devops\"\n\"go\"",
+		`.users[].name`:            "\"alice\"\n\"bob\"\n\"carol\"",
+		`.missing`:                 `null`,
+		`.missing.deeper`:          `null`,
+		`.nested.a.b`:              `null`,
+		`.name?`:                   `"dops"`,
+		`.tags.name?`:              ``,
+		`[.. | numbers]`:           `[2,31,25,42]`,
+		`.users[1] | .name`:        `"bob"`,
+		`.name, .version`:          "\"dops\"\n2",
+		`.missing // "none"`:       `"none"`,
+		`.users[2].admin // false`: `false`,
+	}
+	for expression, want := range tests {
+		got, err := run(t, expression, input)
+		if err != nil {
+			t.Errorf("%s: %v", expression, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s: got %s, want %s", expression, got, want)
+		}
+	}
+}
+
+func TestOperators(t *testing.T) {
+	tests := map[string]string{
+		`1 + 2 * 3`:                      `7`,
+		`(1 + 2) * 3`:                    `9`,
+		`10 / 4`:                         `2.5`,
+		`10 % 3`:                         `1`,
+		`-.version`:                      `-2`,
+		`"a" + "b"`:                      `"ab"`,
+		`[1, 2] + [3]`:                   `[1,2,3]`,
+		`[1, 2, 3, 2] - [2]`:             `[1,3]`,
+		`{a: 1} + {b: 2}`:                `{"a":1,"b":2}`,
+		`{a: {b: 1}} * {a: {c: 2}}`:      `{"a":{"b":1,"c":2}}`,
+		`"a,b" / ","`:                    `["a","b"]`,
+		`null + 1`:                       `1`,
+		`1 == 1.0`:                       `true`,
+		`"a" < "b"`:                      `true`,
+		`null < false`:                   `true`,
+		`[1] < {}`:                       `true`,
+		`.version >= 2 and .name != "x"`: `true`,
+		`false or null`:                  `false`,
+		`.admin | not`:                   `true`,
+	}
+	for expression, want := range tests {
+		got, err := run(t, expression, input)
+		if err != nil {
+			t.Errorf("%s: %v", expression, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s: got %s, want %s", expression, got, want)
+		}
+	}
+}
+
+func TestConstruction(t *testing.T) {
+	tests := map[string]string{
+		`[.users[] | .age]`:         `[31,25,42]`,
+		`{name, version}`:           `{"name":"dops","version":2}`,
+		`{(.name): .version}`:       `{"dops":2}`,
+		`{"a b": 1, c: [.tags[0]]}`: `{"a b":1,"c":["cli"]}`,
+		`{user: .users[].name}`:     "{\"user\":\"alice\"}\n{\"user\":\"bob\"}\n{\"user\":\"carol\"}",
+		`"\(.name) v\(.version)"`:   `"dops v2"`,
+		`if .version > 1 then "new" elif .version == 1 then "old" else "none" end`: `"new"`,
+		`if .missing then 1 end`:                           `{"name":"dops","version":2,"tags":["cli","devops","go"],"users":[{"name":"alice","age":31,"admin":true},{"name":"bob","age":25,"admin":false},{"name":"carol","age":42}],"nested":{"a":{"b":null}}}`,
+		`if .missing then 1 else 2 end`:                    `2`,
+		`.version as $v | [.tags[] | . + ($v | tostring)]`: `["cli2","devops2","go2"]`,
+		`[.users[] | select(.admin)] | length`:             `1`,
+	}
+	for expression, want := range tests {
+		got, err := run(t, expression, input)
+		if err != nil {
+			t.Errorf("%s: %v", expression, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s: got %s, want %s", expression, got, want)
+		}
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	tests := map[string]string{
+		`.tags | length`:      `3`,
+		`.name | length`:      `4`,
+		`keys`:                `["name","nested","tags","users","version"]`,
+		`keys_unsorted`:       `["name","version","tags","users","nested"]`,
+		`.users | map(.name)`: `["alice","bob","carol"]`,
+		`.users | map(select(.age > 30)) | map(.name)`:    `["alice","carol"]`,
+		`.users | sort_by(.age) | map(.name)`:             `["bob","alice","carol"]`,
+		`.users | group_by(.admin) | map(length)`:         `[1,1,1]`,
+		`.users | map(.age) | add`:                        `98`,
+		`.users | map(.age) | sort | reverse`:             `[42,31,25]`,
+		`[3, 1, 3] | unique`:                              `[1,3]`,
+		`[[1, [2]], 3] | flatten`:                         `[1,2,3]`,
+		`.users[0] | to_entries | map(.key)`:              `["name","age","admin"]`,
+		`[{key: "a", value: 1}] | from_entries`:           `{"a":1}`,
+		`.users[0] | with_entries(select(.key != "age"))`: `{"name":"alice","admin":true}`,
+		`.users[0] | has("age")`:                          `true`,
+		`.tags | contains(["go"])`:                        `true`,
+		`.tags | join("-")`:                               `"cli-devops-go"`,
+		`"a,b,c" | split(",")`:                            `["a","b","c"]`,
+		`.name | ascii_upcase`:                            `"DOPS"`,
+		`"  x " | trim`:                                   `"x"`,
+		`.name | startswith("do")`:                        `true`,
+		`.name | ltrimstr("do")`:                          `"ps"`,
+		`.version | tostring`:                             `"2"`,
+		`"42" | tonumber`:                                 `42`,
+		`.tags | tojson`:                                  `"[\"cli\",\"devops\",\"go\"]"`,
+		`"[1]" | fromjson`:                                `[1]`,
+		`.users | map(type) | unique`:                     `["object"]`,
+		`[.[] | type]`:                                    `["string","number","array","array","object"]`,
+		`[range(3)]`:                                      `[0,1,2]`,
+		`[range(1; 3)]`:                                   `[1,2]`,
+		`first(.tags[])`:                                  `"cli"`,
+		`[limit(2; .tags[])]`:                             `["cli","devops"]`,
+		`[limit(0; .tags[])]`:                             `[]`,
+		`[limit(-1; .tags[])]`:                            `[]`,
+		`.tags | any(. == "go")`:                          `true`,
+		`.users | all(.age > 30)`:                         `false`,
+		`[paths] | length`:                                `21`,
+		`.users[0] | [leaf_paths]`:                        `[["name"],["age"],["admin"]]`,
+		`[.nested | recurse] | length`:                    `3`,
+		`.name | test("^d")`:                              `true`,
+		`"abc" | sub("b"; "x")`:                           `"axc"`,
+		`"abab" | gsub("a"; "x")`:                         `"xbxb"`,
+		`"v1.2" | capture("v(?P<major>[0-9]+)")`:          `{"major":"1"}`,
+		`[.[] | strings]`:                                 `["dops"]`,
+		`.users | map(.admin) | map(values)`:              `[true,false]`,
+		`[.tags[] | select(. != "cli")] | isempty(.[])`:   `false`,
+		`[empty]`:                             `[]`,
+		`.users | unique_by(.admin) | length`: `3`,
+		`.users[0] | getpath(["name"])`:       `"alice"`,
+		`.users | map_values(.age)`:           `[31,25,42]`,
+	}
+	for expression, want := range tests {
+		got, err := run(t, expression, input)
+		if err != nil {
+			t.Errorf("%s: %v", expression, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s: got %s, want %s", expression, got, want)
+		}
+	}
+}
+
+func TestFormats(t *testing.T) {
+	tests := map[string]string{
+		`.tags | @csv`:           `"\"cli\",\"devops\",\"go\""`,
+		`.tags | @tsv`:           `"cli\tdevops\tgo"`,
+		`.tags | @json`:          `"[\"cli\",\"devops\",\"go\"]"`,
+		`.name | @base64`:        `"ZG9wcw=="`,
+		`"ZG9wcw==" | @base64d`:  `"dops"`,
+		`"a b&c" | @uri`:         `"a%20b%26c"`,
+		`"<a href='x'>" | @html`: `"&lt;a href=&#39;x&#39;&gt;"`,
+		`"it's" | @sh`:           `"'it'\\''s'"`,
+		`@text "name: \(.name)"`: `"name: dops"`,
+		`@base64 "\(.name)"`:     `"ZG9wcw=="`,
+	}
+	for expression, want := range tests {
+		got, err := run(t, expression, input)
+		if err != nil {
+			t.Errorf("%s: %v", expression, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("%s: got %s, want %s", expression, got, want)
+		}
+	}
+}
+
+func TestVariables(t *testing.T) {
+	if got, err := run(t, `$ENV.DOPS_QUERY_TEST`, `null`); err != nil || got != `null` {
+		t.Errorf("got %s, %v", got, err)
+	}
+	os.Setenv("DOPS_QUERY_TEST", "set")
+	defer os.Unsetenv("DOPS_QUERY_TEST")
+	if got, err := run(t, `$ENV.DOPS_QUERY_TEST`, `null`); err != nil || got != `"set"` {
+		t.Errorf("got %s, %v", got, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	compileErrors := []string{`.[`, `{a:}`, `"\(.a"`, `unknown_function`, `1 +`}
+	for _, expression := range compileErrors {
+		if _, err := Compile(expression); err == nil {
+			t.Errorf("%s: expected a compile error", expression)
+		}
+	}
+
+	runErrors := []string{`.name[0]`, `.tags.name`, `{} - 1`, `.tags | keys | .[0] | ascii_upcase`, `error("failed")`, `$undefined`, `"x" | tonumber`, `1 / 0`, `[limit(1; range(1e12))]`}
+	for _, expression := range runErrors {
+		if _, err := run(t, expression, input); err == nil {
+			t.Errorf("%s: expected an error", expression)
+		}
+	}
+}
+
+func TestQueryEveryYAMLDocument(t *testing.T) {
+	dir := t.TempDir()
+	manifests := filepath.Join(dir, "manifests.yaml")
+	output := filepath.Join(dir, "kinds.txt")
+	if err := ioutil.WriteFile(manifests, []byte("kind: Service\n---\nkind: Deployment\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	app := &cli.App{Commands: Module{}.GetModuleCommands()}
+	if err := app.Run([]string{"dops", "query", ".kind", "-r", "-i", manifests, "-o", output}); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ioutil.ReadFile(output); err != nil || string(data) != "Service\nDeployment\n" {
+		t.Errorf("got %q, %v", data, err)
+	}
+}
@@ -0,0 +1,373 @@
+package query
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/dops-cli/dops/module/convert"
+)
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case int64, float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case *convert.Object:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func isTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	}
+	return true
+}
+
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// numberValue returns whole numbers as int64, so that they are printed without decimals
+func numberValue(f float64) interface{} {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int64(f)
+	}
+	return f
+}
+
+func copyObject(o *convert.Object) *convert.Object {
+	c := convert.NewObject()
+	for _, key := range o.Keys() {
+		value, _ := o.Get(key)
+		c.Set(key, value)
+	}
+	return c
+}
+
+// typeOrder defines the order of types when comparing values: null < false < true < numbers < strings < arrays < objects
+func typeOrder(value interface{}) int {
+	switch v := value.(type) {
+	case nil:
+		return 0
+	case bool:
+		if v {
+			return 2
+		}
+		return 1
+	case int64, float64:
+		return 3
+	case string:
+		return 4
+	case []interface{}:
+		return 5
+	}
+	return 6
+}
+
+func compareValues(a, b interface{}) int {
+	ta, tb := typeOrder(a), typeOrder(b)
+	if ta != tb {
+		if ta < tb {
+			return -1
+		}
+		return 1
+	}
+
+	switch av := a.(type) {
+	case int64, float64:
+		fa, _ := toFloat(av)
+		fb, _ := toFloat(b)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(av, b.(string))
+	case []interface{}:
+		bv := b.([]interface{})
+		for i := 0; i < len(av) && i < len(bv); i++ {
+			if c := compareValues(av[i], bv[i]); c != 0 {
+				return c
+			}
+		}
+		return compareValues(int64(len(av)), int64(len(bv)))
+	case *convert.Object:
+		bv := b.(*convert.Object)
+		ak, bk := sortedKeys(av), sortedKeys(bv)
+		if c := compareValues(stringsToValues(ak), stringsToValues(bk)); c != 0 {
+			return c
+		}
+		for _, key := range ak {
+			x, _ := av.Get(key)
+			y, _ := bv.Get(key)
+			if c := compareValues(x, y); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+func sortedKeys(o *convert.Object) []string {
+	keys := append([]string{}, o.Keys()...)
+	sort.Strings(keys)
+	return keys
+}
+
+func stringsToValues(s []string) []interface{} {
+	values := make([]interface{}, len(s))
+	for i, v := range s {
+		values[i] = v
+	}
+	return values
+}
+
+func binaryOperation(op string, left, right interface{}) (interface{}, error) {
+	switch op {
+	case "==":
+		return compareValues(left, right) == 0, nil
+	case "!=":
+		return compareValues(left, right) != 0, nil
+	case "<":
+		return compareValues(left, right) < 0, nil
+	case "<=":
+		return compareValues(left, right) <= 0, nil
+	case ">":
+		return compareValues(left, right) > 0, nil
+	case ">=":
+		return compareValues(left, right) >= 0, nil
+	}
+
+	if op == "+" {
+		if left == nil {
+			return right, nil
+		}
+		if right == nil {
+			return left, nil
+		}
+	}
+
+	li, lInt := left.(int64)
+	ri, rInt := right.(int64)
+	lf, lNum := toFloat(left)
+	rf, rNum := toFloat(right)
+
+	if lNum && rNum {
+		switch op {
+		case "+":
+			if lInt && rInt {
+				return li + ri, nil
+			}
+			return lf + rf, nil
+		case "-":
+			if lInt && rInt {
+				return li - ri, nil
+			}
+			return lf - rf, nil
+		case "*":
+			if lInt && rInt {
+				return li * ri, nil
+			}
+			return lf * rf, nil
+		case "/":
+			if rf == 0 {
+				return nil, fmt.Errorf("cannot divide %v by zero", left)
+			}
+			return numberValue(lf / rf), nil
+		case "%":
+			if int64(rf) == 0 {
+				return nil, fmt.Errorf("cannot divide %v by zero", left)
+			}
+			return int64(lf) % int64(rf), nil
+		}
+	}
+
+	switch l := left.(type) {
+	case string:
+		if r, ok := right.(string); ok {
+			switch op {
+			case "+":
+				return l + r, nil
+			case "/":
+				return stringsToValues(strings.Split(l, r)), nil
+			}
+		}
+	case []interface{}:
+		if r, ok := right.([]interface{}); ok {
+			switch op {
+			case "+":
+				return append(append([]interface{}{}, l...), r...), nil
+			case "-":
+				var result []interface{}
+				for _, item := range l {
+					found := false
+					for _, remove := range r {
+						if compareValues(item, remove) == 0 {
+							found = true
+							break
+						}
+					}
+					if !found {
+						result = append(result, item)
+					}
+				}
+				if result == nil {
+					result = []interface{}{}
+				}
+				return result, nil
+			}
+		}
+	case *convert.Object:
+		if r, ok := right.(*convert.Object); ok {
+			switch op {
+			case "+":
+				result := copyObject(l)
+				for _, key := range r.Keys() {
+					value, _ := r.Get(key)
+					result.Set(key, value)
+				}
+				return result, nil
+			case "*":
+				return deepMerge(l, r), nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%s (%s) and %s (%s) cannot be combined with '%s'", typeName(left), convert.ToString(left), typeName(right), convert.ToString(right), op)
+}
+
+func deepMerge(a, b *convert.Object) *convert.Object {
+	result := copyObject(a)
+	for _, key := range b.Keys() {
+		bv, _ := b.Get(key)
+		av, _ := result.Get(key)
+		ao, aIsObject := av.(*convert.Object)
+		bo, bIsObject := bv.(*convert.Object)
+		if aIsObject && bIsObject {
+			result.Set(key, deepMerge(ao, bo))
+		} else {
+			result.Set(key, bv)
+		}
+	}
+	return result
+}
+
+// applyFormat implements the format strings like @csv, @base64 and @uri
+func applyFormat(name string, value interface{}) (string, error) {
+	switch name {
+	case "text":
+		if value == nil {
+			return "null", nil
+		}
+		return convert.ToString(value), nil
+	case "json":
+		return jsonString(value), nil
+	case "csv", "tsv":
+		list, ok := value.([]interface{})
+		if !ok {
+			return "", fmt.Errorf("@%s requires an array, got %s", name, typeName(value))
+		}
+		fields := make([]string, len(list))
+		for i, item := range list {
+			s := convert.ToString(item)
+			if name == "csv" {
+				if _, isString := item.(string); isString {
+					s = `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+				}
+			} else {
+				s = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`).Replace(s)
+			}
+			fields[i] = s
+		}
+		if name == "csv" {
+			return strings.Join(fields, ","), nil
+		}
+		return strings.Join(fields, "\t"), nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString([]byte(convert.ToString(value))), nil
+	case "base64d":
+		s := convert.ToString(value)
+		decoded, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			decoded, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+			if err != nil {
+				return "", err
+			}
+		}
+		return string(decoded), nil
+	case "uri":
+		return uriEscape(convert.ToString(value)), nil
+	case "html":
+		return html.EscapeString(convert.ToString(value)), nil
+	case "sh":
+		quote := func(v interface{}) string {
+			return "'" + strings.ReplaceAll(convert.ToString(v), "'", `'\''`) + "'"
+		}
+		if list, ok := value.([]interface{}); ok {
+			parts := make([]string, len(list))
+			for i, item := range list {
+				parts[i] = quote(item)
+			}
+			return strings.Join(parts, " "), nil
+		}
+		return quote(value), nil
+	}
+	return "", fmt.Errorf("unknown format @%s", name)
+}
+
+func jsonString(value interface{}) string {
+	data, err := convert.Encode(convert.JSON, value, convert.EncodeOptions{Compact: true})
+	if err != nil {
+		return convert.ToString(value)
+	}
+	return strings.TrimSuffix(string(data), "\n")
+}
+
+// uriEscape percent-encodes all bytes except the unreserved characters of RFC 3986, like jq does
+func uriEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -142,7 +142,9 @@ The pattern could be a timestamp, or the hashcode of the file, among others.`,
 					}
 
 					if backup {
-						utils.WriteFile(backupFilePath, []byte(file+"|"+newName+"\n"), true)
+						if err := utils.WriteFile(backupFilePath, []byte(file+"|"+newName+"\n"), true); err != nil {
+							return err
+						}
 					}
 
 				}
@@ -164,7 +166,9 @@ The pattern could be a timestamp, or the hashcode of the file, among others.`,
 						}
 					}
 
-					utils.WriteFile(backupFilePath, []byte(strings.Join(lines, "\n")), false)
+					if err := utils.WriteFile(backupFilePath, []byte(strings.Join(lines, "\n")), false); err != nil {
+						return err
+					}
 				}
 
 				return nil
@@ -0,0 +1,174 @@
+package serve
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Route is a canned response of the mock server
+type Route struct {
+	// Method is matched case insensitive, an empty method or '*' matches all methods
+	Method string `yaml:"method"`
+	// Path can contain parameters like /users/{id} and end with /* to match all paths below it
+	Path    string            `yaml:"path"`
+	Status  int               `yaml:"status"`
+	Headers map[string]string `yaml:"headers"`
+	// Body is a Go template, which is executed with a RequestData
+	Body string `yaml:"body"`
+	// File is sent instead of Body, it's relative to the routes file
+	File  string `yaml:"file"`
+	Delay string `yaml:"delay"`
+
+	delay    time.Duration
+	template *template.Template
+}
+
+// RequestData is passed to the body templates of routes
+type RequestData struct {
+	Method  string
+	Path    string
+	Params  map[string]string
+	Query   url.Values
+	Headers http.Header
+	Body    string
+}
+
+// LoadRoutes reads the routes of the mock server from a YAML file like:
+//
+//	routes:
+//	  - method: GET
+//	    path: /users/{id}
+//	    status: 200
+//	    headers:
+//	      Content-Type: application/json
+//	    body: '{"id": "{{.Params.id}}", "page": "{{.Query.Get "page"}}"}'
+//	    delay: 200ms
+func LoadRoutes(path string) ([]*Route, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Routes []*Route `yaml:"routes"`
+	}
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+	if len(file.Routes) == 0 {
+		return nil, fmt.Errorf("%s: %w", path, errNoRoutes)
+	}
+
+	for i, r := range file.Routes {
+		if r.Path == "" {
+			return nil, fmt.Errorf("route %d in %s has no path", i+1, path)
+		}
+		if r.Status == 0 {
+			r.Status = http.StatusOK
+		}
+		if r.Delay != "" {
+			if r.delay, err = time.ParseDuration(r.Delay); err != nil {
+				return nil, fmt.Errorf("route %s in %s has an invalid delay: %w", r.Path, path, err)
+			}
+		}
+		if r.File != "" {
+			content, err := ioutil.ReadFile(filepath.Join(filepath.Dir(path), r.File))
+			if err != nil {
+				return nil, err
+			}
+			r.Body = string(content)
+		}
+		if r.template, err = template.New(r.Path).Parse(r.Body); err != nil {
+			return nil, fmt.Errorf("route %s in %s has an invalid body template: %w", r.Path, path, err)
+		}
+	}
+	return file.Routes, nil
+}
+
+// match returns the path parameters, if the route matches the request
+func (r *Route) match(method, path string) (map[string]string, bool) {
+	if r.Method != "" && r.Method != "*" && !strings.EqualFold(r.Method, method) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	pattern := strings.Split(strings.Trim(r.Path, "/"), "/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, p := range pattern {
+		if p == "*" && i == len(pattern)-1 {
+			params["*"] = strings.Join(segments[i:], "/")
+			return params, i <= len(segments)
+		}
+		if i >= len(segments) {
+			return nil, false
+		}
+		if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+			params[p[1:len(p)-1]] = segments[i]
+			continue
+		}
+		if p != segments[i] {
+			return nil, false
+		}
+	}
+	return params, len(pattern) == len(segments)
+}
+
+// mockHandler answers requests with the first matching route. Requests without a matching route are passed to next.
+func mockHandler(routes []*Route, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for _, route := range routes {
+			params, ok := route.match(req.Method, req.URL.Path)
+			if !ok {
+				continue
+			}
+
+			body, _ := ioutil.ReadAll(req.Body)
+			data := RequestData{
+				Method:  req.Method,
+				Path:    req.URL.Path,
+				Params:  params,
+				Query:   req.URL.Query(),
+				Headers: req.Header,
+				Body:    string(body),
+			}
+			var out bytes.Buffer
+			if err := route.template.Execute(&out, data); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			if route.delay > 0 {
+				select {
+				case <-time.After(route.delay):
+				case <-req.Context().Done():
+					return
+				}
+			}
+			for name, value := range route.Headers {
+				w.Header().Set(name, value)
+			}
+			w.WriteHeader(route.Status)
+			_, _ = w.Write(out.Bytes())
+			return
+		}
+
+		if next == nil {
+			http.NotFound(w, req)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// errNoRoutes is returned if a routes file contains no routes
+var errNoRoutes = errors.New("the routes file contains no routes")
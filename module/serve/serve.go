@@ -0,0 +1,270 @@
+package serve
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// Options configure the handler of the server
+type Options struct {
+	// Dir is served as static files, it's optional in mock mode
+	Dir       string
+	NoListing bool
+	Routes    []*Route
+	CORS      bool
+	Gzip      bool
+	User      string
+	Password  string
+}
+
+// NewHandler returns the handler of the server. Requests are answered by the first matching route,
+// then by the static files of the directory.
+func NewHandler(o Options) http.Handler {
+	var handler http.Handler
+	if o.Dir != "" {
+		var fs http.FileSystem = http.Dir(o.Dir)
+		if o.NoListing {
+			fs = noListing{fs}
+		}
+		handler = http.FileServer(fs)
+	}
+	if len(o.Routes) > 0 {
+		handler = mockHandler(o.Routes, handler)
+	}
+	if handler == nil {
+		handler = http.NotFoundHandler()
+	}
+
+	if o.Gzip {
+		handler = compress(handler)
+	}
+	if o.User != "" {
+		handler = basicAuth(handler, o.User, o.Password)
+	}
+	if o.CORS {
+		handler = cors(handler)
+	}
+	return handler
+}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:      "serve",
+			Usage:     "Serves files and mock responses over HTTP",
+			ArgsUsage: "[DIR]",
+			// Flags are also accepted after the directory
+			InterspersedFlags: true,
+			Description: `Serve starts a web server for local testing, which serves the files of DIR (default: the current directory) with directory listings.
+Every request is logged with its status, size and duration.
+
+With --mock canned responses are returned for requests, which match a route in a YAML file:
+
+  routes:
+    - method: GET
+      path: /users/{id}
+      status: 200
+      headers:
+        Content-Type: application/json
+      body: '{"id": "{{.Params.id}}", "page": "{{.Query.Get "page"}}"}'
+      delay: 200ms
+    - method: POST
+      path: /webhooks/*
+      status: 204
+
+A path can contain parameters like {id} and end with /* to match all paths below it.
+The body is a Go template with .Method, .Path, .Params, .Query, .Headers and .Body of the request, 'file' sends a file instead.
+If a DIR is given in mock mode, requests without a matching route are answered with its files.
+
+--tls serves HTTPS with a self-signed certificate, which is created at start. Clients must skip the verification, like 'dops http -k'.`,
+			Category: categories.Web,
+			Examples: []cli.Example{
+				{
+					ShortDescription: "Serve a directory on port 8080",
+					Usage:            "dops serve --port 8080 ./dir",
+				},
+				{
+					ShortDescription: "Serve files to other machines over HTTPS with basic authentication",
+					Usage:            "dops serve --host 0.0.0.0 --tls --user admin:secret ./dir",
+				},
+				{
+					ShortDescription: "Answer requests with canned responses",
+					Usage:            "dops serve --mock routes.yaml --cors",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				o := Options{
+					Dir:       c.Args().First(),
+					NoListing: c.Bool("no-listing"),
+					CORS:      c.Bool("cors"),
+					Gzip:      c.Bool("gzip"),
+				}
+				if c.NArg() > 1 {
+					return errors.New("only one directory can be served")
+				}
+				if path := c.Path("mock"); path != "" {
+					routes, err := LoadRoutes(path)
+					if err != nil {
+						return err
+					}
+					o.Routes = routes
+				} else if o.Dir == "" {
+					o.Dir = "."
+				}
+				if o.Dir != "" {
+					if stat, err := os.Stat(o.Dir); err != nil {
+						return err
+					} else if !stat.IsDir() {
+						return errors.New(o.Dir + " is not a directory")
+					}
+				}
+				if user := c.String("user"); user != "" {
+					credentials := strings.SplitN(user, ":", 2)
+					if len(credentials) != 2 || credentials[0] == "" {
+						return errors.New("--user must be USER:PASSWORD")
+					}
+					o.User, o.Password = credentials[0], credentials[1]
+				}
+
+				handler := NewHandler(o)
+				if !c.Bool("quiet") {
+					handler = logRequests(handler, color.NewColorizer(true), func(line string) { say.Text(line) })
+				}
+				return listenAndServe(c, handler)
+			},
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:    "port",
+					Aliases: []string{"p"},
+					Usage:   "Listens on `PORT`, 0 picks a free port",
+					Value:   8080,
+				},
+				&cli.StringFlag{
+					Name:  "host",
+					Usage: "Listens on `HOST`, use 0.0.0.0 to accept connections from other machines",
+					Value: "localhost",
+				},
+				&cli.PathFlag{
+					Name:      "mock",
+					Aliases:   []string{"m"},
+					Usage:     "Answers requests with the routes of `FILE`",
+					TakesFile: true,
+				},
+				&cli.BoolFlag{
+					Name:  "cors",
+					Usage: "Allows cross-origin requests from all origins",
+				},
+				&cli.BoolFlag{
+					Name:    "gzip",
+					Aliases: []string{"z"},
+					Usage:   "Compresses responses with gzip",
+				},
+				&cli.StringFlag{
+					Name:    "user",
+					Aliases: []string{"u"},
+					Usage:   "Requires basic authentication with `USER:PASSWORD`",
+				},
+				&cli.BoolFlag{
+					Name:  "tls",
+					Usage: "Serves HTTPS with a self-signed certificate",
+				},
+				&cli.PathFlag{
+					Name:      "cert",
+					Usage:     "Serves HTTPS with the certificate `FILE`, requires --key",
+					TakesFile: true,
+				},
+				&cli.PathFlag{
+					Name:      "key",
+					Usage:     "Uses the private key `FILE` for --cert",
+					TakesFile: true,
+				},
+				&cli.BoolFlag{
+					Name:  "no-listing",
+					Usage: "Does not list the files of directories without index.html",
+				},
+				&cli.BoolFlag{
+					Name:    "quiet",
+					Aliases: []string{"q"},
+					Usage:   "Does not log requests",
+				},
+			},
+		},
+	}
+}
+
+// listenAndServe runs the server until it's interrupted
+func listenAndServe(c *cli.Context, handler http.Handler) error {
+	host := c.String("host")
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(c.Int("port"))))
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{Handler: handler}
+	scheme := "http"
+	switch {
+	case c.Path("cert") != "" || c.Path("key") != "":
+		if c.Path("cert") == "" || c.Path("key") == "" {
+			listener.Close()
+			return errors.New("--cert and --key must be used together")
+		}
+		cert, err := tls.LoadX509KeyPair(c.Path("cert"), c.Path("key"))
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		scheme = "https"
+	case c.Bool("tls"):
+		cert, fingerprint, err := SelfSignedCertificate(host)
+		if err != nil {
+			listener.Close()
+			return fmt.Errorf("could not create certificate: %w", err)
+		}
+		server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		scheme = "https"
+		say.Info(fmt.Sprintf("Using a self-signed certificate with the SHA-256 fingerprint %X", fingerprint))
+	}
+	if server.TLSConfig != nil {
+		listener = tls.NewListener(listener, server.TLSConfig)
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-interrupt
+		// Running requests, like delayed mock responses, are finished before exiting
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	say.Success(fmt.Sprintf("Serving on %s://%s (press Ctrl+C to stop)", scheme, net.JoinHostPort(host, strconv.Itoa(port))))
+	if err := server.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	<-stopped
+	return nil
+}
@@ -0,0 +1,122 @@
+package serve
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testHandler(t *testing.T, o Options) *httptest.Server {
+	server := httptest.NewServer(NewHandler(o))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func get(t *testing.T, r *http.Request) (*http.Response, string) {
+	resp, err := http.DefaultTransport.RoundTrip(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+func TestMock(t *testing.T) {
+	dir := t.TempDir()
+	routes := `routes:
+  - method: GET
+    path: /users/{id}
+    headers:
+      Content-Type: application/json
+    body: '{"id": "{{.Params.id}}", "page": "{{.Query.Get "page"}}"}'
+  - method: post
+    path: /webhooks/*
+    status: 202
+    body: '{{.Body}} {{index .Params "*"}}'
+  - path: /fixture
+    file: fixture.txt
+`
+	if err := ioutil.WriteFile(filepath.Join(dir, "routes.yaml"), []byte(routes), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "fixture.txt"), []byte("from file"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadRoutes(filepath.Join(dir, "routes.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := testHandler(t, Options{Routes: loaded})
+
+	tests := []struct {
+		method, path, body string
+		status             int
+		want               string
+	}{
+		{"GET", "/users/7?page=2", "", http.StatusOK, `{"id": "7", "page": "2"}`},
+		{"POST", "/users/7", "", http.StatusNotFound, "404 page not found\n"},
+		{"GET", "/users/7/posts", "", http.StatusNotFound, "404 page not found\n"},
+		{"POST", "/webhooks/github/push", "event", http.StatusAccepted, "event github/push"},
+		{"DELETE", "/fixture", "", http.StatusOK, "from file"},
+	}
+	for _, tt := range tests {
+		r, _ := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		resp, body := get(t, r)
+		if resp.StatusCode != tt.status || body != tt.want {
+			t.Errorf("%s %s: got %d %q, want %d %q", tt.method, tt.path, resp.StatusCode, body, tt.status, tt.want)
+		}
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "empty.yaml"), []byte("routes: []"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRoutes(filepath.Join(dir, "empty.yaml")); err == nil {
+		t.Error("expected an error for a file without routes")
+	}
+}
+
+func TestStatic(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	content := strings.Repeat("hello ", 100)
+	if err := ioutil.WriteFile(filepath.Join(dir, "sub", "a.txt"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	server := testHandler(t, Options{Dir: dir, CORS: true, Gzip: true, User: "admin", Password: "secret", NoListing: true})
+
+	r, _ := http.NewRequest("GET", server.URL+"/sub/a.txt", nil)
+	if resp, _ := get(t, r); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("request without credentials got status %d", resp.StatusCode)
+	}
+
+	r.SetBasicAuth("admin", "secret")
+	r.Header.Set("Accept-Encoding", "gzip")
+	resp, body := get(t, r)
+	if resp.Header.Get("Content-Encoding") != "gzip" || resp.Header.Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatalf("unexpected headers: %v", resp.Header)
+	}
+	gz, err := gzip.NewReader(strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded, _ := ioutil.ReadAll(gz); string(decoded) != content {
+		t.Errorf("got %q", decoded)
+	}
+
+	r, _ = http.NewRequest("GET", server.URL+"/sub/", nil)
+	r.SetBasicAuth("admin", "secret")
+	if resp, _ := get(t, r); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("directory was listed with status %d", resp.StatusCode)
+	}
+}
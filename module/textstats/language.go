@@ -0,0 +1,41 @@
+package textstats
+
+import "strings"
+
+// stopWords contains the most common words of each language.
+// The language, whose stop words appear most often in a text, is likely the language of the text.
+var stopWords = map[string][]string{
+	"English":    strings.Fields("the and of to a in is it that for you was with on as are be this have not"),
+	"German":     strings.Fields("der die und das ist nicht ein eine zu den von mit sich des auf für im dem auch es"),
+	"French":     strings.Fields("le la les et des est un une du en que qui dans pour pas sur au ne avec"),
+	"Spanish":    strings.Fields("el la de que y los las en un una por con para es del se no al lo"),
+	"Italian":    strings.Fields("il di che e la per un una non sono della con del gli le si nel anche"),
+	"Dutch":      strings.Fields("de het een en van is dat op te in niet zijn voor met die ook er maar"),
+	"Portuguese": strings.Fields("o a de que e os as um uma do da em para com não por se mais no na"),
+}
+
+// minStopWords is the amount of stop words a text needs, before its language is guessed
+const minStopWords = 5
+
+// guessLanguage guesses the language of a text by the frequency of common words.
+// If no language is clearly ahead, the language is unknown.
+func guessLanguage(wordCounts map[string]int) string {
+	best, bestScore, tie := "unknown", 0, false
+	for language, words := range stopWords {
+		score := 0
+		for _, word := range words {
+			score += wordCounts[word]
+		}
+		switch {
+		case score > bestScore:
+			best, bestScore, tie = language, score, false
+		case score == bestScore:
+			tie = true
+		}
+	}
+
+	if bestScore < minStopWords || tie {
+		return "unknown"
+	}
+	return best
+}
@@ -0,0 +1,261 @@
+package textstats
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Character classes
+const (
+	Letters     = "letters"
+	Digits      = "digits"
+	Whitespace  = "whitespace"
+	Punctuation = "punctuation"
+	Symbols     = "symbols"
+	Control     = "control"
+	Other       = "other"
+)
+
+var characterClasses = []string{Letters, Digits, Whitespace, Punctuation, Symbols, Control, Other}
+
+// maxLineLength limits the text of longest lines in the report, their length is always reported completely
+const maxLineLength = 80
+
+// Line is one of the longest lines of a text
+type Line struct {
+	File   string
+	Number int
+	Length int
+	Text   string
+}
+
+// Stats contains the statistics of a text
+type Stats struct {
+	Name       string
+	Lines      int
+	Words      int
+	Characters int
+	Bytes      int
+	Encoding   string
+	Language   string
+
+	wordCounts   map[string]int
+	classes      map[string]int
+	longestLines []Line
+	invalidUTF8  bool
+	nonASCII     bool
+	head         []byte
+}
+
+func newStats(name string) *Stats {
+	return &Stats{Name: name, wordCounts: map[string]int{}, classes: map[string]int{}}
+}
+
+// Analyze reads r line by line and collects its statistics
+func Analyze(name string, r io.Reader, longest int) (*Stats, error) {
+	s := newStats(name)
+	reader := bufio.NewReader(r)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			s.Lines++
+			s.addLine(line, longest)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.Encoding = guessEncoding(s.head, s.invalidUTF8, s.nonASCII)
+	s.Language = guessLanguage(s.wordCounts)
+	return s, nil
+}
+
+func (s *Stats) addLine(line []byte, longest int) {
+	s.Bytes += len(line)
+	if len(s.head) < 4 {
+		s.head = append(s.head, line[:min(4-len(s.head), len(line))]...)
+	}
+	if !utf8.Valid(line) {
+		s.invalidUTF8 = true
+	}
+	if !s.nonASCII {
+		for _, b := range line {
+			if b >= utf8.RuneSelf {
+				s.nonASCII = true
+				break
+			}
+		}
+	}
+
+	text := string(line)
+	if s.Lines == 1 {
+		text = strings.TrimPrefix(text, "\ufeff")
+	}
+
+	for _, r := range text {
+		s.Characters++
+		s.classes[characterClass(r)]++
+	}
+
+	for _, word := range strings.Fields(text) {
+		s.Words++
+		if normalized := normalizeWord(word); normalized != "" {
+			s.wordCounts[normalized]++
+		}
+	}
+
+	text = strings.TrimRight(text, "\r\n")
+	s.addLongestLine(Line{File: s.Name, Number: s.Lines, Length: utf8.RuneCountInString(text), Text: text}, longest)
+}
+
+// addLongestLine keeps the longest lines sorted by their length
+func (s *Stats) addLongestLine(line Line, longest int) {
+	if longest <= 0 || (len(s.longestLines) == longest && s.longestLines[longest-1].Length >= line.Length) {
+		return
+	}
+	if runes := []rune(line.Text); len(runes) > maxLineLength {
+		line.Text = string(runes[:maxLineLength]) + "..."
+	}
+
+	i := sort.Search(len(s.longestLines), func(i int) bool {
+		return s.longestLines[i].Length < line.Length
+	})
+	s.longestLines = append(s.longestLines, Line{})
+	copy(s.longestLines[i+1:], s.longestLines[i:])
+	s.longestLines[i] = line
+	if len(s.longestLines) > longest {
+		s.longestLines = s.longestLines[:longest]
+	}
+}
+
+// Merge adds the statistics of other to s
+func (s *Stats) Merge(other *Stats, longest int) {
+	s.Lines += other.Lines
+	s.Words += other.Words
+	s.Characters += other.Characters
+	s.Bytes += other.Bytes
+	for word, count := range other.wordCounts {
+		s.wordCounts[word] += count
+	}
+	for class, count := range other.classes {
+		s.classes[class] += count
+	}
+	for _, line := range other.longestLines {
+		s.addLongestLine(line, longest)
+	}
+	s.Encoding = mergeEncodings(s.Encoding, other.Encoding)
+	s.Language = guessLanguage(s.wordCounts)
+}
+
+// UniqueWords returns the amount of different words, ignoring case and surrounding punctuation
+func (s *Stats) UniqueWords() int {
+	return len(s.wordCounts)
+}
+
+// WordCount is the frequency of a single word
+type WordCount struct {
+	Word  string
+	Count int
+}
+
+// TopWords returns the n most frequent words
+func (s *Stats) TopWords(n int) []WordCount {
+	words := make([]WordCount, 0, len(s.wordCounts))
+	for word, count := range s.wordCounts {
+		words = append(words, WordCount{Word: word, Count: count})
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].Count != words[j].Count {
+			return words[i].Count > words[j].Count
+		}
+		return words[i].Word < words[j].Word
+	})
+	if len(words) > n {
+		words = words[:n]
+	}
+	return words
+}
+
+// LongestLines returns the longest lines, starting with the longest
+func (s *Stats) LongestLines() []Line {
+	return s.longestLines
+}
+
+// CharacterClasses returns how many characters belong to each character class
+func (s *Stats) CharacterClasses() map[string]int {
+	return s.classes
+}
+
+func characterClass(r rune) string {
+	switch {
+	case unicode.IsLetter(r):
+		return Letters
+	case unicode.IsDigit(r):
+		return Digits
+	case unicode.IsSpace(r):
+		return Whitespace
+	case unicode.IsPunct(r):
+		return Punctuation
+	case unicode.IsSymbol(r):
+		return Symbols
+	case unicode.IsControl(r):
+		return Control
+	}
+	return Other
+}
+
+// normalizeWord lowercases a word and removes surrounding punctuation
+func normalizeWord(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+}
+
+// guessEncoding detects the encoding by its byte order mark or by checking if the text is valid UTF-8
+func guessEncoding(head []byte, invalidUTF8, nonASCII bool) string {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xEF, 0xBB, 0xBF}):
+		return "UTF-8 with BOM"
+	case bytes.HasPrefix(head, []byte{0xFF, 0xFE}):
+		return "UTF-16LE"
+	case bytes.HasPrefix(head, []byte{0xFE, 0xFF}):
+		return "UTF-16BE"
+	case invalidUTF8:
+		return "binary or legacy 8-bit encoding"
+	case len(head) == 0:
+		return "empty"
+	case !nonASCII:
+		return "ASCII"
+	}
+	return "UTF-8"
+}
+
+// mergeEncodings returns the encoding of two combined texts. ASCII is a subset of UTF-8.
+func mergeEncodings(a, b string) string {
+	switch {
+	case a == "" || a == "empty" || a == b:
+		return b
+	case b == "empty":
+		return a
+	case a == "ASCII" && b == "UTF-8", a == "UTF-8" && b == "ASCII":
+		return "UTF-8"
+	}
+	return "mixed"
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
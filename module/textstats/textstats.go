@@ -0,0 +1,244 @@
+package textstats
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/module/convert"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:      "text-stats",
+			Aliases:   []string{"textstats", "ts", "wc"},
+			Usage:     "Shows statistics about texts",
+			ArgsUsage: "[FILES...]",
+			Description: `Text-stats counts lines, words, characters and bytes of files, globs, a URL or stdin.
+It also reports the amount of unique words, the most frequent words, the longest lines, the distribution of character classes and guesses the encoding and language of the text.
+Words are compared case insensitive and without surrounding punctuation.
+If multiple files are given, a total over all files is added.`,
+			Category: categories.Statistics,
+			Examples: []cli.Example{
+				{
+					ShortDescription: "Show statistics of a file",
+					Usage:            "dops text-stats README.md",
+				},
+				{
+					ShortDescription: "Show the 20 most frequent words of all markdown files as JSON",
+					Usage:            "dops text-stats --top 20 --json 'docs/*.md'",
+				},
+				{
+					ShortDescription: "Show statistics of stdin",
+					Usage:            "cat README.md | dops text-stats",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				top := c.Int("top")
+				longest := c.Int("longest")
+
+				var files []string
+				for _, pattern := range c.Args().Slice() {
+					matches, err := filepath.Glob(pattern)
+					if err != nil {
+						return err
+					}
+					if len(matches) == 0 {
+						return errors.New("no files found: " + pattern)
+					}
+					files = append(files, matches...)
+				}
+
+				var results []*Stats
+				if input := c.Path("input"); input != "" || len(files) == 0 {
+					r, err := utils.InputReader(input)
+					if err != nil {
+						return err
+					}
+					name := input
+					if name == "" {
+						name = "stdin"
+					}
+					s, err := Analyze(name, r, longest)
+					r.Close()
+					if err != nil {
+						return err
+					}
+					results = append(results, s)
+				}
+				for _, file := range files {
+					f, err := os.Open(file)
+					if err != nil {
+						return err
+					}
+					s, err := Analyze(file, f, longest)
+					f.Close()
+					if err != nil {
+						return err
+					}
+					results = append(results, s)
+				}
+
+				if len(results) > 1 {
+					total := newStats("total")
+					for _, s := range results {
+						total.Merge(s, longest)
+					}
+					results = append(results, total)
+				}
+
+				var out string
+				if c.Bool("json") {
+					list := make([]interface{}, len(results))
+					for i, s := range results {
+						list[i] = toObject(s, top)
+					}
+					data, err := convert.Encode(convert.JSON, list, convert.EncodeOptions{})
+					if err != nil {
+						return err
+					}
+					out = strings.TrimSuffix(string(data), "\n")
+				} else {
+					colored := color.NewColorizer(c.String("output") == "")
+					reports := make([]string, len(results))
+					for i, s := range results {
+						reports[i] = report(s, top, colored)
+					}
+					out = strings.Join(reports, "\n\n")
+				}
+
+				return utils.WriteOutput(c, []string{out})
+			},
+			Flags: append([]cli.Flag{
+				&cli.PathFlag{
+					Name:      "input",
+					Aliases:   []string{"i"},
+					Usage:     "use `FILE` as input, accepts a file, URL or stdin if not set",
+					TakesFile: true,
+				},
+				&cli.IntFlag{
+					Name:    "top",
+					Aliases: []string{"n"},
+					Usage:   "Shows the `N` most frequent words",
+					Value:   10,
+				},
+				&cli.IntFlag{
+					Name:    "longest",
+					Aliases: []string{"l"},
+					Usage:   "Shows the `N` longest lines",
+					Value:   3,
+				},
+				&cli.BoolFlag{
+					Name:    "json",
+					Aliases: []string{"j"},
+					Usage:   "Outputs the statistics as JSON",
+				},
+			}, utils.OutputFlags()...),
+		},
+	}
+}
+
+// report renders the statistics as tables
+func report(s *Stats, top int, colored color.Colorizer) string {
+	sections := []string{say.Section(s.Name, colored)}
+
+	sections = append(sections, say.Table([][]string{
+		{"Lines", strconv.Itoa(s.Lines)},
+		{"Words", strconv.Itoa(s.Words)},
+		{"Unique words", strconv.Itoa(s.UniqueWords())},
+		{"Characters", strconv.Itoa(s.Characters)},
+		{"Bytes", strconv.Itoa(s.Bytes)},
+		{"Encoding", s.Encoding},
+		{"Language", s.Language},
+	}, false, colored))
+
+	classes := [][]string{{"Character class", "Count", "Share"}}
+	for _, class := range characterClasses {
+		count := s.CharacterClasses()[class]
+		if count == 0 {
+			continue
+		}
+		classes = append(classes, []string{class, strconv.Itoa(count), percent(count, s.Characters)})
+	}
+	if len(classes) > 1 {
+		sections = append(sections, say.Table(classes, true, colored))
+	}
+
+	if words := s.TopWords(top); len(words) > 0 {
+		data := [][]string{{"Word", "Count", "Share"}}
+		for _, w := range words {
+			data = append(data, []string{w.Word, strconv.Itoa(w.Count), percent(w.Count, s.Words)})
+		}
+		sections = append(sections, say.Table(data, true, colored))
+	}
+
+	if lines := s.LongestLines(); len(lines) > 0 {
+		data := [][]string{{"Line", "Length", "Text"}}
+		for _, l := range lines {
+			data = append(data, []string{l.File + ":" + strconv.Itoa(l.Number), strconv.Itoa(l.Length), l.Text})
+		}
+		sections = append(sections, say.Table(data, true, colored))
+	}
+
+	return strings.Join(sections, "\n\n")
+}
+
+// toObject converts the statistics into an object, which can be encoded as JSON
+func toObject(s *Stats, top int) *convert.Object {
+	o := convert.NewObject()
+	o.Set("name", s.Name)
+	o.Set("lines", int64(s.Lines))
+	o.Set("words", int64(s.Words))
+	o.Set("unique_words", int64(s.UniqueWords()))
+	o.Set("characters", int64(s.Characters))
+	o.Set("bytes", int64(s.Bytes))
+	o.Set("encoding", s.Encoding)
+	o.Set("language", s.Language)
+
+	classes := convert.NewObject()
+	for _, class := range characterClasses {
+		classes.Set(class, int64(s.CharacterClasses()[class]))
+	}
+	o.Set("character_classes", classes)
+
+	words := []interface{}{}
+	for _, w := range s.TopWords(top) {
+		word := convert.NewObject()
+		word.Set("word", w.Word)
+		word.Set("count", int64(w.Count))
+		words = append(words, word)
+	}
+	o.Set("top_words", words)
+
+	lines := []interface{}{}
+	for _, l := range s.LongestLines() {
+		line := convert.NewObject()
+		line.Set("file", l.File)
+		line.Set("line", int64(l.Number))
+		line.Set("length", int64(l.Length))
+		line.Set("text", l.Text)
+		lines = append(lines, line)
+	}
+	o.Set("longest_lines", lines)
+
+	return o
+}
+
+func percent(part, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	return strconv.FormatFloat(float64(part)*100/float64(total), 'f', 1, 64) + "%"
+}
@@ -0,0 +1,146 @@
+package textstats
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dops-cli/dops/cli"
+)
+
+func analyze(t *testing.T, text string, longest int) *Stats {
+	t.Helper()
+	s, err := Analyze("test", strings.NewReader(text), longest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCounters(t *testing.T) {
+	tests := []struct {
+		text                                string
+		lines, words, unique, chars, nbytes int
+		encoding                            string
+	}{
+		{"", 0, 0, 0, 0, 0, "empty"},
+		{"hello world\n", 1, 2, 2, 12, 12, "ASCII"},
+		{"a b\nc", 2, 3, 3, 5, 5, "ASCII"},
+		{"The cat, the CAT!\n-- the\n", 2, 6, 2, 25, 25, "ASCII"},
+		{"héllo wörld\r\n", 1, 2, 2, 13, 15, "UTF-8"},
+		{"\ufeffbom text\n", 1, 2, 2, 9, 12, "UTF-8 with BOM"},
+		{"\xff\xfe\n", 1, 1, 0, 3, 3, "UTF-16LE"},
+		{"abc \xc3\x28\n", 1, 2, 1, 7, 7, "binary or legacy 8-bit encoding"},
+	}
+	for _, tt := range tests {
+		s := analyze(t, tt.text, 0)
+		got := []int{s.Lines, s.Words, s.UniqueWords(), s.Characters, s.Bytes}
+		want := []int{tt.lines, tt.words, tt.unique, tt.chars, tt.nbytes}
+		if !reflect.DeepEqual(got, want) || s.Encoding != tt.encoding {
+			t.Errorf("%q: got %v %q, want %v %q", tt.text, got, s.Encoding, want, tt.encoding)
+		}
+	}
+}
+
+func TestTopWords(t *testing.T) {
+	s := analyze(t, "b a c b\n(a) B! d\n", 0)
+	want := []WordCount{{"b", 3}, {"a", 2}, {"c", 1}}
+	if got := s.TopWords(3); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := s.TopWords(10); len(got) != 4 {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestLongestLines(t *testing.T) {
+	long := strings.Repeat("x", maxLineLength+5)
+	s := analyze(t, "ab\nabcd\r\na\nabc\n"+long+"\n", 3)
+	want := []Line{
+		{File: "test", Number: 5, Length: maxLineLength + 5, Text: strings.Repeat("x", maxLineLength) + "..."},
+		{File: "test", Number: 2, Length: 4, Text: "abcd"},
+		{File: "test", Number: 4, Length: 3, Text: "abc"},
+	}
+	if got := s.LongestLines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := analyze(t, "abc\n", 0).LongestLines(); len(got) != 0 {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestCharacterClasses(t *testing.T) {
+	s := analyze(t, "ab 12, €\t\x01", 0)
+	want := map[string]int{Letters: 2, Digits: 2, Whitespace: 3, Punctuation: 1, Symbols: 1, Control: 1}
+	if got := s.CharacterClasses(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	tests := map[string]string{
+		"The cat is on the mat and it is not a dog.":                       "English",
+		"Der Hund ist nicht auf dem Dach und die Katze ist auch nicht da.": "German",
+		"cat dog mat": "unknown",
+	}
+	for text, want := range tests {
+		if got := analyze(t, text, 0).Language; got != want {
+			t.Errorf("%q: got %s, want %s", text, got, want)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	total := newStats("total")
+	total.Merge(analyze(t, "a b\nlonger line\n", 2), 2)
+	total.Merge(analyze(t, "b c\xc3\xa4\n", 2), 2)
+
+	if total.Lines != 3 || total.Words != 6 || total.UniqueWords() != 5 || total.Bytes != 22 || total.Characters != 21 {
+		t.Errorf("got %+v", total)
+	}
+	if total.Encoding != "UTF-8" {
+		t.Errorf("got encoding %s", total.Encoding)
+	}
+	if got := total.TopWords(1); got[0] != (WordCount{"b", 2}) {
+		t.Errorf("got %v", got)
+	}
+	if got := total.LongestLines(); len(got) != 2 || got[0].Text != "longer line" || got[1].Text != "b cä" || got[1].Length != 4 {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestInput(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.txt")
+	output := filepath.Join(dir, "out.json")
+	if err := ioutil.WriteFile(input, []byte("one two\ntwo\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	app := &cli.App{Commands: Module{}.GetModuleCommands()}
+	if err := app.Run([]string{"dops", "text-stats", "-i", input, "--json", "-o", output}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []map[string]interface{}
+	if err := json.Unmarshal(data, &results); err != nil {
+		t.Fatalf("%v: %s", err, data)
+	}
+	if len(results) != 1 || results[0]["lines"] != 2.0 || results[0]["words"] != 3.0 {
+		t.Errorf("got %s", data)
+	}
+
+	// Tables in files are not colored
+	if err := app.Run([]string{"dops", "text-stats", "-i", input, "-o", output}); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ioutil.ReadFile(output); err != nil || !strings.Contains(string(data), "Words") || strings.Contains(string(data), "\x1b[") {
+		t.Errorf("got %q, %v", data, err)
+	}
+}
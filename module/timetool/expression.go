@@ -0,0 +1,265 @@
+package timetool
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Value is the result of an expression, which is either a point in time or a duration
+type Value struct {
+	Time       time.Time
+	Duration   time.Duration
+	IsDuration bool
+}
+
+// Units of Unix timestamps
+const (
+	Seconds      = "s"
+	Milliseconds = "ms"
+	Microseconds = "us"
+	Nanoseconds  = "ns"
+)
+
+// Units contains all units of Unix timestamps
+var Units = []string{Seconds, Milliseconds, Microseconds, Nanoseconds}
+
+var unitDurations = map[string]time.Duration{
+	Seconds:      time.Second,
+	Milliseconds: time.Millisecond,
+	Microseconds: time.Microsecond,
+	Nanoseconds:  time.Nanosecond,
+}
+
+// inputLayouts are tried in order, if a time is neither a keyword, a Unix timestamp nor a duration
+var inputLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.ANSIC,
+	time.UnixDate,
+	time.RubyDate,
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Parser evaluates expressions like 'now + 36h' or '1600000000 - 2020-01-01'
+type Parser struct {
+	// Now is the time of the keyword now
+	Now time.Time
+	// Location is used for times without a zone and for the keywords today, yesterday and tomorrow
+	Location *time.Location
+	// Layout is tried before the built-in layouts, if it's not empty
+	Layout string
+	// Unit is the unit of Unix timestamps. It's detected by the number of digits, if it's empty.
+	Unit string
+}
+
+var (
+	// operator matches + and - between operands. They need spaces around them, because dates and zones contain dashes.
+	operator = regexp.MustCompile(`\s+([+-])\s+`)
+	// keywordOperator allows to leave out the spaces after a keyword, like in now+36h
+	keywordOperator = regexp.MustCompile(`^(now|today|yesterday|tomorrow)\s*([+-])\s*`)
+)
+
+// Evaluate parses the operands of the expression and adds or subtracts them from left to right.
+// A duration added to a time is a time, and the difference of two times is a duration.
+func (p Parser) Evaluate(expr string) (Value, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return Value{}, errors.New("no time given")
+	}
+	expr = keywordOperator.ReplaceAllString(expr, "$1 $2 ")
+
+	operands := operator.Split(expr, -1)
+	operators := operator.FindAllStringSubmatch(expr, -1)
+	result, err := p.operand(operands[0])
+	if err != nil {
+		return Value{}, err
+	}
+	for i, op := range operators {
+		v, err := p.operand(operands[i+1])
+		if err != nil {
+			return Value{}, err
+		}
+		if result, err = apply(result, op[1], v); err != nil {
+			return Value{}, err
+		}
+	}
+	return result, nil
+}
+
+func apply(a Value, op string, b Value) (Value, error) {
+	sign := time.Duration(1)
+	if op == "-" {
+		sign = -1
+	}
+	switch {
+	case a.IsDuration && b.IsDuration:
+		return Value{Duration: a.Duration + sign*b.Duration, IsDuration: true}, nil
+	case !a.IsDuration && b.IsDuration:
+		return Value{Time: a.Time.Add(sign * b.Duration)}, nil
+	case a.IsDuration && op == "+":
+		return Value{Time: b.Time.Add(a.Duration)}, nil
+	case !a.IsDuration && op == "-":
+		return Value{Duration: a.Time.Sub(b.Time), IsDuration: true}, nil
+	case a.IsDuration:
+		return Value{}, errors.New("a time can't be subtracted from a duration")
+	}
+	return Value{}, errors.New("two times can't be added")
+}
+
+func (p Parser) location() *time.Location {
+	if p.Location == nil {
+		return time.Local
+	}
+	return p.Location
+}
+
+func (p Parser) operand(s string) (Value, error) {
+	loc := p.location()
+	now := p.Now.In(loc)
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	switch strings.ToLower(s) {
+	case "now":
+		return Value{Time: now}, nil
+	case "today":
+		return Value{Time: midnight}, nil
+	case "yesterday":
+		return Value{Time: midnight.AddDate(0, 0, -1)}, nil
+	case "tomorrow":
+		return Value{Time: midnight.AddDate(0, 0, 1)}, nil
+	}
+
+	if p.Layout != "" {
+		if t, err := time.ParseInLocation(p.Layout, s, loc); err == nil {
+			return Value{Time: t}, nil
+		}
+	}
+	if t, ok, err := p.unix(s); ok {
+		return Value{Time: t}, err
+	}
+	if d, err := ParseDuration(s); err == nil {
+		return Value{Duration: d, IsDuration: true}, nil
+	}
+	for _, layout := range inputLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return Value{Time: t}, nil
+		}
+	}
+	if p.Layout != "" {
+		_, err := time.ParseInLocation(p.Layout, s, loc)
+		return Value{}, fmt.Errorf("%q matches neither the layout %q nor a built-in format: %w", s, p.Layout, err)
+	}
+	return Value{}, fmt.Errorf("%q is neither a time, a Unix timestamp nor a duration", s)
+}
+
+var unixTimestamp = regexp.MustCompile(`^(-?)(\d+)(?:\.(\d+))?$`)
+
+// unix parses a Unix timestamp. Ok is false, if s is not a number.
+func (p Parser) unix(s string) (t time.Time, ok bool, err error) {
+	m := unixTimestamp.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, false, nil
+	}
+	integer, err := strconv.ParseInt(m[2], 10, 64)
+	if err != nil {
+		return time.Time{}, true, fmt.Errorf("%q is out of range", s)
+	}
+
+	unit := p.Unit
+	if unit == "" {
+		unit = DetectUnit(integer)
+	}
+	scale, ok := unitDurations[unit]
+	if !ok {
+		return time.Time{}, true, fmt.Errorf("unknown unit %q", unit)
+	}
+	if integer > math.MaxInt64/int64(scale) {
+		return time.Time{}, true, fmt.Errorf("%q is out of range for the unit %s", s, unit)
+	}
+
+	// The fraction is added in nanoseconds, so that it's not rounded like a float64
+	fraction := m[3]
+	if len(fraction) > 9 {
+		fraction = fraction[:9]
+	}
+	var nanos int64
+	if fraction != "" {
+		f, _ := strconv.ParseInt(fraction+strings.Repeat("0", 9-len(fraction)), 10, 64)
+		nanos = f * int64(scale) / int64(time.Second)
+	}
+
+	sec := integer * int64(scale) / int64(time.Second)
+	nsec := integer*int64(scale)%int64(time.Second) + nanos
+	if m[1] == "-" {
+		sec, nsec = -sec, -nsec
+	}
+	return time.Unix(sec, nsec).In(p.location()), true, nil
+}
+
+// DetectUnit guesses the unit of a Unix timestamp by its size. Seconds are detected until the year 5138.
+func DetectUnit(timestamp int64) string {
+	if timestamp < 0 {
+		timestamp = -timestamp
+	}
+	switch {
+	case timestamp < 1e11:
+		return Seconds
+	case timestamp < 1e14:
+		return Milliseconds
+	case timestamp < 1e17:
+		return Microseconds
+	}
+	return Nanoseconds
+}
+
+var dayUnit = regexp.MustCompile(`^(\d+(?:\.\d+)?)([dw])`)
+
+// ParseDuration parses a duration like time.ParseDuration, but supports days (d) and weeks (w) as well, like in 1w2d12h
+func ParseDuration(s string) (time.Duration, error) {
+	rest := strings.TrimPrefix(s, "-")
+	negative := rest != s
+	rest = strings.TrimPrefix(rest, "+")
+	if rest == "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	var d time.Duration
+	for {
+		m := dayUnit.FindStringSubmatch(rest)
+		if m == nil {
+			break
+		}
+		n, _ := strconv.ParseFloat(m[1], 64)
+		unit := 24 * time.Hour
+		if m[2] == "w" {
+			unit *= 7
+		}
+		d += time.Duration(n * float64(unit))
+		rest = rest[len(m[0]):]
+	}
+	if rest != "" {
+		clock, err := time.ParseDuration(rest)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d += clock
+	}
+	if negative {
+		d = -d
+	}
+	return d, nil
+}
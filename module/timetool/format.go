@@ -0,0 +1,134 @@
+package timetool
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats, which are no Go layouts
+const (
+	Unix      = "unix"
+	UnixMilli = "unixmilli"
+	UnixMicro = "unixmicro"
+	UnixNano  = "unixnano"
+	Relative  = "relative"
+	Human     = "human"
+)
+
+// Layouts are the Go layouts, which can be used by name
+var Layouts = map[string]string{
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"rfc1123":     time.RFC1123,
+	"rfc1123z":    time.RFC1123Z,
+	"rfc850":      time.RFC850,
+	"rfc822":      time.RFC822,
+	"rfc822z":     time.RFC822Z,
+	"ansic":       time.ANSIC,
+	"unixdate":    time.UnixDate,
+	"rubydate":    time.RubyDate,
+	"kitchen":     time.Kitchen,
+	"date":        "2006-01-02",
+	"datetime":    "2006-01-02 15:04:05",
+}
+
+// Layout returns the Go layout of a name in Layouts, or the layout itself
+func Layout(layout string) string {
+	if named, ok := Layouts[strings.ToLower(layout)]; ok {
+		return named
+	}
+	return layout
+}
+
+// Format formats a time as Unix timestamp, relative to now, or with a Go layout
+func Format(t, now time.Time, format string) string {
+	nanos := t.UnixNano()
+	switch strings.ToLower(format) {
+	case Unix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case UnixMilli:
+		return strconv.FormatInt(nanos/int64(time.Millisecond), 10)
+	case UnixMicro:
+		return strconv.FormatInt(nanos/int64(time.Microsecond), 10)
+	case UnixNano:
+		return strconv.FormatInt(nanos, 10)
+	case Relative, Human:
+		return RelativeTo(t, now)
+	}
+	return t.Format(Layout(format))
+}
+
+// FormatDuration formats a duration in seconds, milliseconds, microseconds, nanoseconds, human readable or like 36h0m0s
+func FormatDuration(d time.Duration, format string) string {
+	switch strings.ToLower(format) {
+	case Unix:
+		return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+	case UnixMilli:
+		return strconv.FormatInt(d.Milliseconds(), 10)
+	case UnixMicro:
+		return strconv.FormatInt(d.Microseconds(), 10)
+	case UnixNano:
+		return strconv.FormatInt(d.Nanoseconds(), 10)
+	case Relative, Human:
+		return HumanDuration(d)
+	}
+	return d.String()
+}
+
+var humanUnits = []struct {
+	name string
+	size time.Duration
+}{
+	{"year", 365 * 24 * time.Hour},
+	{"day", 24 * time.Hour},
+	{"hour", time.Hour},
+	{"minute", time.Minute},
+	{"second", time.Second},
+}
+
+// HumanDuration describes a duration with its two largest units, like '1 day 12 hours'
+func HumanDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		d = -d
+		sign = "-"
+	}
+	if d < time.Second {
+		return sign + d.String()
+	}
+
+	var parts []string
+	for _, unit := range humanUnits {
+		n := d / unit.size
+		if n == 0 {
+			if len(parts) > 0 {
+				break
+			}
+			continue
+		}
+		d -= n * unit.size
+		name := unit.name
+		if n != 1 {
+			name += "s"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", n, name))
+		if len(parts) == 2 {
+			break
+		}
+	}
+	return sign + strings.Join(parts, " ")
+}
+
+// RelativeTo describes a time relative to now, like 'in 1 day 12 hours' or '3 years 2 days ago'
+func RelativeTo(t, now time.Time) string {
+	d := t.Sub(now).Truncate(time.Second)
+	switch {
+	case d == 0:
+		return "now"
+	case d < 0:
+		return HumanDuration(-d) + " ago"
+	}
+	return "in " + HumanDuration(d)
+}
@@ -0,0 +1,203 @@
+package timetool
+
+import (
+	"errors"
+	"strings"
+	"time"
+	// The timezone database is embedded, because Windows and minimal containers don't have one
+	_ "time/tzdata"
+
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:      "time",
+			Usage:     "Converts timestamps between formats and timezones and calculates with times and durations",
+			ArgsUsage: "[EXPRESSION]",
+			Description: `Time converts Unix timestamps in seconds, milliseconds, microseconds or nanoseconds, RFC3339, RFC1123 and other common formats
+into each other and into other timezones. Times in other formats can be parsed with a Go layout like '02.01.2006 15:04'.
+The unit of Unix timestamps is detected by their size, unless --unit is set.
+
+Times and durations can be added and subtracted, like in 'now + 36h', '2020-01-01 - 1w2d' or '2021-01-01 - now'.
+The operators need spaces around them, except after the keywords now, today, yesterday and tomorrow.
+Durations are written like 1h30m and can have days (d) and weeks (w). The difference of two times is a duration.
+
+Without --format, all formats and the time relative to now are shown. With --format, only the formatted time is written, once per zone.
+The expression is read from stdin, if it's not given as argument. Every line of stdin is a separate expression.`,
+			Category: categories.DataAnalysis,
+			Examples: []cli.Example{
+				{
+					ShortDescription: "Show a Unix timestamp in all formats",
+					Usage:            "dops time 1600000000",
+				},
+				{
+					ShortDescription: "Show the time in 36 hours in New York and Tokyo",
+					Usage:            "dops time -z America/New_York -z Asia/Tokyo now + 36h",
+				},
+				{
+					ShortDescription: "Convert a time in a custom layout to a Unix timestamp in milliseconds",
+					Usage:            "dops time --layout '02.01.2006 15:04' --input-zone Europe/Berlin --format unixmilli '24.12.2020 18:00'",
+				},
+				{
+					ShortDescription: "Convert a list of Unix timestamps to RFC3339 in UTC",
+					Usage:            "cat timestamps.txt | dops time --format rfc3339 --zone UTC",
+				},
+				{
+					ShortDescription: "Show the time until the end of the year",
+					Usage:            "dops time --format human 2021-01-01 - now",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				expressions := []string{strings.Join(c.Args().Slice(), " ")}
+				if c.NArg() == 0 {
+					content, err := utils.ReadInput("")
+					if err != nil {
+						return err
+					}
+					expressions = nil
+					for _, line := range strings.Split(content, "\n") {
+						if line = strings.TrimSpace(line); line != "" {
+							expressions = append(expressions, line)
+						}
+					}
+					if len(expressions) == 0 {
+						return errors.New("no time given")
+					}
+				}
+
+				inputZone, err := time.LoadLocation(c.String("input-zone"))
+				if err != nil {
+					return err
+				}
+				now := time.Now()
+				if t := c.Timestamp("now"); t != nil {
+					now = *t
+				}
+				parser := Parser{Now: now, Location: inputZone, Layout: Layout(c.String("layout")), Unit: c.Option("unit")}
+
+				format := c.String("format")
+				zoneNames := c.StringSlice("zone")
+				if len(zoneNames) == 0 {
+					zoneNames = []string{"Local", "UTC"}
+					if format != "" || len(expressions) > 1 {
+						zoneNames = zoneNames[:1]
+					}
+				}
+				zones := make([]*time.Location, len(zoneNames))
+				for i, name := range zoneNames {
+					if zones[i], err = time.LoadLocation(name); err != nil {
+						return err
+					}
+				}
+
+				if format == "" && len(expressions) == 1 {
+					v, err := parser.Evaluate(expressions[0])
+					if err != nil {
+						return err
+					}
+					return utils.WriteOutput(c, []string{table(v, now, zones, color.NewColorizer(c.String("output") == ""))})
+				}
+
+				if format == "" {
+					format = "rfc3339"
+				}
+				var lines []string
+				for _, expr := range expressions {
+					v, err := parser.Evaluate(expr)
+					if err != nil {
+						return err
+					}
+					if v.IsDuration {
+						lines = append(lines, FormatDuration(v.Duration, format))
+						continue
+					}
+					for _, zone := range zones {
+						lines = append(lines, Format(v.Time.In(zone), now, format))
+					}
+				}
+				return utils.WriteOutput(c, lines)
+			},
+			Flags: append([]cli.Flag{
+				&cli.StringFlag{
+					Name:    "format",
+					Aliases: []string{"f"},
+					Usage: "Writes only the time in `FORMAT`, which is unix, unixmilli, unixmicro, unixnano, relative, human, " +
+						"a name like rfc3339, rfc1123, date or datetime, or a Go layout like '2006-01-02 15:04'",
+				},
+				&cli.StringFlag{
+					Name:    "layout",
+					Aliases: []string{"l"},
+					Usage:   "Parses times with the Go `LAYOUT` or a name like rfc850, before trying the built-in formats",
+				},
+				&cli.StringSliceFlag{
+					Name:        "zone",
+					Aliases:     []string{"z"},
+					Usage:       "Shows the time in the `ZONE`, like UTC, Local or Europe/Berlin. Can be used multiple times.",
+					DefaultText: "Local and UTC, or Local with --format",
+				},
+				&cli.StringFlag{
+					Name:  "input-zone",
+					Usage: "Parses times without zone in `ZONE`",
+					Value: "Local",
+				},
+				&cli.OptionFlag{
+					Name:        "unit",
+					Aliases:     []string{"u"},
+					Usage:       "Parses Unix timestamps in `UNIT`",
+					Options:     Units,
+					DefaultText: "detected by size",
+				},
+				&cli.TimestampFlag{
+					Name:        "now",
+					Usage:       "Uses the RFC3339 `TIME` as current time",
+					Layout:      time.RFC3339,
+					DefaultText: "the current time",
+				},
+			}, utils.OutputFlags()...),
+		},
+	}
+}
+
+func zoneLabel(t time.Time) string {
+	abbreviation, _ := t.Zone()
+	name := t.Location().String()
+	if name == abbreviation {
+		return name
+	}
+	return name + " (" + abbreviation + ")"
+}
+
+func table(v Value, now time.Time, zones []*time.Location, colored color.Colorizer) string {
+	if v.IsDuration {
+		data := [][]string{
+			{"Duration", FormatDuration(v.Duration, "")},
+			{"Human", FormatDuration(v.Duration, Human)},
+			{"Seconds", FormatDuration(v.Duration, Unix)},
+			{"Milliseconds", FormatDuration(v.Duration, UnixMilli)},
+		}
+		return say.Table(data, false, colored)
+	}
+
+	unix := [][]string{
+		{"Unix", Format(v.Time, now, Unix)},
+		{"Unix milliseconds", Format(v.Time, now, UnixMilli)},
+		{"Unix nanoseconds", Format(v.Time, now, UnixNano)},
+		{"Relative", Format(v.Time, now, Relative)},
+	}
+	inZones := [][]string{{"Zone", "RFC3339", "RFC1123"}}
+	for _, zone := range zones {
+		t := v.Time.In(zone)
+		inZones = append(inZones, []string{zoneLabel(t), Format(t, now, time.RFC3339Nano), Format(t, now, time.RFC1123)})
+	}
+	return say.Table(inZones, true, colored) + "\n\n" + say.Table(unix, false, colored)
+}
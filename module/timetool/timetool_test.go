@@ -0,0 +1,120 @@
+package timetool
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEvaluate(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2020, 9, 13, 12, 26, 40, 0, time.UTC)
+	parser := Parser{Now: now, Location: berlin}
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"now", now},
+		{"now+36h", now.Add(36 * time.Hour)},
+		{"now - 1w2d", now.Add(-9 * 24 * time.Hour)},
+		{"today", time.Date(2020, 9, 13, 0, 0, 0, 0, berlin)},
+		{"tomorrow + 1h", time.Date(2020, 9, 14, 1, 0, 0, 0, berlin)},
+		{"1600000000", time.Unix(1600000000, 0)},
+		{"1600000000123", time.Unix(1600000000, 123000000)},
+		{"1600000000123456", time.Unix(1600000000, 123456000)},
+		{"1600000000123456789", time.Unix(1600000000, 123456789)},
+		{"1600000000.123456789", time.Unix(1600000000, 123456789)},
+		{"-86400", time.Unix(-86400, 0)},
+		{"2020-09-13T12:26:40Z", now},
+		{"2020-09-13T14:26:40+02:00 + 1s", now.Add(time.Second)},
+		{"Sun, 13 Sep 2020 12:26:40 UTC", now},
+		{"2020-09-13 14:26:40", now},
+		{"2020-09-13", time.Date(2020, 9, 13, 0, 0, 0, 0, berlin)},
+		{"1h + 2020-09-13T12:26:40Z", now.Add(time.Hour)},
+	}
+	for _, tt := range tests {
+		v, err := parser.Evaluate(tt.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if v.IsDuration || !v.Time.Equal(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.expr, v.Time, tt.want)
+		}
+	}
+
+	v, err := parser.Evaluate("2021-01-01T00:00:00Z - now + 1d")
+	if err != nil || !v.IsDuration || v.Duration != 2651*time.Hour+33*time.Minute+20*time.Second {
+		t.Errorf("got %v, %v", v.Duration, err)
+	}
+
+	for _, invalid := range []string{"", "now + now", "1h - now", "13.09.2020", "now + 1x"} {
+		if _, err := parser.Evaluate(invalid); err == nil {
+			t.Errorf("%q: expected an error", invalid)
+		}
+	}
+
+	parser.Layout = "02.01.2006 15:04"
+	if v, err := parser.Evaluate("13.09.2020 14:26"); err != nil || !v.Time.Equal(now.Add(-40*time.Second)) {
+		t.Errorf("custom layout: got %v, %v", v.Time, err)
+	}
+	parser.Unit = Milliseconds
+	if v, err := parser.Evaluate("1600000000"); err != nil || !v.Time.Equal(time.Unix(1600000, 0)) {
+		t.Errorf("unit: got %v, %v", v.Time, err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	now := time.Date(2020, 9, 13, 12, 26, 40, 500000000, time.UTC)
+	tests := []struct {
+		format, want string
+	}{
+		{Unix, "1600000000"},
+		{UnixMilli, "1600000000500"},
+		{UnixNano, "1600000000500000000"},
+		{"RFC1123", "Sun, 13 Sep 2020 12:26:40 UTC"},
+		{"date", "2020-09-13"},
+		{"02.01.2006", "13.09.2020"},
+	}
+	for _, tt := range tests {
+		if got := Format(now, now, tt.format); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	if got := RelativeTo(now.Add(36*time.Hour), now); got != "in 1 day 12 hours" {
+		t.Errorf("got %q", got)
+	}
+	if got := RelativeTo(now.Add(-(3*365+2)*24*time.Hour), now); got != "3 years 2 days ago" {
+		t.Errorf("got %q", got)
+	}
+	if got := RelativeTo(now.Add(time.Hour+5*time.Second), now); got != "in 1 hour" {
+		t.Errorf("got %q", got)
+	}
+	if got := FormatDuration(90*time.Second, Unix); got != "90" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := map[string]time.Duration{
+		"36h":      36 * time.Hour,
+		"1d12h":    36 * time.Hour,
+		"1.5d":     36 * time.Hour,
+		"2w":       14 * 24 * time.Hour,
+		"-1w1d1ms": -(8*24*time.Hour + time.Millisecond),
+	}
+	for s, want := range tests {
+		if got, err := ParseDuration(s); err != nil || got != want {
+			t.Errorf("%s: got %v, %v", s, got, err)
+		}
+	}
+	for _, invalid := range []string{"", "-", "d", "1dd", "1y"} {
+		if _, err := ParseDuration(invalid); err == nil {
+			t.Errorf("%q: expected an error", invalid)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package tlstool
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Chain is the certificate chain of a server or a file
+type Chain struct {
+	Target string
+	// ServerName is the name, which was sent to the server and which the leaf certificate is verified for
+	ServerName   string
+	Certificates []*x509.Certificate
+	// Version and CipherSuite are only set for servers
+	Version     uint16
+	CipherSuite uint16
+	// VerifyError is nil, if the chain is trusted
+	VerifyError error
+}
+
+// Options configure how chains are fetched and verified
+type Options struct {
+	Timeout time.Duration
+	// ServerName overrides the name, which is sent with SNI and verified
+	ServerName string
+	// Roots are the trusted certificates, the system roots are used if nil
+	Roots *x509.CertPool
+}
+
+// SplitTarget returns the address and server name of targets like example.com, example.com:8443 and https://example.com/path
+func SplitTarget(target string) (address, serverName string, err error) {
+	if strings.Contains(target, "://") {
+		u, err := url.Parse(target)
+		if err != nil {
+			return "", "", err
+		}
+		target = u.Host
+	}
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		host, port = strings.Trim(target, "[]"), "443"
+	}
+	if host == "" {
+		return "", "", errors.New("missing host in " + target)
+	}
+	return net.JoinHostPort(host, port), host, nil
+}
+
+// Fetch connects to a server and returns the chain, which it presents.
+// The connection is made without verification, so that untrusted chains can be inspected as well.
+func Fetch(ctx context.Context, target string, o Options) (*Chain, error) {
+	address, serverName, err := SplitTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	if o.ServerName != "" {
+		serverName = o.ServerName
+	}
+
+	dialer := tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: o.Timeout},
+		Config: &tls.Config{
+			ServerName:         serverName,
+			InsecureSkipVerify: true, //nolint:gosec
+		},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	chain := &Chain{
+		Target:       target,
+		ServerName:   serverName,
+		Certificates: state.PeerCertificates,
+		Version:      state.Version,
+		CipherSuite:  state.CipherSuite,
+	}
+	// Server names, which are IP addresses, are verified against the IP SANs
+	chain.VerifyError = chain.verify(o.Roots)
+	return chain, nil
+}
+
+// LoadChain reads the certificates of a PEM or DER file
+func LoadChain(path string, o Options) (*Chain, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	certs, err := ParseCertificates(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	chain := &Chain{Target: path, ServerName: o.ServerName, Certificates: certs}
+	chain.VerifyError = chain.verify(o.Roots)
+	return chain, nil
+}
+
+// ParseCertificates parses all certificates of PEM data, or a single DER encoded certificate
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	rest := data
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) > 0 {
+		return certs, nil
+	}
+	if strings.Contains(string(data), "-----BEGIN") {
+		return nil, errors.New("no certificates found")
+	}
+	cert, err := x509.ParseCertificate(data)
+	if err != nil {
+		return nil, err
+	}
+	return []*x509.Certificate{cert}, nil
+}
+
+// LoadRoots returns a pool with the certificates of a PEM or DER file
+func LoadRoots(path string) (*x509.CertPool, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	certs, err := ParseCertificates(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	return pool, nil
+}
+
+func (c *Chain) verify(roots *x509.CertPool) error {
+	if len(c.Certificates) == 0 {
+		return errors.New("no certificates")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range c.Certificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := c.Certificates[0].Verify(x509.VerifyOptions{
+		DNSName:       c.ServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// DaysRemaining returns the full days until the certificate expires, it's negative for expired certificates
+func DaysRemaining(cert *x509.Certificate, now time.Time) int {
+	return int(math.Floor(cert.NotAfter.Sub(now).Hours() / 24))
+}
+
+// KeyDescription describes the public key of a certificate, like 'RSA 2048 bits' or 'ECDSA P-256'
+func KeyDescription(key interface{}) string {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA %d bits", k.N.BitLen())
+	case *ecdsa.PublicKey:
+		return "ECDSA " + k.Curve.Params().Name
+	case ed25519.PublicKey:
+		return "Ed25519"
+	}
+	return fmt.Sprintf("%T", key)
+}
+
+// Fingerprint returns the SHA-256 fingerprint of DER data, with colons between the bytes
+func Fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return colonHex(sum[:])
+}
+
+func colonHex(b []byte) string {
+	parts := make([]string, len(b))
+	for i := range b {
+		parts[i] = strings.ToUpper(hex.EncodeToString(b[i : i+1]))
+	}
+	return strings.Join(parts, ":")
+}
+
+// SANs returns all subject alternative names of a certificate
+func SANs(cert *x509.Certificate) []string {
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		sans = append(sans, u.String())
+	}
+	return sans
+}
+
+// versionName returns the name of a TLS version
+func versionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	}
+	return fmt.Sprintf("0x%04x", version)
+}
@@ -0,0 +1,333 @@
+package tlstool
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/say/color"
+	"github.com/dops-cli/dops/utils"
+)
+
+// Inspect returns the inspect subcommand
+func Inspect() *cli.Command {
+	return &cli.Command{
+		Name:      "inspect",
+		Usage:     "Shows the certificate chains of servers and files",
+		ArgsUsage: "[TARGETS...]",
+		// Flags are also accepted after the targets
+		InterspersedFlags: true,
+		Description: `Inspect shows the certificates of servers, like example.com or example.com:8443, and of PEM or DER files.
+For every certificate the subject, SANs, issuer, validity, key, signature algorithm and the OCSP and CRL URLs are shown,
+as well as whether the chain is trusted by the system or the certificates of --ca.
+Targets are given as arguments or line by line with --input.
+
+Inspect exits with 1, if a server can't be reached, a server's chain is not trusted or a certificate has expired.
+With --warn-days certificates, which expire within that many days, fail as well, so that endpoint lists can be checked in CI.`,
+		Examples: []cli.Example{
+			{
+				ShortDescription: "Inspect the chain of a server",
+				Usage:            "dops tls inspect example.com:443",
+			},
+			{
+				ShortDescription: "Fail, if a certificate of the endpoints expires within 30 days",
+				Usage:            "dops tls inspect --warn-days 30 --input endpoints.txt",
+			},
+			{
+				ShortDescription: "Inspect a certificate file and verify it with a private CA",
+				Usage:            "dops tls inspect --ca ca.pem server.pem",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			targets := c.Args().Slice()
+			if input := c.String("input"); input != "" {
+				content, err := utils.ReadInput(input)
+				if err != nil {
+					return err
+				}
+				for _, line := range strings.Split(content, "\n") {
+					if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+						targets = append(targets, line)
+					}
+				}
+			}
+			if len(targets) == 0 {
+				return errors.New("no targets to inspect")
+			}
+			o := Options{Timeout: c.Duration("timeout"), ServerName: c.String("servername")}
+			if ca := c.String("ca"); ca != "" {
+				roots, err := LoadRoots(ca)
+				if err != nil {
+					return err
+				}
+				o.Roots = roots
+			}
+
+			now := time.Now()
+			warnDays := c.Int("warn-days")
+			var results []inspection
+			failed := 0
+			for _, target := range targets {
+				r := inspect(target, o)
+				r.problems = problems(r, now, warnDays)
+				if len(r.problems) > 0 {
+					failed++
+				}
+				results = append(results, r)
+			}
+
+			if c.Bool("json") {
+				out := make([]chainJSON, len(results))
+				for i, r := range results {
+					out[i] = toJSON(r, now)
+				}
+				data, err := json.MarshalIndent(out, "", "  ")
+				if err != nil {
+					return err
+				}
+				if err := utils.WriteOutput(c, []string{string(data)}); err != nil {
+					return err
+				}
+			} else {
+				colored := color.NewColorizer(c.String("output") == "")
+				var sections []string
+				for _, r := range results {
+					sections = append(sections, report(r, now, warnDays, colored))
+				}
+				if err := utils.WriteOutput(c, sections); err != nil {
+					return err
+				}
+			}
+
+			if failed > 0 {
+				return cli.Exit(fmt.Sprintf("%d of %d targets have problems", failed, len(targets)), 1)
+			}
+			return nil
+		},
+		Flags: append([]cli.Flag{
+			&cli.PathFlag{
+				Name:      "input",
+				Aliases:   []string{"i"},
+				Usage:     "Reads the targets line by line from `FILE`",
+				TakesFile: true,
+			},
+			&cli.IntFlag{
+				Name:    "warn-days",
+				Aliases: []string{"w"},
+				Usage:   "Fails, if a certificate expires within `DAYS`",
+			},
+			&cli.PathFlag{
+				Name:      "ca",
+				Usage:     "Trusts the certificates of `FILE` instead of the system roots",
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:    "servername",
+				Aliases: []string{"n"},
+				Usage:   "Sends and verifies `NAME` instead of the host of the target",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "Gives up connecting after `DURATION`",
+				Value: 10 * time.Second,
+			},
+			&cli.BoolFlag{
+				Name:    "json",
+				Aliases: []string{"j"},
+				Usage:   "Outputs the chains as JSON",
+			},
+		}, utils.OutputFlags()...),
+	}
+}
+
+// inspection is the result of inspecting a target
+type inspection struct {
+	target   string
+	chain    *Chain
+	err      error
+	server   bool
+	problems []string
+}
+
+// inspect loads the chain of a file, if the target is an existing file, and connects to the target otherwise
+func inspect(target string, o Options) inspection {
+	if info, err := os.Stat(target); err == nil && info.Mode().IsRegular() {
+		chain, err := LoadChain(target, o)
+		return inspection{target: target, chain: chain, err: err}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), o.Timeout)
+	defer cancel()
+	chain, err := Fetch(ctx, target, o)
+	return inspection{target: target, chain: chain, err: err, server: true}
+}
+
+// problems returns why an inspection fails. Untrusted chains of files are no problem, as they are often self-signed or incomplete.
+func problems(r inspection, now time.Time, warnDays int) []string {
+	if r.err != nil {
+		return []string{r.err.Error()}
+	}
+	var found []string
+	if r.server && r.chain.VerifyError != nil {
+		found = append(found, "untrusted chain: "+r.chain.VerifyError.Error())
+	}
+	for _, cert := range r.chain.Certificates {
+		days := DaysRemaining(cert, now)
+		switch {
+		case now.After(cert.NotAfter):
+			found = append(found, fmt.Sprintf("%s expired on %s", name(cert), cert.NotAfter.Format("2006-01-02")))
+		case warnDays > 0 && days < warnDays:
+			found = append(found, fmt.Sprintf("%s expires in %d days", name(cert), days))
+		}
+	}
+	return found
+}
+
+// name returns the common name of a certificate, or its subject if it has none
+func name(cert *x509.Certificate) string {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName
+	}
+	return cert.Subject.String()
+}
+
+// report renders an inspection as tables
+func report(r inspection, now time.Time, warnDays int, colored color.Colorizer) string {
+	sections := []string{say.Section(r.target, colored)}
+	if r.err != nil {
+		return strings.Join(append(sections, colored.Paint(color.SRed, "Error: "+r.err.Error())), "\n")
+	}
+
+	var summary [][]string
+	if r.server {
+		summary = append(summary,
+			[]string{"Protocol", versionName(r.chain.Version)},
+			[]string{"Cipher suite", tls.CipherSuiteName(r.chain.CipherSuite)},
+		)
+	}
+	verification := colored.Paint(color.SGreen, "trusted")
+	if r.chain.VerifyError != nil {
+		verification = colored.Paint(color.SRed, r.chain.VerifyError.Error())
+	}
+	summary = append(summary, []string{"Verification", verification})
+	for _, problem := range r.problems {
+		summary = append(summary, []string{"Problem", colored.Paint(color.SRed, problem)})
+	}
+	sections = append(sections, say.Table(summary, false, colored))
+
+	for i, cert := range r.chain.Certificates {
+		days := DaysRemaining(cert, now)
+		remaining := strconv.Itoa(days)
+		switch {
+		case days < 0:
+			remaining = colored.Paint(color.SRed, remaining)
+		case warnDays > 0 && days < warnDays:
+			remaining = colored.Paint(color.SYellow, remaining)
+		}
+		data := [][]string{
+			{fmt.Sprintf("Certificate %d", i), role(cert, i)},
+			{"Subject", cert.Subject.String()},
+			{"SANs", strings.Join(SANs(cert), ", ")},
+			{"Issuer", cert.Issuer.String()},
+			{"Serial", colonHex(cert.SerialNumber.Bytes())},
+			{"Valid from", cert.NotBefore.Format(time.RFC3339)},
+			{"Valid until", cert.NotAfter.Format(time.RFC3339)},
+			{"Days remaining", remaining},
+			{"Key", KeyDescription(cert.PublicKey)},
+			{"Signature", cert.SignatureAlgorithm.String()},
+			{"OCSP", strings.Join(cert.OCSPServer, ", ")},
+			{"CRL", strings.Join(cert.CRLDistributionPoints, ", ")},
+			{"SHA-256", Fingerprint(cert.Raw)},
+		}
+		sections = append(sections, say.Table(data, true, colored))
+	}
+	return strings.Join(sections, "\n")
+}
+
+// role describes the position of a certificate in a chain
+func role(cert *x509.Certificate, index int) string {
+	switch {
+	case index == 0 && !cert.IsCA:
+		return "Leaf"
+	case cert.Subject.String() == cert.Issuer.String():
+		return "Root"
+	case cert.IsCA:
+		return "Intermediate"
+	}
+	return "Leaf"
+}
+
+type certificateJSON struct {
+	Subject            string    `json:"subject"`
+	SANs               []string  `json:"sans"`
+	Issuer             string    `json:"issuer"`
+	Serial             string    `json:"serial"`
+	NotBefore          time.Time `json:"not_before"`
+	NotAfter           time.Time `json:"not_after"`
+	DaysRemaining      int       `json:"days_remaining"`
+	Key                string    `json:"key"`
+	SignatureAlgorithm string    `json:"signature_algorithm"`
+	OCSP               []string  `json:"ocsp"`
+	CRL                []string  `json:"crl"`
+	CA                 bool      `json:"ca"`
+	Fingerprint        string    `json:"sha256"`
+}
+
+// chainJSON is the JSON form of an inspection
+type chainJSON struct {
+	Target       string            `json:"target"`
+	ServerName   string            `json:"server_name,omitempty"`
+	Protocol     string            `json:"protocol,omitempty"`
+	CipherSuite  string            `json:"cipher_suite,omitempty"`
+	Trusted      bool              `json:"trusted"`
+	VerifyError  string            `json:"verify_error,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	Problems     []string          `json:"problems"`
+	Certificates []certificateJSON `json:"certificates"`
+}
+
+func toJSON(r inspection, now time.Time) chainJSON {
+	out := chainJSON{Target: r.target, Problems: r.problems, Certificates: []certificateJSON{}}
+	if out.Problems == nil {
+		out.Problems = []string{}
+	}
+	if r.err != nil {
+		out.Error = r.err.Error()
+		return out
+	}
+	out.ServerName = r.chain.ServerName
+	if r.server {
+		out.Protocol = versionName(r.chain.Version)
+		out.CipherSuite = tls.CipherSuiteName(r.chain.CipherSuite)
+	}
+	out.Trusted = r.chain.VerifyError == nil
+	if !out.Trusted {
+		out.VerifyError = r.chain.VerifyError.Error()
+	}
+	for _, cert := range r.chain.Certificates {
+		out.Certificates = append(out.Certificates, certificateJSON{
+			Subject:            cert.Subject.String(),
+			SANs:               SANs(cert),
+			Issuer:             cert.Issuer.String(),
+			Serial:             colonHex(cert.SerialNumber.Bytes()),
+			NotBefore:          cert.NotBefore,
+			NotAfter:           cert.NotAfter,
+			DaysRemaining:      DaysRemaining(cert, now),
+			Key:                KeyDescription(cert.PublicKey),
+			SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+			OCSP:               cert.OCSPServer,
+			CRL:                cert.CRLDistributionPoints,
+			CA:                 cert.IsCA,
+			Fingerprint:        Fingerprint(cert.Raw),
+		})
+	}
+	return out
+}
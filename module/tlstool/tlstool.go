@@ -0,0 +1,24 @@
+package tlstool
+
+import (
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:        "tls",
+			Usage:       "Inspects TLS certificates",
+			Description: `TLS contains tools to inspect the certificates of servers and files.`,
+			Category:    categories.Network,
+			Subcommands: []*cli.Command{
+				Inspect(),
+			},
+		},
+	}
+}
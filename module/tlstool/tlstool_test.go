@@ -0,0 +1,93 @@
+package tlstool
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dops-cli/dops/module/serve"
+)
+
+func TestFetch(t *testing.T) {
+	cert, _, err := serve.SelfSignedCertificate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	chain, err := Fetch(context.Background(), "https://"+l.Addr().String()+"/path", Options{Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain.Certificates) != 1 || chain.Certificates[0].Subject.CommonName != "localhost" {
+		t.Fatalf("got an unexpected chain: %+v", chain.Certificates)
+	}
+	if chain.VerifyError == nil {
+		t.Error("a self-signed certificate must not be trusted by the system")
+	}
+	if got := KeyDescription(chain.Certificates[0].PublicKey); got != "ECDSA P-256" {
+		t.Errorf("got key %q", got)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(chain.Certificates[0])
+	chain, err = Fetch(context.Background(), l.Addr().String(), Options{Timeout: time.Second, ServerName: "localhost", Roots: roots})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chain.VerifyError != nil {
+		t.Errorf("chain is not trusted with its own root: %v", chain.VerifyError)
+	}
+
+	r := inspection{chain: chain, server: true}
+	if found := problems(r, time.Now(), 0); len(found) != 0 {
+		t.Errorf("got problems %v", found)
+	}
+	if found := problems(r, time.Now(), 30); len(found) != 1 || !strings.Contains(found[0], "expires in 0 days") {
+		t.Errorf("got problems %v, want the certificate to expire soon", found)
+	}
+	if found := problems(r, time.Now().Add(48*time.Hour), 0); len(found) != 1 || !strings.Contains(found[0], "expired") {
+		t.Errorf("got problems %v, want the certificate to be expired", found)
+	}
+}
+
+func TestParseCertificates(t *testing.T) {
+	cert, _, err := serve.SelfSignedCertificate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	der := cert.Certificate[0]
+	chain := append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+
+	for name, data := range map[string][]byte{"DER": der, "PEM": chain} {
+		certs, err := ParseCertificates(data)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if certs[0].Subject.CommonName != "localhost" {
+			t.Errorf("%s: got subject %s", name, certs[0].Subject)
+		}
+	}
+	if _, err := ParseCertificates(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte{1}})); err == nil {
+		t.Error("expected an error for a PEM file without certificates")
+	}
+}
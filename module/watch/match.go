@@ -0,0 +1,204 @@
+package watch
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dops-cli/dops/utils"
+)
+
+// Match checks, if a slash separated name matches the glob pattern.
+// In addition to the syntax of path.Match, '**' matches any number of directories.
+func Match(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			pattern = pattern[1:]
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := range name {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// Base returns the leading directories of a glob pattern, which contain no wildcards.
+// Only this directory has to be watched for files matching the pattern.
+func Base(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	static := segments[:0]
+	for _, segment := range segments {
+		if strings.ContainsAny(segment, `*?[\`) {
+			break
+		}
+		static = append(static, segment)
+	}
+	base := strings.Join(static, "/")
+	switch {
+	case base == "" && strings.HasPrefix(pattern, "/"):
+		return "/"
+	case base == "":
+		return "."
+	}
+	return base
+}
+
+// roots returns the directories, which have to be watched for the patterns. Nested directories are removed.
+func roots(patterns []string) []string {
+	var dirs []string
+	for _, pattern := range patterns {
+		dir := filepath.FromSlash(Base(pattern))
+		if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+			dir = filepath.Dir(dir)
+		}
+		dirs = append(dirs, dir)
+	}
+
+	var result []string
+	for i, dir := range dirs {
+		nested := false
+		for j, other := range dirs {
+			if i == j {
+				continue
+			}
+			rel, err := filepath.Rel(other, dir)
+			inside := err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+			// Equal directories are only kept once
+			if inside && (rel != "." || j < i) {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			result = append(result, dir)
+		}
+	}
+	return result
+}
+
+// rule is a pattern of a .gitignore file
+type rule struct {
+	// base is the slash separated directory of the .gitignore file
+	base     string
+	pattern  string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// Ignore matches paths against patterns in the syntax of .gitignore files
+type Ignore struct {
+	rules []rule
+}
+
+// Add parses .gitignore patterns, which are relative to the slash separated directory base
+func (i *Ignore) Add(base string, lines []string) {
+	base = path.Clean(base)
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r := rule{base: base}
+		if strings.HasPrefix(line, "!") {
+			r.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		// Patterns with a slash at the beginning or in the middle are relative to the .gitignore file, others match at any level
+		if strings.Contains(line, "/") {
+			r.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		r.pattern = line
+		i.rules = append(i.rules, r)
+	}
+}
+
+// AddFile adds the patterns of a .gitignore file. Missing files are no error.
+func (i *Ignore) AddFile(file string) error {
+	var lines []string
+	err := utils.ForEachLineInFile(file, func(line string) error {
+		lines = append(lines, line)
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	i.Add(filepath.ToSlash(filepath.Dir(file)), lines)
+	return nil
+}
+
+// Ignored checks, if the path or one of its parent directories is ignored. Paths outside of the current directory are never ignored.
+func (i *Ignore) Ignored(name string, dir bool) bool {
+	name = path.Clean(filepath.ToSlash(name))
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") || path.IsAbs(name) {
+		return false
+	}
+	segments := strings.Split(name, "/")
+	for n := 1; n < len(segments); n++ {
+		if i.match(strings.Join(segments[:n], "/"), true) {
+			return true
+		}
+	}
+	return i.match(name, dir)
+}
+
+// match applies the rules to a single path. Like in git, the last matching rule wins.
+func (i *Ignore) match(name string, dir bool) bool {
+	if path.Base(name) == ".git" {
+		return true
+	}
+	ignored := false
+	for _, r := range i.rules {
+		if r.dirOnly && !dir {
+			continue
+		}
+		rel := name
+		if r.base != "." {
+			if !strings.HasPrefix(name, r.base+"/") {
+				continue
+			}
+			rel = strings.TrimPrefix(name, r.base+"/")
+		}
+		matched := false
+		if r.anchored {
+			matched = Match(r.pattern, rel)
+		} else {
+			matched, _ = path.Match(r.pattern, path.Base(rel))
+		}
+		if matched {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,20 @@
+// +build windows
+
+package watch
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+func setProcessGroup(*exec.Cmd) {}
+
+// terminate stops the command and its children. Windows has no signals, which could ask them to stop.
+func terminate(cmd *exec.Cmd) error {
+	return kill(cmd)
+}
+
+// kill stops the command and its children immediately
+func kill(cmd *exec.Cmd) error {
+	return exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+}
@@ -0,0 +1,90 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/dops-cli/dops/say"
+	"github.com/dops-cli/dops/utils"
+)
+
+// stopTimeout is the time a command has to stop after it was asked to, before it is killed
+const stopTimeout = 3 * time.Second
+
+// runner runs a shell command and restarts it on changes
+type runner struct {
+	command string
+	clear   bool
+	cmd     *exec.Cmd
+	started time.Time
+	// done receives the result of the running command. It is nil, if no command is running.
+	done chan error
+}
+
+// start clears the screen and starts the command in the background
+func (r *runner) start(changed []string) {
+	if r.clear {
+		utils.ClearScreen()
+	}
+	if len(changed) > 0 {
+		say.Info("Changed: " + summarize(changed, 5))
+	}
+	say.Info("Running: " + r.command)
+
+	if runtime.GOOS == "windows" {
+		r.cmd = exec.Command("cmd", "/C", r.command)
+	} else {
+		r.cmd = exec.Command("sh", "-c", r.command)
+	}
+	r.cmd.Stdout = os.Stdout
+	r.cmd.Stderr = os.Stderr
+	setProcessGroup(r.cmd)
+
+	r.started = time.Now()
+	if err := r.cmd.Start(); err != nil {
+		say.Error(err)
+		return
+	}
+	done := make(chan error, 1)
+	go func(cmd *exec.Cmd) { done <- cmd.Wait() }(r.cmd)
+	r.done = done
+}
+
+// finished reports the result of the command
+func (r *runner) finished(err error) {
+	r.done = nil
+	duration := time.Since(r.started).Round(time.Millisecond)
+	if err != nil {
+		say.Warning(fmt.Sprintf("Command failed after %s: %v", duration, err))
+	} else {
+		say.Success(fmt.Sprintf("Command finished in %s", duration))
+	}
+	say.Info("Waiting for changes...")
+}
+
+// stop stops a running command and all of its children. They are killed, if they don't stop within the stopTimeout.
+func (r *runner) stop() {
+	if r.done == nil {
+		return
+	}
+	_ = terminate(r.cmd)
+	select {
+	case <-r.done:
+	case <-time.After(stopTimeout):
+		_ = kill(r.cmd)
+		<-r.done
+	}
+	r.done = nil
+}
+
+// summarize joins the first max items and counts the others
+func summarize(items []string, max int) string {
+	if len(items) <= max {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(items[:max], ", "), len(items)-max)
+}
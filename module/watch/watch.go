@@ -0,0 +1,196 @@
+package watch
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dops-cli/dops/categories"
+	"github.com/dops-cli/dops/cli"
+	"github.com/dops-cli/dops/say"
+)
+
+// Module returns the created module
+type Module struct{}
+
+// GetModuleCommands returns the commands of the module
+func (Module) GetModuleCommands() []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "watch",
+			Usage: "Runs a command every time files change",
+			Description: `Watch runs the command once and again every time a file matching one of the globs is created, changed or removed.
+Changes are collected until no file changed for the debounce duration, so that saving many files at once only starts a single run.
+A command, which is still running when files change, is stopped together with all of its children and started again. This restarts servers and other long-running processes.
+Files ignored by .gitignore files are not watched, as well as the .git directory. On Linux changes are reported by inotify, other platforms are polled.`,
+			Category: categories.Execute,
+			Examples: []cli.Example{
+				{
+					ShortDescription: "Run the tests every time a Go file changes",
+					Usage:            "dops watch --glob 'src/**/*.go' --run 'go test ./...'",
+				},
+				{
+					ShortDescription: "Restart a server when its sources or templates change",
+					Usage:            "dops watch -g '**/*.go' -g 'templates/**' -r 'go run ./cmd/server'",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				command := c.String("run")
+				if command == "" {
+					return errors.New("no command given, use --run")
+				}
+				globs := c.StringSlice("glob")
+				for i, glob := range globs {
+					globs[i] = path.Clean(filepath.ToSlash(glob))
+				}
+				dirs := roots(globs)
+
+				ignore := &Ignore{}
+				if !c.Bool("no-gitignore") {
+					if err := loadGitignores(ignore, dirs); err != nil {
+						return err
+					}
+				}
+				ignore.Add(".", c.StringSlice("ignore"))
+
+				watcher, err := NewWatcher(dirs, ignore.Ignored, c.Duration("poll"))
+				if err != nil {
+					return err
+				}
+				defer watcher.Close()
+
+				interrupt := make(chan os.Signal, 1)
+				signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+				defer signal.Stop(interrupt)
+
+				r := &runner{command: command, clear: !c.Bool("no-clear")}
+				r.start(nil)
+				defer r.stop()
+
+				debounce := time.NewTimer(time.Hour)
+				debounce.Stop()
+				changed := make(map[string]bool)
+				for {
+					select {
+					case e := <-watcher.Events():
+						if e.Dir || ignore.Ignored(e.Path, e.Dir) || !matches(globs, e.Path) {
+							continue
+						}
+						changed[filepath.ToSlash(e.Path)] = true
+						if !debounce.Stop() {
+							select {
+							case <-debounce.C:
+							default:
+							}
+						}
+						debounce.Reset(c.Duration("debounce"))
+					case err := <-watcher.Errors():
+						say.Warning(err)
+					case <-debounce.C:
+						files := make([]string, 0, len(changed))
+						for file := range changed {
+							files = append(files, file)
+						}
+						sort.Strings(files)
+						changed = make(map[string]bool)
+						r.stop()
+						r.start(files)
+					case err := <-r.done:
+						r.finished(err)
+					case <-interrupt:
+						return nil
+					}
+				}
+			},
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{
+					Name:    "glob",
+					Aliases: []string{"g"},
+					Usage:   "Watches files matching the `PATTERN`. '**' matches any number of directories. Can be used multiple times.",
+					Value:   cli.NewStringSlice("**"),
+				},
+				&cli.StringFlag{
+					Name:    "run",
+					Aliases: []string{"r"},
+					Usage:   "Runs the `COMMAND` in a shell on every change",
+				},
+				&cli.StringSliceFlag{
+					Name:    "ignore",
+					Aliases: []string{"i"},
+					Usage:   "Ignores files matching the .gitignore `PATTERN`. Can be used multiple times.",
+				},
+				&cli.BoolFlag{
+					Name:  "no-gitignore",
+					Usage: "Watches files, which are ignored by .gitignore files",
+				},
+				&cli.DurationFlag{
+					Name:    "debounce",
+					Aliases: []string{"d"},
+					Usage:   "Waits until no file changed for `DURATION` before running the command",
+					Value:   300 * time.Millisecond,
+				},
+				&cli.BoolFlag{
+					Name:  "no-clear",
+					Usage: "Does not clear the screen before running the command",
+				},
+				&cli.DurationFlag{
+					Name:  "poll",
+					Usage: "Checks for changes every `DURATION` instead of using inotify, which doesn't work on some network filesystems",
+				},
+			},
+		},
+	}
+}
+
+// matches checks, if the path matches one of the globs
+func matches(globs []string, p string) bool {
+	p = path.Clean(filepath.ToSlash(p))
+	for _, glob := range globs {
+		if Match(glob, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadGitignores adds the .gitignore files of the current directory and of all directories on the way to the watched directories and in them.
+// Directories outside of the current directory are not searched, because the ignore rules only apply to the current directory.
+func loadGitignores(ignore *Ignore, dirs []string) error {
+	if err := ignore.AddFile(".gitignore"); err != nil {
+		return err
+	}
+	loaded := map[string]bool{".": true}
+	for _, dir := range dirs {
+		dir = filepath.Clean(dir)
+		if filepath.IsAbs(dir) || dir == ".." || strings.HasPrefix(dir, ".."+string(filepath.Separator)) {
+			continue
+		}
+		parts := strings.Split(dir, string(filepath.Separator))
+		for i := 1; i < len(parts); i++ {
+			parent := filepath.Join(parts[:i]...)
+			if !loaded[parent] {
+				loaded[parent] = true
+				if err := ignore.AddFile(filepath.Join(parent, ".gitignore")); err != nil {
+					return err
+				}
+			}
+		}
+		err := walkDirs([]string{dir}, ignore.Ignored, func(p string, info os.FileInfo) error {
+			if !info.IsDir() || loaded[p] {
+				return nil
+			}
+			loaded[p] = true
+			return ignore.AddFile(filepath.Join(p, ".gitignore"))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package watch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"src/**/*.go", "src/main.go", true},
+		{"src/**/*.go", "src/a/b/main.go", true},
+		{"src/**/*.go", "main.go", false},
+		{"src/**/*.go", "src/main.go.orig", false},
+		{"**", "a/b/c", true},
+		{"**/test", "test", true},
+		{"*.go", "a/main.go", false},
+		{"a/**/b/*", "a/x/y/b/c", true},
+		{"a/**/b/*", "a/x/y/c", false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBase(t *testing.T) {
+	for pattern, want := range map[string]string{"src/**/*.go": "src", "*.go": ".", "a/b/c.txt": "a/b/c.txt", "/tmp/*": "/tmp", "/*": "/"} {
+		if got := Base(pattern); got != want {
+			t.Errorf("Base(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestIgnore(t *testing.T) {
+	ignore := &Ignore{}
+	ignore.Add(".", []string{"# comment", "*.log", "!keep.log", "/build", "node_modules/", "docs/**/*.tmp"})
+	ignore.Add("sub", []string{"generated.go"})
+
+	tests := []struct {
+		name string
+		dir  bool
+		want bool
+	}{
+		{"debug.log", false, true},
+		{"a/b/debug.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build/out", false, true},
+		{"src/build", true, false},
+		{"node_modules", true, true},
+		{"a/node_modules/x.js", false, true},
+		{"node_modules", false, false},
+		{"docs/a/b.tmp", false, true},
+		{"sub/generated.go", false, true},
+		{"generated.go", false, false},
+		{".git/HEAD", false, true},
+		{"../other/debug.log", false, false},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := ignore.Ignored(tt.name, tt.dir); got != tt.want {
+			t.Errorf("Ignored(%q, %v) = %v, want %v", tt.name, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestRoots(t *testing.T) {
+	got := roots([]string{"src/**/*.go", "src/a/*.txt", "docs/**", "src/*.md"})
+	if len(got) != 2 || got[0] != "src" || got[1] != "docs" {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	for name, interval := range map[string]time.Duration{"native": 0, "poll": 20 * time.Millisecond} {
+		dir, err := ioutil.TempDir("", "watch")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		ignored := func(p string, _ bool) bool { return filepath.Base(p) == "ignored" }
+		if err := os.Mkdir(filepath.Join(dir, "ignored"), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		w, err := NewWatcher([]string{dir}, ignored, interval)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		_ = ioutil.WriteFile(filepath.Join(dir, "ignored", "file"), []byte("x"), 0644)
+		_ = os.MkdirAll(filepath.Join(dir, "new", "sub"), 0755)
+		// Give the watcher time to add the new directory, before a file is written into it
+		time.Sleep(50 * time.Millisecond)
+		want := filepath.Join(dir, "new", "sub", "file.txt")
+		_ = ioutil.WriteFile(want, []byte("x"), 0644)
+
+		timeout := time.After(5 * time.Second)
+	loop:
+		for {
+			select {
+			case e := <-w.Events():
+				if filepath.Base(filepath.Dir(e.Path)) == "ignored" {
+					t.Errorf("%s: got event for ignored file %s", name, e.Path)
+				}
+				if e.Path == want {
+					break loop
+				}
+			case err := <-w.Errors():
+				t.Fatalf("%s: %v", name, err)
+			case <-timeout:
+				t.Fatalf("%s: no event for %s", name, want)
+			}
+		}
+		w.Close()
+	}
+}
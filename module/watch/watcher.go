@@ -0,0 +1,150 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Event is a created, changed, moved or removed file or directory
+type Event struct {
+	Path string
+	Dir  bool
+}
+
+// Watcher reports changes of files in directory trees
+type Watcher interface {
+	Events() <-chan Event
+	Errors() <-chan error
+	Close() error
+}
+
+// IgnoreFunc decides, if a file or directory should not be watched
+type IgnoreFunc func(path string, dir bool) bool
+
+// NewWatcher watches the directories recursively. It uses inotify on Linux and polls on other platforms.
+// If interval is greater than zero, the directories are always polled in this interval.
+func NewWatcher(dirs []string, ignored IgnoreFunc, interval time.Duration) (Watcher, error) {
+	if ignored == nil {
+		ignored = func(string, bool) bool { return false }
+	}
+	if interval > 0 {
+		return newPoller(dirs, ignored, interval)
+	}
+	return newNativeWatcher(dirs, ignored)
+}
+
+// walkDirs walks the directory trees and skips ignored directories. Files and directories, which can't be read, are skipped.
+func walkDirs(dirs []string, ignored IgnoreFunc, fn func(path string, info os.FileInfo) error) error {
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				if p == dir {
+					return err
+				}
+				return nil
+			}
+			if p != dir && ignored(p, info.IsDir()) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			return fn(p, info)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+	mode    os.FileMode
+}
+
+// poller compares the modification times and sizes of all files in every interval
+type poller struct {
+	dirs    []string
+	ignored IgnoreFunc
+	files   map[string]fileState
+	events  chan Event
+	errors  chan error
+	done    chan struct{}
+}
+
+func newPoller(dirs []string, ignored IgnoreFunc, interval time.Duration) (*poller, error) {
+	p := &poller{
+		dirs:    dirs,
+		ignored: ignored,
+		events:  make(chan Event, 256),
+		errors:  make(chan error, 1),
+		done:    make(chan struct{}),
+	}
+	files, err := p.scan()
+	if err != nil {
+		return nil, err
+	}
+	p.files = files
+	go p.run(interval)
+	return p, nil
+}
+
+func (p *poller) scan() (map[string]fileState, error) {
+	files := make(map[string]fileState)
+	err := walkDirs(p.dirs, p.ignored, func(path string, info os.FileInfo) error {
+		files[path] = fileState{info.ModTime(), info.Size(), info.Mode()}
+		return nil
+	})
+	return files, err
+}
+
+func (p *poller) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+
+		files, err := p.scan()
+		if err != nil {
+			select {
+			case p.errors <- err:
+			default:
+			}
+			continue
+		}
+		for path, state := range files {
+			if old, ok := p.files[path]; !ok || old != state {
+				p.send(Event{Path: path, Dir: state.mode.IsDir()})
+			}
+		}
+		for path, state := range p.files {
+			if _, ok := files[path]; !ok {
+				p.send(Event{Path: path, Dir: state.mode.IsDir()})
+			}
+		}
+		p.files = files
+	}
+}
+
+func (p *poller) send(e Event) {
+	select {
+	case p.events <- e:
+	case <-p.done:
+	}
+}
+
+func (p *poller) Events() <-chan Event { return p.events }
+
+func (p *poller) Errors() <-chan error { return p.errors }
+
+func (p *poller) Close() error {
+	close(p.done)
+	return nil
+}
@@ -0,0 +1,150 @@
+// +build linux
+
+package watch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const inotifyMask = unix.IN_MODIFY | unix.IN_ATTRIB | unix.IN_CLOSE_WRITE | unix.IN_CREATE | unix.IN_DELETE |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_ONLYDIR
+
+// inotify watches every directory of the trees, because inotify itself is not recursive.
+// New directories are added as soon as they are created.
+type inotify struct {
+	fd      int
+	file    *os.File
+	ignored IgnoreFunc
+	mu      sync.Mutex
+	// dirs maps watch descriptors to the paths of the directories
+	dirs   map[int]string
+	events chan Event
+	errors chan error
+	done   chan struct{}
+}
+
+func newNativeWatcher(dirs []string, ignored IgnoreFunc) (Watcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize inotify: %w", err)
+	}
+	// The non-blocking descriptor is handled by the runtime poller, so that Close interrupts a pending Read
+	w := &inotify{
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		ignored: ignored,
+		dirs:    make(map[int]string),
+		events:  make(chan Event, 256),
+		errors:  make(chan error, 1),
+		done:    make(chan struct{}),
+	}
+	for _, dir := range dirs {
+		if err := w.addTree(dir, false); err != nil {
+			w.file.Close()
+			return nil, err
+		}
+	}
+	go w.read()
+	return w, nil
+}
+
+// addTree watches a directory and all directories in it. If report is set, events are sent for all contained files,
+// because they were moved into the tree or created before the watch was added.
+func (w *inotify) addTree(root string, report bool) error {
+	return walkDirs([]string{root}, w.ignored, func(path string, info os.FileInfo) error {
+		if report && path != root {
+			w.send(Event{Path: path, Dir: info.IsDir()})
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		wd, err := unix.InotifyAddWatch(w.fd, path, inotifyMask)
+		switch {
+		case errors.Is(err, unix.ENOSPC):
+			return fmt.Errorf("could not watch %s, the limit of inotify watches is reached. Raise fs.inotify.max_user_watches or ignore more directories", path)
+		case err != nil && path == root:
+			return fmt.Errorf("could not watch %s: %w", path, err)
+		case err != nil:
+			// Directories, which were removed in the meantime or can't be read, are skipped
+			return filepath.SkipDir
+		}
+		w.mu.Lock()
+		w.dirs[wd] = path
+		w.mu.Unlock()
+		return nil
+	})
+}
+
+func (w *inotify) read() {
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			select {
+			case <-w.done:
+			case w.errors <- err:
+			}
+			return
+		}
+
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			raw := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + unix.SizeofInotifyEvent
+			offset = nameStart + int(raw.Len)
+			name := string(bytes.TrimRight(buf[nameStart:offset], "\x00"))
+
+			if raw.Mask&unix.IN_Q_OVERFLOW != 0 {
+				select {
+				case w.errors <- errors.New("too many changes at once, some were not reported"):
+				default:
+				}
+				continue
+			}
+
+			w.mu.Lock()
+			dir, ok := w.dirs[int(raw.Wd)]
+			if raw.Mask&unix.IN_IGNORED != 0 {
+				delete(w.dirs, int(raw.Wd))
+			}
+			w.mu.Unlock()
+			if !ok || name == "" {
+				continue
+			}
+
+			e := Event{Path: filepath.Join(dir, name), Dir: raw.Mask&unix.IN_ISDIR != 0}
+			if e.Dir && raw.Mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 && !w.ignored(e.Path, true) {
+				if err := w.addTree(e.Path, true); err != nil {
+					select {
+					case w.errors <- err:
+					default:
+					}
+				}
+			}
+			w.send(e)
+		}
+	}
+}
+
+func (w *inotify) send(e Event) {
+	select {
+	case w.events <- e:
+	case <-w.done:
+	}
+}
+
+func (w *inotify) Events() <-chan Event { return w.events }
+
+func (w *inotify) Errors() <-chan error { return w.errors }
+
+func (w *inotify) Close() error {
+	close(w.done)
+	return w.file.Close()
+}
@@ -0,0 +1,12 @@
+// +build !linux
+
+package watch
+
+import "time"
+
+// pollInterval is used on platforms without a native watcher
+const pollInterval = 500 * time.Millisecond
+
+func newNativeWatcher(dirs []string, ignored IgnoreFunc) (Watcher, error) {
+	return newPoller(dirs, ignored, pollInterval)
+}
@@ -0,0 +1,20 @@
+package color
+
+import "github.com/dops-cli/dops/global/options"
+
+// Colorizer colors strings, if it's true. It's used by commands, which only color their output,
+// if it's written to a terminal.
+type Colorizer bool
+
+// NewColorizer returns a Colorizer, which colors strings if enabled is true, --raw is not set and stdout is a terminal
+func NewColorizer(enabled bool) Colorizer {
+	return Colorizer(enabled && !options.Raw && !NoColor)
+}
+
+// Paint colors s with a color function like SRed, or returns s unchanged if c is false
+func (c Colorizer) Paint(colorFunc func(string, ...interface{}) string, s string) string {
+	if !c {
+		return s
+	}
+	return colorFunc("%s", s)
+}
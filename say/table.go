@@ -0,0 +1,26 @@
+package say
+
+import (
+	"github.com/pterm/pterm"
+
+	"github.com/dops-cli/dops/say/color"
+)
+
+// Table renders data as a table. If hasHeader is true, the first row is the header.
+// The header and the separators are only colored, if c colors strings, so that --raw and files get plain text.
+func Table(data [][]string, hasHeader bool, c color.Colorizer) string {
+	table := pterm.DefaultTable.WithHasHeader(hasHeader)
+	if !c {
+		table = table.WithHeaderStyle(pterm.NewStyle()).WithSeparatorStyle(pterm.NewStyle())
+	}
+	return table.WithData(data).Srender()
+}
+
+// Section renders the title of a section, which is only colored if c colors strings
+func Section(title string, c color.Colorizer) string {
+	section := pterm.DefaultSection
+	if !c {
+		section.Style = pterm.NewStyle()
+	}
+	return section.Sprint(title)
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// Compression formats, which are detected by Decompress
+const (
+	CompressionNone  = ""
+	CompressionGzip  = "gzip"
+	CompressionBzip2 = "bzip2"
+	CompressionXz    = "xz"
+	CompressionZstd  = "zstd"
+)
+
+var compressionMagic = []struct {
+	format string
+	magic  []byte
+}{
+	{CompressionGzip, []byte{0x1f, 0x8b}},
+	{CompressionBzip2, []byte("BZh")},
+	{CompressionXz, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+	{CompressionZstd, []byte{0x28, 0xb5, 0x2f, 0xfd}},
+}
+
+// DetectCompression returns the compression format of data, which starts with a magic number
+func DetectCompression(head []byte) string {
+	for _, c := range compressionMagic {
+		if !bytes.HasPrefix(head, c.magic) {
+			continue
+		}
+		// The bzip2 magic is followed by the block size from '1' to '9', so that texts starting with "BZh" are not detected
+		if c.format == CompressionBzip2 && (len(head) < 4 || head[3] < '1' || head[3] > '9') {
+			continue
+		}
+		return c.format
+	}
+	return CompressionNone
+}
+
+// decompressReader closes the decompressor and the underlying reader
+type decompressReader struct {
+	io.Reader
+	closers []func() error
+}
+
+func (d *decompressReader) Close() error {
+	var first error
+	for _, c := range d.closers {
+		if err := c(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Decompress detects gzip, bzip2, xz and zstd compressed streams by their magic number and decompresses them transparently.
+// Uncompressed streams are returned as they are. Closing the returned reader closes r.
+func Decompress(r io.ReadCloser) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(r)
+	head, err := buffered.Peek(6)
+	if err != nil && err != io.EOF {
+		r.Close()
+		return nil, err
+	}
+
+	d := &decompressReader{closers: []func() error{r.Close}}
+	switch DetectCompression(head) {
+	case CompressionGzip:
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		d.Reader = gz
+		d.closers = append([]func() error{gz.Close}, d.closers...)
+	case CompressionBzip2:
+		d.Reader = bzip2.NewReader(buffered)
+	case CompressionXz:
+		x, err := xz.NewReader(buffered)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		d.Reader = x
+	case CompressionZstd:
+		z, err := zstd.NewReader(buffered)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		d.Reader = z
+		d.closers = append([]func() error{func() error { z.Close(); return nil }}, d.closers...)
+	default:
+		d.Reader = buffered
+	}
+	return d, nil
+}
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// bzip2Hello is "hello\n" compressed with bzip2, because the standard library can't write bzip2
+var bzip2Hello = []byte{66, 90, 104, 57, 49, 65, 89, 38, 83, 89, 193, 192, 128, 226, 0, 0, 1, 65, 0, 0, 16, 2, 68, 160, 0, 48, 205, 0, 195, 70, 41, 151, 23, 114, 69, 56, 80, 144, 193, 192, 128, 226}
+
+func compress(t *testing.T, format string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	var err error
+	switch format {
+	case CompressionGzip:
+		w = gzip.NewWriter(&buf)
+	case CompressionBzip2:
+		return bzip2Hello
+	case CompressionXz:
+		w, err = xz.NewWriter(&buf)
+	case CompressionZstd:
+		w, err = zstd.NewWriter(&buf)
+	default:
+		return data
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetectCompression(t *testing.T) {
+	tests := map[string]string{
+		"\x1f\x8b\x08":         CompressionGzip,
+		"BZh91AY":              CompressionBzip2,
+		"BZh1":                 CompressionBzip2,
+		"BZh":                  CompressionNone,
+		"BZh0":                 CompressionNone,
+		"BZhello":              CompressionNone,
+		"\xfd7zXZ\x00\x00":     CompressionXz,
+		"\xfd7zXZ":             CompressionNone,
+		"\x28\xb5\x2f\xfd\x00": CompressionZstd,
+		"hello":                CompressionNone,
+		"":                     CompressionNone,
+	}
+	for head, want := range tests {
+		if got := DetectCompression([]byte(head)); got != want {
+			t.Errorf("%q: got %q, want %q", head, got, want)
+		}
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	for _, format := range []string{CompressionNone, CompressionGzip, CompressionBzip2, CompressionXz, CompressionZstd} {
+		r, err := Decompress(ioutil.NopCloser(bytes.NewReader(compress(t, format, []byte("hello\n")))))
+		if err != nil {
+			t.Errorf("%s: %v", format, err)
+			continue
+		}
+		data, err := ioutil.ReadAll(r)
+		if err != nil || string(data) != "hello\n" {
+			t.Errorf("%s: got %q, %v", format, data, err)
+		}
+		if err := r.Close(); err != nil {
+			t.Errorf("%s: %v", format, err)
+		}
+	}
+
+	// Short and empty streams are returned as they are
+	for _, input := range []string{"", "BZh", "BZhello world"} {
+		r, err := Decompress(ioutil.NopCloser(bytes.NewReader([]byte(input))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, err := ioutil.ReadAll(r); err != nil || string(data) != input {
+			t.Errorf("%q: got %q, %v", input, data, err)
+		}
+	}
+}
+
+// closeRecorder records when it's closed
+type closeRecorder struct {
+	io.Reader
+	closed *[]string
+	err    error
+}
+
+func (c closeRecorder) Close() error {
+	*c.closed = append(*c.closed, "source")
+	return c.err
+}
+
+func TestDecompressClose(t *testing.T) {
+	for _, format := range []string{CompressionGzip, CompressionZstd} {
+		var closed []string
+		source := closeRecorder{Reader: bytes.NewReader(compress(t, format, []byte("hello\n"))), closed: &closed, err: errors.New("close failed")}
+		r, err := Decompress(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The decompressor is closed before the source
+		closers := r.(*decompressReader).closers
+		if len(closers) != 2 {
+			t.Fatalf("%s: got %d closers", format, len(closers))
+		}
+		if err := closers[0](); err != nil || len(closed) != 0 {
+			t.Errorf("%s: the source was closed before the decompressor: %v", format, err)
+		}
+		if err := r.Close(); err == nil || err.Error() != "close failed" {
+			t.Errorf("%s: got %v", format, err)
+		}
+		if !reflect.DeepEqual(closed, []string{"source"}) {
+			t.Errorf("%s: got %v", format, closed)
+		}
+	}
+
+	// The source is closed, if the stream is invalid
+	var closed []string
+	if _, err := Decompress(closeRecorder{Reader: bytes.NewReader([]byte("\x1f\x8b\x00")), closed: &closed}); err == nil {
+		t.Error("expected an error for an invalid gzip header")
+	}
+	if !reflect.DeepEqual(closed, []string{"source"}) {
+		t.Errorf("got %v", closed)
+	}
+}
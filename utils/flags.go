@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/dops-cli/dops/cli"
+)
+
+// StringList returns the values of a StringSliceFlag like Context.StringSlice.
+// Values are also split at commas, so that "-f a,b" is the same as "-f a -f b".
+func StringList(c *cli.Context, name string) []string {
+	var values []string
+	for _, value := range c.StringSlice(name) {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
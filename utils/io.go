@@ -1,13 +1,11 @@
 package utils
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
 	"os"
-	"strconv"
 	"strings"
 
 	"github.com/dops-cli/dops/say"
@@ -21,87 +19,110 @@ func ForEachLineInFile(path string, cb func(line string) error) error {
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		err = cb(scanner.Text())
-		if err != nil {
-			return err
-		}
-	}
+	return ForEachLine(file, cb)
+}
 
-	if err := scanner.Err(); err != nil {
+// WriteFile writes content to path. If append is true, the content will be appended to the file at path.
+// Otherwise the file is replaced atomically.
+func WriteFile(path string, content []byte, append bool) error {
+	sink, err := OpenOutput(OutputOptions{Path: path, Append: append})
+	if err != nil {
 		return err
 	}
-
-	return nil
+	if _, err := sink.Write(content); err != nil {
+		sink.Abort()
+		return err
+	}
+	return sink.Close()
 }
 
-// WriteFile writes content to path. If append is true, the content will be appended to the file at path.
-func WriteFile(path string, content []byte, append bool) {
-	if append {
-		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-		if err != nil {
-			say.Fatal(err)
-		}
-		defer f.Close()
-		if _, err := f.Write(content); err != nil {
-			say.Fatal(err)
-		}
-	} else {
-		err := ioutil.WriteFile(path, content, 0600)
-		if err != nil {
-			say.Fatal(err)
-		}
-	}
+// HTTPStatusError is returned, if an input URL responds with a status other than 200 OK
+type HTTPStatusError struct {
+	URL        string
+	Status     string
+	StatusCode int
+}
 
+func (e *HTTPStatusError) Error() string {
+	return "could not load " + e.URL + ": " + e.Status
 }
 
 // Input is used for flags, which accept input in any form. Input supports HTTP and HTTPS resources, file paths and stdin.
+// Compressed input is decompressed transparently. Input exits the program on errors, use ReadInput or InputReader to handle them.
 func Input(path string) string {
+	content, err := ReadInput(path)
+	if err != nil {
+		say.Fatal(err)
+	}
+	return content
+}
+
+// ReadInput works like Input, but returns errors instead of exiting
+func ReadInput(path string) (string, error) {
+	r, err := InputReader(path)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("could not read %s: %w", inputName(path), err)
+	}
+	return string(content), nil
+}
+
+// InputReader works like Input, but returns a reader instead of loading the whole input into memory.
+// Input compressed with gzip, bzip2, xz or zstd is decompressed transparently.
+// The returned reader has to be closed by the caller.
+func InputReader(path string) (io.ReadCloser, error) {
+	var r io.ReadCloser
 	if path == "" {
-		bytes, err := ioutil.ReadAll(os.Stdin)
-		if err != nil {
-			say.Fatal(err)
-		}
-		return string(bytes)
+		r = ioutil.NopCloser(os.Stdin)
 	} else if strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://") {
-		var client http.Client
-		resp, err := client.Get(path)
+		resp, err := http.Get(path) //nolint:gosec
 		if err != nil {
-			say.Fatal(err)
+			return nil, err
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == http.StatusOK {
-			bodyBytes, err := ioutil.ReadAll(resp.Body)
-			if err != nil {
-				log.Fatal(err)
-			}
-			bodyString := string(bodyBytes)
-			return bodyString
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, &HTTPStatusError{URL: path, Status: resp.Status, StatusCode: resp.StatusCode}
 		}
-
-		return "Error: " + strconv.Itoa(resp.StatusCode)
+		r = resp.Body
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		r = f
 	}
 
-	file, err := ioutil.ReadFile(path)
+	d, err := Decompress(r)
 	if err != nil {
-		say.Fatal(err)
+		return nil, fmt.Errorf("could not decompress %s: %w", inputName(path), err)
+	}
+	return d, nil
+}
+
+func inputName(path string) string {
+	if path == "" {
+		return "stdin"
 	}
-	return string(file)
+	return path
 }
 
 // Output is used for flags, which accept output paths. If append is true, the output will be appended to the file at path.
+// Output exits the program on errors, commands should use a Sink to handle them.
 func Output(path string, lines []string, append bool) {
-	if path == "" {
-		for _, s := range lines {
-			say.Text(s)
-		}
-	} else {
-		var out string
-		for _, s := range lines {
-			out += fmt.Sprintf("%v", s) + "\n"
-		}
-		WriteFile(path, []byte(out), append)
+	sink, err := OpenOutput(OutputOptions{Path: path, Append: append})
+	if err != nil {
+		say.Fatal(err)
+	}
+	if err := sink.WriteLines(lines); err != nil {
+		sink.Abort()
+		say.Fatal(err)
+	}
+	if err := sink.Close(); err != nil {
+		say.Fatal(err)
 	}
 }
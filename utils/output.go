@@ -0,0 +1,63 @@
+package utils
+
+import "github.com/dops-cli/dops/cli"
+
+// OutputFlags returns the flags, which configure the output of a command.
+// They are read by CommandOutput.
+func OutputFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "Writes to `FILE`, if not set it writes to stdout. Files ending with .gz are compressed",
+		},
+		&cli.BoolFlag{
+			Name:    "append",
+			Aliases: []string{"a"},
+			Usage:   "append instead of overriding output",
+		},
+		&cli.BoolFlag{
+			Name:  "tee",
+			Usage: "Writes to stdout as well, if --output is set",
+		},
+	}
+}
+
+// RecordFlags returns the flags, which configure how commands with record based output format each record.
+// They are read by CommandOutput.
+func RecordFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "template",
+			Usage: "Formats each record with the Go `TEMPLATE`, see the description for the fields of a record",
+		},
+		&cli.BoolFlag{
+			Name:  "jsonl",
+			Usage: "Writes each record as a single line of JSON",
+		},
+	}
+}
+
+// CommandOutput opens the output of a command, which is configured by OutputFlags and RecordFlags
+func CommandOutput(c *cli.Context) (*Sink, error) {
+	return OpenOutput(OutputOptions{
+		Path:      c.String("output"),
+		Append:    c.Bool("append"),
+		Tee:       c.Bool("tee"),
+		Template:  c.String("template"),
+		JSONLines: c.Bool("jsonl"),
+	})
+}
+
+// WriteOutput writes lines to the output of a command, which is configured by OutputFlags
+func WriteOutput(c *cli.Context, lines []string) error {
+	out, err := CommandOutput(c)
+	if err != nil {
+		return err
+	}
+	if err := out.WriteLines(lines); err != nil {
+		out.Abort()
+		return err
+	}
+	return out.Close()
+}
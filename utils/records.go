@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// MaxRecordSize is the maximum size of a single line or record in bytes
+var MaxRecordSize = 64 * 1024 * 1024
+
+// RecordReader iterates over the records of a stream without loading the whole stream into memory.
+// It's used like a bufio.Scanner:
+//
+//	lines := utils.NewLineReader(r)
+//	for lines.Next() {
+//		fmt.Println(lines.Number(), lines.Text())
+//	}
+//	if err := lines.Err(); err != nil {
+//		return err
+//	}
+type RecordReader struct {
+	scanner  *bufio.Scanner
+	number   int
+	offset   int64
+	consumed int64
+}
+
+// NewLineReader returns a RecordReader, which splits r into lines.
+// Line endings ("\n" or "\r\n") are not part of the lines.
+func NewLineReader(r io.Reader) *RecordReader {
+	return newRecordReader(r, bufio.ScanLines)
+}
+
+// NewRecordReader returns a RecordReader, which splits r into records separated by delimiter, like "\x00" or "\n\n".
+// The delimiter is not part of the records.
+func NewRecordReader(r io.Reader, delimiter string) *RecordReader {
+	if delimiter == "\n" {
+		return NewLineReader(r)
+	}
+	sep := []byte(delimiter)
+	return newRecordReader(r, func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		if i := bytes.Index(data, sep); i >= 0 {
+			return i + len(sep), data[:i], nil
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	})
+}
+
+func newRecordReader(r io.Reader, split bufio.SplitFunc) *RecordReader {
+	rr := &RecordReader{scanner: bufio.NewScanner(r)}
+	// The initial buffer must not be larger than MaxRecordSize, otherwise it would allow longer records
+	size := 64 * 1024
+	if size > MaxRecordSize {
+		size = MaxRecordSize
+	}
+	rr.scanner.Buffer(make([]byte, size), MaxRecordSize)
+	rr.scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := split(data, atEOF)
+		if token != nil {
+			rr.offset = rr.consumed
+		}
+		rr.consumed += int64(advance)
+		return advance, token, err
+	})
+	return rr
+}
+
+// Next advances to the next record and returns false at the end of the stream or on errors
+func (r *RecordReader) Next() bool {
+	if !r.scanner.Scan() {
+		return false
+	}
+	r.number++
+	return true
+}
+
+// Text returns the current record
+func (r *RecordReader) Text() string {
+	return r.scanner.Text()
+}
+
+// Bytes returns the current record. The slice is only valid until the next call of Next.
+func (r *RecordReader) Bytes() []byte {
+	return r.scanner.Bytes()
+}
+
+// Number returns the number of the current record, starting at 1
+func (r *RecordReader) Number() int {
+	return r.number
+}
+
+// Offset returns the byte offset of the current record in the stream, starting at 0
+func (r *RecordReader) Offset() int64 {
+	return r.offset
+}
+
+// Err returns the first error, which occurred while reading
+func (r *RecordReader) Err() error {
+	err := r.scanner.Err()
+	if err == bufio.ErrTooLong {
+		return fmt.Errorf("record %d is longer than %d bytes: %w", r.number+1, MaxRecordSize, err)
+	}
+	return err
+}
+
+// ForEachLine runs cb over all lines of r
+func ForEachLine(r io.Reader, cb func(line string) error) error {
+	lines := NewLineReader(r)
+	for lines.Next() {
+		if err := cb(lines.Text()); err != nil {
+			return err
+		}
+	}
+	return lines.Err()
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type record struct {
+	Number int
+	Offset int64
+	Text   string
+}
+
+func readRecords(t *testing.T, r *RecordReader) []record {
+	t.Helper()
+	var records []record
+	for r.Next() {
+		records = append(records, record{r.Number(), r.Offset(), r.Text()})
+	}
+	if err := r.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestLineReader(t *testing.T) {
+	tests := map[string][]record{
+		"":             nil,
+		"a":            {{1, 0, "a"}},
+		"a\nbc\n":      {{1, 0, "a"}, {2, 2, "bc"}},
+		"a\r\nbc\r\nd": {{1, 0, "a"}, {2, 3, "bc"}, {3, 7, "d"}},
+		"\n\nx\n":      {{1, 0, ""}, {2, 1, ""}, {3, 2, "x"}},
+		"é\r\nü\n":     {{1, 0, "é"}, {2, 4, "ü"}},
+	}
+	for input, want := range tests {
+		if got := readRecords(t, NewLineReader(strings.NewReader(input))); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: got %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestRecordReader(t *testing.T) {
+	tests := []struct {
+		input, delimiter string
+		want             []record
+	}{
+		{"a\x00bc\x00", "\x00", []record{{1, 0, "a"}, {2, 2, "bc"}}},
+		{"a\nb\n\nc\n", "\n\n", []record{{1, 0, "a\nb"}, {2, 5, "c\n"}}},
+		{"a||b||||c", "||", []record{{1, 0, "a"}, {2, 3, "b"}, {3, 6, ""}, {4, 8, "c"}}},
+		{"a\r\nb", "\n", []record{{1, 0, "a"}, {2, 3, "b"}}},
+	}
+	for _, tt := range tests {
+		if got := readRecords(t, NewRecordReader(strings.NewReader(tt.input), tt.delimiter)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q split at %q: got %v, want %v", tt.input, tt.delimiter, got, tt.want)
+		}
+	}
+
+	// Offsets stay correct, when records cross the boundaries of the internal buffer
+	long := strings.Repeat("x", 100*1024)
+	got := readRecords(t, NewRecordReader(strings.NewReader(long+"--"+long+"--y"), "--"))
+	if len(got) != 3 || got[1].Offset != int64(len(long)+2) || got[2].Offset != int64(2*len(long)+4) || got[2].Text != "y" {
+		t.Errorf("got %d records", len(got))
+	}
+}
+
+func TestRecordTooLong(t *testing.T) {
+	defer func(size int) { MaxRecordSize = size }(MaxRecordSize)
+	MaxRecordSize = 16
+
+	lines := NewLineReader(strings.NewReader("short\n" + strings.Repeat("x", 100) + "\nend\n"))
+	var read []string
+	for lines.Next() {
+		read = append(read, lines.Text())
+	}
+	err := lines.Err()
+	if !reflect.DeepEqual(read, []string{"short"}) || !errors.Is(err, bufio.ErrTooLong) {
+		t.Fatalf("got %v, %v", read, err)
+	}
+	if !strings.Contains(err.Error(), "record 2") {
+		t.Errorf("the error doesn't contain the record number: %v", err)
+	}
+}
+
+func TestForEachLine(t *testing.T) {
+	var lines []string
+	err := ForEachLine(strings.NewReader("a\nb\nc\n"), func(line string) error {
+		lines = append(lines, line)
+		if line == "b" {
+			return errors.New("stop")
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "stop" || !reflect.DeepEqual(lines, []string{"a", "b"}) {
+		t.Errorf("got %v, %v", lines, err)
+	}
+}
@@ -0,0 +1,233 @@
+package utils
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/dops-cli/dops/say/color"
+)
+
+// OutputOptions configure where and how a Sink writes its output
+type OutputOptions struct {
+	// Path is the file the output is written to, the output is written to stdout if it's empty.
+	// Files ending with .gz are compressed with gzip.
+	Path string
+	// Append appends to Path instead of replacing it
+	Append bool
+	// Tee writes to stdout as well, if Path is set
+	Tee bool
+	// Template is a Go template, which is executed for every record
+	Template string
+	// JSONLines writes every record as a single line of JSON
+	JSONLines bool
+	// Mode is the permission of new files, the default is 0600. Replaced files keep their permission.
+	Mode os.FileMode
+}
+
+// defaultFileMode is the permission of new output files, which may contain secrets like cookies
+const defaultFileMode = 0600
+
+// Sink writes the output of a command.
+// If the output replaces a regular file, it is written to a temporary file first, which is renamed on Close,
+// so that the file is never left half written.
+type Sink struct {
+	writers   []io.Writer
+	file      *os.File
+	buffer    *bufio.Writer
+	gzip      *gzip.Writer
+	temp      string
+	path      string
+	mode      os.FileMode
+	template  *template.Template
+	jsonLines bool
+}
+
+// templateFuncs are available in output templates
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// OpenOutput opens a Sink. The Sink has to be closed to write the output.
+func OpenOutput(options OutputOptions) (*Sink, error) {
+	s := &Sink{jsonLines: options.JSONLines}
+
+	if options.Template != "" {
+		t, err := template.New("output").Funcs(templateFuncs).Parse(options.Template)
+		if err != nil {
+			return nil, err
+		}
+		s.template = t
+	}
+
+	if options.Path == "" || options.Tee {
+		s.writers = append(s.writers, color.Output)
+	}
+	if options.Path == "" {
+		return s, nil
+	}
+
+	s.mode = options.Mode
+	if s.mode == 0 {
+		s.mode = defaultFileMode
+	}
+	var err error
+	if options.Append {
+		s.file, err = os.OpenFile(options.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, s.mode)
+	} else {
+		err = s.create(options.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.buffer = bufio.NewWriter(s.file)
+	var w io.Writer = s.buffer
+	if strings.HasSuffix(options.Path, ".gz") {
+		s.gzip = gzip.NewWriter(s.buffer)
+		w = s.gzip
+	}
+	s.writers = append(s.writers, w)
+	return s, nil
+}
+
+// create opens the file, which replaces path on Close. Symbolic links are resolved, so that the file they point to
+// is replaced instead of the link. Files, which are not regular files like /dev/stdout or named pipes, are written directly.
+func (s *Sink) create(path string) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if info, err := os.Stat(path); err == nil && !info.Mode().IsRegular() {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, s.mode)
+		if err != nil {
+			return err
+		}
+		s.file = file
+		return nil
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	s.file = file
+	s.temp = file.Name()
+	s.path = path
+	return nil
+}
+
+// Write writes p unchanged to all outputs
+func (s *Sink) Write(p []byte) (int, error) {
+	for _, w := range s.writers {
+		if _, err := w.Write(p); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// WriteLine writes a single line
+func (s *Sink) WriteLine(line string) error {
+	_, err := io.WriteString(s, line+"\n")
+	return err
+}
+
+// WriteLines writes multiple lines
+func (s *Sink) WriteLines(lines []string) error {
+	for _, line := range lines {
+		if err := s.WriteLine(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Records returns true if records are formatted by a template or as JSON lines instead of their text
+func (s *Sink) Records() bool {
+	return s.template != nil || s.jsonLines
+}
+
+// WriteRecord writes a record. The record is passed to the template or encoded as JSON, if one of them is requested,
+// otherwise the text representation of the record is written.
+func (s *Sink) WriteRecord(text string, record interface{}) error {
+	switch {
+	case s.template != nil:
+		var b strings.Builder
+		if err := s.template.Execute(&b, record); err != nil {
+			return err
+		}
+		text = b.String()
+	case s.jsonLines:
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		text = string(data)
+	}
+	return s.WriteLine(text)
+}
+
+// Close flushes the output and replaces the output file
+func (s *Sink) Close() error {
+	if s.file == nil {
+		return nil
+	}
+
+	var err error
+	if s.gzip != nil {
+		err = s.gzip.Close()
+	}
+	if flushErr := s.buffer.Flush(); err == nil {
+		err = flushErr
+	}
+	if s.temp != "" && err == nil {
+		err = s.file.Sync()
+	}
+	if closeErr := s.file.Close(); err == nil {
+		err = closeErr
+	}
+	s.file = nil
+	if s.temp == "" {
+		return err
+	}
+
+	if err == nil {
+		mode := s.mode
+		if info, statErr := os.Stat(s.path); statErr == nil {
+			mode = info.Mode().Perm()
+		}
+		err = os.Chmod(s.temp, mode)
+	}
+	if err == nil {
+		err = os.Rename(s.temp, s.path)
+	}
+	if err != nil {
+		os.Remove(s.temp)
+	}
+	return err
+}
+
+// Abort closes the Sink without replacing the output file, if it was opened without Append
+func (s *Sink) Abort() {
+	if s.file == nil {
+		return
+	}
+	s.file.Close()
+	s.file = nil
+	if s.temp != "" {
+		os.Remove(s.temp)
+	}
+}
@@ -0,0 +1,248 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// files returns the names of all files in dir
+func files(t *testing.T, dir string) []string {
+	t.Helper()
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	return names
+}
+
+func TestSinkReplacesAtomically(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+	if err := ioutil.WriteFile(path, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := OpenOutput(OutputOptions{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteLines([]string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+	// The file is only replaced, when the sink is closed
+	if got := readFile(t, path); got != "old\n" {
+		t.Errorf("the file was changed before Close: %q", got)
+	}
+	if len(files(t, dir)) != 2 {
+		t.Errorf("expected a temporary file, got %v", files(t, dir))
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "a\nb\n" {
+		t.Errorf("got %q", got)
+	}
+	if got := files(t, dir); len(got) != 1 {
+		t.Errorf("the temporary file was not removed: %v", got)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("closing twice: %v", err)
+	}
+}
+
+func TestSinkAbort(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+	if err := ioutil.WriteFile(path, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := OpenOutput(OutputOptions{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteLine("new"); err != nil {
+		t.Fatal(err)
+	}
+	s.Abort()
+	s.Abort()
+	if got := readFile(t, path); got != "old\n" {
+		t.Errorf("got %q", got)
+	}
+	if got := files(t, dir); len(got) != 1 {
+		t.Errorf("the temporary file was not removed: %v", got)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("closing after Abort: %v", err)
+	}
+
+	// Aborting a new file doesn't create it
+	s, err = OpenOutput(OutputOptions{Path: filepath.Join(dir, "new.txt")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Abort()
+	if got := files(t, dir); len(got) != 1 {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestSinkSymlinksAndDevices(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links and /dev/null are not available on windows")
+	}
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.txt")
+	link := filepath.Join(dir, "link.txt")
+	if err := ioutil.WriteFile(target, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("target.txt", link); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file behind a link is replaced, the link is kept
+	if err := WriteFile(link, []byte("new\n"), false); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("the link was replaced: %v", err)
+	}
+	if got := readFile(t, target); got != "new\n" {
+		t.Errorf("got %q", got)
+	}
+	if got := files(t, dir); len(got) != 2 {
+		t.Errorf("the temporary file was not removed: %v", got)
+	}
+
+	// Devices are written directly
+	if err := WriteFile(os.DevNull, []byte("discarded\n"), false); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSinkAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	for _, line := range []string{"a", "b"} {
+		if err := WriteFile(path, []byte(line+"\n"), true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := readFile(t, path); got != "a\nb\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestSinkGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt.gz")
+	if err := WriteFile(path, []byte("hello\n"), false); err != nil {
+		t.Fatal(err)
+	}
+	r, err := gzip.NewReader(bytes.NewReader([]byte(readFile(t, path))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ioutil.ReadAll(r); err != nil || string(data) != "hello\n" {
+		t.Errorf("got %q, %v", data, err)
+	}
+}
+
+func TestSinkMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not supported on windows")
+	}
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		existing os.FileMode
+		options  OutputOptions
+		want     os.FileMode
+	}{
+		{"new", 0, OutputOptions{}, 0600},
+		{"new-append", 0, OutputOptions{Append: true}, 0600},
+		{"new-mode", 0, OutputOptions{Mode: 0640}, 0640},
+		{"existing", 0644, OutputOptions{}, 0644},
+		{"existing-mode", 0604, OutputOptions{Mode: 0600}, 0604},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if tt.existing != 0 {
+			if err := ioutil.WriteFile(path, nil, tt.existing); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chmod(path, tt.existing); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tt.options.Path = path
+		s, err := OpenOutput(tt.options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != tt.want {
+			t.Errorf("%s: got %o, want %o", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSinkRecords(t *testing.T) {
+	dir := t.TempDir()
+	record := struct {
+		Name string `json:"name"`
+		Tags []string
+	}{"x", []string{"a", "b"}}
+
+	tests := map[string]OutputOptions{
+		"text\n":                               {},
+		"x: a,b A\n":                           {Template: `{{.Name}}: {{join .Tags ","}} {{upper "a"}}`},
+		`{"name":"x","Tags":["a","b"]}` + "\n": {JSONLines: true},
+	}
+	for want, options := range tests {
+		options.Path = filepath.Join(dir, "out")
+		s, err := OpenOutput(options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Records() != (options.Template != "" || options.JSONLines) {
+			t.Errorf("%q: Records() = %v", want, s.Records())
+		}
+		if err := s.WriteRecord("text", record); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if got := readFile(t, options.Path); got != want {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+
+	if _, err := OpenOutput(OutputOptions{Template: "{{.Name"}); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+}
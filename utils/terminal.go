@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/pterm/pterm"
+)
+
+// ClearScreen clears the terminal
+func ClearScreen() {
+	pterm.Println("\033[2J")
+	clear := exec.Command("clear")
+	clear.Stdout = os.Stdout
+	_ = clear.Run()
+}